@@ -0,0 +1,113 @@
+package jseq
+
+import (
+	"iter"
+	"slices"
+)
+
+// AccessFilter strips disallowed fields from JSON values,
+// according to lists of allowed and denied [Pattern]s.
+//
+// A location is allowed if it is inside a subtree matched by some Allow pattern
+// (or if Allow is empty),
+// and it is not inside a subtree matched by any Deny pattern.
+// Deny takes precedence over Allow.
+// Objects and arrays that are not themselves allowed
+// but that contain allowed locations
+// are kept, with only their allowed members.
+// (Removing array elements renumbers the ones that follow.)
+type AccessFilter struct {
+	Allow []Pattern
+	Deny  []Pattern
+}
+
+// Apply returns the parts of v permitted by f.
+// The input is not modified,
+// though the result may share structure with it.
+// The boolean result is false if v as a whole is denied.
+func (f *AccessFilter) Apply(v any) (any, bool) {
+	result, ok := f.apply(v, nil)
+	if ok {
+		return result, true
+	}
+	if f.denied(nil) {
+		return nil, false
+	}
+
+	// Nothing in v is allowed, but v itself isn't denied.
+	// Produce an empty container of the right type.
+	switch v.(type) {
+	case map[string]any:
+		return map[string]any{}, true
+	case []any:
+		return []any{}, true
+	}
+	return nil, false
+}
+
+// Records consumes a sequence of pointer/value pairs as produced by [Values]
+// and yields each top-level value filtered by [AccessFilter.Apply].
+// Values that are denied as a whole are skipped.
+func (f *AccessFilter) Records(values iter.Seq2[Pointer, any]) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		for pointer, val := range values {
+			if len(pointer) > 0 {
+				continue
+			}
+			result, ok := f.Apply(val)
+			if !ok {
+				continue
+			}
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+func (f *AccessFilter) apply(v any, pointer Pointer) (any, bool) {
+	if f.denied(pointer) {
+		return nil, false
+	}
+
+	allowed := f.allowed(pointer)
+	if allowed && !slices.ContainsFunc(f.Deny, func(p Pattern) bool { return p.MatchBelow(pointer) }) {
+		return v, true
+	}
+	if !allowed && !slices.ContainsFunc(f.Allow, func(p Pattern) bool { return p.MatchBelow(pointer) }) {
+		return nil, false
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		result := make(map[string]any)
+		for key, val := range v {
+			if newVal, ok := f.apply(val, append(slices.Clip(pointer), key)); ok {
+				result[key] = newVal
+			}
+		}
+		return result, allowed || len(result) > 0
+
+	case []any:
+		var result []any
+		for i, val := range v {
+			if newVal, ok := f.apply(val, append(slices.Clip(pointer), i)); ok {
+				result = append(result, newVal)
+			}
+		}
+		return result, allowed || len(result) > 0
+
+	case Expanded:
+		return f.apply(v.Value, pointer)
+	}
+
+	return v, allowed
+}
+
+func (f *AccessFilter) allowed(pointer Pointer) bool {
+	return len(f.Allow) == 0 || slices.ContainsFunc(f.Allow, func(p Pattern) bool { return p.MatchPrefix(pointer) })
+}
+
+func (f *AccessFilter) denied(pointer Pointer) bool {
+	return slices.ContainsFunc(f.Deny, func(p Pattern) bool { return p.MatchPrefix(pointer) })
+}
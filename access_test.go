@@ -0,0 +1,83 @@
+package jseq_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestAccessFilter(t *testing.T) {
+	val := map[string]any{
+		"id": "u1",
+		"profile": map[string]any{
+			"name":  "Ann",
+			"email": "ann@example.com",
+			"ssn":   "123-45-6789",
+		},
+		"orders": []any{
+			map[string]any{"sku": "a", "card": "4111"},
+			map[string]any{"sku": "b", "card": "4222"},
+		},
+		"internal": map[string]any{"score": jseq.Int(7)},
+	}
+
+	cases := []struct {
+		name        string
+		allow, deny []string
+		want        any
+	}{{
+		name: "deny_only",
+		deny: []string{"/internal", "/profile/ssn", "/orders/*/card"},
+		want: map[string]any{
+			"id":      "u1",
+			"profile": map[string]any{"name": "Ann", "email": "ann@example.com"},
+			"orders":  []any{map[string]any{"sku": "a"}, map[string]any{"sku": "b"}},
+		},
+	}, {
+		name:  "allow_only",
+		allow: []string{"/id", "/profile/name", "/orders/*/sku"},
+		want: map[string]any{
+			"id":      "u1",
+			"profile": map[string]any{"name": "Ann"},
+			"orders":  []any{map[string]any{"sku": "a"}, map[string]any{"sku": "b"}},
+		},
+	}, {
+		name:  "allow_and_deny",
+		allow: []string{"/profile"},
+		deny:  []string{"/**/ssn"},
+		want: map[string]any{
+			"profile": map[string]any{"name": "Ann", "email": "ann@example.com"},
+		},
+	}, {
+		name:  "nothing_allowed",
+		allow: []string{"/nope"},
+		want:  map[string]any{},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &jseq.AccessFilter{Allow: patterns(tc.allow), Deny: patterns(tc.deny)}
+			got, ok := f.Apply(val)
+			if !ok {
+				t.Fatal("record denied")
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	f := &jseq.AccessFilter{Deny: patterns([]string{""})}
+	if _, ok := f.Apply(val); ok {
+		t.Error("got record, want it denied")
+	}
+}
+
+func patterns(strs []string) []jseq.Pattern {
+	var result []jseq.Pattern
+	for _, s := range strs {
+		result = append(result, jseq.MustParsePattern(s))
+	}
+	return result
+}
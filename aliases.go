@@ -0,0 +1,82 @@
+package jseq
+
+import "golang.org/x/text/cases"
+
+// KeyAliases is a [KeyOption] that treats the keys in each of the given sets as equivalent.
+// For example,
+//
+//	KeyAliases([]string{"user_id", "userId", "uid"})
+//
+// allows a [Pattern] or [Pointer.Locate] mentioning any one of those keys
+// to match an object member with any of the others.
+//
+// Aliases combine with [FoldCase] and [NormalizeKeys]:
+// if those are also in effect,
+// a key is an alias when it matches a member of the set under them.
+func KeyAliases(sets ...[]string) KeyOption {
+	return func(m *keyMatcher) {
+		m.aliases = append(m.aliases, sets...)
+	}
+}
+
+// group returns the index of the alias set containing key, if any.
+func (m keyMatcher) group(key string) (int, bool) {
+	if len(m.aliases) == 0 {
+		return 0, false
+	}
+	key = m.aliasKey(key)
+	for i, set := range m.aliases {
+		for _, alias := range set {
+			if m.aliasKey(alias) == key {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// aliasKey returns the form of key used for comparing with aliases.
+func (m keyMatcher) aliasKey(key string) string {
+	key = m.canon(key)
+	if m.fold {
+		key = cases.Fold().String(key)
+	}
+	return key
+}
+
+// CanonicalKeys is an [Option] that causes [Values] to rename object keys
+// that appear in any of the given alias sets
+// to the first key in the set.
+// For example, with
+//
+//	CanonicalKeys([]string{"user_id", "userId", "uid"})
+//
+// an input member "uid": 7 is produced at a pointer ending in "user_id",
+// and its containing object has a "user_id" key.
+// If an object contains more than one alias of the same key,
+// the one appearing last in the input wins.
+//
+// Keys are compared exactly.
+// Empty sets are ignored.
+func CanonicalKeys(sets ...[]string) Option {
+	return func(c *config) {
+		if c.canonicalKeys == nil {
+			c.canonicalKeys = make(map[string]string)
+		}
+		for _, set := range sets {
+			if len(set) == 0 {
+				continue
+			}
+			for _, alias := range set[1:] {
+				c.canonicalKeys[alias] = set[0]
+			}
+		}
+	}
+}
+
+func (p *parser) canonicalKey(key string) string {
+	if canon, ok := p.canonicalKeys[key]; ok {
+		return canon
+	}
+	return key
+}
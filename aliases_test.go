@@ -0,0 +1,64 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestKeyAliases(t *testing.T) {
+	aliases := jseq.KeyAliases([]string{"user_id", "userId", "uid"})
+
+	val := map[string]any{"uid": jseq.Int(7)}
+	got, err := jseq.Pointer{"userId"}.Locate(val, aliases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, jseq.Int(7)) {
+		t.Errorf("got %v, want 7", got)
+	}
+
+	pat := jseq.MustParsePattern("/events/*/user_id", aliases)
+	if !pat.Match(jseq.Pointer{"events", 3, "uid"}) {
+		t.Error("pattern did not match alias")
+	}
+	if pat.Match(jseq.Pointer{"events", 3, "UID"}) {
+		t.Error("pattern matched alias with different case")
+	}
+
+	pat = jseq.MustParsePattern("/events/*/user_id", aliases, jseq.FoldCase())
+	if !pat.Match(jseq.Pointer{"events", 3, "UID"}) {
+		t.Error("case-folding pattern did not match alias with different case")
+	}
+}
+
+func TestCanonicalKeys(t *testing.T) {
+	const inp = `{"uid": 1, "name": "a"} {"userId": 2}`
+
+	got := collect(t, strings.NewReader(inp), jseq.CanonicalKeys([]string{"user_id", "userId", "uid"}))
+	want := []pair{
+		{p: jseq.Pointer{"user_id"}, v: jseq.Int(1)},
+		{p: jseq.Pointer{"name"}, v: "a"},
+		{p: nil, v: map[string]any{"user_id": jseq.Int(1), "name": "a"}},
+		{p: jseq.Pointer{"user_id"}, v: jseq.Int(2)},
+		{p: nil, v: map[string]any{"user_id": jseq.Int(2)}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCanonicalKeysEmptySet(t *testing.T) {
+	const inp = `{"uid": 1}`
+
+	got := collect(t, strings.NewReader(inp), jseq.CanonicalKeys([]string{}, []string{"user_id", "uid"}, nil))
+	want := []pair{
+		{p: jseq.Pointer{"user_id"}, v: jseq.Int(1)},
+		{p: nil, v: map[string]any{"user_id": jseq.Int(1)}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package jseq
+
+import (
+	"encoding/json"
+	"encoding/json/jsontext"
+	"iter"
+	"maps"
+	"math/big"
+	"reflect"
+	"slices"
+)
+
+// Path returns a generalized form of p
+// in which every array index is replaced by a wildcard.
+// It is rendered as a JSON pointer string,
+// so Pointer{"items", 3, "price"} becomes "/items/*/price".
+//
+// Paths are how the analysis functions in this package
+// aggregate values across many records and many array elements.
+// (Note that an object key of "*" is not distinguished from an array index.)
+func (p Pointer) Path() string {
+	var result jsontext.Pointer
+	for _, tok := range p {
+		switch tok := tok.(type) {
+		case string:
+			result = result.AppendToken(tok)
+		case int:
+			result = result.AppendToken("*")
+		}
+	}
+	return string(result)
+}
+
+// TypeName returns the name of the JSON type of v:
+// "object", "array", "string", "number", "boolean", or "null".
+// It returns the empty string if v is not a JSON value of a type produced by [Values].
+func TypeName(v any) string {
+	switch v := v.(type) {
+	case map[string]any, *Object:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case Number, float64, json.Number, *big.Int, *big.Float:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil, Null:
+		return "null"
+	case Expanded:
+		return TypeName(v.Value)
+	case Container:
+		if v.Kind == '[' {
+			return "array"
+		}
+		return "object"
+	}
+	if b, ok := rawBytes(v); ok {
+		return rawTypeName(b)
+	}
+	if reflect.ValueOf(v).Kind() == reflect.Map {
+		// A map produced by the MapKeys option.
+		return "object"
+	}
+	return ""
+}
+
+// TypeConflict describes a [Pointer.Path] whose values have inconsistent JSON types
+// across a stream of records.
+// See [TypeConflicts].
+type TypeConflict struct {
+	Path string
+
+	// Examples maps each type name observed at Path (see [TypeName])
+	// to the ordinals of the first few records in which it appeared.
+	// Record ordinals count top-level values from zero.
+	Examples map[string][]int
+}
+
+// maxExamples is the number of example record ordinals kept per type in a [TypeConflict].
+const maxExamples = 3
+
+// TypeConflicts consumes a sequence of pointer/value pairs as produced by [Values]
+// and reports the paths (see [Pointer.Path])
+// at which values of more than one JSON type were seen.
+// Null does not count as a conflicting type,
+// but where there is a conflict,
+// the records containing nulls are included among the examples.
+//
+// The result is sorted by path.
+func TypeConflicts(values iter.Seq2[Pointer, any]) []TypeConflict {
+	var (
+		examples = make(map[string]map[string][]int)
+		record   int
+	)
+
+	for pointer, val := range values {
+		if len(pointer) == 0 {
+			record++
+			continue
+		}
+
+		path := pointer.Path()
+		byType := examples[path]
+		if byType == nil {
+			byType = make(map[string][]int)
+			examples[path] = byType
+		}
+		typ := TypeName(val)
+		ordinals := byType[typ]
+		if len(ordinals) < maxExamples && (len(ordinals) == 0 || ordinals[len(ordinals)-1] != record) {
+			byType[typ] = append(ordinals, record)
+		}
+	}
+
+	var result []TypeConflict
+	for _, path := range slices.Sorted(maps.Keys(examples)) {
+		byType := examples[path]
+		nonNull := len(byType)
+		if _, ok := byType["null"]; ok {
+			nonNull--
+		}
+		if nonNull < 2 {
+			continue
+		}
+		result = append(result, TypeConflict{Path: path, Examples: byType})
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestPath(t *testing.T) {
+	p := jseq.Pointer{"items", 3, "a/b"}
+	if got := p.Path(); got != "/items/*/a~1b" {
+		t.Errorf("got %s, want /items/*/a~1b", got)
+	}
+}
+
+func TestTypeConflicts(t *testing.T) {
+	const inp = `
+{"price": 1, "tags": ["a"], "n": null}
+{"price": "2", "tags": ["b", 3], "n": 1}
+{"price": 3, "tags": [], "n": null}
+{"price": null, "tags": [[]]}
+`
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+	got := jseq.TypeConflicts(values)
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+
+	want := []jseq.TypeConflict{{
+		Path: "/price",
+		Examples: map[string][]int{
+			"number": {0, 2},
+			"string": {1},
+			"null":   {3},
+		},
+	}, {
+		Path: "/tags/*",
+		Examples: map[string][]int{
+			"string": {0, 1},
+			"number": {1},
+			"array":  {3},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
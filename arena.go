@@ -0,0 +1,118 @@
+package jseq
+
+// arenaChunk is the number of items in each chunk an [Arena] allocates.
+const arenaChunk = 4096
+
+// Arena supplies storage for the values produced by [Values] in bulk
+// (see [InArena]).
+// The zero value is an empty arena ready to use.
+//
+// An Arena is not safe for concurrent use:
+// it must serve only one call to Values at a time.
+// Its storage is freed as a unit,
+// when the arena and all values built in it are no longer referenced.
+type Arena struct {
+	elts  []any // unused tail of the current chunk of array elements
+	ints  []int64
+	uints []uint64
+
+	// stack holds the elements of the arrays being parsed,
+	// which are copied to elts when their lengths are known.
+	stack []any
+}
+
+// InArena is an [Option] that causes [Values]
+// to allocate the storage of the values it builds from the given [Arena],
+// in large chunks instead of one piece at a time.
+// This reduces the work of the garbage collector in batch jobs
+// that decode many small values and discard them together:
+// there are fewer, larger objects to track,
+// and a value in the arena keeps its whole chunk alive
+// until every value sharing the chunk is unreachable.
+// For that reason,
+// retaining a few values from a large batch retains much more memory than they need;
+// use [Clone] to copy any that must outlive the rest.
+//
+// Array elements and the integer parts of [Number]s come from the arena.
+// Go maps cannot be allocated this way,
+// so objects are not affected.
+// Arrays in the arena have no spare capacity,
+// so appending to one copies it.
+func InArena(a *Arena) Option {
+	return func(c *config) {
+		c.arena = a
+	}
+}
+
+// mark returns the position in a's element stack
+// at which the elements of a new array begin.
+func (a *Arena) mark() int {
+	return len(a.stack)
+}
+
+// push adds the next element of the innermost array being parsed.
+func (a *Arena) push(val any) {
+	a.stack = append(a.stack, val)
+}
+
+// elements returns the array whose elements were pushed since mark returned base,
+// and removes them from the stack.
+func (a *Arena) elements(base int) []any {
+	n := len(a.stack) - base
+	if n == 0 {
+		return nil
+	}
+	var result []any
+	if n > arenaChunk/4 {
+		result = make([]any, n) // too big to share a chunk
+	} else {
+		if len(a.elts) < n {
+			a.elts = make([]any, arenaChunk)
+		}
+		result = a.elts[:n:n]
+		a.elts = a.elts[n:]
+	}
+	copy(result, a.stack[base:])
+	a.truncate(base)
+	return result
+}
+
+// truncate discards the elements pushed since mark returned base.
+func (a *Arena) truncate(base int) {
+	clear(a.stack[base:]) // don't retain the values
+	a.stack = a.stack[:base]
+}
+
+// int64 returns a pointer to a copy of i,
+// from the arena if a is not nil.
+func (a *Arena) int64(i int64) *int64 {
+	if a == nil {
+		p := new(int64)
+		*p = i
+		return p
+	}
+	if len(a.ints) == 0 {
+		a.ints = make([]int64, arenaChunk)
+	}
+	p := &a.ints[0]
+	*p = i
+	a.ints = a.ints[1:]
+	return p
+}
+
+// uint64 returns a pointer to a copy of u,
+// from the arena if a is not nil.
+func (a *Arena) uint64(u uint64) *uint64 {
+	if a == nil {
+		p := new(uint64)
+		*p = u
+		return p
+	}
+	if len(a.uints) == 0 {
+		a.uints = make([]uint64, arenaChunk)
+	}
+	p := &a.uints[0]
+	*p = u
+	a.uints = a.uints[1:]
+	return p
+}
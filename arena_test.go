@@ -0,0 +1,92 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestInArena(t *testing.T) {
+	const inp = `
+		{"a": [1, 2, [3, 4, []], {"b": [5, -6.5]}], "c": 7}
+		[[], [8], "x", null, 18446744073709551615]
+		[1, 2, 3`
+
+	collect := func(opts ...jseq.Option) ([]any, error) {
+		tokens, _ := jseq.Tokens(strings.NewReader(inp))
+		values, errptr := jseq.Values(tokens, opts...)
+		var got []any
+		for pointer, val := range values {
+			if len(pointer) == 0 {
+				got = append(got, val)
+			}
+		}
+		return got, *errptr
+	}
+
+	want, wantErr := collect()
+	if wantErr == nil {
+		t.Fatal("got no error for truncated input")
+	}
+
+	var arena jseq.Arena
+	for range 2 { // the same arena serves successive parses
+		got, err := collect(jseq.InArena(&arena))
+		if err == nil || err.Error() != wantErr.Error() {
+			t.Errorf("got error %v, want %v", err, wantErr)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+
+	t.Run("no_spare_capacity", func(t *testing.T) {
+		tokens, _ := jseq.Tokens(strings.NewReader(`[[1, 2], [3, 4]]`))
+		values, errptr := jseq.Values(tokens, jseq.InArena(&arena))
+		var top []any
+		for pointer, val := range values {
+			if len(pointer) == 0 {
+				top = val.([]any)
+			}
+		}
+		if err := *errptr; err != nil {
+			t.Fatal(err)
+		}
+
+		// Appending to one array must not overwrite its neighbor in the arena.
+		first := top[0].([]any)
+		_ = append(first, jseq.Int(99))
+		if want := []any{jseq.Int(3), jseq.Int(4)}; !reflect.DeepEqual(top[1], want) {
+			t.Errorf("got %v, want %v", top[1], want)
+		}
+	})
+
+	t.Run("early_stop", func(t *testing.T) {
+		// Stopping partway through an array must not leave its elements
+		// to be mistaken for those of a later array.
+		tokens, _ := jseq.Tokens(strings.NewReader(`[1, 2, 3]`))
+		values, _ := jseq.Values(tokens, jseq.InArena(&arena))
+		for pointer := range values {
+			if len(pointer) == 1 && pointer[0] == 1 {
+				break
+			}
+		}
+
+		tokens, _ = jseq.Tokens(strings.NewReader(`[4]`))
+		values, errptr := jseq.Values(tokens, jseq.InArena(&arena))
+		var got any
+		for pointer, val := range values {
+			if len(pointer) == 0 {
+				got = val
+			}
+		}
+		if err := *errptr; err != nil {
+			t.Fatal(err)
+		}
+		if want := []any{jseq.Int(4)}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
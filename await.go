@@ -0,0 +1,81 @@
+package jseq
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"sync"
+)
+
+// Result is the outcome of [Await].
+type Result struct {
+	Value any
+	Err   error
+}
+
+// Await watches a sequence of pointer/value pairs,
+// as from [Values],
+// for the first value at ptr.
+// It returns a sequence that passes through all the pairs of values unchanged,
+// for consumption by the rest of the program,
+// and a channel that receives a single [Result] and is then closed.
+//
+// The channel receives the value at ptr as soon as it is complete,
+// while the rest of the stream continues to flow.
+// If the sequence ends without producing a value at ptr,
+// the channel receives an error wrapping [io.EOF].
+// If the consumer stops early without a value at ptr,
+// the channel receives a different error.
+//
+// The channel receives nothing until the returned sequence is consumed.
+// If it is consumed more than once,
+// only the first result is sent.
+//
+// A typical use is to grab a value from the header of a large document,
+// such as a session token,
+// and hand it to another goroutine
+// without waiting for the whole document to be parsed.
+func Await(values iter.Seq2[Pointer, any], ptr Pointer) (iter.Seq2[Pointer, any], <-chan Result) {
+	var (
+		ch   = make(chan Result, 1)
+		once sync.Once
+	)
+	send := func(r Result) {
+		once.Do(func() {
+			ch <- r
+			close(ch)
+		})
+	}
+
+	f := func(yield func(Pointer, any) bool) {
+		var (
+			sent  bool
+			ended bool
+		)
+
+		defer func() {
+			if sent {
+				return
+			}
+			if ended {
+				send(Result{Err: fmt.Errorf("no value at %q: %w", ptr.Text(), io.EOF)})
+			} else {
+				send(Result{Err: fmt.Errorf("stream stopped before value at %q", ptr.Text())})
+			}
+		}()
+
+		for pointer, val := range values {
+			if !sent && slices.Equal(pointer, ptr) {
+				send(Result{Value: val})
+				sent = true
+			}
+			if !yield(pointer, val) {
+				return
+			}
+		}
+		ended = true
+	}
+
+	return f, ch
+}
@@ -0,0 +1,86 @@
+package jseq_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestAwait(t *testing.T) {
+	const inp = `{"header": {"session": "abc123"}, "body": [1, 2, 3]}`
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+	values, ch := jseq.Await(values, jseq.Pointer{"header", "session"})
+
+	var (
+		resolved  bool
+		bodyCount int
+	)
+	for pointer := range values {
+		if len(pointer) == 0 || pointer[0] != "body" {
+			continue
+		}
+		if !resolved {
+			// The result should be available before the body is streamed.
+			select {
+			case res := <-ch:
+				if res.Err != nil {
+					t.Fatal(res.Err)
+				}
+				if res.Value != "abc123" {
+					t.Errorf("got %v, want abc123", res.Value)
+				}
+				resolved = true
+			default:
+				t.Fatal("value not available before body")
+			}
+		}
+		bodyCount++
+	}
+	if err := errors.Join(*errptr1, *errptr2); err != nil {
+		t.Fatal(err)
+	}
+	if bodyCount != 4 {
+		t.Errorf("got %d body values, want 4", bodyCount)
+	}
+}
+
+func TestAwaitMissing(t *testing.T) {
+	tokens, _ := jseq.Tokens(strings.NewReader(`{"a": 1}`))
+	values, _ := jseq.Values(tokens)
+	values, ch := jseq.Await(values, jseq.Pointer{"b"})
+	for range values {
+	}
+	res := <-ch
+	if !errors.Is(res.Err, io.EOF) {
+		t.Errorf("got error %v, want io.EOF", res.Err)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("channel not closed")
+	}
+}
+
+func TestAwaitTwice(t *testing.T) {
+	values, ch := jseq.Await(jseq.Walk(map[string]any{"a": "x"}), jseq.Pointer{"a"})
+	for range 2 {
+		var n int
+		for range values {
+			n++
+		}
+		if n != 2 {
+			t.Errorf("got %d pairs, want 2", n)
+		}
+	}
+
+	res := <-ch
+	if res.Err != nil || res.Value != "x" {
+		t.Errorf("got %v, %v; want x, nil", res.Value, res.Err)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("channel not closed after result")
+	}
+}
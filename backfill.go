@@ -0,0 +1,149 @@
+package jseq
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// FieldDefault is a default value for the field at Pointer.
+// See [Backfill].
+type FieldDefault struct {
+	Pointer Pointer
+	Value   any
+}
+
+// Backfill returns a [Transform] that inserts default values into records
+// (top-level values)
+// at locations where they are absent,
+// so that downstream consumers can rely on those fields being present.
+// Present values, including null, are never overwritten.
+// Missing objects along the path to a default are created,
+// but a default is skipped if the path runs through a non-object
+// or a missing array element.
+//
+// Pairs inside a record pass through unchanged.
+// When defaults are inserted into a record,
+// the transform produces pairs for each new subtree
+// and for each changed object containing one
+// before producing the updated record itself,
+// preserving the children-before-parents order of [Values].
+//
+// Each record receives its own copy of each default (see [Clone]).
+func Backfill(defaults ...FieldDefault) Transform {
+	return func(values iter.Seq2[Pointer, any]) iter.Seq2[Pointer, any] {
+		return func(yield func(Pointer, any) bool) {
+			for pointer, val := range values {
+				if len(pointer) > 0 {
+					if !yield(pointer, val) {
+						return
+					}
+					continue
+				}
+				if !backfillRecord(val, defaults, yield) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// backfillRecord applies defaults to rec
+// and yields the resulting pairs, ending with the updated record.
+func backfillRecord(rec any, defaults []FieldDefault, yield func(Pointer, any) bool) bool {
+	var added []Pointer // the shallowest newly created location for each default applied
+
+	for _, d := range defaults {
+		if len(d.Pointer) == 0 {
+			continue
+		}
+
+		// Find the shallowest location along the path that is absent.
+		var (
+			n   = 1
+			err error
+		)
+		for ; n <= len(d.Pointer); n++ {
+			var found bool
+			_, found, err = d.Pointer[:n].find(rec, keyMatcher{}) // a present null (nil under V1Values) counts
+			if err != nil || !found {
+				break
+			}
+		}
+		if err != nil {
+			continue // the path runs through a non-object, such as a null
+		}
+		if n > len(d.Pointer) {
+			continue // present
+		}
+		updated, err := d.Pointer.Set(rec, Clone(d.Value))
+		if err != nil {
+			continue
+		}
+		added = append(added, d.Pointer[:n])
+		rec = updated
+	}
+
+	if len(added) == 0 {
+		return yield(nil, rec)
+	}
+
+	// A location inside another new subtree is produced as part of that subtree.
+	added = slices.DeleteFunc(added, func(ptr Pointer) bool {
+		return slices.ContainsFunc(added, func(other Pointer) bool {
+			return len(other) < len(ptr) && slices.Equal(other, ptr[:len(other)])
+		})
+	})
+
+	for _, ptr := range added {
+		sub, _ := ptr.Locate(rec)
+		for p, v := range Walk(sub) {
+			if !yield(append(slices.Clip(ptr), p...), v) {
+				return false
+			}
+		}
+	}
+
+	// Then the changed containers, deepest first.
+	var parents []Pointer
+	for _, ptr := range added {
+		for n := len(ptr) - 1; n > 0; n-- {
+			if !slices.ContainsFunc(parents, func(p Pointer) bool { return slices.Equal(p, ptr[:n]) }) {
+				parents = append(parents, ptr[:n])
+			}
+		}
+	}
+	slices.SortStableFunc(parents, func(a, b Pointer) int { return cmp.Compare(len(b), len(a)) })
+	for _, ptr := range parents {
+		v, _ := ptr.Locate(rec)
+		if !yield(ptr, v) {
+			return false
+		}
+	}
+
+	return yield(nil, rec)
+}
+
+// DefaultsFrom produces a [FieldDefault] for each field in the defaults document doc,
+// for use with [Backfill].
+// Objects in doc are descended into,
+// so that each of their members supplies its own default;
+// any other value (including an array) is a default as a whole.
+func DefaultsFrom(doc any) []FieldDefault {
+	var result []FieldDefault
+	defaultsFrom(decodedForm(doc), nil, &result)
+	return result
+}
+
+func defaultsFrom(doc any, pointer Pointer, result *[]FieldDefault) {
+	m, ok := asObject(doc)
+	if !ok || len(m) == 0 {
+		if len(pointer) > 0 {
+			*result = append(*result, FieldDefault{Pointer: pointer, Value: doc})
+		}
+		return
+	}
+	for _, key := range SortedKeys(m) {
+		defaultsFrom(decodedForm(m[key]), append(slices.Clip(pointer), key), result)
+	}
+}
@@ -0,0 +1,113 @@
+package jseq_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestBackfill(t *testing.T) {
+	defaults := jseq.DefaultsFrom(map[string]any{
+		"status": "active",
+		"prefs":  map[string]any{"theme": "light", "lang": "en"},
+		"tags":   []any{},
+	})
+
+	const inp = `
+{"status": "banned", "prefs": {"theme": "dark"}, "tags": ["x"]}
+{"status": null, "other": 1}
+[1]
+`
+
+	var got []string
+	sink := jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+		b, err := jseq.Marshal(val)
+		if err != nil {
+			return err
+		}
+		got = append(got, fmt.Sprintf("%s %s", pointer.Text(), b))
+		return nil
+	})
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), sink, nil, jseq.Backfill(defaults...)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		// First record: only prefs/lang is missing.
+		`/status "banned"`,
+		`/prefs/theme "dark"`,
+		`/prefs {"theme":"dark"}`,
+		`/tags/0 "x"`,
+		`/tags ["x"]`,
+		`/prefs/lang "en"`,
+		`/prefs {"lang":"en","theme":"dark"}`,
+		` {"prefs":{"lang":"en","theme":"dark"},"status":"banned","tags":["x"]}`,
+
+		// Second record: null status is kept; prefs and tags are created.
+		`/status null`,
+		`/other 1`,
+		`/prefs/lang "en"`,
+		`/prefs/theme "light"`,
+		`/prefs {"lang":"en","theme":"light"}`,
+		`/tags []`,
+		` {"other":1,"prefs":{"lang":"en","theme":"light"},"status":null,"tags":[]}`,
+
+		// Third record: not an object, so no defaults apply.
+		`/0 1`,
+		` [1]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestBackfillV1(t *testing.T) {
+	// Under V1Values a JSON null is a Go nil,
+	// which must still count as present.
+	defaults := []jseq.FieldDefault{
+		{Pointer: jseq.Pointer{"status"}, Value: "active"},
+		{Pointer: jseq.Pointer{"prefs", "theme"}, Value: "light"},
+	}
+
+	var got []any
+	sink := jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+		if len(pointer) == 0 {
+			got = append(got, val)
+		}
+		return nil
+	})
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(`{"status": null, "prefs": null} {}`)), sink, []jseq.Option{jseq.V1Values()}, jseq.Backfill(defaults...)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []any{
+		map[string]any{"status": nil, "prefs": nil},
+		map[string]any{"status": "active", "prefs": map[string]any{"theme": "light"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDefaultsFromObject(t *testing.T) {
+	var (
+		prefs jseq.Object
+		doc   jseq.Object
+	)
+	prefs.Set("theme", "light")
+	doc.Set("status", "active")
+	doc.Set("prefs", &prefs)
+
+	got := jseq.DefaultsFrom(&doc)
+	want := []jseq.FieldDefault{
+		{Pointer: jseq.Pointer{"prefs", "theme"}, Value: "light"},
+		{Pointer: jseq.Pointer{"status"}, Value: "active"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package jseq
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MemoryBudget is an [Option] that limits the memory [Values] may hold
+// in the arrays and objects of each record (top-level value) while building it.
+// When the approximate number of bytes held exceeds budget,
+// the sequence ends with a [*MemoryBudgetError].
+// A budget of zero or less means no limit.
+//
+// The estimate counts the bytes of strings, numbers, and object keys,
+// plus a fixed overhead for each array element, object member, and container,
+// approximating the memory used by the values Values produces.
+// It is reset at the start of each record.
+// Values that are not built,
+// as with the [LeafOnly], [Unmaterialized], and [PreOrder] options,
+// are not counted.
+//
+// This protects programs such as multi-tenant services
+// from untrusted inputs that would otherwise exhaust memory.
+// See also [MaxDepth].
+func MemoryBudget(budget int64) Option {
+	return func(c *config) {
+		c.memoryBudget = budget
+	}
+}
+
+// MemoryBudgetError is the error produced by [MemoryBudget]
+// when a record exceeds the budget.
+type MemoryBudgetError struct {
+	// Budget is the limit, in bytes.
+	Budget int64
+
+	// Pointer locates the value that caused the budget to be exceeded
+	// within its top-level value.
+	Pointer Pointer
+}
+
+func (e *MemoryBudgetError) Error() string {
+	return fmt.Sprintf("memory budget of %d bytes exceeded at %q", e.Budget, e.Pointer.Text())
+}
+
+// Approximate sizes, in bytes, of the parts of values produced by Values.
+const (
+	sizeIface     = 16 // an interface value, as in an array element
+	sizeMember    = 48 // an object member besides its key and value: map entry and string header
+	sizeNumber    = 56 // a Number besides its raw text
+	sizeContainer = 48 // an array or object besides its contents
+)
+
+// hold accounts for val being stored in an array or object under construction,
+// as the member named key (empty for an array element).
+func (p *parser) hold(pointer Pointer, key string, val any) error {
+	if p.memoryBudget <= 0 {
+		return nil
+	}
+
+	n := int64(sizeIface)
+	if len(pointer) > 0 {
+		if _, ok := pointer[len(pointer)-1].(string); ok {
+			n += sizeMember + int64(len(key))
+		}
+	}
+	switch val := val.(type) {
+	case string:
+		n += int64(len(val))
+	case Number:
+		n += sizeNumber + int64(len(val.raw))
+	case []any, map[string]any, *Object:
+		n += sizeContainer // contents were counted as they were stored
+	}
+
+	p.held += n
+	if p.held > p.memoryBudget {
+		return &MemoryBudgetError{Budget: p.memoryBudget, Pointer: slices.Clone(pointer)}
+	}
+	return nil
+}
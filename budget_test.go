@@ -0,0 +1,96 @@
+package jseq_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	big := strings.Repeat("x", 1000)
+
+	cases := []struct {
+		name        string
+		inp         string
+		budget      int64
+		opts        []jseq.Option
+		wantRecords int
+		wantPointer jseq.Pointer // nil for no error
+	}{
+		{
+			name:        "within",
+			inp:         `{"a": "` + big + `"} {"b": "` + big + `"}`,
+			budget:      2000,
+			wantRecords: 2,
+		},
+		{
+			name:        "object",
+			inp:         `{"a": "small"} {"a": "small", "b": "` + big + `"}`,
+			budget:      500,
+			wantRecords: 1,
+			wantPointer: jseq.Pointer{"b"},
+		},
+		{
+			name:        "nested array",
+			inp:         `{"a": [1, 2, "` + big + `"]}`,
+			budget:      500,
+			wantPointer: jseq.Pointer{"a", 2},
+		},
+		{
+			name:        "accumulated",
+			inp:         `[` + strings.Repeat(`"`+big[:100]+`", `, 20) + `0]`,
+			budget:      1000,
+			wantPointer: jseq.Pointer{8},
+		},
+		{
+			name:        "unlimited",
+			inp:         `["` + big + `"]`,
+			wantRecords: 1,
+		},
+		{
+			name:        "unmaterialized",
+			inp:         `["` + big + `"]`,
+			budget:      10,
+			opts:        []jseq.Option{jseq.Unmaterialized()},
+			wantRecords: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(tc.inp))
+			values, errptr := jseq.Values(tokens, append(tc.opts, jseq.MemoryBudget(tc.budget))...)
+
+			var records int
+			for pointer := range values {
+				if len(pointer) == 0 {
+					records++
+				}
+			}
+			if records != tc.wantRecords {
+				t.Errorf("got %d records, want %d", records, tc.wantRecords)
+			}
+
+			err := *errptr
+			if tc.wantPointer == nil {
+				if err != nil {
+					t.Errorf("got error %v, want none", err)
+				}
+				return
+			}
+			var berr *jseq.MemoryBudgetError
+			if !errors.As(err, &berr) {
+				t.Fatalf("got error %v, want a MemoryBudgetError", err)
+			}
+			if berr.Budget != tc.budget {
+				t.Errorf("got budget %d, want %d", berr.Budget, tc.budget)
+			}
+			if !reflect.DeepEqual(berr.Pointer, tc.wantPointer) {
+				t.Errorf("got pointer %v, want %v", berr.Pointer, tc.wantPointer)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+)
+
+// TokenError describes a malformed token stream.
+// See [CheckTokens].
+type TokenError struct {
+	// Pointer locates the failure point within its top-level value.
+	Pointer Pointer
+
+	// Index is the position of the offending token in the stream, counting from zero.
+	// At the end of the stream it is the total number of tokens.
+	Index int
+
+	// Got is the kind of the offending token,
+	// or zero if the stream ended prematurely.
+	Got jsontext.Kind
+
+	// Want describes what was expected instead.
+	Want string
+}
+
+func (e *TokenError) Error() string {
+	got := "end of input"
+	if e.Got != 0 {
+		got = fmt.Sprintf("%s token", e.Got)
+	}
+	return fmt.Sprintf("token %d at %q: got %s, want %s", e.Index, e.Pointer.Text(), got, e.Want)
+}
+
+// Unwrap returns [io.ErrUnexpectedEOF] if the stream ended prematurely,
+// and nil otherwise.
+func (e *TokenError) Unwrap() error {
+	if e.Got == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// CheckTokens passes through the tokens in its input,
+// checking that they form a well-formed sequence of JSON values:
+// that arrays and objects are properly nested,
+// and that object members are keyed by strings.
+// It is meant for token streams from sources other than [jsontext.Decoder],
+// which checks this already.
+//
+// The output sequence ends at the first malformed token.
+// After consuming it,
+// the caller may check for errors by dereferencing the returned error pointer.
+// Errors are of type [*TokenError].
+func CheckTokens(tokens iter.Seq[jsontext.Token]) (iter.Seq[jsontext.Token], *error) {
+	var err error
+
+	f := func(yield func(jsontext.Token) bool) {
+		var (
+			c     checker
+			index int
+		)
+		for tok := range tokens {
+			if want, ok := c.check(tok); !ok {
+				err = &TokenError{Pointer: slices.Clone(c.pointer), Index: index, Got: tok.Kind(), Want: want}
+				return
+			}
+			if !yield(tok) {
+				return
+			}
+			index++
+		}
+		if len(c.stack) > 0 {
+			err = &TokenError{Pointer: slices.Clone(c.pointer), Index: index, Want: c.want()}
+		}
+	}
+	return f, &err
+}
+
+type checker struct {
+	stack   []checkerFrame
+	pointer Pointer
+}
+
+type checkerFrame struct {
+	kind     jsontext.Kind // '{' or '['
+	inMember bool          // for objects: whether a key has been read and its value is pending
+	n        int           // for arrays: number of elements read
+}
+
+// check advances the state of c with the given token.
+// If the token is not allowed here,
+// it returns false and a description of what was expected.
+func (c *checker) check(tok jsontext.Token) (string, bool) {
+	kind := tok.Kind()
+	if len(c.stack) > 0 {
+		top := &c.stack[len(c.stack)-1]
+		switch {
+		case top.kind == '{' && !top.inMember:
+			switch kind {
+			case '"':
+				top.inMember = true
+				c.pointer = append(c.pointer, tok.String())
+				return "", true
+			case '}':
+				c.pop()
+				return "", true
+			}
+			return c.want(), false
+
+		case top.kind == '[' && kind == ']':
+			c.pop()
+			return "", true
+
+		case top.kind == '[':
+			c.pointer = append(c.pointer, top.n)
+		}
+	}
+
+	switch kind {
+	case 'n', 'f', 't', '"', '0':
+		c.endValue()
+		return "", true
+	case '{', '[':
+		c.stack = append(c.stack, checkerFrame{kind: kind})
+		return "", true
+	}
+	if len(c.stack) > 0 && c.stack[len(c.stack)-1].kind == '[' {
+		c.pointer = c.pointer[:len(c.pointer)-1]
+	}
+	return c.want(), false
+}
+
+func (c *checker) pop() {
+	c.stack = c.stack[:len(c.stack)-1]
+	c.endValue()
+}
+
+func (c *checker) endValue() {
+	if len(c.stack) == 0 {
+		return
+	}
+	top := &c.stack[len(c.stack)-1]
+	switch top.kind {
+	case '{':
+		top.inMember = false
+	case '[':
+		top.n++
+	}
+	c.pointer = c.pointer[:len(c.pointer)-1]
+}
+
+// want describes what c expects next.
+func (c *checker) want() string {
+	if len(c.stack) == 0 {
+		return "value"
+	}
+	top := c.stack[len(c.stack)-1]
+	switch {
+	case top.kind == '{' && !top.inMember:
+		return "string or }"
+	case top.kind == '{':
+		return "value"
+	default:
+		return "value or ]"
+	}
+}
@@ -0,0 +1,82 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestCheckTokens(t *testing.T) {
+	cases := []struct {
+		name   string
+		tokens []jsontext.Token
+		want   *jseq.TokenError
+	}{{
+		name: "ok",
+		tokens: []jsontext.Token{
+			jsontext.BeginObject, jsontext.String("a"), jsontext.BeginArray, jsontext.Int(1), jsontext.Null, jsontext.EndArray, jsontext.EndObject,
+			jsontext.True,
+		},
+	}, {
+		name: "non_string_key",
+		tokens: []jsontext.Token{
+			jsontext.BeginObject, jsontext.String("a"), jsontext.Int(1), jsontext.Int(2),
+		},
+		want: &jseq.TokenError{Pointer: jseq.Pointer{}, Index: 3, Got: '0', Want: "string or }"},
+	}, {
+		name: "mismatched_close",
+		tokens: []jsontext.Token{
+			jsontext.BeginObject, jsontext.String("a"), jsontext.BeginArray, jsontext.String("x"), jsontext.EndObject,
+		},
+		want: &jseq.TokenError{Pointer: jseq.Pointer{"a"}, Index: 4, Got: '}', Want: "value or ]"},
+	}, {
+		name: "close_without_value",
+		tokens: []jsontext.Token{
+			jsontext.BeginObject, jsontext.String("a"), jsontext.EndObject,
+		},
+		want: &jseq.TokenError{Pointer: jseq.Pointer{"a"}, Index: 2, Got: '}', Want: "value"},
+	}, {
+		name: "truncated",
+		tokens: []jsontext.Token{
+			jsontext.BeginArray, jsontext.Int(1), jsontext.BeginObject,
+		},
+		want: &jseq.TokenError{Pointer: jseq.Pointer{1}, Index: 3, Want: "string or }"},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checked, errptr := jseq.CheckTokens(slices.Values(tc.tokens))
+			var n int
+			for range checked {
+				n++
+			}
+			if tc.want == nil {
+				if *errptr != nil {
+					t.Fatal(*errptr)
+				}
+				if n != len(tc.tokens) {
+					t.Errorf("got %d tokens, want %d", n, len(tc.tokens))
+				}
+				return
+			}
+			var got *jseq.TokenError
+			if !errors.As(*errptr, &got) {
+				t.Fatalf("got error %v, want a TokenError", *errptr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %#v, want %#v", got, tc.want)
+			}
+			if n != tc.want.Index {
+				t.Errorf("got %d tokens before the error, want %d", n, tc.want.Index)
+			}
+			if truncated := tc.want.Got == 0; errors.Is(got, io.ErrUnexpectedEOF) != truncated {
+				t.Errorf("errors.Is(err, io.ErrUnexpectedEOF) is %v, want %v", !truncated, truncated)
+			}
+		})
+	}
+}
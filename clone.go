@@ -0,0 +1,90 @@
+package jseq
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/json/jsontext"
+	"math/big"
+	"reflect"
+)
+
+// Clone returns a deep copy of v,
+// which must be a value of the kind produced by [Values].
+// Arrays and objects are copied recursively,
+// allocated at their final size.
+// Strings, bools, numbers, and nulls are immutable
+// and are returned as-is,
+// except for *[big.Int] and *[big.Float] numbers (see [NumbersAsBig]),
+// which are copied.
+// Undecoded JSON values
+// (of type [jsontext.Value] or [encoding/json.RawMessage])
+// are copied byte for byte.
+//
+// Clone is useful for taking ownership of values
+// (such as aggregated arrays and objects from a [Values] stream)
+// that may be shared with other parts of a program.
+func Clone(v any) any {
+	switch v := v.(type) {
+	case nil, string, bool, Number, float64, json.Number, Null:
+		return v
+
+	case *big.Int:
+		return new(big.Int).Set(v)
+
+	case *big.Float:
+		return new(big.Float).Copy(v)
+
+	case []any:
+		if v == nil {
+			return v
+		}
+		result := make([]any, len(v))
+		for i, elt := range v {
+			result[i] = Clone(elt)
+		}
+		return result
+
+	case map[string]any:
+		if v == nil {
+			return v
+		}
+		result := make(map[string]any, len(v))
+		for key, val := range v {
+			result[key] = Clone(val)
+		}
+		return result
+
+	case *Object:
+		if v == nil {
+			return v
+		}
+		result := v.clone()
+		for key, val := range result.m {
+			result.m[key] = Clone(val)
+		}
+		return result
+
+	case Expanded:
+		return Expanded{Value: Clone(v.Value), Original: v.Original}
+	}
+
+	if v, ok := v.(jsontext.Value); ok {
+		return jsontext.Value(bytes.Clone(v))
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map && !rv.IsNil() {
+		// A map produced by the MapKeys option.
+		result := reflect.MakeMapWithSize(rv.Type(), rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			val := reflect.ValueOf(Clone(iter.Value().Interface()))
+			if !val.IsValid() {
+				val = reflect.Zero(rv.Type().Elem())
+			}
+			result.SetMapIndex(iter.Key(), val)
+		}
+		return result.Interface()
+	}
+
+	return v
+}
@@ -0,0 +1,37 @@
+package jseq_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestClone(t *testing.T) {
+	orig := map[string]any{
+		"a": []any{jseq.Int(1), jseq.Null{}, map[string]any{"b": "c"}},
+		"d": map[int64]any{1: []any{true}, 2: nil},
+		"e": []any(nil),
+		"f": jseq.Expanded{Value: []any{"g"}, Original: `["g"]`},
+	}
+
+	cloned := jseq.Clone(orig)
+	if !reflect.DeepEqual(cloned, orig) {
+		t.Fatalf("got %v, want %v", cloned, orig)
+	}
+
+	c := cloned.(map[string]any)
+	c["a"].([]any)[2].(map[string]any)["b"] = "changed"
+	c["d"].(map[int64]any)[1].([]any)[0] = false
+	c["f"].(jseq.Expanded).Value.([]any)[0] = "changed"
+
+	if orig["a"].([]any)[2].(map[string]any)["b"] != "c" {
+		t.Error("nested object was shared")
+	}
+	if orig["d"].(map[int64]any)[1].([]any)[0] != true {
+		t.Error("MapKeys map member was shared")
+	}
+	if orig["f"].(jseq.Expanded).Value.([]any)[0] != "g" {
+		t.Error("Expanded value was shared")
+	}
+}
@@ -0,0 +1,24 @@
+package jseq
+
+import "slices"
+
+// ClonePointers is an [Option] that causes [Values]
+// to produce a freshly allocated [Pointer] with each value.
+// Without it,
+// the pointers that Values produces may share storage with one another,
+// so that a pointer retained after the iteration that produced it
+// can appear to change.
+// With it,
+// pointers may safely be collected into slices and maps,
+// at the cost of an allocation for each value.
+func ClonePointers() Option {
+	return func(c *config) {
+		c.clonePointers = true
+	}
+}
+
+func clonePointersYield(yield func(Pointer, any) bool) func(Pointer, any) bool {
+	return func(pointer Pointer, val any) bool {
+		return yield(slices.Clone(pointer), val)
+	}
+}
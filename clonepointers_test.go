@@ -0,0 +1,50 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestClonePointers(t *testing.T) {
+	const inp = `{"a": [{"b": [1, 2, 3], "c": {"d": 4, "e": 5}}, [6, [7, 8]]]}`
+
+	tokens, _ := jseq.Tokens(strings.NewReader(inp))
+	values, errptr := jseq.Values(tokens, jseq.ClonePointers())
+
+	var (
+		pointers []jseq.Pointer
+		texts    []string
+	)
+	for pointer := range values {
+		pointers = append(pointers, pointer)
+		texts = append(texts, string(pointer.Text()))
+	}
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+
+	// Checked after the iteration is complete,
+	// the retained pointers must still be what they were when produced.
+	var got []string
+	for _, pointer := range pointers {
+		got = append(got, string(pointer.Text()))
+	}
+	if !reflect.DeepEqual(got, texts) {
+		t.Errorf("retained pointers changed: got %v, want %v", got, texts)
+	}
+
+	want := []string{
+		"/a/0/b/0", "/a/0/b/1", "/a/0/b/2", "/a/0/b",
+		"/a/0/c/d", "/a/0/c/e", "/a/0/c",
+		"/a/0",
+		"/a/1/0", "/a/1/1/0", "/a/1/1/1", "/a/1/1", "/a/1",
+		"/a",
+		"",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
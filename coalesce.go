@@ -0,0 +1,62 @@
+package jseq
+
+import (
+	"iter"
+	"slices"
+)
+
+// Coalesce returns the value at the first of the given pointers
+// that locates a non-null value within root.
+// The boolean result is false if there is no such pointer.
+//
+// This is useful when the same datum appears in different places
+// in different versions of a schema.
+func Coalesce(root any, ptrs ...Pointer) (any, bool) {
+	for _, ptr := range ptrs {
+		val, err := ptr.Locate(root)
+		if err != nil || isNull(val) {
+			continue
+		}
+		return val, true
+	}
+	return nil, false
+}
+
+// CoalesceRecords is the streaming equivalent of [Coalesce].
+// It consumes a sequence of pointer/value pairs as produced by [Values]
+// and, for each top-level value,
+// produces the value at the first of the given pointers
+// that locates a non-null value within it,
+// together with true;
+// or nil and false if there is no such pointer.
+func CoalesceRecords(values iter.Seq2[Pointer, any], ptrs ...Pointer) iter.Seq2[any, bool] {
+	rootIdx := slices.IndexFunc(ptrs, func(p Pointer) bool { return len(p) == 0 })
+
+	return func(yield func(any, bool) bool) {
+		var (
+			best    any
+			bestIdx = len(ptrs)
+		)
+		for pointer, val := range values {
+			if len(pointer) == 0 {
+				if rootIdx >= 0 && rootIdx < bestIdx && !isNull(val) {
+					best, bestIdx = val, rootIdx
+				}
+				if !yield(best, bestIdx < len(ptrs)) {
+					return
+				}
+				best, bestIdx = nil, len(ptrs)
+				continue
+			}
+			if isNull(val) {
+				continue
+			}
+			for i, ptr := range ptrs[:bestIdx] {
+				if slices.Equal(ptr, pointer) {
+					best, bestIdx = val, i
+					break
+				}
+			}
+		}
+	}
+}
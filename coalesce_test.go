@@ -0,0 +1,61 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestCoalesce(t *testing.T) {
+	ptrs := []jseq.Pointer{{"user", "email"}, {"email"}, {"contact", 0}}
+
+	cases := []struct {
+		inp    string
+		want   any
+		wantOK bool
+	}{
+		{inp: `{"user": {"email": "a@x"}, "email": "b@x"}`, want: "a@x", wantOK: true},
+		{inp: `{"user": {"email": null}, "email": "b@x"}`, want: "b@x", wantOK: true},
+		{inp: `{"user": "bob", "contact": ["c@x"]}`, want: "c@x", wantOK: true},
+		{inp: `{"contact": [], "email": null}`, want: nil, wantOK: false},
+	}
+
+	var (
+		inputs     []string
+		wantValues []any
+		wantOKs    []bool
+	)
+	for _, tc := range cases {
+		pairs := collect(t, strings.NewReader(tc.inp))
+		got, ok := jseq.Coalesce(pairs[len(pairs)-1].v, ptrs...)
+		if !reflect.DeepEqual(got, tc.want) || ok != tc.wantOK {
+			t.Errorf("Coalesce(%s) = %v, %v; want %v, %v", tc.inp, got, ok, tc.want, tc.wantOK)
+		}
+
+		inputs = append(inputs, tc.inp)
+		wantValues = append(wantValues, tc.want)
+		wantOKs = append(wantOKs, tc.wantOK)
+	}
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(strings.Join(inputs, "\n")))
+	values, errptr2 := jseq.Values(tokens)
+	var (
+		gotValues []any
+		gotOKs    []bool
+	)
+	for val, ok := range jseq.CoalesceRecords(values, ptrs...) {
+		gotValues = append(gotValues, val)
+		gotOKs = append(gotOKs, ok)
+	}
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+	if !reflect.DeepEqual(gotValues, wantValues) || !reflect.DeepEqual(gotOKs, wantOKs) {
+		t.Errorf("CoalesceRecords got %v %v, want %v %v", gotValues, gotOKs, wantValues, wantOKs)
+	}
+}
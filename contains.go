@@ -0,0 +1,66 @@
+package jseq
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"io"
+	"iter"
+	"strings"
+
+	"github.com/bobg/errors"
+)
+
+// Contains finds the string values in r that contain needle as a substring.
+// The input may contain multiple top-level JSON values ("records").
+// Each match is produced as the zero-based ordinal of the record containing it,
+// paired with the [Pointer] that locates the matching string within that record.
+// Object keys are not searched.
+//
+// Contains is designed for quickly searching large inputs.
+// Each record is first scanned as raw bytes,
+// without building any values,
+// and only a record whose bytes could contain a match is fully parsed.
+// (A record could contain a match if its bytes contain needle,
+// or if it contains escape sequences,
+// which may disguise needle.)
+//
+// After consuming the resulting sequence,
+// the caller may check for errors by dereferencing the returned error pointer.
+func Contains(r io.Reader, needle string) (iter.Seq2[int, Pointer], *error) {
+	var err error
+
+	f := func(yield func(int, Pointer) bool) {
+		var (
+			dec = jsontext.NewDecoder(r)
+			nb  = []byte(needle)
+		)
+		for record := 0; ; record++ {
+			raw, e := dec.ReadValue()
+			if errors.Is(e, io.EOF) {
+				return
+			}
+			if e != nil {
+				err = e
+				return
+			}
+			if !bytes.Contains(raw, nb) && bytes.IndexByte(raw, '\\') < 0 {
+				continue
+			}
+
+			tokens, errptr1 := Tokens(bytes.NewReader(raw))
+			values, errptr2 := Values(tokens)
+			for pointer, val := range values {
+				if s, ok := val.(string); ok && strings.Contains(s, needle) {
+					if !yield(record, pointer) {
+						return
+					}
+				}
+			}
+			if e := errors.Join(*errptr1, *errptr2); e != nil {
+				err = errors.Wrapf(e, "in record %d", record)
+				return
+			}
+		}
+	}
+	return f, &err
+}
@@ -0,0 +1,57 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestContains(t *testing.T) {
+	const inp = `
+{"name": "Alice", "email": "alice@example.com", "tags": ["x", "alice@example.com"]}
+{"name": "Bob", "email": "bob@example.com"}
+{"alice@example.com": "key only"}
+{"note": "escaped: alice\u0040example.com"}
+"alice@example.com"
+`
+
+	type match struct {
+		record  int
+		pointer string
+	}
+
+	var got []match
+	seq, errptr := jseq.Contains(strings.NewReader(inp), "alice@example.com")
+	for record, pointer := range seq {
+		got = append(got, match{record: record, pointer: string(pointer.Text())})
+	}
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []match{
+		{record: 0, pointer: "/email"},
+		{record: 0, pointer: "/tags/1"},
+		{record: 3, pointer: "/note"},
+		{record: 4, pointer: ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestContainsError(t *testing.T) {
+	seq, errptr := jseq.Contains(strings.NewReader(`{"a": "needle"} {"b": `), "needle")
+	var n int
+	for range seq {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("got %d matches, want 1", n)
+	}
+	if *errptr == nil {
+		t.Error("got no error, want one")
+	}
+}
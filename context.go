@@ -0,0 +1,85 @@
+package jseq
+
+import (
+	"context"
+	"encoding/json/jsontext"
+	"io"
+	"iter"
+)
+
+// TokensContext is like [Tokens],
+// but the sequence ends promptly when ctx is canceled or its deadline passes,
+// and the returned error pointer then holds ctx.Err().
+//
+// To make this possible,
+// TokensContext reads r in a separate goroutine.
+// After cancellation, that goroutine may remain blocked in a call to r.Read
+// until it returns;
+// closing r will release it.
+func TokensContext(ctx context.Context, r io.Reader, opts ...jsontext.Options) (iter.Seq[jsontext.Token], *error) {
+	var outerErr error
+
+	f := func(yield func(jsontext.Token) bool) {
+		type tokErr struct {
+			tok jsontext.Token
+			err error
+		}
+		var (
+			ch   = make(chan tokErr)
+			done = make(chan struct{})
+		)
+		defer close(done)
+
+		go func() {
+			defer close(ch)
+			for tok, err := range TokensE(r, opts...) {
+				select {
+				case ch <- tokErr{tok: tok.Clone(), err: err}: // the original may be invalidated when the next token is read
+				case <-done:
+					return
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			if err := ctx.Err(); err != nil {
+				outerErr = err
+				return
+			}
+			select {
+			case <-ctx.Done():
+				outerErr = ctx.Err()
+				return
+
+			case te, ok := <-ch:
+				if !ok {
+					return
+				}
+				if te.err != nil {
+					outerErr = te.err
+					return
+				}
+				if !yield(te.tok) {
+					return
+				}
+			}
+		}
+	}
+	return f, &outerErr
+}
+
+// ValuesContext is like [Values],
+// but the sequence ends promptly when ctx is canceled or its deadline passes,
+// and the returned error pointer then holds ctx.Err().
+// As with [RecordTimeout],
+// Values then reads its input tokens in a separate goroutine,
+// which may remain blocked until its next token arrives.
+func ValuesContext(ctx context.Context, tokens iter.Seq[jsontext.Token], opts ...Option) (iter.Seq2[Pointer, any], *error) {
+	opts = append(opts[:len(opts):len(opts)], func(c *config) {
+		c.ctx = ctx
+	})
+	return Values(tokens, opts...)
+}
@@ -0,0 +1,65 @@
+package jseq_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bobg/jseq"
+)
+
+func TestTokensContext(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go pw.Write([]byte(`[1, 2, `)) // and then nothing more
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tokens, errptr := jseq.TokensContext(ctx, pr)
+
+	var n int
+	start := time.Now()
+	for range tokens {
+		n++
+		if n == 3 {
+			cancel()
+		}
+	}
+	if n != 3 {
+		t.Errorf("got %d tokens, want 3", n)
+	}
+	if !errors.Is(*errptr, context.Canceled) {
+		t.Errorf("got error %v, want %v", *errptr, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %s to stop", elapsed)
+	}
+}
+
+func TestValuesContext(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go pw.Write([]byte(`{"a": 1} {"b": [2, `)) // and then nothing more
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	tokens, _ := jseq.Tokens(pr)
+	values, errptr := jseq.ValuesContext(ctx, tokens)
+
+	var recs int
+	for pointer := range values {
+		if len(pointer) == 0 {
+			recs++
+		}
+	}
+	if recs != 1 {
+		t.Errorf("got %d records, want 1", recs)
+	}
+	if !errors.Is(*errptr, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", *errptr, context.DeadlineExceeded)
+	}
+}
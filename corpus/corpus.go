@@ -0,0 +1,273 @@
+// Package corpus supplies standard JSON inputs for benchmarking code built on jseq.
+//
+// Two kinds of corpus are available.
+// Fetched corpora, such as [Canada], [CITMCatalog], and [Twitter],
+// are well-known benchmark files downloaded on first use,
+// verified against a SHA-256 checksum,
+// and cached on local disk.
+// Synthetic corpora, made with [Synthetic],
+// are generated deterministically by [jseq.Generate]
+// and need no network access.
+//
+// The cache directory is $JSEQ_CORPUS_DIR if that is set,
+// and a "jseq-corpus" subdirectory of [os.UserCacheDir] otherwise.
+package corpus
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/bobg/errors"
+
+	"github.com/bobg/jseq"
+)
+
+// Corpus is a JSON input for benchmarking.
+type Corpus struct {
+	// Name identifies the corpus.
+	// For fetched corpora it is also the name of the cached file.
+	Name string
+
+	// URL is where a fetched corpus is downloaded from.
+	URL string
+
+	// SHA256 is the hex-encoded SHA-256 checksum of a fetched corpus.
+	SHA256 string
+
+	synthetic *synthetic
+}
+
+type synthetic struct {
+	seed uint64
+	spec jseq.GenSpec
+}
+
+// The nativejson-benchmark files,
+// as pinned in the testdata of github.com/valyala/fastjson at v1.6.10.
+//
+// The checksums are those of the files in the Go module zip
+// github.com/valyala/fastjson@v1.6.10
+// (module hash h1:/yjJg8jaVQdYR3arGxPE2X5z89xrlhS0eGXdv+ADTh4=),
+// which can be reproduced with
+//
+//	go mod download github.com/valyala/fastjson@v1.6.10
+//	sha256sum $(go env GOMODCACHE)/github.com/valyala/fastjson@v1.6.10/testdata/*.json
+//
+// TestStandardChecksums checks that the URLs still serve those files
+// when JSEQ_CORPUS_FETCH is set.
+var (
+	// Canada is geographic data dominated by arrays of floating-point numbers (about 2.2MB).
+	Canada = Corpus{
+		Name:   "canada.json",
+		URL:    "https://raw.githubusercontent.com/valyala/fastjson/v1.6.10/testdata/canada.json",
+		SHA256: "bfbc12b8b6da35cdcc15046304be1739a82a335de17ef9959ea3dd75225467a4",
+	}
+
+	// CITMCatalog is a catalog of events dominated by objects with numeric keys (about 1.7MB).
+	CITMCatalog = Corpus{
+		Name:   "citm_catalog.json",
+		URL:    "https://raw.githubusercontent.com/valyala/fastjson/v1.6.10/testdata/citm_catalog.json",
+		SHA256: "a73e7a883f6ea8de113dff59702975e60119b4b58d451d518a929f31c92e2059",
+	}
+
+	// Twitter is a Twitter API search response dominated by strings, many of them non-ASCII (about 630KB).
+	Twitter = Corpus{
+		Name:   "twitter.json",
+		URL:    "https://raw.githubusercontent.com/valyala/fastjson/v1.6.10/testdata/twitter.json",
+		SHA256: "a08b769f32b95f426cbc3abafcec65c1a19d3eb544d4ddf320eae142c99efc5d",
+	}
+)
+
+// Standard returns the standard fetched corpora.
+func Standard() []Corpus {
+	return []Corpus{Canada, CITMCatalog, Twitter}
+}
+
+// Synthetic returns a corpus generated by [jseq.Generate]
+// using a PCG random-number generator with the given seed.
+func Synthetic(name string, seed uint64, spec jseq.GenSpec) Corpus {
+	return Corpus{Name: name, synthetic: &synthetic{seed: seed, spec: spec}}
+}
+
+// Open opens the content of c for reading.
+// A fetched corpus is downloaded first if it is not already in the cache.
+// The caller must close the result.
+func (c Corpus) Open(ctx context.Context) (io.ReadCloser, error) {
+	if c.synthetic != nil {
+		return c.synthetic.open(), nil
+	}
+	path, err := c.Path(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Tokens returns the JSON tokens of c, as from [jseq.Tokens].
+// The corpus is opened when iteration begins and closed when it ends.
+//
+// After consuming the resulting sequence,
+// the caller may check for errors by dereferencing the returned error pointer.
+func (c Corpus) Tokens(ctx context.Context, opts ...jsontext.Options) (iter.Seq[jsontext.Token], *error) {
+	var err error
+
+	f := func(yield func(jsontext.Token) bool) {
+		r, openErr := c.Open(ctx)
+		if openErr != nil {
+			err = errors.Wrapf(openErr, "opening corpus %s", c.Name)
+			return
+		}
+		defer r.Close()
+
+		tokens, errptr := jseq.Tokens(r, opts...)
+		for tok := range tokens {
+			if !yield(tok) {
+				return
+			}
+		}
+		err = *errptr
+	}
+	return f, &err
+}
+
+// Values returns the JSON values of c, as from [jseq.Values].
+// The corpus is opened when iteration begins and closed when it ends.
+//
+// After consuming the resulting sequence,
+// the caller may check for errors by dereferencing the returned error pointer.
+func (c Corpus) Values(ctx context.Context, opts ...jseq.Option) (iter.Seq2[jseq.Pointer, any], *error) {
+	var err error
+
+	f := func(yield func(jseq.Pointer, any) bool) {
+		tokens, errptr1 := c.Tokens(ctx)
+		values, errptr2 := jseq.Values(tokens, opts...)
+		for ptr, val := range values {
+			if !yield(ptr, val) {
+				return
+			}
+		}
+		err = errors.Join(*errptr1, *errptr2)
+	}
+	return f, &err
+}
+
+// Path returns the name of the cached file holding a fetched corpus,
+// downloading it first if necessary.
+// It is an error to call Path on a synthetic corpus.
+func (c Corpus) Path(ctx context.Context) (string, error) {
+	if c.synthetic != nil {
+		return "", fmt.Errorf("corpus %s is synthetic", c.Name)
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, "getting cache dir")
+	}
+	path := filepath.Join(dir, c.Name)
+
+	if sum, err := fileSHA256(path); err == nil && sum == c.SHA256 {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", errors.Wrapf(err, "creating %s", dir)
+	}
+	if err := c.fetch(ctx, path); err != nil {
+		return "", errors.Wrapf(err, "fetching corpus %s", c.Name)
+	}
+	return path, nil
+}
+
+// fetch downloads c to a temporary file in the directory of path,
+// checks its checksum,
+// and renames it to path.
+func (c Corpus) fetch(ctx context.Context, path string) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "requesting %s", c.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("requesting %s: status %s", c.URL, resp.Status)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), c.Name+".*")
+	if err != nil {
+		return errors.Wrap(err, "creating temp file")
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(f, io.TeeReader(resp.Body, h)); err != nil {
+		return errors.Wrapf(err, "downloading %s", c.URL)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrapf(err, "closing %s", f.Name())
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != c.SHA256 {
+		return fmt.Errorf("checksum mismatch: got %s, want %s", sum, c.SHA256)
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func cacheDir() (string, error) {
+	if dir := os.Getenv("JSEQ_CORPUS_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "jseq-corpus"), nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// open streams the generated content of s through a pipe.
+func (s *synthetic) open() io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		var (
+			rng = rand.New(rand.NewPCG(s.seed, 0))
+			enc = jsontext.NewEncoder(pw)
+			err error
+		)
+		for tok := range jseq.Generate(rng, s.spec) {
+			if err = enc.WriteToken(tok); err != nil {
+				break
+			}
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
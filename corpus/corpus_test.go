@@ -0,0 +1,113 @@
+package corpus_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bobg/jseq"
+	"github.com/bobg/jseq/corpus"
+)
+
+func TestFetch(t *testing.T) {
+	const content = `{"a": [1, 2]}`
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, content)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	t.Setenv("JSEQ_CORPUS_DIR", dir)
+
+	sum := sha256.Sum256([]byte(content))
+	c := corpus.Corpus{Name: "test.json", URL: srv.URL, SHA256: hex.EncodeToString(sum[:])}
+
+	ctx := context.Background()
+	for range 2 {
+		values, errptr := c.Values(ctx)
+		var n int
+		for range values {
+			n++
+		}
+		if *errptr != nil {
+			t.Fatal(*errptr)
+		}
+		if n != 4 {
+			t.Errorf("got %d values, want 4", n)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+
+	bad := corpus.Corpus{Name: "bad.json", URL: srv.URL, SHA256: "0000"}
+	if _, err := bad.Path(ctx); err == nil {
+		t.Error("got no error for checksum mismatch")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bad.json")); !os.IsNotExist(err) {
+		t.Errorf("bad corpus was cached (stat error %v)", err)
+	}
+}
+
+func TestSynthetic(t *testing.T) {
+	ctx := context.Background()
+	read := func(c corpus.Corpus) []byte {
+		r, err := c.Open(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	spec := jseq.GenSpec{Size: 200}
+	b1 := read(corpus.Synthetic("s", 7, spec))
+	b2 := read(corpus.Synthetic("s", 7, spec))
+	if string(b1) != string(b2) {
+		t.Error("synthetic corpus is not deterministic")
+	}
+	if len(b1) == 0 {
+		t.Error("synthetic corpus is empty")
+	}
+}
+
+// TestStandardChecksums downloads the standard corpora
+// and verifies their checksums.
+// It needs network access,
+// so it runs only when JSEQ_CORPUS_FETCH is set.
+func TestStandardChecksums(t *testing.T) {
+	if os.Getenv("JSEQ_CORPUS_FETCH") == "" {
+		t.Skip("set JSEQ_CORPUS_FETCH to download and verify the standard corpora")
+	}
+
+	t.Setenv("JSEQ_CORPUS_DIR", t.TempDir()) // force a download
+
+	for _, c := range corpus.Standard() {
+		t.Run(c.Name, func(t *testing.T) {
+			path, err := c.Path(context.Background()) // fails on a checksum mismatch
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != c.SHA256 {
+				t.Errorf("got checksum %x, want %s", sum, c.SHA256)
+			}
+		})
+	}
+}
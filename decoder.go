@@ -0,0 +1,96 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"io"
+	"iter"
+	"sync/atomic"
+
+	"github.com/bobg/errors"
+	"github.com/bobg/seqs"
+)
+
+// ValuesFromDecoder is like [Values],
+// but it reads tokens directly from dec
+// instead of from a token sequence such as [Tokens] produces.
+// This avoids the cost of the intermediate sequence,
+// which can matter when parsing many millions of tokens.
+//
+// Errors from dec, as well as from parsing,
+// are placed in the returned error pointer.
+//
+// The [RecordTimeout], [Heartbeat], and [ValuesContext] features
+// read tokens in a separate goroutine,
+// so with them the fast path does not apply
+// (though they still work).
+func ValuesFromDecoder(dec *jsontext.Decoder, opts ...Option) (iter.Seq2[Pointer, any], *error) {
+	var err error
+
+	f := func(yield func(Pointer, any) bool) {
+		p := newParser(nil, nil, yield, opts)
+
+		async := p.recordTimeout > 0 || p.heartbeat > 0 || p.ctx != nil
+		if p.summary != nil && !async {
+			start := dec.InputOffset()
+			defer func() { p.summary.Bytes = dec.InputOffset() - start }()
+		}
+
+		var decErr atomic.Pointer[error]
+		read := func() (jsontext.Token, bool) {
+			tok, err := dec.ReadToken()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					e := err
+					decErr.Store(&e)
+				}
+				return jsontext.Token{}, false
+			}
+			return tok, true
+		}
+
+		if async {
+			tokens := func(yield func(jsontext.Token) bool) {
+				for {
+					tok, ok := read()
+					if !ok || !yield(tok) {
+						return
+					}
+				}
+			}
+			tokens, stop := p.asyncTokens(tokens)
+			defer stop()
+
+			next, peek, stopPeeker := seqs.Peeker(tokens)
+			defer stopPeeker()
+			p.next, p.peek = next, peek
+		} else {
+			var (
+				peeked    jsontext.Token
+				hasPeeked bool
+			)
+			p.next = func() (jsontext.Token, bool) {
+				if hasPeeked {
+					hasPeeked = false
+					return peeked, true
+				}
+				return read()
+			}
+			p.peek = func() (jsontext.Token, bool) {
+				if !hasPeeked {
+					tok, ok := read()
+					if !ok {
+						return tok, false
+					}
+					peeked, hasPeeked = tok, true
+				}
+				return peeked, true
+			}
+		}
+
+		err = p.values()
+		if e := decErr.Load(); e != nil && !p.timed && !p.canceled {
+			err = *e
+		}
+	}
+	return f, &err
+}
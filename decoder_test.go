@@ -0,0 +1,71 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestValuesFromDecoder(t *testing.T) {
+	cases := []struct {
+		inp     string
+		opts    []jseq.Option
+		wantErr error // nil to expect the same results as Values
+	}{
+		{inp: `{"a": [1, {"b": null}], "c": "x"} 17 [true, false]`},
+		{inp: `{"a": "b"}`, opts: []jseq.Option{jseq.PreOrder()}},
+		{inp: `[1, 2`, wantErr: io.ErrUnexpectedEOF},
+		{inp: `[1, 2}`, wantErr: new(jsontext.SyntacticError)},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i+1), func(t *testing.T) {
+			type pair struct {
+				pointer string
+				val     any
+			}
+
+			dec := jsontext.NewDecoder(strings.NewReader(tc.inp))
+			values, errptr := jseq.ValuesFromDecoder(dec, tc.opts...)
+			var got []pair
+			for pointer, val := range values {
+				got = append(got, pair{pointer: string(pointer.Text()), val: val})
+			}
+
+			if tc.wantErr != nil {
+				var syntaxErr *jsontext.SyntacticError
+				if errors.As(tc.wantErr, &syntaxErr) {
+					if !errors.As(*errptr, &syntaxErr) {
+						t.Errorf("got error %v, want a syntax error", *errptr)
+					}
+				} else if !errors.Is(*errptr, tc.wantErr) {
+					t.Errorf("got error %v, want %v", *errptr, tc.wantErr)
+				}
+				return
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+
+			tokens, _ := jseq.Tokens(strings.NewReader(tc.inp))
+			values, errptr = jseq.Values(tokens, tc.opts...)
+			var want []pair
+			for pointer, val := range values {
+				want = append(want, pair{pointer: string(pointer.Text()), val: val})
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
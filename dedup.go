@@ -0,0 +1,162 @@
+package jseq
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bobg/errors"
+)
+
+// SeenSet is a set of idempotency keys,
+// consulted by a [DedupSink].
+// Implementations backed by durable storage
+// allow deduplication across restarts.
+type SeenSet interface {
+	// Contains tells whether key is in the set.
+	Contains(key string) (bool, error)
+
+	// Add adds key to the set.
+	Add(key string) error
+}
+
+// MemorySeenSet is an in-memory [SeenSet].
+// The zero value is an empty set ready to use.
+// It is safe for concurrent use.
+type MemorySeenSet struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+// Contains implements [SeenSet].
+func (s *MemorySeenSet) Contains(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys[key], nil
+}
+
+// Add implements [SeenSet].
+func (s *MemorySeenSet) Add(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys == nil {
+		s.keys = make(map[string]bool)
+	}
+	s.keys[key] = true
+	return nil
+}
+
+// Len returns the number of keys in s.
+func (s *MemorySeenSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.keys)
+}
+
+// DedupSink is a [Sink] that passes records (top-level values) to another sink
+// only if their idempotency keys have not been seen before,
+// so that replaying an input
+// (e.g. after resuming from a checkpoint)
+// does not duplicate records downstream.
+// Create one with [NewDedupSink].
+type DedupSink struct {
+	sink     Sink
+	seen     SeenSet
+	pointers []Pointer
+
+	pending []dedupPair // the pairs inside the current record
+	skipped int
+}
+
+type dedupPair struct {
+	pointer Pointer
+	val     any
+}
+
+// NewDedupSink creates a [DedupSink] that delivers to sink,
+// consulting seen.
+// A record's idempotency key is made from the values at the given pointers,
+// or from the whole record if there are none.
+// A key is added to seen only after its record has been delivered successfully.
+//
+// The pairs inside a record are held until the record itself arrives
+// and are delivered (or not) together with it.
+// A record lacking any of the key values is always delivered.
+func NewDedupSink(sink Sink, seen SeenSet, pointers ...Pointer) *DedupSink {
+	return &DedupSink{sink: sink, seen: seen, pointers: pointers}
+}
+
+// Consume implements [Sink].
+func (s *DedupSink) Consume(pointer Pointer, val any) error {
+	if len(pointer) > 0 {
+		s.pending = append(s.pending, dedupPair{pointer: slices.Clone(pointer), val: val})
+		return nil
+	}
+
+	pending := s.pending
+	s.pending = s.pending[:0]
+
+	key, ok := s.key(val)
+	if ok {
+		seen, err := s.seen.Contains(key)
+		if err != nil {
+			return errors.Wrap(err, "checking idempotency key")
+		}
+		if seen {
+			s.skipped++
+			return nil
+		}
+	}
+
+	for _, p := range pending {
+		if err := s.sink.Consume(p.pointer, p.val); err != nil {
+			return err
+		}
+	}
+	if err := s.sink.Consume(pointer, val); err != nil {
+		return err
+	}
+
+	if ok {
+		return errors.Wrap(s.seen.Add(key), "recording idempotency key")
+	}
+	return nil
+}
+
+// key computes the idempotency key of rec.
+func (s *DedupSink) key(rec any) (string, bool) {
+	return recordKey(rec, s.pointers)
+}
+
+// recordKey makes a key for rec from the values at the given pointers,
+// or from the whole record if there are none.
+// It returns false if rec lacks any of the values.
+func recordKey(rec any, pointers []Pointer) (string, bool) {
+	if len(pointers) == 0 {
+		pointers = []Pointer{nil}
+	}
+	var parts []string
+	for _, ptr := range pointers {
+		val, err := ptr.Locate(rec)
+		if err != nil || val == nil {
+			return "", false
+		}
+		b, err := Marshal(val)
+		if err != nil {
+			return "", false
+		}
+		parts = append(parts, string(b))
+	}
+	return strings.Join(parts, "\x00"), true
+}
+
+// Skipped returns the number of records skipped as duplicates.
+func (s *DedupSink) Skipped() int {
+	return s.skipped
+}
+
+// Close implements [Sink].
+// It closes the underlying sink.
+func (s *DedupSink) Close() error {
+	return s.sink.Close()
+}
@@ -0,0 +1,101 @@
+package jseq_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestDedupSink(t *testing.T) {
+	const inp = `
+{"id": 1, "v": "a"}
+{"id": 2, "v": "b"}
+{"id": 1, "v": "a again"}
+{"v": "no id"}
+{"id": 2, "v": ["b", "again"]}
+{"id": "1", "v": "string id"}
+`
+	var seen jseq.MemorySeenSet
+
+	run := func(inp string) ([]any, int, int) {
+		t.Helper()
+		var (
+			recs  []any
+			inner int
+		)
+		sink := jseq.NewDedupSink(jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+			if len(pointer) > 0 {
+				inner++
+				return nil
+			}
+			recs = append(recs, val.(map[string]any)["v"])
+			return nil
+		}), &seen, jseq.Pointer{"id"})
+		if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), sink, nil); err != nil {
+			t.Fatal(err)
+		}
+		return recs, inner, sink.Skipped()
+	}
+
+	recs, inner, skipped := run(inp)
+	if want := []any{"a", "b", "no id", "string id"}; !reflect.DeepEqual(recs, want) {
+		t.Errorf("got %v, want %v", recs, want)
+	}
+	if inner != 7 {
+		t.Errorf("got %d inner pairs, want 7", inner)
+	}
+	if skipped != 2 {
+		t.Errorf("got %d skipped, want 2", skipped)
+	}
+	if seen.Len() != 3 {
+		t.Errorf("got %d seen keys, want 3", seen.Len())
+	}
+
+	// Replaying delivers only records without keys.
+	recs, _, skipped = run(inp)
+	if want := []any{"no id"}; !reflect.DeepEqual(recs, want) {
+		t.Errorf("on replay got %v, want %v", recs, want)
+	}
+	if skipped != 5 {
+		t.Errorf("on replay got %d skipped, want 5", skipped)
+	}
+}
+
+func TestDedupSinkWholeRecord(t *testing.T) {
+	var (
+		seen jseq.MemorySeenSet
+		n    int
+	)
+	sink := jseq.NewDedupSink(jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+		if len(pointer) == 0 {
+			n++
+		}
+		return nil
+	}), &seen)
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(`{"a": 1, "b": 2} {"b": 2, "a": 1} {"a": 2}`)), sink, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d records, want 2", n)
+	}
+}
+
+func TestDedupSinkFailure(t *testing.T) {
+	var seen jseq.MemorySeenSet
+	errBoom := errors.New("boom")
+	sink := jseq.NewDedupSink(jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+		return errBoom
+	}), &seen, jseq.Pointer{"id"})
+
+	if err := sink.Consume(nil, map[string]any{"id": jseq.Int(1)}); !errors.Is(err, errBoom) {
+		t.Errorf("got error %v, want %v", err, errBoom)
+	}
+	// A record that was not delivered is not marked as seen.
+	if seen.Len() != 0 {
+		t.Errorf("got %d seen keys, want 0", seen.Len())
+	}
+}
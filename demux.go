@@ -0,0 +1,73 @@
+package jseq
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Route describes a value produced by [Demux].
+type Route struct {
+	// Name is the name of the pattern that matched.
+	Name string
+
+	// Pointer locates the value within its top-level value.
+	Pointer Pointer
+
+	// Captures holds the captures of the pattern (see [Pattern.Captures]).
+	Captures map[string]string
+}
+
+// Demux consumes a sequence of pointer/value pairs,
+// as from [Values],
+// and routes them to named patterns in a single pass.
+// Each pair whose pointer matches a pattern is produced,
+// paired with a [Route] naming that pattern.
+// A pair matching more than one pattern is produced once for each,
+// in order of pattern name.
+// Pairs matching no pattern are dropped.
+//
+// A consumer typically switches on the Name of each Route
+// to dispatch the value to its handler.
+//
+// Work is shared among siblings:
+// a pattern that cannot match anything inside a given array or object
+// is not consulted for any of its members.
+func Demux(values iter.Seq2[Pointer, any], patterns map[string]Pattern) iter.Seq2[Route, any] {
+	names := slices.Sorted(maps.Keys(patterns))
+
+	return func(yield func(Route, any) bool) {
+		var (
+			parent Pointer
+			live   []string // names of the patterns that can match below parent
+			cached bool
+		)
+		for pointer, val := range values {
+			candidates := names
+			if len(pointer) > 0 {
+				p := pointer[:len(pointer)-1]
+				if !cached || !slices.Equal(p, parent) {
+					parent = slices.Clone(p)
+					live = live[:0]
+					for _, name := range names {
+						if patterns[name].MatchBelow(parent) {
+							live = append(live, name)
+						}
+					}
+					cached = true
+				}
+				candidates = live
+			}
+
+			for _, name := range candidates {
+				caps, ok := patterns[name].Captures(pointer)
+				if !ok {
+					continue
+				}
+				if !yield(Route{Name: name, Pointer: pointer, Captures: caps}, val) {
+					return
+				}
+			}
+		}
+	}
+}
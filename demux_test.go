@@ -0,0 +1,59 @@
+package jseq_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestDemux(t *testing.T) {
+	const inp = `
+{"orders": [{"id": 1, "total": 10}, {"id": 2, "total": 20}], "user": {"email": "a@x"}}
+{"user": {"email": "b@x"}}
+`
+
+	patterns := map[string]jseq.Pattern{
+		"email":  jseq.MustParsePattern("/user/email"),
+		"record": jseq.MustParsePattern(""),
+		"total":  jseq.MustParsePattern("/orders/{n}/total"),
+		"user":   jseq.MustParsePattern("/user/**"),
+	}
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+
+	var got []string
+	for route, val := range jseq.Demux(values, patterns) {
+		switch route.Name {
+		case "record":
+			got = append(got, "record")
+		case "total":
+			got = append(got, fmt.Sprintf("total %s=%v", route.Captures["n"], val))
+		default:
+			got = append(got, fmt.Sprintf("%s %s=%v", route.Name, route.Pointer.Text(), val))
+		}
+	}
+	if err := errors.Join(*errptr1, *errptr2); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"total 0=10",
+		"total 1=20",
+		"email /user/email=a@x",
+		"user /user/email=a@x",
+		"user /user=map[email:a@x]",
+		"record",
+		"email /user/email=b@x",
+		"user /user/email=b@x",
+		"user /user=map[email:b@x]",
+		"record",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// MaxDepth is an [Option] that limits the nesting depth of arrays and objects
+// parsed by [Values].
+// A top-level array or object is at depth 1.
+// A container nested more deeply than depth
+// ends the sequence with a [*DepthError].
+//
+// This protects programs from adversarial inputs
+// whose deep nesting would otherwise consume excessive memory and time.
+// A depth of zero or less means no limit.
+//
+// To limit the depth of a token stream without parsing it,
+// see [LimitDepth].
+func MaxDepth(depth int) Option {
+	return func(c *config) {
+		c.maxDepth = depth
+	}
+}
+
+// DepthError is the error produced by [MaxDepth] and [LimitDepth]
+// for input nested too deeply.
+type DepthError struct {
+	// Max is the depth limit.
+	Max int
+
+	// Pointer locates the array or object that exceeds the limit
+	// within its top-level value.
+	Pointer Pointer
+}
+
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("nesting depth exceeds %d at %q", e.Max, e.Pointer.Text())
+}
+
+// checkDepth reports whether a container beginning at pointer is too deeply nested.
+func (p *parser) checkDepth(pointer Pointer) error {
+	if p.maxDepth > 0 && len(pointer) >= p.maxDepth {
+		return &DepthError{Max: p.maxDepth, Pointer: slices.Clone(pointer)}
+	}
+	return nil
+}
+
+// LimitDepth passes through the tokens in its input,
+// as from [Tokens],
+// ending the sequence with a [*DepthError]
+// at the first array or object nested more deeply than depth
+// (where a top-level array or object is at depth 1).
+// It is the token-level counterpart of the [MaxDepth] option,
+// for consumers such as [Events] that do not use [Values].
+//
+// Like [CheckTokens],
+// LimitDepth also ends the sequence with a [*TokenError]
+// at the first malformed token.
+//
+// After consuming the resulting sequence,
+// the caller may check for errors by dereferencing the returned error pointer.
+func LimitDepth(tokens iter.Seq[jsontext.Token], depth int) (iter.Seq[jsontext.Token], *error) {
+	var err error
+
+	f := func(yield func(jsontext.Token) bool) {
+		var (
+			c     checker
+			index int
+		)
+		for tok := range tokens {
+			if want, ok := c.check(tok); !ok {
+				err = &TokenError{Pointer: slices.Clone(c.pointer), Index: index, Got: tok.Kind(), Want: want}
+				return
+			}
+			if depth > 0 && len(c.stack) > depth {
+				err = &DepthError{Max: depth, Pointer: slices.Clone(c.pointer)}
+				return
+			}
+			if !yield(tok) {
+				return
+			}
+			index++
+		}
+	}
+	return f, &err
+}
@@ -0,0 +1,82 @@
+package jseq_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestMaxDepth(t *testing.T) {
+	cases := []struct {
+		inp         string
+		depth       int
+		wantPointer jseq.Pointer // nil for no error
+	}{
+		{inp: `{"a": [1, {"b": 2}]}`, depth: 3},
+		{inp: `{"a": [1, {"b": 2}]}`, depth: 2, wantPointer: jseq.Pointer{"a", 1}},
+		{inp: `{"a": [1, {"b": 2}]}`, depth: 1, wantPointer: jseq.Pointer{"a"}},
+		{inp: `1 [] [[]]`, depth: 1, wantPointer: jseq.Pointer{0}},
+		{inp: `[[[[[[]]]]]]`, depth: 0},
+		{inp: `[[[[[[]]]]]]`, depth: 4, wantPointer: jseq.Pointer{0, 0, 0, 0}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.inp, func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(tc.inp))
+			values, errptr := jseq.Values(tokens, jseq.MaxDepth(tc.depth))
+			for range values {
+			}
+			checkDepthError(t, *errptr, tc.depth, tc.wantPointer)
+
+			tokens, _ = jseq.Tokens(strings.NewReader(tc.inp))
+			limited, errptr := jseq.LimitDepth(tokens, tc.depth)
+			for range limited {
+			}
+			checkDepthError(t, *errptr, tc.depth, tc.wantPointer)
+		})
+	}
+}
+
+func checkDepthError(t *testing.T, err error, depth int, wantPointer jseq.Pointer) {
+	t.Helper()
+
+	if wantPointer == nil {
+		if err != nil {
+			t.Errorf("got error %v, want none", err)
+		}
+		return
+	}
+	var derr *jseq.DepthError
+	if !errors.As(err, &derr) {
+		t.Fatalf("got error %v, want a DepthError", err)
+	}
+	if derr.Max != depth {
+		t.Errorf("got max %d, want %d", derr.Max, depth)
+	}
+	if !reflect.DeepEqual(derr.Pointer, wantPointer) {
+		t.Errorf("got pointer %v, want %v", derr.Pointer, wantPointer)
+	}
+}
+
+func TestLimitDepthPassesTokens(t *testing.T) {
+	tokens, _ := jseq.Tokens(strings.NewReader(`{"a": [1, 2]} [3]`))
+	limited, errptr := jseq.LimitDepth(tokens, 2)
+	values, errptr2 := jseq.Values(limited)
+
+	var n int
+	for range values {
+		n++
+	}
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+	if err := *errptr2; err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 {
+		t.Errorf("got %d values, want 6", n)
+	}
+}
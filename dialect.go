@@ -0,0 +1,621 @@
+package jseq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"iter"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Dialect is a named bundle of tolerances for JSON-like input,
+// selecting in one value what would otherwise take many separate knobs.
+// Use one of the predefined dialects:
+//
+//   - [Strict8259]: JSON exactly as specified by RFC 8259
+//   - [Lenient]: JSON with duplicate object keys, invalid UTF-8, comments, and trailing commas
+//   - [JSONC]: JSON with comments and trailing commas, as in many config files
+//   - [JSON5]: the JSON5 superset of JSON (see below)
+//   - [LLM]: JSON5, Python literals, and surrounding prose, as in the output of language models
+//
+// Comments are // to end of line and /* ... */.
+// A trailing comma is one just before the closing } or ] of an object or array.
+//
+// The JSON5 dialect adds: single-quoted strings;
+// unquoted object keys that are identifiers;
+// the escapes \', \x, \0, and \v,
+// backslash-newline line continuations,
+// and escaping of any other character as itself;
+// hexadecimal numbers;
+// numbers with a leading +
+// or with a leading or trailing decimal point.
+// Infinity and NaN are rejected, since they cannot be represented in JSON.
+// Duplicate object keys are allowed.
+//
+// The LLM dialect adds to JSON5:
+// the Python literals True, False, and None;
+// invalid UTF-8;
+// and it ignores any text outside the top-level arrays and objects in the input,
+// such as explanatory prose and Markdown code fences.
+// In this dialect, top-level values must be arrays or objects.
+//
+// Except in the Strict8259 dialect,
+// input is normalized to strict JSON on the fly before parsing,
+// so byte offsets in error messages refer to the normalized input.
+type Dialect struct {
+	name           string
+	comments       bool
+	trailingCommas bool
+	json5          bool
+	llm            bool
+	opts           []jsontext.Options
+	escape         EscapeFunc
+}
+
+// The predefined dialects. See [Dialect].
+var (
+	Strict8259 = Dialect{name: "strict8259"}
+	Lenient    = Dialect{
+		name:           "lenient",
+		comments:       true,
+		trailingCommas: true,
+		opts:           []jsontext.Options{jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true)},
+	}
+	JSONC = Dialect{name: "jsonc", comments: true, trailingCommas: true}
+	JSON5 = Dialect{
+		name:           "json5",
+		comments:       true,
+		trailingCommas: true,
+		json5:          true,
+		opts:           []jsontext.Options{jsontext.AllowDuplicateNames(true)},
+	}
+	LLM = Dialect{
+		name:           "llm",
+		comments:       true,
+		trailingCommas: true,
+		json5:          true,
+		llm:            true,
+		opts:           []jsontext.Options{jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true)},
+	}
+)
+
+// String returns the name of d.
+func (d Dialect) String() string {
+	return d.name
+}
+
+// EscapeFunc translates an escape sequence in a string
+// that would otherwise be rejected,
+// returning the text it stands for,
+// or an error.
+// See [Dialect.WithEscapes].
+type EscapeFunc func(seq string) (string, error)
+
+// WithEscapes returns a copy of d
+// that calls f for each escape sequence in a string that d would otherwise reject.
+// The sequence passed to f is one of:
+//
+//   - \x followed by the next two characters, e.g. \x41
+//   - \u followed by four hex digits that are an unpaired UTF-16 surrogate, e.g. \ud800
+//   - a backslash followed by any other non-standard character, e.g. \q
+//
+// (In the JSON5 and LLM dialects only the unpaired surrogates are non-standard.)
+// The text that f returns replaces the sequence in the string.
+// If f returns an error,
+// parsing fails with an [*EscapeError] giving the location of the string.
+func (d Dialect) WithEscapes(f EscapeFunc) Dialect {
+	d.escape = f
+	return d
+}
+
+// EscapeError is the error produced
+// when the [EscapeFunc] of a [Dialect] rejects an escape sequence.
+type EscapeError struct {
+	// Pointer is the location of the string containing the escape sequence.
+	// For an object key, it is the location of the object.
+	Pointer Pointer
+
+	Seq string
+	Err error
+}
+
+func (e *EscapeError) Error() string {
+	return fmt.Sprintf("at %q: escape sequence %s: %s", e.Pointer.Text(), e.Seq, e.Err)
+}
+
+func (e *EscapeError) Unwrap() error {
+	return e.Err
+}
+
+// Options returns the [jsontext.Options] for parsing input in dialect d.
+func (d Dialect) Options() []jsontext.Options {
+	return d.opts
+}
+
+// Reader returns a reader that normalizes the input in r,
+// which is in dialect d,
+// to strict JSON.
+func (d Dialect) Reader(r io.Reader) io.Reader {
+	if !d.comments && !d.trailingCommas && !d.json5 && !d.llm && d.escape == nil {
+		return r
+	}
+	return &dialectReader{d: d, r: bufio.NewReader(r)}
+}
+
+// Tokens is like the top-level [Tokens] function
+// but parses input in dialect d.
+func (d Dialect) Tokens(r io.Reader) (iter.Seq[jsontext.Token], *error) {
+	return Tokens(d.Reader(r), d.opts...)
+}
+
+// Source returns a [Source] that parses input in dialect d from r.
+func (d Dialect) Source(r io.Reader) Source {
+	return ReaderSource(d.Reader(r), d.opts...)
+}
+
+// dialectReader normalizes its input to strict JSON
+// according to its dialect.
+type dialectReader struct {
+	d   Dialect
+	r   *bufio.Reader
+	out []byte
+	err error
+
+	stack     []byte // open containers, '{' or '['
+	expectKey bool   // whether an object key may come next
+
+	// When there is an EscapeFunc,
+	// path tracks the location in the input for error reporting.
+	// It has an element for each element of stack.
+	path  Pointer
+	inKey bool // whether the string being read is an object key
+
+	// A comma is held back until the next significant character shows
+	// whether it is a trailing comma.
+	// Whitespace and comments that follow it are held in pending.
+	pendingComma bool
+	pending      []byte
+}
+
+func (dr *dialectReader) Read(buf []byte) (int, error) {
+	for len(dr.out) == 0 && dr.err == nil {
+		dr.err = dr.step()
+	}
+	n := copy(buf, dr.out)
+	dr.out = dr.out[n:]
+	if len(dr.out) == 0 {
+		dr.out = nil
+		if dr.err != nil {
+			return n, dr.err
+		}
+	}
+	return n, nil
+}
+
+// step normalizes the next lexeme in the input.
+func (dr *dialectReader) step() error {
+	c, err := dr.r.ReadByte()
+	if err != nil {
+		dr.significant(false)
+		return err
+	}
+
+	switch c {
+	case ' ', '\t', '\r', '\n':
+		dr.space(c)
+		return nil
+
+	case '/':
+		if dr.d.comments {
+			return dr.comment()
+		}
+	}
+
+	if dr.d.llm && len(dr.stack) == 0 && c != '{' && c != '[' {
+		// Prose outside of any top-level value.
+		return nil
+	}
+
+	expectKey := dr.expectKey
+	dr.expectKey = false
+
+	switch {
+	case c == '{' || c == '[':
+		dr.significant(false)
+		dr.out = append(dr.out, c)
+		dr.stack = append(dr.stack, c)
+		dr.expectKey = c == '{'
+		if dr.d.escape != nil {
+			if c == '{' {
+				dr.path = append(dr.path, "")
+			} else {
+				dr.path = append(dr.path, 0)
+			}
+		}
+
+	case c == '}' || c == ']':
+		dr.significant(true)
+		dr.out = append(dr.out, c)
+		if len(dr.stack) > 0 {
+			dr.stack = dr.stack[:len(dr.stack)-1]
+		}
+		if len(dr.path) > 0 {
+			dr.path = dr.path[:len(dr.path)-1]
+		}
+
+	case c == ',':
+		dr.significant(false)
+		if dr.d.trailingCommas {
+			dr.pendingComma = true
+		} else {
+			dr.out = append(dr.out, c)
+		}
+		dr.expectKey = len(dr.stack) > 0 && dr.stack[len(dr.stack)-1] == '{'
+		if n := len(dr.path); n > 0 {
+			if i, ok := dr.path[n-1].(int); ok {
+				dr.path[n-1] = i + 1
+			}
+		}
+
+	case c == '"' || (c == '\'' && dr.d.json5):
+		dr.significant(false)
+		start := len(dr.out)
+		dr.inKey = expectKey
+		if err := dr.str(c); err != nil {
+			return err
+		}
+		if expectKey {
+			dr.setKey(dr.out[start:])
+		}
+
+	case dr.d.json5 && isIdentStart(c):
+		dr.significant(false)
+		return dr.ident(c, expectKey)
+
+	case dr.d.json5 && (c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9')):
+		dr.significant(false)
+		return dr.number(c)
+
+	default:
+		dr.significant(false)
+		dr.out = append(dr.out, c)
+	}
+
+	return nil
+}
+
+// space handles a whitespace character,
+// or the replacement for a comment.
+func (dr *dialectReader) space(c byte) {
+	if dr.pendingComma {
+		dr.pending = append(dr.pending, c)
+	} else {
+		dr.out = append(dr.out, c)
+	}
+}
+
+// significant is called before emitting a significant character.
+// It resolves any pending comma,
+// dropping it if the character is a closing bracket.
+func (dr *dialectReader) significant(closer bool) {
+	if !dr.pendingComma {
+		return
+	}
+	if !closer {
+		dr.out = append(dr.out, ',')
+	}
+	dr.out = append(dr.out, dr.pending...)
+	dr.pending = dr.pending[:0]
+	dr.pendingComma = false
+}
+
+func (dr *dialectReader) comment() error {
+	next, err := dr.r.ReadByte()
+	if err != nil {
+		dr.significant(false)
+		dr.out = append(dr.out, '/')
+		return err
+	}
+
+	switch next {
+	case '/':
+		for {
+			c, err := dr.r.ReadByte()
+			if err != nil {
+				return err
+			}
+			if c == '\n' {
+				dr.space('\n')
+				return nil
+			}
+		}
+
+	case '*':
+		var star bool
+		for {
+			c, err := dr.r.ReadByte()
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return err
+			}
+			if star && c == '/' {
+				dr.space(' ')
+				return nil
+			}
+			star = c == '*'
+		}
+	}
+
+	// Not a comment. Let the parser report the error.
+	dr.significant(false)
+	dr.out = append(dr.out, '/', next)
+	return nil
+}
+
+// str normalizes a string whose opening quotation mark q has been read.
+func (dr *dialectReader) str(q byte) error {
+	dr.out = append(dr.out, '"')
+	for {
+		c, err := dr.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case q:
+			dr.out = append(dr.out, '"')
+			return nil
+
+		case '"':
+			// Only in a single-quoted string.
+			dr.out = append(dr.out, '\\', '"')
+
+		case '\\':
+			if err := dr.escape(); err != nil {
+				return err
+			}
+
+		default:
+			dr.out = append(dr.out, c)
+		}
+	}
+}
+
+// setKey records the normalized, quoted object key q in dr.path.
+func (dr *dialectReader) setKey(q []byte) {
+	n := len(dr.path)
+	if n == 0 {
+		return
+	}
+	tok, err := jsontext.NewDecoder(bytes.NewReader(q)).ReadToken()
+	if err != nil {
+		return
+	}
+	dr.path[n-1] = tok.String()
+}
+
+// escape normalizes an escape sequence in a string,
+// whose backslash has been read.
+func (dr *dialectReader) escape() error {
+	e, err := dr.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	switch e {
+	case 'u':
+		if dr.d.escape != nil {
+			return dr.unicodeEscape()
+		}
+		fallthrough
+
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		dr.out = append(dr.out, '\\', e)
+		return nil
+	}
+
+	if !dr.d.json5 {
+		if dr.d.escape == nil {
+			// Let the parser report the error.
+			dr.out = append(dr.out, '\\', e)
+			return nil
+		}
+		seq := []byte{'\\', e}
+		if e == 'x' {
+			var hex [2]byte
+			n, err := io.ReadFull(dr.r, hex[:])
+			seq = append(seq, hex[:n]...)
+			if err != nil {
+				return err
+			}
+		}
+		return dr.translate(string(seq))
+	}
+
+	switch e {
+	case 'x':
+		var hex [2]byte
+		if _, err := io.ReadFull(dr.r, hex[:]); err != nil {
+			return err
+		}
+		dr.out = append(dr.out, `\u00`...)
+		dr.out = append(dr.out, hex[:]...)
+
+	case '0':
+		dr.out = append(dr.out, `\u0000`...)
+
+	case 'v':
+		dr.out = append(dr.out, `\u000b`...)
+
+	case '\n':
+		// Line continuation.
+
+	case '\r':
+		// Line continuation.
+		if next, err := dr.r.Peek(1); err == nil && next[0] == '\n' {
+			dr.r.ReadByte()
+		}
+
+	default:
+		// Any other character escapes itself.
+		dr.out = append(dr.out, e)
+	}
+	return nil
+}
+
+// unicodeEscape normalizes a \\u escape sequence,
+// whose \\u has been read,
+// checking for unpaired surrogates.
+func (dr *dialectReader) unicodeEscape() error {
+	var hex [4]byte
+	n, err := io.ReadFull(dr.r, hex[:])
+	if err != nil {
+		dr.out = append(dr.out, `\u`...)
+		dr.out = append(dr.out, hex[:n]...)
+		return err
+	}
+	seq := `\u` + string(hex[:])
+	r, err := strconv.ParseUint(string(hex[:]), 16, 16)
+	switch {
+	case err != nil:
+		// Let the parser report the error.
+
+	case r >= 0xd800 && r < 0xdc00:
+		next, _ := dr.r.Peek(6)
+		if len(next) == 6 && next[0] == '\\' && next[1] == 'u' {
+			if lo, err := strconv.ParseUint(string(next[2:]), 16, 16); err == nil && lo >= 0xdc00 && lo < 0xe000 {
+				dr.r.Discard(6)
+				dr.out = append(dr.out, seq...)
+				dr.out = append(dr.out, next...)
+				return nil
+			}
+		}
+		return dr.translate(seq)
+
+	case r >= 0xdc00 && r < 0xe000:
+		return dr.translate(seq)
+	}
+
+	dr.out = append(dr.out, seq...)
+	return nil
+}
+
+// translate replaces the escape sequence seq in a string
+// with the result of the dialect's EscapeFunc.
+func (dr *dialectReader) translate(seq string) error {
+	text, err := dr.d.escape(seq)
+	if err == nil {
+		var q []byte
+		if q, err = jsontext.AppendQuote(nil, text); err == nil {
+			dr.out = append(dr.out, q[1:len(q)-1]...)
+			return nil
+		}
+	}
+
+	pointer := dr.path
+	if dr.inKey && len(pointer) > 0 {
+		pointer = pointer[:len(pointer)-1]
+	}
+	return &EscapeError{Pointer: slices.Clone(pointer), Seq: seq, Err: err}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// ident normalizes an identifier whose first character c has been read.
+func (dr *dialectReader) ident(c byte, expectKey bool) error {
+	word, err := dr.readWhile(c, isIdentChar)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case expectKey:
+		dr.out = append(dr.out, '"')
+		dr.out = append(dr.out, word...)
+		dr.out = append(dr.out, '"')
+		dr.setKey([]byte(strconv.Quote(word)))
+		return nil
+
+	case word == "Infinity" || word == "NaN":
+		return fmt.Errorf("%s cannot be represented in JSON", word)
+
+	case dr.d.llm && word == "True":
+		word = "true"
+	case dr.d.llm && word == "False":
+		word = "false"
+	case dr.d.llm && word == "None":
+		word = "null"
+	}
+
+	// Anything else other than true, false, or null is an error for the parser to report.
+	dr.out = append(dr.out, word...)
+	return nil
+}
+
+// number normalizes a number whose first character c has been read.
+func (dr *dialectReader) number(c byte) error {
+	s, err := dr.readWhile(c, func(c byte) bool {
+		return c == '+' || c == '-' || c == '.' || isIdentChar(c)
+	})
+	if err != nil {
+		return err
+	}
+
+	s = strings.TrimPrefix(s, "+")
+	var sign string
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		sign, s = "-", rest
+	}
+
+	switch {
+	case s == "Infinity" || s == "NaN":
+		return fmt.Errorf("%s%s cannot be represented in JSON", sign, s)
+
+	case strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X"):
+		if n, ok := new(big.Int).SetString(s[2:], 16); ok {
+			s = n.String()
+		}
+
+	default:
+		if strings.HasPrefix(s, ".") {
+			s = "0" + s
+		}
+		if i := strings.IndexByte(s, '.'); i >= 0 && (i+1 == len(s) || s[i+1] < '0' || s[i+1] > '9') {
+			s = s[:i+1] + "0" + s[i+1:]
+		}
+	}
+
+	dr.out = append(dr.out, sign...)
+	dr.out = append(dr.out, s...)
+	return nil
+}
+
+// readWhile returns c followed by the input characters satisfying pred.
+func (dr *dialectReader) readWhile(c byte, pred func(byte) bool) (string, error) {
+	buf := []byte{c}
+	for {
+		next, err := dr.r.Peek(1)
+		if err == io.EOF {
+			return string(buf), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if !pred(next[0]) {
+			return string(buf), nil
+		}
+		dr.r.ReadByte()
+		buf = append(buf, next[0])
+	}
+}
@@ -0,0 +1,215 @@
+package jseq_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestDialects(t *testing.T) {
+	cases := []struct {
+		name    string
+		dialect jseq.Dialect
+		inp     string
+		want    []string // top-level values, re-encoded
+		wantErr bool
+	}{{
+		name:    "strict",
+		dialect: jseq.Strict8259,
+		inp:     `{"a": [1, 2]}`,
+		want:    []string{`{"a":[1,2]}`},
+	}, {
+		name:    "strict_rejects_comments",
+		dialect: jseq.Strict8259,
+		inp:     `{"a": 1 /* one */}`,
+		wantErr: true,
+	}, {
+		name:    "strict_rejects_duplicates",
+		dialect: jseq.Strict8259,
+		inp:     `{"a": 1, "a": 2}`,
+		wantErr: true,
+	}, {
+		name:    "jsonc",
+		dialect: jseq.JSONC,
+		inp:     "{\n  // comment\n  \"a\": [1, 2,], /* another */\n  \"b\": \"x // not a comment\",\n}",
+		want:    []string{`{"a":[1,2],"b":"x // not a comment"}`},
+	}, {
+		name:    "jsonc_rejects_unquoted_keys",
+		dialect: jseq.JSONC,
+		inp:     `{a: 1}`,
+		wantErr: true,
+	}, {
+		name:    "lenient",
+		dialect: jseq.Lenient,
+		inp:     `{"a": 1, "a": 2,} [3,]`,
+		want:    []string{`{"a":2}`, `[3]`},
+	}, {
+		name:    "json5",
+		dialect: jseq.JSON5,
+		inp:     `{unquoted: 'single "quoted"', hex: 0x1F, neg: -0XFF, plus: +1, lead: .5, trail: 5., esc: '\x41\'\v', cont: 'a\` + "\n" + `b', $_x1: [true, false, null,],}`,
+		want:    []string{`{"$_x1":[true,false,null],"cont":"ab","esc":"A'\u000b","hex":31,"lead":0.5,"neg":-255,"plus":1,"trail":5.0,"unquoted":"single \"quoted\""}`},
+	}, {
+		name:    "json5_rejects_infinity",
+		dialect: jseq.JSON5,
+		inp:     `[-Infinity]`,
+		wantErr: true,
+	}, {
+		name:    "json5_rejects_unquoted_values",
+		dialect: jseq.JSON5,
+		inp:     `{a: b}`,
+		wantErr: true,
+	}, {
+		name:    "llm",
+		dialect: jseq.LLM,
+		inp:     "Sure! Here is the JSON you asked for:\n\n```json\n{'ok': True, 'items': [None, 1,],}\n```\n\nLet me know if you need anything else.",
+		want:    []string{`{"items":[null,1],"ok":true}`},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, errptr1 := tc.dialect.Tokens(strings.NewReader(tc.inp))
+			values, errptr2 := jseq.Values(tokens)
+
+			var got []string
+			for pointer, val := range values {
+				if len(pointer) > 0 {
+					continue
+				}
+				b, err := jseq.Marshal(val)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, string(b))
+			}
+			err := errors.Join(*errptr1, *errptr2)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("got no error, want one")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDialectReaderSmallReads(t *testing.T) {
+	const inp = `{a: 1, /* x */ b: [2,],}`
+	r := jseq.JSON5.Reader(strings.NewReader(inp))
+
+	var (
+		buf [1]byte
+		got []byte
+	)
+	for {
+		n, err := r.Read(buf[:])
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := `{"a": 1,   "b": [2]}`; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDialectEscapes(t *testing.T) {
+	errBad := errors.New("bad escape")
+	escape := func(seq string) (string, error) {
+		switch {
+		case strings.HasPrefix(seq, `\x`):
+			n, err := strconv.ParseUint(seq[2:], 16, 8)
+			if err != nil {
+				return "", err
+			}
+			return string(rune(n)), nil
+		case strings.HasPrefix(seq, `\u`):
+			return "\uFFFD", nil
+		}
+		return "", errBad
+	}
+
+	cases := []struct {
+		name     string
+		dialect  jseq.Dialect
+		inp      string
+		want     string
+		wantPtr  jseq.Pointer
+		wantSeq  string
+		wantFail bool
+	}{{
+		name:    "hex",
+		dialect: jseq.Lenient,
+		inp:     `{"a": "\x41\x42"}`,
+		want:    `{"a":"AB"}`,
+	}, {
+		name:    "surrogates",
+		dialect: jseq.Strict8259,
+		inp:     `["\ud800x", "\udc00", "\ud83d\ude00"]`,
+		want:    "[\"\uFFFDx\",\"\uFFFD\",\"\U0001F600\"]",
+	}, {
+		name:     "rejected",
+		dialect:  jseq.Lenient,
+		inp:      `{"a": [1, {"b": "\q"}]}`,
+		wantPtr:  jseq.Pointer{"a", 1, "b"},
+		wantSeq:  `\q`,
+		wantFail: true,
+	}, {
+		name:     "rejected_key",
+		dialect:  jseq.JSONC,
+		inp:      `{"a": {"\q": 1}}`,
+		wantPtr:  jseq.Pointer{"a"},
+		wantSeq:  `\q`,
+		wantFail: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, errptr1 := tc.dialect.WithEscapes(escape).Tokens(strings.NewReader(tc.inp))
+			values, errptr2 := jseq.Values(tokens)
+
+			var got string
+			for pointer, val := range values {
+				if len(pointer) > 0 {
+					continue
+				}
+				b, err := jseq.Marshal(val)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = string(b)
+			}
+			err := errors.Join(*errptr1, *errptr2)
+
+			if tc.wantFail {
+				var eerr *jseq.EscapeError
+				if !errors.As(err, &eerr) {
+					t.Fatalf("got error %v, want EscapeError", err)
+				}
+				if !reflect.DeepEqual(eerr.Pointer, tc.wantPtr) || eerr.Seq != tc.wantSeq || !errors.Is(err, errBad) {
+					t.Errorf("got %+v, want pointer %v and sequence %s", eerr, tc.wantPtr, tc.wantSeq)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
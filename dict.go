@@ -0,0 +1,288 @@
+package jseq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+
+	"github.com/bobg/errors"
+)
+
+// The "jseq-dict" format is a compact binary encoding of a stream of JSON values,
+// for intermediate files in pipelines
+// whose records repeat the same object keys and string values.
+// It is written by [DictSink] and read via [Open].
+//
+// The format begins with dictMagic,
+// followed by a sequence of tokens,
+// each an opcode byte and its operands.
+// Each string (key or value) of up to maxDictString bytes
+// is added to a dictionary the first time it appears,
+// while there is room,
+// and thereafter is written as its index in the dictionary.
+const dictMagic = "JSEQDICT\x01"
+
+const (
+	maxDictEntries = 1 << 16
+	maxDictString  = 256
+)
+
+const (
+	dictBeginObject byte = iota + 1
+	dictEndObject
+	dictBeginArray
+	dictEndArray
+	dictNull
+	dictTrue
+	dictFalse
+	dictNumber  // uvarint length, raw JSON number
+	dictNewStr  // uvarint length, bytes; added to the dictionary
+	dictStrRef  // uvarint dictionary index
+	dictLiteral // uvarint length, bytes; not added to the dictionary
+)
+
+// DictSink returns a [Sink] that writes each top-level value it consumes to w
+// in the "jseq-dict" format,
+// a compact binary encoding in which repeated object keys and string values
+// are replaced by references to their first occurrence.
+// Values that are not top-level are ignored.
+// Object keys are written in sorted order (see [Encode]).
+//
+// The output can be read back with [Open].
+// Closing the sink flushes its output but does not close w.
+func DictSink(w io.Writer) Sink {
+	return &dictSink{w: bufio.NewWriter(w), dict: make(map[string]uint64)}
+}
+
+type dictSink struct {
+	w           *bufio.Writer
+	dict        map[string]uint64
+	wroteHeader bool
+}
+
+func (s *dictSink) Consume(pointer Pointer, val any) error {
+	if len(pointer) > 0 {
+		return nil
+	}
+	if err := s.header(); err != nil {
+		return err
+	}
+
+	b, err := Marshal(val)
+	if err != nil {
+		return err
+	}
+	dec := jsontext.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := dec.ReadToken()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.writeToken(tok); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *dictSink) Close() error {
+	if err := s.header(); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+func (s *dictSink) header() error {
+	if s.wroteHeader {
+		return nil
+	}
+	s.wroteHeader = true
+	_, err := s.w.WriteString(dictMagic)
+	return err
+}
+
+func (s *dictSink) writeToken(tok jsontext.Token) error {
+	switch tok.Kind() {
+	case '{':
+		return s.w.WriteByte(dictBeginObject)
+	case '}':
+		return s.w.WriteByte(dictEndObject)
+	case '[':
+		return s.w.WriteByte(dictBeginArray)
+	case ']':
+		return s.w.WriteByte(dictEndArray)
+	case 'n':
+		return s.w.WriteByte(dictNull)
+	case 't':
+		return s.w.WriteByte(dictTrue)
+	case 'f':
+		return s.w.WriteByte(dictFalse)
+	case '0':
+		return s.writeBytes(dictNumber, tok.String())
+	case '"':
+		str := tok.String()
+		if idx, ok := s.dict[str]; ok {
+			if err := s.w.WriteByte(dictStrRef); err != nil {
+				return err
+			}
+			_, err := s.w.Write(binary.AppendUvarint(nil, idx))
+			return err
+		}
+		if len(str) > maxDictString || len(s.dict) >= maxDictEntries {
+			return s.writeBytes(dictLiteral, str)
+		}
+		s.dict[str] = uint64(len(s.dict))
+		return s.writeBytes(dictNewStr, str)
+	}
+	return fmt.Errorf("unknown token kind %v", tok.Kind())
+}
+
+func (s *dictSink) writeBytes(op byte, str string) error {
+	if err := s.w.WriteByte(op); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(binary.AppendUvarint(nil, uint64(len(str)))); err != nil {
+		return err
+	}
+	_, err := s.w.WriteString(str)
+	return err
+}
+
+func detectDict(filename, contentType string, peek []byte) bool {
+	return bytes.HasPrefix(peek, []byte(dictMagic))
+}
+
+func openDict(r io.Reader) (Source, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(dictMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, errors.Wrap(err, "reading header")
+	}
+	if string(magic) != dictMagic {
+		return nil, fmt.Errorf("bad header %q", magic)
+	}
+	return &dictSource{r: br}, nil
+}
+
+type dictSource struct {
+	r *bufio.Reader
+}
+
+func (s *dictSource) Tokens() (iter.Seq[jsontext.Token], *error) {
+	var err error
+
+	f := func(yield func(jsontext.Token) bool) {
+		var (
+			dict   []string
+			numDec = jsontext.NewDecoder(bytes.NewReader(nil))
+		)
+		for {
+			op, e := s.r.ReadByte()
+			if errors.Is(e, io.EOF) {
+				return
+			}
+			if e != nil {
+				err = e
+				return
+			}
+
+			var tok jsontext.Token
+			switch op {
+			case dictBeginObject:
+				tok = jsontext.BeginObject
+			case dictEndObject:
+				tok = jsontext.EndObject
+			case dictBeginArray:
+				tok = jsontext.BeginArray
+			case dictEndArray:
+				tok = jsontext.EndArray
+			case dictNull:
+				tok = jsontext.Null
+			case dictTrue:
+				tok = jsontext.True
+			case dictFalse:
+				tok = jsontext.False
+
+			case dictNumber:
+				raw, e := s.readBytes()
+				if e != nil {
+					err = e
+					return
+				}
+				numDec.Reset(bytes.NewReader(raw))
+				t, e := numDec.ReadToken()
+				if e != nil || t.Kind() != '0' {
+					err = fmt.Errorf("bad number %q", raw)
+					return
+				}
+				tok = t.Clone()
+
+			case dictNewStr, dictLiteral:
+				b, e := s.readBytes()
+				if e != nil {
+					err = e
+					return
+				}
+				str := string(b)
+				if op == dictNewStr {
+					dict = append(dict, str)
+				}
+				tok = jsontext.String(str)
+
+			case dictStrRef:
+				idx, e := binary.ReadUvarint(s.r)
+				if e != nil {
+					err = noEOF(e)
+					return
+				}
+				if idx >= uint64(len(dict)) {
+					err = fmt.Errorf("dictionary index %d out of range", idx)
+					return
+				}
+				tok = jsontext.String(dict[idx])
+
+			default:
+				err = fmt.Errorf("unknown opcode %d", op)
+				return
+			}
+
+			if !yield(tok) {
+				return
+			}
+		}
+	}
+	return f, &err
+}
+
+func (s *dictSource) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(s.r)
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	if n > math.MaxInt64 {
+		return nil, fmt.Errorf("string length %d out of range", n)
+	}
+
+	// The length is untrusted,
+	// so let the buffer grow only as the bytes arrive.
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, s.r, int64(n)); err != nil {
+		return nil, noEOF(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// noEOF converts io.EOF in the middle of a token to io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package jseq_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestDictSink(t *testing.T) {
+	var inp strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&inp, `{"customer_name": "Acme Corporation", "status": "shipped", "order": %d, "price": 1.50, "tags": ["priority", null, true, false], "note": %q}`+"\n", i, strings.Repeat("z", 300))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp.String())), jseq.DictSink(buf), nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() >= inp.Len() {
+		t.Errorf("encoded size %d is not smaller than input size %d", buf.Len(), inp.Len())
+	}
+
+	src, name, err := jseq.Open(bytes.NewReader(buf.Bytes()), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "jseq-dict" {
+		t.Fatalf("got format %s, want jseq-dict", name)
+	}
+
+	got := records(t, src)
+	want := records(t, jseq.ReaderSource(strings.NewReader(inp.String())))
+	if !reflect.DeepEqual(got, want) {
+		t.Error("round trip produced different values")
+	}
+}
+
+func TestDictSinkEmpty(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := jseq.DictSink(buf).Close(); err != nil {
+		t.Fatal(err)
+	}
+	src, name, err := jseq.Open(bytes.NewReader(buf.Bytes()), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "jseq-dict" {
+		t.Fatalf("got format %s, want jseq-dict", name)
+	}
+	if got := records(t, src); len(got) != 0 {
+		t.Errorf("got %d records, want 0", len(got))
+	}
+}
+
+func TestDictSourceHugeLength(t *testing.T) {
+	// A corrupt or hostile length must produce an error,
+	// not a panic or a huge allocation.
+	for _, n := range []uint64{1 << 62, math.MaxUint64} {
+		inp := []byte("JSEQDICT\x01")
+		inp = append(inp, 11) // a literal string
+		inp = binary.AppendUvarint(inp, n)
+		inp = append(inp, "abc"...)
+
+		src, _, err := jseq.Open(bytes.NewReader(inp), "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens, errptr := src.Tokens()
+		for range tokens {
+			t.Errorf("length %d: got a token, want none", n)
+		}
+		if *errptr == nil {
+			t.Errorf("length %d: got no error", n)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package jseq
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+)
+
+// ChangeKind is the kind of a [Change].
+type ChangeKind int
+
+const (
+	// Added means a value exists in the new version but not the old.
+	Added ChangeKind = iota
+
+	// Removed means a value exists in the old version but not the new.
+	Removed
+
+	// Modified means a value differs between the old and new versions.
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is a difference between two values, reported by [Diff].
+type Change struct {
+	Kind    ChangeKind
+	Pointer Pointer
+
+	// Old is the value at Pointer in the old version (nil for [Added]).
+	Old any
+
+	// New is the value at Pointer in the new version (nil for [Removed]).
+	New any
+}
+
+// Diff reports the differences between old and new,
+// which are values of the kind produced by [Values].
+// Objects are compared member by member,
+// in sorted key order (see [SortedKeys]),
+// and arrays element by element.
+// Where a value differs in type, or is a scalar that differs in value,
+// a single [Modified] change is reported for it.
+// Numbers are compared by their JSON representation,
+// so 1 and 1.0 differ.
+func Diff(old, new any) []Change {
+	var changes []Change
+	diff(old, new, nil, &changes)
+	return changes
+}
+
+func diff(old, new any, pointer Pointer, changes *[]Change) {
+	oldInner, newInner := decodedForm(old), decodedForm(new)
+	if o, ok := oldInner.(*Object); ok {
+		oldInner = o.Map()
+	}
+	if o, ok := newInner.(*Object); ok {
+		newInner = o.Map()
+	}
+
+	switch o := oldInner.(type) {
+	case map[string]any:
+		if n, ok := newInner.(map[string]any); ok {
+			for _, key := range SortedKeys(o) {
+				sub := append(slices.Clip(pointer), key)
+				if nv, ok := n[key]; ok {
+					diff(o[key], nv, sub, changes)
+				} else {
+					*changes = append(*changes, Change{Kind: Removed, Pointer: sub, Old: o[key]})
+				}
+			}
+			for _, key := range SortedKeys(n) {
+				if _, ok := o[key]; !ok {
+					*changes = append(*changes, Change{Kind: Added, Pointer: append(slices.Clip(pointer), key), New: n[key]})
+				}
+			}
+			return
+		}
+
+	case []any:
+		if n, ok := newInner.([]any); ok {
+			for i := range max(len(o), len(n)) {
+				sub := append(slices.Clip(pointer), i)
+				switch {
+				case i >= len(n):
+					*changes = append(*changes, Change{Kind: Removed, Pointer: sub, Old: o[i]})
+				case i >= len(o):
+					*changes = append(*changes, Change{Kind: Added, Pointer: sub, New: n[i]})
+				default:
+					diff(o[i], n[i], sub, changes)
+				}
+			}
+			return
+		}
+	}
+
+	if !scalarsEqual(oldInner, newInner) {
+		*changes = append(*changes, Change{Kind: Modified, Pointer: pointer, Old: old, New: new})
+	}
+}
+
+// decodedForm returns v with any [Expanded] wrapper removed
+// and any undecoded JSON decoded.
+func decodedForm(v any) any {
+	if e, ok := v.(Expanded); ok {
+		v = e.Value
+	}
+	if b, ok := rawBytes(v); ok {
+		if decoded, err := decodeRaw(b); err == nil {
+			v = decoded
+		}
+	}
+	return v
+}
+
+// scalarsEqual tells whether a and b have the same JSON encoding.
+// It is false if either cannot be encoded.
+func scalarsEqual(a, b any) bool {
+	ab, err := Marshal(a)
+	if err != nil {
+		return false
+	}
+	bb, err := Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ab, bb)
+}
@@ -0,0 +1,43 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestDiff(t *testing.T) {
+	old := map[string]any{
+		"a": jseq.Int(1),
+		"b": []any{"x", "y", "z"},
+		"c": map[string]any{"d": true},
+		"e": "gone",
+	}
+	new := map[string]any{
+		"a": jseq.Int(1),
+		"b": []any{"x", "Y"},
+		"c": jsontext.Value(`{"d": true, "f": null}`),
+		"g": "new",
+	}
+
+	got := jseq.Diff(old, new)
+	want := []jseq.Change{
+		{Kind: jseq.Modified, Pointer: jseq.Pointer{"b", 1}, Old: "y", New: "Y"},
+		{Kind: jseq.Removed, Pointer: jseq.Pointer{"b", 2}, Old: "z"},
+		{Kind: jseq.Added, Pointer: jseq.Pointer{"c", "f"}, New: jseq.Null{}},
+		{Kind: jseq.Removed, Pointer: jseq.Pointer{"e"}, Old: "gone"},
+		{Kind: jseq.Added, Pointer: jseq.Pointer{"g"}, New: "new"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := jseq.Diff(jseq.Int(1), []any{}); len(got) != 1 || got[0].Kind != jseq.Modified || len(got[0].Pointer) != 0 {
+		t.Errorf("got %v, want a single modification at the root", got)
+	}
+	if got := jseq.Diff(old, old); len(got) != 0 {
+		t.Errorf("got %v, want no changes", got)
+	}
+}
@@ -0,0 +1,169 @@
+package jseq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathOption is the type of an option that can be passed to [Pointer.Dotted] and [ParseDotted].
+type PathOption func(*pathStyle)
+
+type pathStyle struct {
+	indexBase int
+	brackets  bool
+}
+
+// IndexBase is a [PathOption] that sets the number of the first array element.
+// The default is 0.
+// Some systems number array elements from 1.
+func IndexBase(base int) PathOption {
+	return func(s *pathStyle) {
+		s.indexBase = base
+	}
+}
+
+// BracketIndexes is a [PathOption] that renders array indexes in brackets,
+// as in "a.b[0].c",
+// instead of as dotted path elements,
+// as in "a.b.0.c".
+func BracketIndexes() PathOption {
+	return func(s *pathStyle) {
+		s.brackets = true
+	}
+}
+
+func newPathStyle(opts []PathOption) pathStyle {
+	var s pathStyle
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// Dotted renders p in the dotted style used by many JSON tools,
+// e.g. "items.0.name".
+// See [PathOption] for ways to alter the style.
+//
+// Object keys containing ".", "[", or "\" have those characters escaped with a backslash.
+// Without [BracketIndexes],
+// an object key beginning with a digit has that digit escaped too,
+// to distinguish it from an array index.
+// The result can be converted back to a [Pointer] with [ParseDotted].
+func (p Pointer) Dotted(opts ...PathOption) string {
+	style := newPathStyle(opts)
+
+	var buf strings.Builder
+	for i, elt := range p {
+		switch elt := elt.(type) {
+		case string:
+			if i > 0 {
+				buf.WriteByte('.')
+			}
+			for j, c := range elt {
+				if c == '.' || c == '[' || c == '\\' || (j == 0 && !style.brackets && c >= '0' && c <= '9') {
+					buf.WriteByte('\\')
+				}
+				buf.WriteRune(c)
+			}
+
+		case int:
+			if style.brackets {
+				fmt.Fprintf(&buf, "[%d]", elt+style.indexBase)
+			} else {
+				if i > 0 {
+					buf.WriteByte('.')
+				}
+				buf.WriteString(strconv.Itoa(elt + style.indexBase))
+			}
+		}
+	}
+	return buf.String()
+}
+
+// ParseDotted parses a path in the style produced by [Pointer.Dotted]
+// with the same options.
+func ParseDotted(s string, opts ...PathOption) (Pointer, error) {
+	style := newPathStyle(opts)
+
+	var (
+		result  Pointer
+		buf     strings.Builder
+		escaped bool // whether the current segment contains an escape
+		pending bool // whether there is a segment in progress
+	)
+
+	finish := func() error {
+		if !pending {
+			return nil
+		}
+		seg := buf.String()
+		buf.Reset()
+		pending = false
+		if !style.brackets && !escaped {
+			if n, err := strconv.Atoi(seg); err == nil {
+				if n < style.indexBase {
+					return fmt.Errorf("index %d below base %d", n, style.indexBase)
+				}
+				result = append(result, n-style.indexBase)
+				return nil
+			}
+		}
+		result = append(result, seg)
+		escaped = false
+		return nil
+	}
+
+	if s == "" {
+		return nil, nil
+	}
+	pending = s[0] != '['
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("trailing backslash in %q", s)
+			}
+			i++
+			buf.WriteByte(s[i])
+			escaped = true
+			pending = true
+
+		case '.':
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			pending = true
+
+		case '[':
+			if !style.brackets {
+				return nil, fmt.Errorf("unexpected [ at position %d in %q", i, s)
+			}
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ at position %d in %q", i, s)
+			}
+			n, err := strconv.Atoi(s[i+1 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("bad index %q in %q", s[i+1:i+end], s)
+			}
+			if n < style.indexBase {
+				return nil, fmt.Errorf("index %d below base %d", n, style.indexBase)
+			}
+			result = append(result, n-style.indexBase)
+			i += end
+
+		default:
+			buf.WriteByte(c)
+			pending = true
+		}
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package jseq_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestDotted(t *testing.T) {
+	cases := []struct {
+		p    jseq.Pointer
+		opts []jseq.PathOption
+		want string
+	}{
+		{nil, nil, ""},
+		{jseq.Pointer{"a", 0, "b"}, nil, "a.0.b"},
+		{jseq.Pointer{"a", 0, "b"}, []jseq.PathOption{jseq.IndexBase(1)}, "a.1.b"},
+		{jseq.Pointer{"a", 0, "b"}, []jseq.PathOption{jseq.BracketIndexes()}, "a[0].b"},
+		{jseq.Pointer{0, 1, "b"}, []jseq.PathOption{jseq.BracketIndexes(), jseq.IndexBase(1)}, "[1][2].b"},
+		{jseq.Pointer{"a.b", "0", "x[y]", `c\d`}, nil, `a\.b.\0.x\[y].c\\d`},
+		{jseq.Pointer{"0", 0}, []jseq.PathOption{jseq.BracketIndexes()}, "0[0]"},
+	}
+
+	for _, tc := range cases {
+		got := tc.p.Dotted(tc.opts...)
+		if got != tc.want {
+			t.Errorf("%v.Dotted() = %q, want %q", tc.p, got, tc.want)
+			continue
+		}
+		back, err := jseq.ParseDotted(got, tc.opts...)
+		if err != nil {
+			t.Errorf("ParseDotted(%q): %s", got, err)
+			continue
+		}
+		if !reflect.DeepEqual(back, tc.p) {
+			t.Errorf("ParseDotted(%q) = %v, want %v", got, back, tc.p)
+		}
+	}
+
+	for _, bad := range []string{"a[0", "a.b\\", "a[x]"} {
+		if _, err := jseq.ParseDotted(bad, jseq.BracketIndexes()); err == nil {
+			t.Errorf("ParseDotted(%q): got no error", bad)
+		}
+	}
+	if _, err := jseq.ParseDotted("a.0", jseq.IndexBase(1)); err == nil {
+		t.Error("got no error for index below base")
+	}
+}
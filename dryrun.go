@@ -0,0 +1,137 @@
+package jseq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/bobg/errors"
+)
+
+// PipelineConfig is a configuration of the parsing options and transforms
+// of a pipeline run by [Pipe].
+// See [DryRun].
+type PipelineConfig struct {
+	Options    []Option
+	Transforms []Transform
+}
+
+// DryRunReport is the result of [DryRun].
+type DryRunReport struct {
+	// OldRecords and NewRecords are the numbers of records (top-level values)
+	// produced by the old and new configurations.
+	OldRecords, NewRecords int
+
+	// Diffs lists the output records that differ,
+	// in order.
+	Diffs []RecordDiff
+}
+
+// RecordDiff describes how an output record differs between two configurations.
+type RecordDiff struct {
+	// Record is the ordinal of the output record, counting from zero.
+	Record int
+
+	// Changes are the differences,
+	// from the old configuration's output to the new one's
+	// (see [Diff]).
+	// A record produced by only one configuration
+	// is a single [Added] or [Removed] change with an empty pointer.
+	Changes []Change
+}
+
+// Equal tells whether the two configurations produced the same output.
+func (r DryRunReport) Equal() bool {
+	return len(r.Diffs) == 0 && r.OldRecords == r.NewRecords
+}
+
+// WriteText writes r to w, one change per line,
+// in the form RECORD:POINTER: KIND VALUES,
+// where VALUES is the old value for a removal,
+// the new value for an addition,
+// and "OLD -> NEW" for a modification,
+// each in its JSON encoding.
+func (r DryRunReport) WriteText(w io.Writer) error {
+	for _, d := range r.Diffs {
+		for _, c := range d.Changes {
+			var vals string
+			switch c.Kind {
+			case Added:
+				vals = dryRunText(c.New)
+			case Removed:
+				vals = dryRunText(c.Old)
+			default:
+				vals = dryRunText(c.Old) + " -> " + dryRunText(c.New)
+			}
+			if _, err := fmt.Fprintf(w, "%d:%s: %s %s\n", d.Record, c.Pointer.Text(), c.Kind, vals); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func dryRunText(val any) string {
+	b, err := Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	return string(b)
+}
+
+// DryRun runs the JSON input in sample
+// through pipelines with two configurations,
+// old and new,
+// and reports how their outputs differ,
+// so that a change to a pipeline can be checked before it is rolled out.
+// The output records of the two configurations are compared in order,
+// the first with the first, the second with the second, and so on.
+//
+// The sample is read into memory,
+// so it should be of modest size.
+// Errors from either pipeline (see [Pipe]) are returned.
+func DryRun(ctx context.Context, sample io.Reader, old, new PipelineConfig) (DryRunReport, error) {
+	b, err := io.ReadAll(sample)
+	if err != nil {
+		return DryRunReport{}, errors.Wrap(err, "reading sample")
+	}
+
+	run := func(config PipelineConfig) ([]any, error) {
+		var records []any
+		sink := SinkFunc(func(pointer Pointer, val any) error {
+			if len(pointer) == 0 {
+				records = append(records, val)
+			}
+			return nil
+		})
+		err := Pipe(ctx, ReaderSource(bytes.NewReader(b)), sink, config.Options, config.Transforms...)
+		return records, err
+	}
+
+	oldRecords, err := run(old)
+	if err != nil {
+		return DryRunReport{}, errors.Wrap(err, "running old configuration")
+	}
+	newRecords, err := run(new)
+	if err != nil {
+		return DryRunReport{}, errors.Wrap(err, "running new configuration")
+	}
+
+	report := DryRunReport{OldRecords: len(oldRecords), NewRecords: len(newRecords)}
+	for i := range max(len(oldRecords), len(newRecords)) {
+		var changes []Change
+		switch {
+		case i >= len(newRecords):
+			changes = []Change{{Kind: Removed, Old: oldRecords[i]}}
+		case i >= len(oldRecords):
+			changes = []Change{{Kind: Added, New: newRecords[i]}}
+		default:
+			changes = Diff(oldRecords[i], newRecords[i])
+		}
+		if len(changes) > 0 {
+			report.Diffs = append(report.Diffs, RecordDiff{Record: i, Changes: changes})
+		}
+	}
+	return report, nil
+}
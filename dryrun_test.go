@@ -0,0 +1,80 @@
+package jseq_test
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestDryRun(t *testing.T) {
+	const sample = `
+{"price": 3, "quantity": 5}
+{"price": 1, "quantity": 2, "note": "x"}
+{"price": 2, "quantity": 2}
+`
+
+	compute := func(def string) jseq.Transform {
+		f, err := jseq.ParseComputedField(def)
+		if err != nil {
+			t.Fatal(err)
+		}
+		transform, _ := jseq.Compute(f)
+		return transform
+	}
+
+	// Drops records whose quantity is 2 and that have no note.
+	dropPairs := func(values iter.Seq2[jseq.Pointer, any]) iter.Seq2[jseq.Pointer, any] {
+		return func(yield func(jseq.Pointer, any) bool) {
+			for pointer, val := range values {
+				if len(pointer) == 0 {
+					m := val.(map[string]any)
+					if q, _ := m["quantity"].(jseq.Number).Int(); q == 2 {
+						if _, ok := m["note"]; !ok {
+							continue
+						}
+					}
+				}
+				if !yield(pointer, val) {
+					return
+				}
+			}
+		}
+	}
+
+	oldConfig := jseq.PipelineConfig{Transforms: []jseq.Transform{compute("total = price * quantity")}}
+	newConfig := jseq.PipelineConfig{Transforms: []jseq.Transform{compute("total = price * quantity + 1"), dropPairs}}
+
+	report, err := jseq.DryRun(context.Background(), strings.NewReader(sample), oldConfig, newConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Equal() {
+		t.Error("report is equal, want differences")
+	}
+	if report.OldRecords != 3 || report.NewRecords != 2 {
+		t.Errorf("got %d old and %d new records, want 3 and 2", report.OldRecords, report.NewRecords)
+	}
+
+	buf := new(strings.Builder)
+	if err := report.WriteText(buf); err != nil {
+		t.Fatal(err)
+	}
+	const want = `0:/total: modified 15 -> 16
+1:/total: modified 2 -> 3
+2:: removed {"price":2,"quantity":2,"total":4}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	report, err = jseq.DryRun(context.Background(), strings.NewReader(sample), oldConfig, oldConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Equal() {
+		t.Errorf("got %d diffs, want none", len(report.Diffs))
+	}
+}
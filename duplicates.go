@@ -0,0 +1,101 @@
+package jseq
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/bobg/errors"
+)
+
+// DuplicateKeyPolicy tells [Values] what to do about an object
+// containing more than one member with the same key.
+// See [DuplicateKeys].
+//
+// The JSON standard (RFC 8259) leaves the meaning of such objects undefined,
+// and [jsontext.Decoder] rejects them unless given the
+// [jsontext.AllowDuplicateNames] option,
+// so a policy matters only for token streams that allow them.
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicatesKeepLast keeps the value of the last member with a given key.
+	// This is the default.
+	DuplicatesKeepLast DuplicateKeyPolicy = iota
+
+	// DuplicatesKeepFirst keeps the value of the first member with a given key.
+	// Later values for the key are skipped without being produced.
+	DuplicatesKeepFirst
+
+	// DuplicatesError ends the sequence with a [*DuplicateKeyError]
+	// at the first duplicate key.
+	DuplicatesError
+)
+
+// DuplicateKeys is an [Option] that sets the policy of [Values]
+// for duplicate object keys.
+// Keys are compared after renaming by [CanonicalKeys], if any.
+// Except under [DuplicatesError],
+// each duplicate is reported as a warning (see [OnWarning]).
+func DuplicateKeys(policy DuplicateKeyPolicy) Option {
+	return func(c *config) {
+		c.duplicateKeys = policy
+	}
+}
+
+// DuplicateKeyError is the error produced under [DuplicatesError]
+// for a duplicate object key.
+type DuplicateKeyError struct {
+	Key string
+
+	// Pointer locates the duplicate member within its top-level value.
+	Pointer Pointer
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate key %q at %q", e.Key, e.Pointer.Text())
+}
+
+// checkDuplicate applies the duplicate-key policy to key,
+// the key of the next member of the object at pointer,
+// given the keys seen so far in that object.
+// It reports whether it skipped the member's value.
+func (p *parser) checkDuplicate(seen *map[string]struct{}, pointer Pointer, key string) (bool, error) {
+	if p.duplicateKeys == DuplicatesKeepLast {
+		return false, nil
+	}
+	if _, dup := (*seen)[key]; dup {
+		if p.duplicateKeys == DuplicatesError {
+			return false, &DuplicateKeyError{Key: key, Pointer: slices.Clone(append(pointer, key))}
+		}
+		p.warn(SeverityWarning, append(pointer, key), "duplicate key %q; the earlier value wins", key)
+		return true, errors.Wrapf(p.skipValue(append(pointer, key)), "skipping value for object key %q", key)
+	}
+	if *seen == nil {
+		*seen = make(map[string]struct{})
+	}
+	(*seen)[key] = struct{}{}
+	return false, nil
+}
+
+// skipValue consumes the tokens of the next value,
+// which is at pointer,
+// without producing anything.
+func (p *parser) skipValue(pointer Pointer) error {
+	var depth int
+	for {
+		tok, ok := p.next()
+		if !ok {
+			return p.endOfInput(pointer, io.ErrUnexpectedEOF)
+		}
+		switch tok.Kind() {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
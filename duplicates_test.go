@@ -0,0 +1,131 @@
+package jseq_test
+
+import (
+	"context"
+	"encoding/json/jsontext"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobg/jseq"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	const inp = `{"a": 1, "b": {"c": 2}, "a": [3, 4], "b": 5}`
+
+	cases := []struct {
+		name        string
+		policy      jseq.DuplicateKeyPolicy
+		opts        []jseq.Option
+		want        []string
+		wantWarn    int
+		wantPointer jseq.Pointer
+	}{
+		{
+			name:   "keep last",
+			policy: jseq.DuplicatesKeepLast,
+			want: []string{
+				`/a 1`,
+				`/b/c 2`,
+				`/b {"c":2}`,
+				`/a/0 3`,
+				`/a/1 4`,
+				`/a [3,4]`,
+				`/b 5`,
+				` {"a":[3,4],"b":5}`,
+			},
+			wantWarn: 2,
+		},
+		{
+			name:   "keep first",
+			policy: jseq.DuplicatesKeepFirst,
+			want: []string{
+				`/a 1`,
+				`/b/c 2`,
+				`/b {"c":2}`,
+				` {"a":1,"b":{"c":2}}`,
+			},
+			wantWarn: 2,
+		},
+		{
+			name:        "error",
+			policy:      jseq.DuplicatesError,
+			want:        []string{`/a 1`, `/b/c 2`, `/b {"c":2}`},
+			wantPointer: jseq.Pointer{"a"},
+		},
+		{
+			name:   "keep first unmaterialized",
+			policy: jseq.DuplicatesKeepFirst,
+			opts:   []jseq.Option{jseq.LeafOnly()},
+			want: []string{
+				`/a 1`,
+				`/b/c 2`,
+			},
+			wantWarn: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var warnings int
+			opts := append(tc.opts, jseq.DuplicateKeys(tc.policy), jseq.OnWarning(func(jseq.Warning) { warnings++ }))
+
+			tokens, _ := jseq.Tokens(strings.NewReader(inp), jsontext.AllowDuplicateNames(true))
+			values, errptr := jseq.Values(tokens, opts...)
+
+			var got []string
+			for pointer, val := range values {
+				b, err := jseq.Marshal(val)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, string(pointer.Text())+" "+string(b))
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+			if warnings != tc.wantWarn {
+				t.Errorf("got %d warnings, want %d", warnings, tc.wantWarn)
+			}
+
+			err := *errptr
+			if tc.wantPointer == nil {
+				if err != nil {
+					t.Errorf("got error %v, want none", err)
+				}
+				return
+			}
+			var derr *jseq.DuplicateKeyError
+			if !errors.As(err, &derr) {
+				t.Fatalf("got error %v, want a DuplicateKeyError", err)
+			}
+			if derr.Key != "a" || !reflect.DeepEqual(derr.Pointer, tc.wantPointer) {
+				t.Errorf("got key %q at %v, want %q at %v", derr.Key, derr.Pointer, "a", tc.wantPointer)
+			}
+		})
+	}
+}
+
+func TestDuplicateKeysSkipCanceled(t *testing.T) {
+	// The input stalls while a duplicate's value is being skipped.
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go pw.Write([]byte(`{"a": 1, "a": [2, `)) // and then nothing more
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	tokens, _ := jseq.Tokens(pr, jsontext.AllowDuplicateNames(true))
+	values, errptr := jseq.ValuesContext(ctx, tokens, jseq.DuplicateKeys(jseq.DuplicatesKeepFirst))
+	for range values {
+	}
+	if !errors.Is(*errptr, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", *errptr, context.DeadlineExceeded)
+	}
+	if errors.Is(*errptr, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v, want no %v", *errptr, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,152 @@
+package jseq
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Set returns a copy of root in which the value at p is replaced by val.
+// Missing objects along the path are created,
+// but missing arrays are not.
+// An array index may equal the length of the array,
+// in which case val is appended.
+// With the empty pointer, Set returns val.
+//
+// The input is not modified.
+// The result shares structure with root except along the path to p.
+func (p Pointer) Set(root, val any) (any, error) {
+	if len(p) == 0 {
+		return val, nil
+	}
+	root = decodedForm(root)
+
+	switch first := p[0].(type) {
+	case string:
+		var m map[string]any
+		switch r := root.(type) {
+		case nil:
+			m = make(map[string]any)
+		case map[string]any:
+			m = maps.Clone(r)
+		case *Object:
+			obj := r.clone()
+			old, _ := obj.Get(first)
+			child, err := p[1:].Set(old, val)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(first, child)
+			return obj, nil
+		default:
+			return nil, fmt.Errorf("type mismatch: non-object %T for key %q", root, first)
+		}
+		child, err := p[1:].Set(m[first], val)
+		if err != nil {
+			return nil, err
+		}
+		m[first] = child
+		return m, nil
+
+	case int:
+		a, ok := root.([]any)
+		if !ok {
+			return nil, fmt.Errorf("type mismatch: non-array %T for index %d", root, first)
+		}
+		if first < 0 || first > len(a) {
+			return nil, fmt.Errorf("array index %d out of bounds", first)
+		}
+		var old any
+		if first < len(a) {
+			old = a[first]
+		}
+		child, err := p[1:].Set(old, val)
+		if err != nil {
+			return nil, err
+		}
+		if first == len(a) {
+			return append(slices.Clip(a), child), nil
+		}
+		a = slices.Clone(a)
+		a[first] = child
+		return a, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected %T in Pointer", first)
+	}
+}
+
+// Remove returns a copy of root without the value at p.
+// An object member is deleted;
+// an array element is removed, shifting later elements down.
+// If p does not locate anything in root, root is returned unchanged.
+// It is an error to remove the root itself.
+//
+// The input is not modified.
+// The result shares structure with root except along the path to p.
+func (p Pointer) Remove(root any) (any, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("cannot remove the root")
+	}
+	orig := root
+	root = decodedForm(root)
+
+	switch first := p[0].(type) {
+	case string:
+		if obj, ok := root.(*Object); ok {
+			child, ok := obj.Get(first)
+			if !ok {
+				return orig, nil
+			}
+			obj = obj.clone()
+			if len(p) == 1 {
+				obj.Delete(first)
+				return obj, nil
+			}
+			newChild, err := p[1:].Remove(child)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(first, newChild)
+			return obj, nil
+		}
+		m, ok := root.(map[string]any)
+		if !ok {
+			return orig, nil
+		}
+		child, ok := m[first]
+		if !ok {
+			return orig, nil
+		}
+		m = maps.Clone(m)
+		if len(p) == 1 {
+			delete(m, first)
+			return m, nil
+		}
+		newChild, err := p[1:].Remove(child)
+		if err != nil {
+			return nil, err
+		}
+		m[first] = newChild
+		return m, nil
+
+	case int:
+		a, ok := root.([]any)
+		if !ok || first < 0 || first >= len(a) {
+			return orig, nil
+		}
+		if len(p) == 1 {
+			return slices.Delete(slices.Clone(a), first, first+1), nil
+		}
+		newChild, err := p[1:].Remove(a[first])
+		if err != nil {
+			return nil, err
+		}
+		a = slices.Clone(a)
+		a[first] = newChild
+		return a, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected %T in Pointer", first)
+	}
+}
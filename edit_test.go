@@ -0,0 +1,76 @@
+package jseq_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestPointerSet(t *testing.T) {
+	root := map[string]any{"a": []any{"x"}}
+
+	cases := []struct {
+		ptr     jseq.Pointer
+		val     any
+		want    any
+		wantErr bool
+	}{
+		{ptr: jseq.Pointer{"a", 0}, val: "y", want: map[string]any{"a": []any{"y"}}},
+		{ptr: jseq.Pointer{"a", 1}, val: "y", want: map[string]any{"a": []any{"x", "y"}}},
+		{ptr: jseq.Pointer{"b", "c"}, val: true, want: map[string]any{"a": []any{"x"}, "b": map[string]any{"c": true}}},
+		{ptr: nil, val: "z", want: "z"},
+		{ptr: jseq.Pointer{"a", 2}, val: "y", wantErr: true},
+		{ptr: jseq.Pointer{"a", "b"}, val: "y", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := tc.ptr.Set(root, tc.val)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("Set(%v): got no error", tc.ptr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Set(%v): %s", tc.ptr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Set(%v): got %v, want %v", tc.ptr, got, tc.want)
+		}
+	}
+
+	if !reflect.DeepEqual(root, map[string]any{"a": []any{"x"}}) {
+		t.Errorf("root was modified: %v", root)
+	}
+}
+
+func TestPointerRemove(t *testing.T) {
+	root := map[string]any{"a": []any{"x", "y", "z"}, "b": "c"}
+
+	cases := []struct {
+		ptr  jseq.Pointer
+		want any
+	}{
+		{ptr: jseq.Pointer{"a", 1}, want: map[string]any{"a": []any{"x", "z"}, "b": "c"}},
+		{ptr: jseq.Pointer{"b"}, want: map[string]any{"a": []any{"x", "y", "z"}}},
+		{ptr: jseq.Pointer{"nope", "x"}, want: root},
+	}
+	for _, tc := range cases {
+		got, err := tc.ptr.Remove(root)
+		if err != nil {
+			t.Errorf("Remove(%v): %s", tc.ptr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Remove(%v): got %v, want %v", tc.ptr, got, tc.want)
+		}
+	}
+
+	if _, err := (jseq.Pointer{}).Remove(root); err == nil {
+		t.Error("removing the root: got no error")
+	}
+	if len(root["a"].([]any)) != 3 || root["b"] != "c" {
+		t.Errorf("root was modified: %v", root)
+	}
+}
@@ -0,0 +1,170 @@
+package jseq
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"reflect"
+	"slices"
+
+	"github.com/bobg/errors"
+)
+
+// Encode writes the JSON encoding of val to w.
+//
+// The value must be of a type that can be produced by [Values]:
+// []any, map[string]any, string, bool, [Null], [Number], or [Expanded]
+// (which is encoded as its Value).
+// A nil value is encoded as null.
+// Undecoded JSON values
+// (of type [jsontext.Value] or [encoding/json.RawMessage])
+// are written as-is.
+// Object keys are written in sorted order
+// (see [SortedKeys]; for other orders see [KeyOrder.Encode]).
+//
+// The options are passed to [jsontext.NewEncoder].
+// By default, strings are written as raw UTF-8,
+// with only the escaping that JSON itself requires:
+// quotation marks, backslashes, and control characters.
+// To produce output that is safe to embed in HTML (e.g. in a <script> tag),
+// pass [jsontext.EscapeForHTML](true),
+// which escapes <, >, and &.
+// To produce output that is safe to embed in JavaScript source,
+// pass [jsontext.EscapeForJS](true),
+// which escapes U+2028 and U+2029.
+func Encode(w io.Writer, val any, opts ...jsontext.Options) error {
+	return LexicalOrder.Encode(w, val, opts...)
+}
+
+// Marshal returns the JSON encoding of val.
+// See [Encode].
+func Marshal(val any, opts ...jsontext.Options) ([]byte, error) {
+	return LexicalOrder.Marshal(val, opts...)
+}
+
+// Encode is like the top-level [Encode] function,
+// but writes object keys in order o.
+func (o KeyOrder) Encode(w io.Writer, val any, opts ...jsontext.Options) error {
+	enc := jsontext.NewEncoder(w, opts...)
+	return o.encode(enc, val)
+}
+
+// Marshal is like the top-level [Marshal] function,
+// but writes object keys in order o.
+func (o KeyOrder) Marshal(val any, opts ...jsontext.Options) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := o.Encode(buf, val, opts...); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func encodeValue(enc *jsontext.Encoder, val any) error {
+	return LexicalOrder.encode(enc, val)
+}
+
+func (o KeyOrder) encode(enc *jsontext.Encoder, val any) error {
+	switch val := val.(type) {
+	case nil, Null:
+		return enc.WriteToken(jsontext.Null)
+
+	case bool:
+		return enc.WriteToken(jsontext.Bool(val))
+
+	case string:
+		return enc.WriteToken(jsontext.String(val))
+
+	case Number:
+		return enc.WriteValue(jsontext.Value(val.raw))
+
+	case float64:
+		return enc.WriteToken(jsontext.Float(val))
+
+	case json.Number:
+		return enc.WriteValue(jsontext.Value(val))
+
+	case *big.Int:
+		return enc.WriteValue(jsontext.Value(val.String()))
+
+	case *big.Float:
+		return enc.WriteValue(jsontext.Value(val.Text('g', -1)))
+
+	case Expanded:
+		return o.encode(enc, val.Value)
+
+	case []any:
+		if err := enc.WriteToken(jsontext.BeginArray); err != nil {
+			return err
+		}
+		for i, elt := range val {
+			if err := o.encode(enc, elt); err != nil {
+				return errors.Wrapf(err, "encoding array element %d", i)
+			}
+		}
+		return enc.WriteToken(jsontext.EndArray)
+
+	case map[string]any:
+		if err := enc.WriteToken(jsontext.BeginObject); err != nil {
+			return err
+		}
+		for _, key := range o.Keys(val) {
+			if err := enc.WriteToken(jsontext.String(key)); err != nil {
+				return err
+			}
+			if err := o.encode(enc, val[key]); err != nil {
+				return errors.Wrapf(err, "encoding value for object key %q", key)
+			}
+		}
+		return enc.WriteToken(jsontext.EndObject)
+
+	case *Object:
+		// An ordered object keeps its own order.
+		if err := enc.WriteToken(jsontext.BeginObject); err != nil {
+			return err
+		}
+		for key, elt := range val.All() {
+			if err := enc.WriteToken(jsontext.String(key)); err != nil {
+				return err
+			}
+			if err := o.encode(enc, elt); err != nil {
+				return errors.Wrapf(err, "encoding value for object key %q", key)
+			}
+		}
+		return enc.WriteToken(jsontext.EndObject)
+
+	default:
+		if b, ok := rawBytes(val); ok {
+			return enc.WriteValue(jsontext.Value(b))
+		}
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.Map {
+			// A map produced by the MapKeys option.
+			return o.encodeMap(enc, rv)
+		}
+		return fmt.Errorf("cannot encode %T", val)
+	}
+}
+
+func (o KeyOrder) encodeMap(enc *jsontext.Encoder, m reflect.Value) error {
+	keys := make(map[string]reflect.Value, m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		keys[fmt.Sprint(iter.Key().Interface())] = iter.Value()
+	}
+
+	if err := enc.WriteToken(jsontext.BeginObject); err != nil {
+		return err
+	}
+	for _, key := range slices.SortedFunc(maps.Keys(keys), o) {
+		if err := enc.WriteToken(jsontext.String(key)); err != nil {
+			return err
+		}
+		if err := o.encode(enc, keys[key].Interface()); err != nil {
+			return errors.Wrapf(err, "encoding value for object key %q", key)
+		}
+	}
+	return enc.WriteToken(jsontext.EndObject)
+}
@@ -0,0 +1,57 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestMarshal(t *testing.T) {
+	val := []any{map[string]any{"z": jseq.Uint(1), "a": nil}, "s", false}
+	got, err := jseq.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `[{"a":null,"z":1},"s",false]`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestMarshalEscaping(t *testing.T) {
+	const s = "<a>&\u2028\u00e9\u2029</a>"
+
+	cases := []struct {
+		name string
+		opts []jsontext.Options
+		want string
+	}{{
+		name: "default",
+		want: "\"<a>&\u2028\u00e9\u2029</a>\"",
+	}, {
+		name: "html",
+		opts: []jsontext.Options{jsontext.EscapeForHTML(true)},
+		want: `"\u003ca\u003e\u0026` + "\u2028\u00e9\u2029" + `\u003c/a\u003e"`,
+	}, {
+		name: "js",
+		opts: []jsontext.Options{jsontext.EscapeForJS(true)},
+		want: `"<a>&\u2028` + "\u00e9" + `\u2029</a>"`,
+	}, {
+		name: "both",
+		opts: []jsontext.Options{jsontext.EscapeForHTML(true), jsontext.EscapeForJS(true)},
+		want: `"\u003ca\u003e\u0026\u2028` + "\u00e9" + `\u2029\u003c/a\u003e"`,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := jseq.Marshal(s, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package jseq
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+
+	"github.com/bobg/errors"
+)
+
+// EnrichSpec describes the enrichment performed by [Enrich].
+// Exactly one of Lookup and BatchLookup must be set.
+type EnrichSpec struct {
+	// Key locates the lookup key in each record.
+	// Records without a key pass through unchanged.
+	Key Pointer
+
+	// Target is where the lookup result goes in each record.
+	// If there is already an object there,
+	// and the result is an object,
+	// the result is merged into it (see [Merge]);
+	// otherwise the result replaces whatever is there.
+	Target Pointer
+
+	// Lookup looks up a single key.
+	// A nil result means the key was not found,
+	// and the record passes through unchanged.
+	Lookup func(ctx context.Context, key any) (any, error)
+
+	// BatchLookup looks up several keys at once,
+	// returning one result (possibly nil, as for Lookup) for each.
+	BatchLookup func(ctx context.Context, keys []any) ([]any, error)
+
+	// BatchSize is the maximum number of keys passed to BatchLookup.
+	// The default is 100.
+	BatchSize int
+
+	// Concurrency is the maximum number of calls to Lookup or BatchLookup
+	// in progress at once.
+	// The default is 1.
+	Concurrency int
+
+	// CacheSize is the number of lookup results to remember,
+	// evicting the least recently used.
+	// The default, zero, means no caching.
+	CacheSize int
+}
+
+// Enrich returns a [Transform] that enriches each record (top-level value)
+// with the result of looking up a key from the record,
+// as described by spec.
+// This is the classic step of joining a stream against a reference table.
+//
+// Lookups run concurrently,
+// but records are produced in their original order,
+// each preceded by the pairs inside it.
+// When a record is enriched,
+// the pairs for the enriched location and the objects containing it
+// are replaced by pairs for their new values.
+//
+// The first lookup error ends the sequence
+// and is placed in the returned error pointer,
+// which the caller may check after consuming the sequence.
+// Lookups are canceled if the caller stops early.
+func Enrich(ctx context.Context, spec EnrichSpec) (Transform, *error) {
+	var err error
+
+	lookup := spec.BatchLookup
+	if spec.Lookup != nil {
+		spec.BatchSize = 1
+		lookup = func(ctx context.Context, keys []any) ([]any, error) {
+			result, err := spec.Lookup(ctx, keys[0])
+			return []any{result}, err
+		}
+	}
+	if spec.BatchSize <= 0 {
+		spec.BatchSize = 100
+	}
+	if spec.Concurrency <= 0 {
+		spec.Concurrency = 1
+	}
+
+	t := func(values iter.Seq2[Pointer, any]) iter.Seq2[Pointer, any] {
+		return func(yield func(Pointer, any) bool) {
+			if (spec.Lookup == nil) == (spec.BatchLookup == nil) {
+				err = errors.New("enrich spec must have exactly one of Lookup and BatchLookup")
+				return
+			}
+			e := &enricher{
+				spec:   spec,
+				lookup: lookup,
+				sem:    make(chan struct{}, spec.Concurrency),
+				cache:  newLookupCache(spec.CacheSize),
+			}
+			err = e.run(ctx, values, yield)
+		}
+	}
+
+	return t, &err
+}
+
+type enricher struct {
+	spec   EnrichSpec
+	lookup func(context.Context, []any) ([]any, error)
+	sem    chan struct{}
+	cache  *lookupCache
+
+	batch []*enrichJob // jobs awaiting dispatch
+}
+
+type enrichJob struct {
+	held   []enrichPair // the pairs inside the record
+	rec    any
+	key    any
+	keyStr string // the encoded key, if there is one
+	result any
+	err    error
+	done   chan struct{}
+}
+
+type enrichPair struct {
+	pointer Pointer
+	val     any
+}
+
+func (e *enricher) run(ctx context.Context, values iter.Seq2[Pointer, any], yield func(Pointer, any) bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	var (
+		held     []enrichPair
+		queue    []*enrichJob
+		maxQueue = e.spec.Concurrency * e.spec.BatchSize
+	)
+
+	// finish produces the pairs for the job at the head of the queue.
+	finish := func() (bool, error) {
+		j := queue[0]
+		queue = queue[1:]
+		if slices.Contains(e.batch, j) {
+			e.dispatch(ctx, &wg)
+		}
+		<-j.done
+		if j.err != nil {
+			return false, j.err
+		}
+		if j.keyStr != "" {
+			e.cache.put(j.keyStr, j.result)
+		}
+		return e.emit(j, yield)
+	}
+
+	for pointer, val := range values {
+		if len(pointer) > 0 {
+			held = append(held, enrichPair{pointer: slices.Clone(pointer), val: val})
+			continue
+		}
+
+		j := &enrichJob{held: held, rec: val, done: make(chan struct{})}
+		held = nil
+		e.prepare(ctx, j, &wg)
+		queue = append(queue, j)
+
+		for len(queue) > maxQueue {
+			if ok, err := finish(); !ok || err != nil {
+				return err
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		if ok, err := finish(); !ok || err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepare finds j's key and arranges for its lookup.
+func (e *enricher) prepare(ctx context.Context, j *enrichJob, wg *sync.WaitGroup) {
+	key, err := e.spec.Key.Locate(j.rec)
+	if err != nil || key == nil {
+		close(j.done)
+		return
+	}
+	b, err := Marshal(key)
+	if err != nil {
+		close(j.done)
+		return
+	}
+	j.key, j.keyStr = key, string(b)
+
+	if result, ok := e.cache.get(j.keyStr); ok {
+		j.result = result
+		close(j.done)
+		return
+	}
+
+	e.batch = append(e.batch, j)
+	if len(e.batch) >= e.spec.BatchSize {
+		e.dispatch(ctx, wg)
+	}
+}
+
+// dispatch starts the lookup for the pending batch of jobs.
+func (e *enricher) dispatch(ctx context.Context, wg *sync.WaitGroup) {
+	if len(e.batch) == 0 {
+		return
+	}
+	batch := e.batch
+	e.batch = nil
+
+	e.sem <- struct{}{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() { <-e.sem }()
+
+		keys := make([]any, len(batch))
+		for i, j := range batch {
+			keys[i] = j.key
+		}
+		results, err := e.lookup(ctx, keys)
+		if err == nil && len(results) != len(keys) {
+			err = fmt.Errorf("got %d lookup results for %d keys", len(results), len(keys))
+		}
+		for i, j := range batch {
+			if err != nil {
+				j.err = errors.Wrapf(err, "looking up %s", j.keyStr)
+			} else {
+				j.result = results[i]
+			}
+			close(j.done)
+		}
+	}()
+}
+
+// emit produces the pairs for a finished job.
+func (e *enricher) emit(j *enrichJob, yield func(Pointer, any) bool) (bool, error) {
+	if j.result == nil {
+		for _, h := range j.held {
+			if !yield(h.pointer, h.val) {
+				return false, nil
+			}
+		}
+		return yield(nil, j.rec), nil
+	}
+
+	target := e.spec.Target
+	newVal := j.result
+	if old, err := target.Locate(j.rec); err == nil && old != nil {
+		newVal = Merge(old, newVal)
+	}
+	newRec, err := target.Set(j.rec, newVal)
+	if err != nil {
+		return false, errors.Wrapf(err, "setting enrichment for %s", j.keyStr)
+	}
+
+	// Pairs for locations unaffected by the enrichment pass through.
+	for _, h := range j.held {
+		if n := min(len(h.pointer), len(target)); slices.Equal(h.pointer[:n], target[:n]) {
+			continue // inside the target or containing it
+		}
+		if !yield(h.pointer, h.val) {
+			return false, nil
+		}
+	}
+
+	// Then the new value at the target, the objects containing it, and the record.
+	for sub, v := range Walk(newVal) {
+		if len(target) == 0 && len(sub) == 0 {
+			break // the record, produced below
+		}
+		if !yield(append(slices.Clip(target), sub...), v) {
+			return false, nil
+		}
+	}
+	for n := len(target) - 1; n > 0; n-- {
+		v, _ := target[:n].Locate(newRec)
+		if !yield(target[:n], v) {
+			return false, nil
+		}
+	}
+	return yield(nil, newRec), nil
+}
+
+// lookupCache is an LRU cache of lookup results.
+// A nil *lookupCache caches nothing.
+type lookupCache struct {
+	max   int
+	lru   *list.List // of *lookupEntry, most recently used first
+	byKey map[string]*list.Element
+}
+
+type lookupEntry struct {
+	key string
+	val any
+}
+
+func newLookupCache(max int) *lookupCache {
+	if max <= 0 {
+		return nil
+	}
+	return &lookupCache{max: max, lru: list.New(), byKey: make(map[string]*list.Element)}
+}
+
+func (c *lookupCache) get(key string) (any, bool) {
+	if c == nil {
+		return nil, false
+	}
+	elt, ok := c.byKey[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elt)
+	return elt.Value.(*lookupEntry).val, true
+}
+
+func (c *lookupCache) put(key string, val any) {
+	if c == nil {
+		return
+	}
+	if elt, ok := c.byKey[key]; ok {
+		elt.Value.(*lookupEntry).val = val
+		c.lru.MoveToFront(elt)
+		return
+	}
+	c.byKey[key] = c.lru.PushFront(&lookupEntry{key: key, val: val})
+	if c.lru.Len() > c.max {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.byKey, oldest.Value.(*lookupEntry).key)
+	}
+}
@@ -0,0 +1,152 @@
+package jseq_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestEnrich(t *testing.T) {
+	const inp = `
+{"user": 1, "meta": {"src": "a"}}
+{"user": 2}
+{"user": 1, "meta": {"src": "b", "name": "old"}}
+{"nouser": true}
+{"user": 3}
+`
+	users := map[string]any{
+		"1": map[string]any{"name": "Alice"},
+		"2": map[string]any{"name": "Bob"},
+	}
+	want := []string{
+		`{"meta":{"name":"Alice","src":"a"},"user":1}`,
+		`{"meta":{"name":"Bob"},"user":2}`,
+		`{"meta":{"name":"Alice","src":"b"},"user":1}`,
+		`{"nouser":true}`,
+		`{"user":3}`,
+	}
+
+	var calls atomic.Int64
+
+	specs := map[string]jseq.EnrichSpec{
+		"single": {
+			Lookup: func(ctx context.Context, key any) (any, error) {
+				calls.Add(1)
+				return users[key.(jseq.Number).String()], nil
+			},
+		},
+		"concurrent cached": {
+			Lookup: func(ctx context.Context, key any) (any, error) {
+				calls.Add(1)
+				return users[key.(jseq.Number).String()], nil
+			},
+			Concurrency: 3,
+			CacheSize:   10,
+		},
+		"batch": {
+			BatchLookup: func(ctx context.Context, keys []any) ([]any, error) {
+				calls.Add(1)
+				var result []any
+				for _, key := range keys {
+					result = append(result, users[key.(jseq.Number).String()])
+				}
+				return result, nil
+			},
+			BatchSize:   2,
+			Concurrency: 2,
+		},
+	}
+	wantCalls := map[string]int64{"single": 4, "concurrent cached": 4, "batch": 2}
+
+	for name, spec := range specs {
+		t.Run(name, func(t *testing.T) {
+			calls.Store(0)
+			spec.Key = jseq.Pointer{"user"}
+			spec.Target = jseq.Pointer{"meta"}
+
+			transform, errptr := jseq.Enrich(context.Background(), spec)
+			tokens, _ := jseq.Tokens(strings.NewReader(inp))
+			values, _ := jseq.Values(tokens)
+
+			var (
+				got   []string
+				pairs = make(map[string]any)
+			)
+			for pointer, val := range transform(values) {
+				if len(pointer) > 0 {
+					pairs[string(pointer.Text())] = val
+					continue
+				}
+				b, err := jseq.Marshal(val)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, strings.TrimSpace(string(b)))
+
+				// The pairs for the record agree with the record.
+				wantPairs := make(map[string]any)
+				for p, v := range jseq.Walk(val) {
+					if len(p) > 0 {
+						wantPairs[string(p.Text())] = v
+					}
+				}
+				if !reflect.DeepEqual(pairs, wantPairs) {
+					t.Errorf("got pairs %v, want %v", pairs, wantPairs)
+				}
+				clear(pairs)
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+
+			// With the cache, the repeated key 1 may or may not be looked up again,
+			// depending on whether its first lookup finished in time.
+			if c := calls.Load(); c != wantCalls[name] && !(name == "concurrent cached" && c == 3) {
+				t.Errorf("got %d lookup calls, want %d", c, wantCalls[name])
+			}
+		})
+	}
+}
+
+func TestEnrichErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+	transform, errptr := jseq.Enrich(context.Background(), jseq.EnrichSpec{
+		Key:    jseq.Pointer{"id"},
+		Target: jseq.Pointer{"x"},
+		Lookup: func(ctx context.Context, key any) (any, error) {
+			if n, _ := key.(jseq.Number).Int(); n == 2 {
+				return nil, errBoom
+			}
+			return "ok", nil
+		},
+	})
+	tokens, _ := jseq.Tokens(strings.NewReader(`{"id": 1} {"id": 2} {"id": 3}`))
+	values, _ := jseq.Values(tokens)
+	var n int
+	for pointer := range transform(values) {
+		if len(pointer) == 0 {
+			n++
+		}
+	}
+	if !errors.Is(*errptr, errBoom) {
+		t.Errorf("got error %v, want %v", *errptr, errBoom)
+	}
+	if n != 1 {
+		t.Errorf("got %d records, want 1", n)
+	}
+
+	transform, errptr = jseq.Enrich(context.Background(), jseq.EnrichSpec{})
+	for range transform(func(func(jseq.Pointer, any) bool) {}) {
+	}
+	if *errptr == nil {
+		t.Error("got no error for spec without lookup")
+	}
+}
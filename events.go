@@ -0,0 +1,179 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+)
+
+// EventKind is the kind of an [Event].
+type EventKind int
+
+const (
+	BeginObject EventKind = iota
+	EndObject
+	BeginArray
+	EndArray
+	Key
+	Scalar
+)
+
+// String returns the name of k.
+func (k EventKind) String() string {
+	switch k {
+	case BeginObject:
+		return "BeginObject"
+	case EndObject:
+		return "EndObject"
+	case BeginArray:
+		return "BeginArray"
+	case EndArray:
+		return "EndArray"
+	case Key:
+		return "Key"
+	case Scalar:
+		return "Scalar"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Event is a structural event in a stream of JSON,
+// produced by [Events].
+type Event struct {
+	Kind EventKind
+
+	// Pointer is the location of the event within its top-level value.
+	// For BeginObject, EndObject, BeginArray, EndArray, and Scalar,
+	// it is the location of the container or scalar.
+	// For Key,
+	// it is the location of the member whose key it is.
+	Pointer Pointer
+
+	// Key is the object key, for Key events.
+	Key string
+
+	// Value is the value of a Scalar event:
+	// a string, [Number], bool, or [Null],
+	// as in the output of [Values].
+	Value any
+}
+
+// Events consumes a sequence of JSON tokens,
+// as from [Tokens],
+// and produces a sequence of events describing their structure,
+// in the style of a SAX parser:
+// the beginning and end of each object and array,
+// each object key,
+// and each scalar value,
+// in input order.
+// Unlike [Values],
+// Events builds no arrays or objects.
+//
+// After consuming the resulting sequence,
+// the caller may check for errors by dereferencing the returned error pointer.
+// If the input ends in the middle of a JSON value,
+// the error is [io.ErrUnexpectedEOF].
+func Events(tokens iter.Seq[jsontext.Token]) (iter.Seq[Event], *error) {
+	var err error
+
+	f := func(yield func(Event) bool) {
+		type frame struct {
+			kind    jsontext.Kind // '{' or '['
+			pointer Pointer
+			key     string // for objects: the key of the current member
+			haveKey bool   // for objects: whether the key has been seen for the next member
+			n       int    // for arrays: the index of the next element
+		}
+		var stack []frame
+
+		// child is the pointer of the value beginning at the current position.
+		child := func() Pointer {
+			if len(stack) == 0 {
+				return nil
+			}
+			top := &stack[len(stack)-1]
+			if top.kind == '{' {
+				return append(slices.Clip(top.pointer), top.key)
+			}
+			return append(slices.Clip(top.pointer), top.n)
+		}
+		// done notes the end of a value in the current container.
+		done := func() {
+			if len(stack) == 0 {
+				return
+			}
+			top := &stack[len(stack)-1]
+			if top.kind == '{' {
+				top.haveKey = false
+			} else {
+				top.n++
+			}
+		}
+
+		for tok := range tokens {
+			kind := tok.Kind()
+
+			if len(stack) > 0 && stack[len(stack)-1].kind == '{' && !stack[len(stack)-1].haveKey && kind == '"' {
+				top := &stack[len(stack)-1]
+				top.key, top.haveKey = tok.String(), true
+				if !yield(Event{Kind: Key, Pointer: child(), Key: top.key}) {
+					return
+				}
+				continue
+			}
+
+			var ev Event
+			switch kind {
+			case '{', '[':
+				ptr := child()
+				stack = append(stack, frame{kind: kind, pointer: ptr})
+				ev = Event{Kind: BeginObject, Pointer: ptr}
+				if kind == '[' {
+					ev.Kind = BeginArray
+				}
+
+			case '}', ']':
+				if len(stack) == 0 {
+					err = fmt.Errorf("unexpected %s", kind)
+					return
+				}
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				ev = Event{Kind: EndObject, Pointer: top.pointer}
+				if kind == ']' {
+					ev.Kind = EndArray
+				}
+				done()
+
+			case 'n':
+				ev = Event{Kind: Scalar, Pointer: child(), Value: Null{}}
+				done()
+			case 'f', 't':
+				ev = Event{Kind: Scalar, Pointer: child(), Value: tok.Bool()}
+				done()
+			case '"':
+				ev = Event{Kind: Scalar, Pointer: child(), Value: tok.String()}
+				done()
+			case '0':
+				ev = Event{Kind: Scalar, Pointer: child(), Value: NewNumber(tok)}
+				done()
+
+			default:
+				err = fmt.Errorf("unknown token kind '%v'", kind)
+				return
+			}
+
+			if !yield(ev) {
+				return
+			}
+		}
+
+		if len(stack) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+	}
+
+	return f, &err
+}
@@ -0,0 +1,94 @@
+package jseq_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestEvents(t *testing.T) {
+	const inp = `{"a": [1, "x", {}], "b": {"c": null}} true`
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	events, errptr2 := jseq.Events(tokens)
+
+	var got []string
+	for ev := range events {
+		s := fmt.Sprintf("%s %s", ev.Kind, ev.Pointer.Text())
+		switch ev.Kind {
+		case jseq.Key:
+			s += " " + ev.Key
+		case jseq.Scalar:
+			s += fmt.Sprintf(" %v", ev.Value)
+		}
+		got = append(got, s)
+	}
+	if err := *errptr1; err != nil {
+		t.Fatal(err)
+	}
+	if err := *errptr2; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"BeginObject ",
+		"Key /a a",
+		"BeginArray /a",
+		"Scalar /a/0 1",
+		"Scalar /a/1 x",
+		"BeginObject /a/2",
+		"EndObject /a/2",
+		"EndArray /a",
+		"Key /b b",
+		"BeginObject /b",
+		"Key /b/c c",
+		"Scalar /b/c null",
+		"EndObject /b",
+		"EndObject ",
+		"Scalar  true",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEventsValues(t *testing.T) {
+	tokens, _ := jseq.Tokens(strings.NewReader(`[7, "s", false, null]`))
+	events, errptr := jseq.Events(tokens)
+
+	var got []any
+	for ev := range events {
+		if ev.Kind == jseq.Scalar {
+			got = append(got, ev.Value)
+		}
+	}
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []any{jseq.Int(7), "s", false, jseq.Null{}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestEventsTruncated(t *testing.T) {
+	tokens, _ := jseq.Tokens(strings.NewReader(`{"a": [1`))
+	events, errptr := jseq.Events(tokens)
+
+	var n int
+	for range events {
+		n++
+	}
+	if n != 4 {
+		t.Errorf("got %d events, want 4", n)
+	}
+	if err := *errptr; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
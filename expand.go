@@ -0,0 +1,104 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"slices"
+	"strings"
+
+	"github.com/bobg/errors"
+	"github.com/bobg/seqs"
+)
+
+// ExpandStrings is an [Option] that causes [Values] to expand string values
+// that themselves contain valid JSON.
+// The expanded value takes the place of the string in the output,
+// and its members are produced with pointers that extend the string's own pointer,
+// just as if the JSON had appeared in the input unquoted.
+//
+// With no arguments,
+// any string that holds a valid JSON object or array is expanded.
+// (Strings like "123" and "true" are valid JSON too,
+// but are left alone in this mode.)
+// If one or more pointers are given,
+// only strings at those locations are expanded,
+// and they may hold any kind of JSON value.
+// Strings that are not valid JSON are never expanded.
+//
+// See also [KeepOriginalStrings].
+func ExpandStrings(pointers ...Pointer) Option {
+	return func(c *config) {
+		c.expandStrings = true
+		c.expandAt = pointers
+	}
+}
+
+// KeepOriginalStrings is an [Option] that,
+// in combination with [ExpandStrings],
+// causes each expanded string to be produced as an [Expanded] value,
+// retaining the original string alongside the value it was expanded into.
+func KeepOriginalStrings() Option {
+	return func(c *config) {
+		c.keepOriginal = true
+	}
+}
+
+// Expanded is the type of a value produced by [Values]
+// for a string that was expanded under the [ExpandStrings] and [KeepOriginalStrings] options.
+type Expanded struct {
+	// Value is the JSON value parsed from Original.
+	Value any
+
+	// Original is the string as it appeared in the input.
+	Original string
+}
+
+func (p *parser) shouldExpand(pointer Pointer, s string) bool {
+	if !p.expandStrings {
+		return false
+	}
+	if len(p.expandAt) > 0 {
+		if !slices.ContainsFunc(p.expandAt, func(q Pointer) bool { return slices.Equal(q, pointer) }) {
+			return false
+		}
+	} else {
+		trimmed := strings.TrimLeft(s, " \t\r\n")
+		if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+			return false
+		}
+	}
+	return jsontext.Value(s).IsValid()
+}
+
+func (p *parser) expand(pointer Pointer, s string) (any, bool, error) {
+	tokens, errptr := Tokens(strings.NewReader(s))
+	next, peek, stop := seqs.Peeker(tokens)
+	defer stop()
+
+	// The sub-parser produces the members of the expanded value,
+	// but not the expanded value itself,
+	// which is produced below.
+	yield := func(q Pointer, v any) bool {
+		if len(q) == len(pointer) {
+			return true
+		}
+		return p.yield(q, v)
+	}
+	sub := &parser{config: p.config, next: next, peek: peek, yield: yield, record: p.record}
+
+	val, ok, err := sub.nextValue(pointer)
+	if err == nil {
+		err = *errptr
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "expanding string at %s", pointer.Text())
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	if p.keepOriginal {
+		val = Expanded{Value: val, Original: s}
+	}
+	ok = p.yield(pointer, val)
+	return val, ok, nil
+}
@@ -0,0 +1,75 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestExpandStrings(t *testing.T) {
+	const inp = `{"msg": "hi", "payload": "{\"a\": [1, \"[2]\"]}", "n": "17"}`
+
+	cases := []struct {
+		name string
+		opts []jseq.Option
+		want []pair
+	}{{
+		name: "off",
+		want: []pair{
+			{jseq.Pointer{"msg"}, "hi"},
+			{jseq.Pointer{"payload"}, `{"a": [1, "[2]"]}`},
+			{jseq.Pointer{"n"}, "17"},
+			{nil, map[string]any{"msg": "hi", "payload": `{"a": [1, "[2]"]}`, "n": "17"}},
+		},
+	}, {
+		name: "detect",
+		opts: []jseq.Option{jseq.ExpandStrings()},
+		want: []pair{
+			{jseq.Pointer{"msg"}, "hi"},
+			{jseq.Pointer{"payload", "a", 0}, jseq.Int(1)},
+			{jseq.Pointer{"payload", "a", 1, 0}, jseq.Int(2)},
+			{jseq.Pointer{"payload", "a", 1}, []any{jseq.Int(2)}},
+			{jseq.Pointer{"payload", "a"}, []any{jseq.Int(1), []any{jseq.Int(2)}}},
+			{jseq.Pointer{"payload"}, map[string]any{"a": []any{jseq.Int(1), []any{jseq.Int(2)}}}},
+			{jseq.Pointer{"n"}, "17"},
+			{nil, map[string]any{
+				"msg":     "hi",
+				"payload": map[string]any{"a": []any{jseq.Int(1), []any{jseq.Int(2)}}},
+				"n":       "17",
+			}},
+		},
+	}, {
+		name: "configured",
+		opts: []jseq.Option{jseq.ExpandStrings(jseq.Pointer{"n"}, jseq.Pointer{"msg"})},
+		want: []pair{
+			{jseq.Pointer{"msg"}, "hi"},
+			{jseq.Pointer{"payload"}, `{"a": [1, "[2]"]}`},
+			{jseq.Pointer{"n"}, jseq.Int(17)},
+			{nil, map[string]any{"msg": "hi", "payload": `{"a": [1, "[2]"]}`, "n": jseq.Int(17)}},
+		},
+	}, {
+		name: "keep_original",
+		opts: []jseq.Option{jseq.ExpandStrings(jseq.Pointer{"n"}), jseq.KeepOriginalStrings()},
+		want: []pair{
+			{jseq.Pointer{"msg"}, "hi"},
+			{jseq.Pointer{"payload"}, `{"a": [1, "[2]"]}`},
+			{jseq.Pointer{"n"}, jseq.Expanded{Value: jseq.Int(17), Original: "17"}},
+			{nil, map[string]any{
+				"msg":     "hi",
+				"payload": `{"a": [1, "[2]"]}`,
+				"n":       jseq.Expanded{Value: jseq.Int(17), Original: "17"},
+			}},
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := collect(t, strings.NewReader(inp), tc.opts...)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
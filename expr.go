@@ -0,0 +1,913 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"iter"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bobg/errors"
+)
+
+// Expr is an expression that computes a value from a record.
+// Create one with [ParseExpr].
+//
+// An expression may contain:
+//
+//   - literals: numbers, "strings" (with JSON escapes), true, false, and null
+//   - references to parts of the record, as dotted paths like price or items.0.sku
+//     (see [ParseDotted]);
+//     a reference to a missing location is null
+//   - arithmetic: + - * / % and unary -,
+//     where + also concatenates two strings
+//   - comparisons: == != < <= > >=,
+//     of two numbers or two strings
+//     (== and != compare any two values)
+//   - logic: && || and unary !,
+//     in which null counts as false
+//   - a ?? b, which is a unless a is null, else b
+//   - conditionals: cond ? a : b
+//   - parentheses
+//   - calls to the functions described below
+//
+// The functions are:
+//
+//   - at(s): the part of the record located by s, a JSON pointer string such as "/a b/c"
+//   - len(x): the number of characters in a string, elements in an array, or members in an object
+//   - lower(s), upper(s), trim(s): case conversion and whitespace trimming of strings
+//   - contains(s, sub): whether string s contains sub
+//   - string(x): x converted to a string; a container becomes its JSON encoding
+//   - number(x): x converted to a number, which may be a numeric string
+//   - round(x), floor(x), ceil(x): x rounded to an integer
+//
+// Integer arithmetic is exact as long as the result fits in an int64.
+// Otherwise arithmetic is done in floating point;
+// a result that is infinite or not a number is an error.
+//
+// Evaluation is safe for untrusted expressions:
+// it cannot loop, call arbitrary code, or modify the record.
+type Expr struct {
+	text string
+	root exprNode
+}
+
+// ParseExpr parses an [Expr].
+func ParseExpr(s string) (Expr, error) {
+	toks, err := lexExpr(s)
+	if err != nil {
+		return Expr{}, errors.Wrapf(err, "parsing %q", s)
+	}
+	p := &exprParser{toks: toks}
+	root, err := p.parse()
+	if err != nil {
+		return Expr{}, errors.Wrapf(err, "parsing %q", s)
+	}
+	if !p.at(exprEOF) {
+		return Expr{}, fmt.Errorf("parsing %q: unexpected %s at offset %d", s, p.peek(), p.peek().pos)
+	}
+	return Expr{text: s, root: root}, nil
+}
+
+// MustParseExpr is like [ParseExpr] but panics on error.
+func MustParseExpr(s string) Expr {
+	e, err := ParseExpr(s)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String returns the text from which e was parsed.
+func (e Expr) String() string {
+	return e.text
+}
+
+// Eval evaluates e against rec,
+// a value of the kind produced by [Values].
+// The result is a string, [Number], bool, [Null],
+// or (from a reference) a part of rec.
+func (e Expr) Eval(rec any) (any, error) {
+	if e.root == nil {
+		return Null{}, nil
+	}
+	result, err := e.root.eval(rec)
+	return result, errors.Wrapf(err, "evaluating %q", e.text)
+}
+
+// ComputedField is a field whose value is computed by an [Expr].
+// See [Compute].
+type ComputedField struct {
+	Pointer Pointer
+	Expr    Expr
+}
+
+// ParseComputedField parses a definition of the form "NAME = EXPR",
+// such as "total = price * quantity",
+// where NAME is a dotted path (see [ParseDotted])
+// and EXPR is an expression (see [Expr]).
+func ParseComputedField(s string) (ComputedField, error) {
+	name, expr, ok := strings.Cut(s, "=")
+	if !ok || strings.HasPrefix(expr, "=") {
+		return ComputedField{}, fmt.Errorf("no assignment in %q", s)
+	}
+	name = strings.TrimSpace(name)
+	toks, err := lexExpr(name)
+	if err != nil || len(toks) != 2 || toks[0].kind != exprRef {
+		return ComputedField{}, fmt.Errorf("invalid field name %q in %q", name, s)
+	}
+	pointer, err := ParseDotted(name)
+	if err != nil {
+		return ComputedField{}, errors.Wrapf(err, "parsing field name in %q", s)
+	}
+	e, err := ParseExpr(strings.TrimSpace(expr))
+	if err != nil {
+		return ComputedField{}, err
+	}
+	return ComputedField{Pointer: pointer, Expr: e}, nil
+}
+
+// Compute returns a [Transform] that adds computed fields to each record
+// (top-level value).
+// The fields are computed in order,
+// so an expression may refer to a field computed before it.
+// A computed field replaces any value already at its location,
+// and missing objects along the path to it are created.
+//
+// Pairs inside a record pass through,
+// except that pairs for computed locations and the objects containing them
+// are replaced by pairs for their new values,
+// produced just before the updated record.
+//
+// The first evaluation error ends the sequence
+// and is placed in the returned error pointer,
+// which the caller may check after consuming the sequence.
+func Compute(fields ...ComputedField) (Transform, *error) {
+	var err error
+
+	f := func(values iter.Seq2[Pointer, any]) iter.Seq2[Pointer, any] {
+		return func(yield func(Pointer, any) bool) {
+			var (
+				held   []enrichPair
+				record int
+			)
+			for pointer, val := range values {
+				if len(pointer) > 0 {
+					held = append(held, enrichPair{pointer: slices.Clone(pointer), val: val})
+					continue
+				}
+
+				rec := val
+				for _, field := range fields {
+					v, e := field.Expr.Eval(rec)
+					if e != nil {
+						err = errors.Wrapf(e, "computing %s in record %d", field.Pointer.Text(), record)
+						return
+					}
+					rec, e = field.Pointer.Set(rec, v)
+					if e != nil {
+						err = errors.Wrapf(e, "setting %s in record %d", field.Pointer.Text(), record)
+						return
+					}
+				}
+				if !computeEmit(held, rec, fields, yield) {
+					return
+				}
+				held = nil
+				record++
+			}
+		}
+	}
+
+	return f, &err
+}
+
+// computeEmit produces the pairs for a record updated by [Compute].
+func computeEmit(held []enrichPair, rec any, fields []ComputedField, yield func(Pointer, any) bool) bool {
+	if slices.ContainsFunc(fields, func(f ComputedField) bool { return len(f.Pointer) == 0 }) {
+		// The whole record was replaced.
+		for sub, v := range Walk(rec) {
+			if !yield(sub, v) {
+				return false
+			}
+		}
+		return true
+	}
+
+	affected := func(ptr Pointer) bool {
+		return slices.ContainsFunc(fields, func(f ComputedField) bool {
+			n := min(len(ptr), len(f.Pointer))
+			return slices.Equal(ptr[:n], f.Pointer[:n])
+		})
+	}
+
+	for _, h := range held {
+		if affected(h.pointer) {
+			continue
+		}
+		if !yield(h.pointer, h.val) {
+			return false
+		}
+	}
+
+	// The new values, skipping any inside another computed field.
+	var parents []Pointer
+	for i, f := range fields {
+		if slices.ContainsFunc(fields, func(other ComputedField) bool {
+			return len(other.Pointer) < len(f.Pointer) && slices.Equal(other.Pointer, f.Pointer[:len(other.Pointer)])
+		}) {
+			continue
+		}
+		if slices.ContainsFunc(fields[i+1:], func(other ComputedField) bool { return slices.Equal(other.Pointer, f.Pointer) }) {
+			continue // produced for the later field
+		}
+		v, _ := f.Pointer.Locate(rec)
+		for sub, subval := range Walk(v) {
+			if !yield(append(slices.Clip(f.Pointer), sub...), subval) {
+				return false
+			}
+		}
+		for n := len(f.Pointer) - 1; n > 0; n-- {
+			if !slices.ContainsFunc(parents, func(p Pointer) bool { return slices.Equal(p, f.Pointer[:n]) }) {
+				parents = append(parents, f.Pointer[:n])
+			}
+		}
+	}
+
+	// Then the objects containing them, deepest first.
+	slices.SortStableFunc(parents, func(a, b Pointer) int { return len(b) - len(a) })
+	for _, ptr := range parents {
+		if slices.ContainsFunc(fields, func(f ComputedField) bool {
+			return len(f.Pointer) <= len(ptr) && slices.Equal(f.Pointer, ptr[:len(f.Pointer)])
+		}) {
+			continue // inside a computed field, already produced
+		}
+		v, _ := ptr.Locate(rec)
+		if !yield(ptr, v) {
+			return false
+		}
+	}
+
+	return yield(nil, rec)
+}
+
+// Lexing.
+
+type exprTokKind int
+
+const (
+	exprEOF exprTokKind = iota
+	exprNum
+	exprStr
+	exprRef // a dotted path, or a keyword or function name
+	exprOp  // an operator or punctuation
+)
+
+type exprTok struct {
+	kind exprTokKind
+	text string
+	val  any // for exprNum and exprStr
+	pos  int
+}
+
+func (t exprTok) String() string {
+	if t.kind == exprEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+var exprOps = []string{"==", "!=", "<=", ">=", "&&", "||", "??", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")", ",", "="}
+
+func lexExpr(s string) ([]exprTok, error) {
+	var result []exprTok
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(s) && (isDigit(s[j]) || s[j] == '.' || s[j] == 'e' || s[j] == 'E' ||
+				((s[j] == '+' || s[j] == '-') && (s[j-1] == 'e' || s[j-1] == 'E'))) {
+				j++
+			}
+			raw := s[i:j]
+			if !jsontext.Value(raw).IsValid() {
+				return nil, fmt.Errorf("invalid number %q at offset %d", raw, i)
+			}
+			result = append(result, exprTok{kind: exprNum, text: raw, val: numberFromRaw(raw), pos: i})
+			i = j
+
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			raw := s[i : j+1]
+			var str string
+			if err := unmarshalString(raw, &str); err != nil {
+				return nil, fmt.Errorf("invalid string %s at offset %d", raw, i)
+			}
+			result = append(result, exprTok{kind: exprStr, text: raw, val: str, pos: i})
+			i = j + 1
+
+		case isIdentStart(c):
+			j := i
+			for j < len(s) {
+				if isIdentChar(s[j]) {
+					j++
+					continue
+				}
+				if s[j] == '.' && j+1 < len(s) && isIdentChar(s[j+1]) {
+					j++
+					continue
+				}
+				break
+			}
+			result = append(result, exprTok{kind: exprRef, text: s[i:j], pos: i})
+			i = j
+
+		default:
+			var found bool
+			for _, op := range exprOps {
+				if strings.HasPrefix(s[i:], op) {
+					result = append(result, exprTok{kind: exprOp, text: op, pos: i})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				r, _ := utf8.DecodeRuneInString(s[i:])
+				return nil, fmt.Errorf("unexpected %q at offset %d", r, i)
+			}
+		}
+	}
+
+	return append(result, exprTok{kind: exprEOF, pos: len(s)}), nil
+}
+
+func unmarshalString(raw string, s *string) error {
+	dec := jsontext.NewDecoder(strings.NewReader(raw))
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("not a string")
+	}
+	*s = tok.String()
+	return nil
+}
+
+// Parsing, by recursive descent.
+// From lowest to highest precedence:
+// ?:, ??, ||, &&, comparisons, + -, * / %, unary - !.
+
+type exprParser struct {
+	toks []exprTok
+	pos  int
+}
+
+func (p *exprParser) peek() exprTok {
+	return p.toks[p.pos]
+}
+
+func (p *exprParser) next() exprTok {
+	tok := p.toks[p.pos]
+	if tok.kind != exprEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) at(kind exprTokKind, ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != kind {
+		return false
+	}
+	return len(ops) == 0 || slices.Contains(ops, tok.text)
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.at(exprOp, op) {
+		tok := p.peek()
+		return fmt.Errorf("expected %q, got %s at offset %d", op, tok, tok.pos)
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) parse() (exprNode, error) {
+	cond, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.at(exprOp, "?") {
+		return cond, nil
+	}
+	p.next()
+	a, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	b, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &condNode{cond: cond, a: a, b: b}, nil
+}
+
+// exprLevels lists the binary operators at each precedence level,
+// from lowest to highest.
+var exprLevels = [][]string{
+	{"??"},
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *exprParser) binary(level int) (exprNode, error) {
+	if level >= len(exprLevels) {
+		return p.unary()
+	}
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for p.at(exprOp, exprLevels[level]...) {
+		op := p.next().text
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryNode{op: op, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	if p.at(exprOp, "-", "!") {
+		op := p.next().text
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, x: x}, nil
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case exprNum, exprStr:
+		return &litNode{val: tok.val}, nil
+
+	case exprRef:
+		switch tok.text {
+		case "true":
+			return &litNode{val: true}, nil
+		case "false":
+			return &litNode{val: false}, nil
+		case "null":
+			return &litNode{val: Null{}}, nil
+		}
+		if p.at(exprOp, "(") {
+			return p.call(tok)
+		}
+		ptr, err := ParseDotted(tok.text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing reference at offset %d", tok.pos)
+		}
+		return &refNode{pointer: jsonPointerText(ptr)}, nil
+
+	case exprOp:
+		if tok.text == "(" {
+			x, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s at offset %d", tok, tok.pos)
+}
+
+func (p *exprParser) call(name exprTok) (exprNode, error) {
+	fn, ok := exprFuncs[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at offset %d", name.text, name.pos)
+	}
+	p.next() // (
+	var args []exprNode
+	for !p.at(exprOp, ")") {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next() // )
+	if len(args) != fn.nargs {
+		return nil, fmt.Errorf("%s takes %d argument(s), got %d at offset %d", name.text, fn.nargs, len(args), name.pos)
+	}
+	return &callNode{name: name.text, fn: fn.f, args: args}, nil
+}
+
+// jsonPointerText converts ptr to JSON pointer syntax
+// for use with locateText,
+// which interprets each token according to the value it is applied to.
+func jsonPointerText(ptr Pointer) string {
+	var buf strings.Builder
+	for _, elt := range ptr {
+		buf.WriteByte('/')
+		s := fmt.Sprint(elt)
+		buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1"))
+	}
+	return buf.String()
+}
+
+// Evaluation.
+
+type exprNode interface {
+	eval(rec any) (any, error)
+}
+
+type (
+	litNode struct {
+		val any
+	}
+
+	refNode struct {
+		pointer string // JSON pointer syntax
+	}
+
+	unaryNode struct {
+		op string
+		x  exprNode
+	}
+
+	binaryNode struct {
+		op   string
+		x, y exprNode
+	}
+
+	condNode struct {
+		cond, a, b exprNode
+	}
+
+	callNode struct {
+		name string
+		fn   func(rec any, args []any) (any, error)
+		args []exprNode
+	}
+)
+
+func (n *litNode) eval(any) (any, error) {
+	return n.val, nil
+}
+
+func (n *refNode) eval(rec any) (any, error) {
+	return locateExpr(rec, n.pointer)
+}
+
+func locateExpr(rec any, pointer string) (any, error) {
+	val, err := locateText(rec, pointer)
+	if err != nil {
+		return nil, err
+	}
+	if val == nil {
+		return Null{}, nil
+	}
+	return decodedForm(val), nil
+}
+
+func (n *unaryNode) eval(rec any) (any, error) {
+	x, err := n.x.eval(rec)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		b, err := truth(x)
+		return !b, err
+	}
+	num, ok := x.(Number)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", TypeName(x))
+	}
+	return arith("-", Int(0), num)
+}
+
+func (n *binaryNode) eval(rec any) (any, error) {
+	x, err := n.x.eval(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "??":
+		if _, ok := x.(Null); !ok {
+			return x, nil
+		}
+		return n.y.eval(rec)
+
+	case "&&", "||":
+		b, err := truth(x)
+		if err != nil {
+			return nil, err
+		}
+		if b == (n.op == "||") {
+			return b, nil
+		}
+		y, err := n.y.eval(rec)
+		if err != nil {
+			return nil, err
+		}
+		return truth(y)
+	}
+
+	y, err := n.y.eval(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return exprEqual(x, y), nil
+	case "!=":
+		return !exprEqual(x, y), nil
+	case "<", "<=", ">", ">=":
+		c, err := exprCompare(x, y)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	}
+
+	if xs, ok := x.(string); ok && n.op == "+" {
+		if ys, ok := y.(string); ok {
+			return xs + ys, nil
+		}
+	}
+	xn, ok1 := x.(Number)
+	yn, ok2 := y.(Number)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("cannot apply %s to %s and %s", n.op, TypeName(x), TypeName(y))
+	}
+	return arith(n.op, xn, yn)
+}
+
+func (n *condNode) eval(rec any) (any, error) {
+	c, err := n.cond.eval(rec)
+	if err != nil {
+		return nil, err
+	}
+	b, err := truth(c)
+	if err != nil {
+		return nil, err
+	}
+	if b {
+		return n.a.eval(rec)
+	}
+	return n.b.eval(rec)
+}
+
+func (n *callNode) eval(rec any) (any, error) {
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		var err error
+		if args[i], err = arg.eval(rec); err != nil {
+			return nil, err
+		}
+	}
+	result, err := n.fn(rec, args)
+	return result, errors.Wrapf(err, "in %s", n.name)
+}
+
+// truth interprets val as a condition.
+func truth(val any) (bool, error) {
+	switch val := val.(type) {
+	case bool:
+		return val, nil
+	case Null:
+		return false, nil
+	}
+	return false, fmt.Errorf("%s is not a boolean", TypeName(val))
+}
+
+// arith applies a binary arithmetic operator to two numbers.
+func arith(op string, x, y Number) (any, error) {
+	xi, ok1 := x.Int()
+	yi, ok2 := y.Int()
+	if ok1 && ok2 {
+		var (
+			a = big.NewInt(xi)
+			b = big.NewInt(yi)
+			r = new(big.Int)
+		)
+		switch op {
+		case "+":
+			r.Add(a, b)
+		case "-":
+			r.Sub(a, b)
+		case "*":
+			r.Mul(a, b)
+		case "/", "%":
+			if yi == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			var m big.Int
+			r.QuoRem(a, b, &m)
+			if op == "%" {
+				r = &m
+			} else if m.Sign() != 0 {
+				r = nil // not exact; use floating point
+			}
+		}
+		if r != nil && r.IsInt64() {
+			return Int(r.Int64()), nil
+		}
+	} else if op == "%" {
+		return nil, fmt.Errorf("%% requires integers")
+	}
+
+	var f float64
+	switch op {
+	case "+":
+		f = x.Float() + y.Float()
+	case "-":
+		f = x.Float() - y.Float()
+	case "*":
+		f = x.Float() * y.Float()
+	case "/":
+		if y.Float() == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		f = x.Float() / y.Float()
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("%s %s %s is not finite", x, op, y)
+	}
+	return Float(f), nil
+}
+
+func exprEqual(x, y any) bool {
+	if xn, ok := x.(Number); ok {
+		if yn, ok := y.(Number); ok {
+			if c, err := exprCompare(xn, yn); err == nil {
+				return c == 0
+			}
+		}
+	}
+	return len(Diff(x, y)) == 0
+}
+
+func exprCompare(x, y any) (int, error) {
+	switch x := x.(type) {
+	case Number:
+		if y, ok := y.(Number); ok {
+			xr, ok1 := x.rat()
+			yr, ok2 := y.rat()
+			if ok1 && ok2 {
+				return xr.Cmp(yr), nil
+			}
+			switch xf, yf := x.Float(), y.Float(); {
+			case xf < yf:
+				return -1, nil
+			case xf > yf:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if y, ok := y.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s and %s", TypeName(x), TypeName(y))
+}
+
+type exprFunc struct {
+	nargs int
+	f     func(rec any, args []any) (any, error)
+}
+
+var exprFuncs map[string]exprFunc
+
+func init() {
+	strFunc := func(f func(string) string) exprFunc {
+		return exprFunc{nargs: 1, f: func(_ any, args []any) (any, error) {
+			s, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a string", TypeName(args[0]))
+			}
+			return f(s), nil
+		}}
+	}
+	roundFunc := func(f func(float64) float64) exprFunc {
+		return exprFunc{nargs: 1, f: func(_ any, args []any) (any, error) {
+			n, ok := args[0].(Number)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a number", TypeName(args[0]))
+			}
+			if _, ok := n.Int(); ok {
+				return n, nil
+			}
+			r := f(n.Float())
+			if r >= math.MinInt64 && r < math.MaxInt64 {
+				return Int(int64(r)), nil
+			}
+			return Float(r), nil
+		}}
+	}
+
+	exprFuncs = map[string]exprFunc{
+		"at": {nargs: 1, f: func(rec any, args []any) (any, error) {
+			s, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a string", TypeName(args[0]))
+			}
+			return locateExpr(rec, s)
+		}},
+		"len": {nargs: 1, f: func(_ any, args []any) (any, error) {
+			switch v := args[0].(type) {
+			case string:
+				return Int(int64(utf8.RuneCountInString(v))), nil
+			case []any:
+				return Int(int64(len(v))), nil
+			case map[string]any:
+				return Int(int64(len(v))), nil
+			case *Object:
+				return Int(int64(v.Len())), nil
+			}
+			return nil, fmt.Errorf("%s has no length", TypeName(args[0]))
+		}},
+		"lower": strFunc(strings.ToLower),
+		"upper": strFunc(strings.ToUpper),
+		"trim":  strFunc(strings.TrimSpace),
+		"contains": {nargs: 2, f: func(_ any, args []any) (any, error) {
+			s, ok1 := args[0].(string)
+			sub, ok2 := args[1].(string)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("cannot search %s for %s", TypeName(args[0]), TypeName(args[1]))
+			}
+			return strings.Contains(s, sub), nil
+		}},
+		"string": {nargs: 1, f: func(_ any, args []any) (any, error) {
+			if s, ok := args[0].(string); ok {
+				return s, nil
+			}
+			b, err := Marshal(args[0])
+			return string(b), err
+		}},
+		"number": {nargs: 1, f: func(_ any, args []any) (any, error) {
+			switch v := args[0].(type) {
+			case Number:
+				return v, nil
+			case string:
+				s := strings.TrimSpace(v)
+				if tok, err := jsontext.NewDecoder(strings.NewReader(s)).ReadToken(); err == nil && tok.Kind() == '0' && jsontext.Value(s).IsValid() {
+					return NewNumber(tok), nil
+				}
+				return nil, fmt.Errorf("%q is not a number", v)
+			}
+			return nil, fmt.Errorf("cannot convert %s to a number", TypeName(args[0]))
+		}},
+		"round": roundFunc(math.Round),
+		"floor": roundFunc(math.Floor),
+		"ceil":  roundFunc(math.Ceil),
+	}
+}
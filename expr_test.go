@@ -0,0 +1,205 @@
+package jseq_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestExprEval(t *testing.T) {
+	rec := map[string]any{
+		"price":    jseq.Float(2.5),
+		"quantity": jseq.Int(4),
+		"name":     " Widget ",
+		"items":    []any{map[string]any{"sku": "a1"}, map[string]any{"sku": "b2"}},
+		"flag":     true,
+		"a b":      map[string]any{"c": "spaced"},
+	}
+
+	cases := []struct {
+		expr    string
+		want    any
+		wantErr bool
+	}{
+		{expr: "price * quantity", want: jseq.Float(10)},
+		{expr: "quantity * 3 + 1", want: jseq.Int(13)},
+		{expr: "quantity * (3 + 1)", want: jseq.Int(16)},
+		{expr: "-quantity", want: jseq.Int(-4)},
+		{expr: "7 / 2", want: jseq.Float(3.5)},
+		{expr: "8 / 2", want: jseq.Int(4)},
+		{expr: "7 % 4", want: jseq.Int(3)},
+		{expr: "4611686018427387904 * 4", want: jseq.Float(18446744073709551616)},
+		{expr: "1 / 0", wantErr: true},
+		{expr: `"a" + "b"`, want: "ab"},
+		{expr: `"a" + 1`, wantErr: true},
+		{expr: "price > 2 && quantity <= 4", want: true},
+		{expr: "1 == 1.0", want: true},
+		{expr: `"abc" < "abd"`, want: true},
+		{expr: `items.1.sku == "b2"`, want: true},
+		{expr: "missing", want: jseq.Null{}},
+		{expr: "missing ?? 5", want: jseq.Int(5)},
+		{expr: "quantity ?? 5", want: jseq.Int(4)},
+		{expr: "!missing", want: true},
+		{expr: "missing || flag", want: true},
+		{expr: "quantity || flag", wantErr: true},
+		{expr: `quantity > 3 ? "many" : "few"`, want: "many"},
+		{expr: `flag ? 1 : missing.x.y`, want: jseq.Int(1)},
+		{expr: `upper(trim(name))`, want: "WIDGET"},
+		{expr: `len(items) + len("héllo")`, want: jseq.Int(7)},
+		{expr: `contains(name, "dg")`, want: true},
+		{expr: `string(quantity) + string(items.0)`, want: `4{"sku":"a1"}`},
+		{expr: `number("12") * 2`, want: jseq.Int(24)},
+		{expr: `number("x")`, wantErr: true},
+		{expr: "round(price)", want: jseq.Int(3)},
+		{expr: "floor(price)", want: jseq.Int(2)},
+		{expr: "ceil(-price)", want: jseq.Int(-2)},
+		{expr: `at("/a b/c")`, want: "spaced"},
+		{expr: `"tab\t"`, want: "tab\t"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := jseq.ParseExpr(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := e.Eval(rec)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("got %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	cases := []string{
+		"1 +",
+		"(1",
+		"1 2",
+		"a = b",
+		"nosuch(1)",
+		"len(1, 2)",
+		`"unterminated`,
+		"01",
+		"a ? b",
+		"#",
+	}
+	for _, s := range cases {
+		if _, err := jseq.ParseExpr(s); err == nil {
+			t.Errorf("parsing %q: got no error, want one", s)
+		}
+	}
+}
+
+func TestParseComputedField(t *testing.T) {
+	f, err := jseq.ParseComputedField("totals.net = price * quantity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (jseq.Pointer{"totals", "net"}); !reflect.DeepEqual(f.Pointer, want) {
+		t.Errorf("got pointer %v, want %v", f.Pointer, want)
+	}
+	if got, want := f.Expr.String(), "price * quantity"; got != want {
+		t.Errorf("got expression %q, want %q", got, want)
+	}
+
+	for _, s := range []string{"total", "a == b", "1 = 2", "a b = 1", "x = 1 +"} {
+		if _, err := jseq.ParseComputedField(s); err == nil {
+			t.Errorf("parsing %q: got no error, want one", s)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	var fields []jseq.ComputedField
+	for _, s := range []string{
+		"total = price * quantity",
+		"summary.big = total > 10",
+	} {
+		f, err := jseq.ParseComputedField(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fields = append(fields, f)
+	}
+
+	const inp = `
+{"price": 3, "quantity": 5, "summary": {"note": "x"}, "total": 0}
+{"price": 1, "quantity": 2}
+`
+
+	var got []string
+	sink := jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+		b, err := jseq.Marshal(val)
+		if err != nil {
+			return err
+		}
+		got = append(got, fmt.Sprintf("%s %s", pointer.Text(), b))
+		return nil
+	})
+	transform, errptr := jseq.Compute(fields...)
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), sink, nil, transform); err != nil {
+		t.Fatal(err)
+	}
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`/price 3`,
+		`/quantity 5`,
+		`/summary/note "x"`,
+		`/total 15`,
+		`/summary/big true`,
+		`/summary {"big":true,"note":"x"}`,
+		` {"price":3,"quantity":5,"summary":{"big":true,"note":"x"},"total":15}`,
+
+		`/price 1`,
+		`/quantity 2`,
+		`/total 2`,
+		`/summary/big false`,
+		`/summary {"big":false}`,
+		` {"price":1,"quantity":2,"summary":{"big":false},"total":2}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestComputeError(t *testing.T) {
+	f, err := jseq.ParseComputedField(`x = a + 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transform, errptr := jseq.Compute(f)
+
+	var n int
+	sink := jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+		if len(pointer) == 0 {
+			n++
+		}
+		return nil
+	})
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(`{"a": 1} {"a": "s"} {"a": 2}`)), sink, nil, transform); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("got %d records, want 1", n)
+	}
+	if *errptr == nil {
+		t.Error("got no error, want one")
+	}
+}
@@ -0,0 +1,89 @@
+package jseq
+
+import "slices"
+
+// ObjectBuilder constructs the representation of a JSON object
+// for an [ObjectFactory].
+type ObjectBuilder interface {
+	// Add adds a member to the object.
+	// It is called once for each member, in input order.
+	// If the input repeats a key,
+	// Add is called again with the same key
+	// (unless [DuplicateKeys] says otherwise),
+	// and it is up to the builder which value wins.
+	Add(key string, val any) error
+
+	// Build returns the finished object.
+	// It is called once, after all members have been added.
+	Build() (any, error)
+}
+
+// ArrayBuilder constructs the representation of a JSON array
+// for an [ArrayFactory].
+type ArrayBuilder interface {
+	// Append adds the next element to the array.
+	Append(val any) error
+
+	// Build returns the finished array.
+	// It is called once, after all elements have been appended.
+	Build() (any, error)
+}
+
+// ObjectFactory is an [Option] that lets the caller choose how [Values] represents objects.
+// For each object in the input,
+// Values calls f with the object's pointer.
+// If f returns a builder,
+// Values adds the object's members to it
+// and produces the result of its Build method in place of the usual map[string]any
+// (or [*Object], with [OrderedObjects]).
+// If f returns nil,
+// the object gets the usual representation.
+//
+// The members passed to the builder are themselves in the representation chosen for them.
+// Objects built this way are not subject to [MapKeys], [SparseArrays], or [OrderedObjects],
+// nor shared by [ShareSubtrees];
+// and other functions in this package that handle JSON values
+// (such as [Marshal] and [Pointer.Locate])
+// see them as leaves unless the builder produces a type they understand.
+//
+// The factory is not used with [LeafOnly], [Unmaterialized], or [PreOrder],
+// which do not build containers.
+func ObjectFactory(f func(Pointer) ObjectBuilder) Option {
+	return func(c *config) {
+		c.objectFactory = f
+	}
+}
+
+// ArrayFactory is an [Option] that lets the caller choose how [Values] represents arrays.
+// It is the counterpart of [ObjectFactory]:
+// for each array in the input,
+// Values calls f with the array's pointer,
+// and if the result is non-nil,
+// Values appends the array's elements to it
+// and produces the result of its Build method in place of the usual []any.
+//
+// Arrays built this way are not subject to [ArraysAsObjects],
+// nor shared by [ShareSubtrees].
+func ArrayFactory(f func(Pointer) ArrayBuilder) Option {
+	return func(c *config) {
+		c.arrayFactory = f
+	}
+}
+
+// objectBuilder returns the caller's builder for the object at pointer,
+// or nil if there is none.
+func (p *parser) objectBuilder(pointer Pointer) ObjectBuilder {
+	if p.objectFactory == nil || !p.materialize() {
+		return nil
+	}
+	return p.objectFactory(slices.Clone(pointer))
+}
+
+// arrayBuilder returns the caller's builder for the array at pointer,
+// or nil if there is none.
+func (p *parser) arrayBuilder(pointer Pointer) ArrayBuilder {
+	if p.arrayFactory == nil || !p.materialize() {
+		return nil
+	}
+	return p.arrayFactory(slices.Clone(pointer))
+}
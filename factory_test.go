@@ -0,0 +1,130 @@
+package jseq_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+// labels builds a map[string]string from an object of strings.
+type labels map[string]string
+
+func (l labels) Add(key string, val any) error {
+	s, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("label %q is %s, not a string", key, jseq.TypeName(val))
+	}
+	l[key] = s
+	return nil
+}
+
+func (l labels) Build() (any, error) {
+	return map[string]string(l), nil
+}
+
+// point builds a point from a two-element array of numbers.
+type point struct {
+	x, y  float64
+	count int
+}
+
+func (p *point) Append(val any) error {
+	n, ok := val.(jseq.Number)
+	if !ok {
+		return fmt.Errorf("coordinate is %s, not a number", jseq.TypeName(val))
+	}
+	f := n.Float()
+	switch p.count {
+	case 0:
+		p.x = f
+	case 1:
+		p.y = f
+	default:
+		return errors.New("too many coordinates")
+	}
+	p.count++
+	return nil
+}
+
+func (p *point) Build() (any, error) {
+	if p.count != 2 {
+		return nil, fmt.Errorf("got %d coordinates, want 2", p.count)
+	}
+	return [2]float64{p.x, p.y}, nil
+}
+
+func TestContainerFactories(t *testing.T) {
+	var (
+		objectFactory = jseq.ObjectFactory(func(pointer jseq.Pointer) jseq.ObjectBuilder {
+			if len(pointer) == 1 && pointer[0] == "labels" {
+				return make(labels)
+			}
+			return nil
+		})
+		arrayFactory = jseq.ArrayFactory(func(pointer jseq.Pointer) jseq.ArrayBuilder {
+			if len(pointer) == 1 && pointer[0] == "at" {
+				return new(point)
+			}
+			return nil
+		})
+	)
+
+	cases := []struct {
+		inp     string
+		want    []any
+		wantErr bool
+	}{{
+		inp: `{"labels": {"a": "x", "b": "y"}, "at": [1, 2], "tags": ["t"]}`,
+		want: []any{
+			"x", "y",
+			map[string]string{"a": "x", "b": "y"},
+			jseq.Int(1), jseq.Int(2),
+			[2]float64{1, 2},
+			"t",
+			[]any{"t"},
+			map[string]any{
+				"labels": map[string]string{"a": "x", "b": "y"},
+				"at":     [2]float64{1, 2},
+				"tags":   []any{"t"},
+			},
+		},
+	}, {
+		inp:     `{"labels": {"a": 1}}`,
+		wantErr: true,
+	}, {
+		inp:     `{"at": [1, 2, 3]}`,
+		wantErr: true,
+	}, {
+		inp:     `{"at": [1]}`,
+		wantErr: true,
+	}}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i+1), func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(tc.inp))
+			values, errptr := jseq.Values(tokens, objectFactory, arrayFactory)
+
+			var got []any
+			for _, val := range values {
+				got = append(got, val)
+			}
+			if tc.wantErr {
+				if *errptr == nil {
+					t.Error("got no error, want one")
+				}
+				return
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
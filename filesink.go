@@ -0,0 +1,393 @@
+package jseq
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bobg/errors"
+)
+
+// SyncPolicy is when a [FileSink] flushes its file to stable storage
+// with [os.File.Sync].
+type SyncPolicy int
+
+const (
+	// SyncOnClose syncs only when the file is closed or rotated.
+	SyncOnClose SyncPolicy = iota
+
+	// SyncEachRecord syncs after every record.
+	// This is the most durable and the slowest policy.
+	SyncEachRecord
+
+	// SyncInterval syncs after a record
+	// when at least [FileSinkOptions.Interval] has passed since the last sync.
+	SyncInterval
+
+	// SyncNever leaves syncing to the operating system.
+	SyncNever
+)
+
+// FileSinkOptions controls the behavior of a [FileSink].
+// The zero value is a usable default.
+type FileSinkOptions struct {
+	Sync SyncPolicy
+
+	// Interval is for [SyncInterval].
+	// The default is one second.
+	Interval time.Duration
+
+	// Perm is the permission for newly created files.
+	// The default is 0644.
+	Perm os.FileMode
+
+	// KeepPartial controls what happens to a partial final line
+	// found when opening an existing file,
+	// the remains of a write interrupted by a crash.
+	// By default it is truncated away.
+	// If KeepPartial is true,
+	// it is instead terminated with a newline,
+	// leaving a malformed record for a reader to deal with
+	// (e.g. with [Records]).
+	// Either way, it is available from [FileSink.Partial].
+	KeepPartial bool
+
+	// Manifest, if non-nil,
+	// receives a [ManifestFile] describing each file the sink finishes with,
+	// when it is rotated away (see [FileSink.Rotate])
+	// or when the sink is closed.
+	// The description includes any content the file had when it was opened.
+	Manifest *Manifest
+
+	// Key locates the value in each record
+	// whose range is recorded in the Manifest.
+	// If nil, no key range is recorded.
+	Key Pointer
+}
+
+// FileSink is a [Sink] that appends top-level values to a file
+// as newline-delimited JSON.
+// Create one with [OpenFileSink].
+//
+// Each record is written with a single append,
+// so a crash leaves at most one partial line at the end of the file,
+// which is dealt with the next time the file is opened.
+//
+// The methods of a FileSink are safe for concurrent use,
+// so that e.g. [FileSink.Rotate] may be called on a timer
+// while records are being written.
+type FileSink struct {
+	path  string
+	fopts FileSinkOptions
+	opts  []jsontext.Options
+
+	mu       sync.Mutex
+	f        *os.File
+	buf      bytes.Buffer
+	enc      *jsontext.Encoder
+	lastSync time.Time
+	partial  []byte
+	stats    *fileStats // if fopts.Manifest is non-nil
+	err      error      // why the file was closed, after a failure
+}
+
+// OpenFileSink opens the file at path for appending records,
+// creating it if necessary.
+// Records are encoded with [Encode] using the given options,
+// one per line
+// ([jsontext.Multiline] is overridden).
+func OpenFileSink(path string, fopts FileSinkOptions, opts ...jsontext.Options) (*FileSink, error) {
+	if fopts.Interval <= 0 {
+		fopts.Interval = time.Second
+	}
+	if fopts.Perm == 0 {
+		fopts.Perm = 0644
+	}
+	s := &FileSink{
+		path:  path,
+		fopts: fopts,
+		opts:  append(slices.Clip(opts), jsontext.Multiline(false)),
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open opens s.path and deals with any partial final line.
+func (s *FileSink) open() error {
+	_, err := os.Stat(s.path)
+	created := errors.Is(err, os.ErrNotExist)
+
+	f, err := os.OpenFile(s.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, s.fopts.Perm)
+	if err != nil {
+		return errors.Wrapf(err, "opening %s", s.path)
+	}
+
+	partial, err := trailingPartial(f)
+	if err != nil {
+		f.Close()
+		return errors.Wrapf(err, "checking %s for a partial line", s.path)
+	}
+	if len(partial) > 0 {
+		if s.fopts.KeepPartial {
+			_, err = f.Write([]byte{'\n'})
+		} else {
+			var info os.FileInfo
+			if info, err = f.Stat(); err == nil {
+				err = f.Truncate(info.Size() - int64(len(partial)))
+			}
+		}
+		if err == nil {
+			err = f.Sync()
+		}
+		if err != nil {
+			f.Close()
+			return errors.Wrapf(err, "repairing partial line in %s", s.path)
+		}
+	}
+
+	if created {
+		if err := syncDir(s.path); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	var stats *fileStats
+	if s.fopts.Manifest != nil {
+		stats = newFileStats(s.fopts.Key)
+		info, err := f.Stat()
+		if err == nil {
+			err = stats.scan(io.NewSectionReader(f, 0, info.Size()))
+		}
+		if err != nil {
+			f.Close()
+			return errors.Wrapf(err, "scanning %s for manifest", s.path)
+		}
+	}
+
+	s.f, s.partial, s.stats, s.lastSync = f, partial, stats, time.Now()
+	s.enc = jsontext.NewEncoder(&s.buf, s.opts...)
+	return nil
+}
+
+// trailingPartial returns the bytes after the last newline in f.
+func trailingPartial(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		end   = info.Size()
+		chunk = make([]byte, 4096)
+	)
+	for pos := end; pos > 0; {
+		n := min(int64(len(chunk)), pos)
+		pos -= n
+		if _, err := f.ReadAt(chunk[:n], pos); err != nil {
+			return nil, err
+		}
+		if i := bytes.LastIndexByte(chunk[:n], '\n'); i >= 0 {
+			pos += int64(i) + 1
+			result := make([]byte, end-pos)
+			_, err := f.ReadAt(result, pos)
+			return result, err
+		}
+	}
+	result := make([]byte, end)
+	if _, err := f.ReadAt(result, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Partial returns the partial final line,
+// if any,
+// found when the file was last opened.
+// See [FileSinkOptions.KeepPartial].
+func (s *FileSink) Partial() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.partial
+}
+
+// Consume implements [Sink].
+// Values that are not top-level are ignored.
+//
+// If writing a record fails partway,
+// the part that was written is truncated away.
+// If that fails too,
+// the file is closed and later writes fail.
+func (s *FileSink) Consume(pointer Pointer, val any) error {
+	if len(pointer) > 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return s.closedErr()
+	}
+
+	s.buf.Reset()
+	if err := encodeValue(s.enc, val); err != nil {
+		return err
+	}
+	if n, err := s.f.Write(s.buf.Bytes()); err != nil {
+		err = errors.Wrapf(err, "writing to %s", s.path)
+		if n > 0 {
+			err = errors.Join(err, s.unwrite(n))
+		}
+		return err
+	}
+	if s.stats != nil {
+		s.stats.add(s.buf.Bytes(), val)
+	}
+
+	switch s.fopts.Sync {
+	case SyncEachRecord:
+		return s.sync()
+	case SyncInterval:
+		if time.Since(s.lastSync) >= s.fopts.Interval {
+			return s.sync()
+		}
+	}
+	return nil
+}
+
+// unwrite removes the last n bytes of the file,
+// the partial line left by a failed write,
+// so that the next record does not run into it.
+// If that fails,
+// the file is closed and later writes fail with the error.
+func (s *FileSink) unwrite(n int) error {
+	info, err := s.f.Stat()
+	if err == nil {
+		err = s.f.Truncate(info.Size() - int64(n))
+	}
+	if err != nil {
+		s.f.Close()
+		s.f = nil
+		s.err = errors.Wrapf(err, "removing partial line from %s", s.path)
+		return s.err
+	}
+	return nil
+}
+
+// Sync flushes the file to stable storage,
+// regardless of the sync policy.
+func (s *FileSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	return s.sync()
+}
+
+func (s *FileSink) sync() error {
+	if err := s.f.Sync(); err != nil {
+		return errors.Wrapf(err, "syncing %s", s.path)
+	}
+	s.lastSync = time.Now()
+	return nil
+}
+
+// Rotate atomically renames the file to newpath
+// and continues writing to a new, empty file at the original path.
+// The old file is synced (unless the policy is [SyncNever]) before it is renamed,
+// so newpath never refers to an incomplete file.
+// Both paths must be on the same file system.
+//
+// If the file cannot be renamed,
+// Rotate reports the error and writing continues to the original file.
+// If the file at the original path cannot then be opened,
+// later writes fail with that error.
+func (s *FileSink) Rotate(newpath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return s.closedErr()
+	}
+	if err := s.closeFile(); err != nil {
+		return errors.Join(err, s.reopen())
+	}
+	if err := os.Rename(s.path, newpath); err != nil {
+		return errors.Join(errors.Wrapf(err, "renaming %s to %s", s.path, newpath), s.reopen())
+	}
+	s.addToManifest(newpath)
+	return errors.Join(syncDir(newpath), s.reopen())
+}
+
+// reopen opens the file at s.path after rotating,
+// recording the error if that fails.
+func (s *FileSink) reopen() error {
+	if err := s.open(); err != nil {
+		s.err = errors.Wrap(err, "reopening after rotation")
+		return s.err
+	}
+	return nil
+}
+
+// closedErr is the error for using s after its file is closed.
+func (s *FileSink) closedErr() error {
+	if s.err != nil {
+		return s.err
+	}
+	return errors.New("file sink is closed")
+}
+
+// Close implements [Sink].
+// It syncs (unless the policy is [SyncNever]) and closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.f == nil {
+		return nil
+	}
+	err := s.closeFile()
+	s.addToManifest(s.path)
+	return err
+}
+
+func (s *FileSink) addToManifest(path string) {
+	if s.stats != nil {
+		s.fopts.Manifest.Files = append(s.fopts.Manifest.Files, s.stats.file(path))
+		s.stats = nil
+	}
+}
+
+func (s *FileSink) closeFile() error {
+	var err error
+	if s.fopts.Sync != SyncNever {
+		err = s.sync()
+	}
+	err = errors.Join(err, s.f.Close())
+	s.f = nil
+	return err
+}
+
+// syncDir syncs the directory containing path,
+// making the creation or renaming of a file in it durable.
+func syncDir(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil // not supported
+	}
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return errors.Wrap(err, "opening directory")
+	}
+	defer dir.Close()
+	return errors.Wrapf(dir.Sync(), "syncing directory of %s", path)
+}
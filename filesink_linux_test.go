@@ -0,0 +1,50 @@
+package jseq_test
+
+import (
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestFileSinkShortWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.ndjson")
+
+	s, err := jseq.OpenFileSink(path, jseq.FileSinkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	consume(t, s, "first")
+
+	// Limit the file size so that the next record is written only in part.
+	var lim syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_FSIZE, &lim); err != nil {
+		t.Fatal(err)
+	}
+	signal.Ignore(syscall.SIGXFSZ)
+	defer signal.Reset(syscall.SIGXFSZ)
+	short := lim
+	short.Cur = uint64(len("\"first\"\n") + 4)
+	if err := syscall.Setrlimit(syscall.RLIMIT_FSIZE, &short); err != nil {
+		t.Skip(err)
+	}
+	err = s.Consume(nil, "a long second record")
+	if err := syscall.Setrlimit(syscall.RLIMIT_FSIZE, &lim); err != nil {
+		t.Fatal(err)
+	}
+	if err == nil {
+		t.Fatal("got no error for short write")
+	}
+
+	consume(t, s, "third")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readFile(t, path), "\"first\"\n\"third\"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
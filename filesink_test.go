@@ -0,0 +1,168 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.ndjson")
+
+	for _, policy := range []jseq.SyncPolicy{jseq.SyncOnClose, jseq.SyncEachRecord, jseq.SyncInterval, jseq.SyncNever} {
+		os.Remove(path)
+
+		s, err := jseq.OpenFileSink(path, jseq.FileSinkOptions{Sync: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		consume(t, s, map[string]any{"a": jseq.Int(1)})
+		consume(t, s, []any{"x"})
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Reopening appends.
+		s, err = jseq.OpenFileSink(path, jseq.FileSinkOptions{Sync: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		consume(t, s, true)
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		const want = "{\"a\":1}\n[\"x\"]\ntrue\n"
+		if got := readFile(t, path); got != want {
+			t.Errorf("policy %d: got %q, want %q", policy, got, want)
+		}
+	}
+}
+
+func TestFileSinkPartial(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.ndjson")
+
+	cases := []struct {
+		name        string
+		content     string
+		keepPartial bool
+		wantPartial string
+		want        string
+	}{
+		{name: "clean", content: "1\n2\n", want: "1\n2\n3\n"},
+		{name: "truncate", content: "1\n{\"a\": ", wantPartial: "{\"a\": ", want: "1\n3\n"},
+		{name: "keep", content: "1\n{\"a\": ", keepPartial: true, wantPartial: "{\"a\": ", want: "1\n{\"a\": \n3\n"},
+		{name: "only partial", content: "{\"a\"", wantPartial: "{\"a\"", want: "3\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			s, err := jseq.OpenFileSink(path, jseq.FileSinkOptions{KeepPartial: tc.keepPartial})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(s.Partial()); got != tc.wantPartial {
+				t.Errorf("got partial %q, want %q", got, tc.wantPartial)
+			}
+			consume(t, s, jseq.Int(3))
+			if err := s.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := readFile(t, path); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFileSinkRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.ndjson")
+
+	s, err := jseq.OpenFileSink(path, jseq.FileSinkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	consume(t, s, jseq.Int(1))
+	if err := s.Rotate(filepath.Join(dir, "events.1.ndjson")); err != nil {
+		t.Fatal(err)
+	}
+	consume(t, s, jseq.Int(2))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, filepath.Join(dir, "events.1.ndjson")); got != "1\n" {
+		t.Errorf("got rotated %q, want %q", got, "1\n")
+	}
+	if got := readFile(t, path); got != "2\n" {
+		t.Errorf("got current %q, want %q", got, "2\n")
+	}
+
+	if err := s.Consume(nil, jseq.Int(3)); err == nil {
+		t.Error("got no error consuming after close")
+	}
+}
+
+func TestFileSinkRotateFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.ndjson")
+
+	s, err := jseq.OpenFileSink(path, jseq.FileSinkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	consume(t, s, jseq.Int(1))
+	if err := s.Rotate(filepath.Join(dir, "nonexistent", "events.1.ndjson")); err == nil {
+		t.Fatal("got no error rotating into a nonexistent directory")
+	}
+
+	// Writing continues to the original file.
+	consume(t, s, jseq.Int(2))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "1\n2\n" {
+		t.Errorf("got %q, want %q", got, "1\n2\n")
+	}
+}
+
+func TestFileSinkOptionsUnchanged(t *testing.T) {
+	// The caller's options must not be overwritten
+	// by the Multiline option that OpenFileSink adds.
+	opts := make([]jsontext.Options, 1, 2)
+	opts[0] = jsontext.SpaceAfterColon(true)
+
+	s, err := jseq.OpenFileSink(filepath.Join(t.TempDir(), "events.ndjson"), jseq.FileSinkOptions{}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if extra := opts[:2][1]; extra != nil {
+		t.Errorf("caller's options slice was modified: %v", extra)
+	}
+}
+
+func consume(t *testing.T, s jseq.Sink, val any) {
+	t.Helper()
+	if err := s.Consume(nil, val); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,164 @@
+package jseq
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FormatDetector tells whether an input is in some format.
+// It is given the input's filename and MIME content type,
+// either of which may be empty,
+// and the first few bytes of the input.
+type FormatDetector func(filename, contentType string, peek []byte) bool
+
+// FormatOpener produces a [Source] of JSON tokens
+// from an input in some format.
+type FormatOpener func(io.Reader) (Source, error)
+
+type format struct {
+	name   string
+	detect FormatDetector
+	open   FormatOpener
+}
+
+var (
+	formatsMu sync.Mutex
+	formats   = []format{
+		{name: "json", detect: detectJSON, open: openJSON},
+		{name: "json-seq", detect: detectJSONSeq, open: openJSONSeq},
+		{name: "jseq-dict", detect: detectDict, open: openDict},
+	}
+)
+
+// peekSize is the number of bytes of input passed to a [FormatDetector].
+const peekSize = 512
+
+// RegisterFormat registers an input format for use by [Open].
+// Formats are tried in reverse order of registration,
+// so a later registration takes precedence over an earlier one,
+// including over the built-in formats:
+//
+//   - "json": JSON, including concatenated and newline-delimited JSON
+//   - "json-seq": JSON text sequences, RFC 7464
+//   - "jseq-dict": the dictionary-compressed output of [DictSink]
+//
+// Registering a name that is already registered replaces it,
+// keeping its place in the order.
+// Replacing "json" changes the handling of input that no format's detector accepts.
+func RegisterFormat(name string, detect FormatDetector, open FormatOpener) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	f := format{name: name, detect: detect, open: open}
+	for i := range formats {
+		if formats[i].name == name {
+			formats[i] = f
+			return
+		}
+	}
+	formats = append(formats, f)
+}
+
+// Open opens r as a [Source] of JSON tokens,
+// choosing a registered format (see [RegisterFormat])
+// based on the filename, content type, and initial bytes of the input.
+// Either filename or contentType may be empty.
+// If no format's detector accepts the input,
+// it is treated as JSON
+// (using the format registered as "json").
+//
+// The name of the chosen format is returned along with the source.
+func Open(r io.Reader, filename, contentType string) (Source, string, error) {
+	br := bufio.NewReaderSize(r, peekSize)
+	peek, err := br.Peek(peekSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, "", err
+	}
+
+	formatsMu.Lock()
+	candidates := append([]format(nil), formats...)
+	formatsMu.Unlock()
+
+	var chosen format
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if candidates[i].detect(filename, contentType, peek) {
+			chosen = candidates[i]
+			break
+		}
+	}
+	if chosen.open == nil {
+		for _, f := range candidates {
+			if f.name == "json" {
+				chosen = f
+				break
+			}
+		}
+	}
+
+	src, err := chosen.open(br)
+	if err != nil {
+		return nil, "", fmt.Errorf("opening %s input: %w", chosen.name, err)
+	}
+	return src, chosen.name, nil
+}
+
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return mt
+}
+
+func detectJSON(filename, contentType string, peek []byte) bool {
+	switch mt := mediaType(contentType); {
+	case mt == "application/json", mt == "application/x-ndjson", mt == "application/jsonl", strings.HasSuffix(mt, "+json"):
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json", ".ndjson", ".jsonl":
+		return true
+	}
+	return false
+}
+
+func openJSON(r io.Reader) (Source, error) {
+	return ReaderSource(r), nil
+}
+
+// recordSeparator is the ASCII RS character that begins each record in RFC 7464.
+const recordSeparator = 0x1e
+
+func detectJSONSeq(filename, contentType string, peek []byte) bool {
+	if mediaType(contentType) == "application/json-seq" {
+		return true
+	}
+	if strings.ToLower(filepath.Ext(filename)) == ".json-seq" {
+		return true
+	}
+	return len(peek) > 0 && peek[0] == recordSeparator
+}
+
+func openJSONSeq(r io.Reader) (Source, error) {
+	return ReaderSource(&rsReader{r: r}), nil
+}
+
+// rsReader replaces each RFC 7464 record separator in its input with a newline,
+// turning a JSON text sequence into plain concatenated JSON.
+type rsReader struct {
+	r io.Reader
+}
+
+func (r *rsReader) Read(buf []byte) (int, error) {
+	n, err := r.r.Read(buf)
+	for i := bytes.IndexByte(buf[:n], recordSeparator); i >= 0; i = bytes.IndexByte(buf[:n], recordSeparator) {
+		buf[i] = '\n'
+	}
+	return n, err
+}
@@ -0,0 +1,114 @@
+package jseq_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestOpen(t *testing.T) {
+	cases := []struct {
+		name, filename, contentType, inp string
+		wantFormat                       string
+	}{
+		{"plain", "", "", `{"a": 1} [2]`, "json"},
+		{"ndjson_ext", "x.ndjson", "", "{\"a\": 1}\n[2]\n", "json"},
+		{"seq_sniff", "", "", "\x1e{\"a\": 1}\n\x1e[2]\n", "json-seq"},
+		{"seq_type", "", "application/json-seq; charset=utf-8", "\x1e{\"a\": 1}\n\x1e[2]\n", "json-seq"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			src, name, err := jseq.Open(strings.NewReader(tc.inp), tc.filename, tc.contentType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tc.wantFormat {
+				t.Errorf("got format %s, want %s", name, tc.wantFormat)
+			}
+			got := records(t, src)
+			want := []any{map[string]any{"a": jseq.Int(1)}, []any{jseq.Int(2)}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	// A toy format: JSON with a "JS1" magic prefix.
+	jseq.RegisterFormat("js1",
+		func(_, _ string, peek []byte) bool { return bytes.HasPrefix(peek, []byte("JS1")) },
+		func(r io.Reader) (jseq.Source, error) {
+			var magic [3]byte
+			if _, err := io.ReadFull(r, magic[:]); err != nil {
+				return nil, err
+			}
+			return jseq.ReaderSource(r), nil
+		},
+	)
+
+	src, name, err := jseq.Open(strings.NewReader(`JS1 {"a": 1} [2]`), "x.json", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "js1" {
+		t.Errorf("got format %s, want js1", name)
+	}
+	got := records(t, src)
+	want := []any{map[string]any{"a": jseq.Int(1)}, []any{jseq.Int(2)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRegisterFormatReplace(t *testing.T) {
+	// Replacing "json" keeps it as the format for input no detector accepts.
+	var opened int
+	jseq.RegisterFormat("json",
+		func(_, _ string, _ []byte) bool { return false },
+		func(r io.Reader) (jseq.Source, error) {
+			opened++
+			return jseq.ReaderSource(r), nil
+		},
+	)
+	t.Cleanup(func() {
+		// Equivalent to the built-in format,
+		// whose detector matters only for formats registered before it
+		// (of which there are none).
+		jseq.RegisterFormat("json",
+			func(_, _ string, _ []byte) bool { return false },
+			func(r io.Reader) (jseq.Source, error) { return jseq.ReaderSource(r), nil },
+		)
+	})
+
+	src, name, err := jseq.Open(strings.NewReader(`{"a": 1} [2]`), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "json" {
+		t.Errorf("got format %s, want json", name)
+	}
+	if opened != 1 {
+		t.Errorf("replacement opener called %d times, want 1", opened)
+	}
+	got := records(t, src)
+	want := []any{map[string]any{"a": jseq.Int(1)}, []any{jseq.Int(2)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Its place in the order is unchanged,
+	// so later formats still take precedence.
+	_, name, err = jseq.Open(strings.NewReader("\x1e{\"a\": 1}\n"), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "json-seq" {
+		t.Errorf("got format %s, want json-seq", name)
+	}
+}
@@ -0,0 +1,154 @@
+package jseq
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"math/big"
+	"slices"
+)
+
+// Frozen is a read-only view of a JSON value
+// of the kind produced by [Values].
+// Its methods give access to the value's contents
+// without exposing the underlying maps and slices,
+// so it can be shared freely among goroutines.
+// Create one with [Freeze].
+type Frozen struct {
+	v any
+}
+
+// Freeze returns a read-only view of v.
+//
+// Freeze does not copy v.
+// The caller must not modify v after freezing it,
+// or the change will be visible through the view.
+func Freeze(v any) Frozen {
+	return Frozen{v: decodedForm(v)}
+}
+
+// Type returns the JSON type of the value.
+// See [TypeName].
+func (f Frozen) Type() string {
+	return TypeName(f.v)
+}
+
+// Len returns the number of members of an array or object,
+// or zero for other types.
+func (f Frozen) Len() int {
+	switch v := f.v.(type) {
+	case []any:
+		return len(v)
+	case map[string]any:
+		return len(v)
+	case *Object:
+		return v.Len()
+	}
+	return 0
+}
+
+// Index returns the element of an array at index i.
+// It panics if the value is not an array or i is out of range.
+func (f Frozen) Index(i int) Frozen {
+	a, ok := f.v.([]any)
+	if !ok {
+		panic(fmt.Sprintf("Index called on %s", f.Type()))
+	}
+	return Freeze(a[i])
+}
+
+// Get returns the member of an object with the given key.
+// The boolean result is false if the value is not an object
+// or has no such key.
+func (f Frozen) Get(key string) (Frozen, bool) {
+	m, ok := f.object()
+	if !ok {
+		return Frozen{}, false
+	}
+	val, ok := m[key]
+	return Freeze(val), ok
+}
+
+// Keys returns the keys of an object in sorted order
+// (or in their own order, for an [*Object]),
+// or nil for other types.
+func (f Frozen) Keys() []string {
+	switch v := f.v.(type) {
+	case map[string]any:
+		return SortedKeys(v)
+	case *Object:
+		return slices.Clone(v.keys)
+	}
+	return nil
+}
+
+// Elements iterates over the elements of an array.
+// It produces nothing for other types.
+func (f Frozen) Elements() iter.Seq2[int, Frozen] {
+	return func(yield func(int, Frozen) bool) {
+		a, _ := f.v.([]any)
+		for i, elt := range a {
+			if !yield(i, Freeze(elt)) {
+				return
+			}
+		}
+	}
+}
+
+// Members iterates over the members of an object in the order of [Frozen.Keys].
+// It produces nothing for other types.
+func (f Frozen) Members() iter.Seq2[string, Frozen] {
+	return func(yield func(string, Frozen) bool) {
+		m, _ := f.object()
+		for _, key := range f.Keys() {
+			if !yield(key, Freeze(m[key])) {
+				return
+			}
+		}
+	}
+}
+
+// Locate locates the part of the value represented by p.
+// See [Pointer.Locate].
+func (f Frozen) Locate(p Pointer) (Frozen, error) {
+	v, err := p.Locate(f.v)
+	if err != nil {
+		return Frozen{}, err
+	}
+	return Freeze(v), nil
+}
+
+// Scalar returns the value if it is a string, bool, number, or null.
+// A [*big.Int] or [*big.Float] is copied.
+// The boolean result is false for arrays and objects,
+// whose contents must be reached via the other methods of [Frozen],
+// and for other types (such as those built by an [ObjectFactory]),
+// which a read-only view cannot protect.
+func (f Frozen) Scalar() (any, bool) {
+	switch v := f.v.(type) {
+	case string, bool, Number, float64, json.Number, Null, nil:
+		return v, true
+	case *big.Int:
+		return new(big.Int).Set(v), true
+	case *big.Float:
+		return new(big.Float).Copy(v), true
+	}
+	return nil, false
+}
+
+// object returns the members of an object value.
+func (f Frozen) object() (map[string]any, bool) {
+	switch v := f.v.(type) {
+	case map[string]any:
+		return v, true
+	case *Object:
+		return v.m, true
+	}
+	return nil, false
+}
+
+// Thaw returns a deep copy of the value,
+// which the caller is free to modify.
+func (f Frozen) Thaw() any {
+	return Clone(f.v)
+}
@@ -0,0 +1,138 @@
+package jseq_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestFreeze(t *testing.T) {
+	val := map[string]any{
+		"b": []any{"x", jseq.Int(2)},
+		"a": map[string]any{"c": jseq.Null{}},
+	}
+	f := jseq.Freeze(val)
+
+	if typ := f.Type(); typ != "object" {
+		t.Errorf("got type %s, want object", typ)
+	}
+	if n := f.Len(); n != 2 {
+		t.Errorf("got len %d, want 2", n)
+	}
+	if keys := f.Keys(); !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("got keys %v, want [a b]", keys)
+	}
+
+	b, ok := f.Get("b")
+	if !ok {
+		t.Fatal("no member b")
+	}
+	if s, ok := b.Index(0).Scalar(); !ok || s != "x" {
+		t.Errorf("got %v, %v; want x, true", s, ok)
+	}
+	if _, ok := b.Scalar(); ok {
+		t.Error("got scalar for array")
+	}
+
+	var elts []any
+	for _, elt := range b.Elements() {
+		s, _ := elt.Scalar()
+		elts = append(elts, s)
+	}
+	if !reflect.DeepEqual(elts, []any{"x", jseq.Int(2)}) {
+		t.Errorf("got elements %v", elts)
+	}
+
+	var keys []string
+	for key := range f.Members() {
+		keys = append(keys, key)
+	}
+	if !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("got member keys %v", keys)
+	}
+
+	c, err := f.Locate(jseq.Pointer{"a", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ := c.Type(); typ != "null" {
+		t.Errorf("got type %s, want null", typ)
+	}
+
+	thawed := f.Thaw().(map[string]any)
+	thawed["b"].([]any)[0] = "changed"
+	if val["b"].([]any)[0] != "x" {
+		t.Error("modifying thawed copy changed the original")
+	}
+}
+
+func TestFreezeObject(t *testing.T) {
+	tokens, _ := jseq.Tokens(strings.NewReader(`{"b": 1, "a": {"c": 2}}`))
+	values, errptr := jseq.Values(tokens, jseq.OrderedObjects())
+	var val any
+	for _, v := range values {
+		val = v
+	}
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+
+	f := jseq.Freeze(val)
+	if n := f.Len(); n != 2 {
+		t.Errorf("got len %d, want 2", n)
+	}
+	if keys := f.Keys(); !slices.Equal(keys, []string{"b", "a"}) {
+		t.Errorf("got keys %v, want [b a]", keys)
+	}
+	var keys []string
+	for key := range f.Members() {
+		keys = append(keys, key)
+	}
+	if !slices.Equal(keys, []string{"b", "a"}) {
+		t.Errorf("got member keys %v", keys)
+	}
+	a, ok := f.Get("a")
+	if !ok || a.Len() != 1 {
+		t.Errorf("got %v, %v for member a", a, ok)
+	}
+	if _, ok := f.Scalar(); ok {
+		t.Error("got scalar for object")
+	}
+}
+
+func TestFreezeScalar(t *testing.T) {
+	cases := []struct {
+		name   string
+		val    any
+		want   any
+		wantOK bool
+	}{
+		{name: "string", val: "x", want: "x", wantOK: true},
+		{name: "null", val: nil, want: nil, wantOK: true},
+		{name: "big_int", val: big.NewInt(7), want: big.NewInt(7), wantOK: true},
+		{name: "big_float", val: big.NewFloat(1.5), want: big.NewFloat(1.5), wantOK: true},
+		{name: "raw", val: json.RawMessage(`[1]`), want: nil, wantOK: false},
+		{name: "other", val: struct{ X []int }{}, want: nil, wantOK: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := jseq.Freeze(tc.val).Scalar()
+			if ok != tc.wantOK || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, %v; want %v, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+
+	// A big number is copied, so changing it doesn't change the original.
+	n := big.NewInt(7)
+	got, _ := jseq.Freeze(n).Scalar()
+	got.(*big.Int).SetInt64(8)
+	if n.Int64() != 7 {
+		t.Error("modifying scalar changed the original")
+	}
+}
@@ -0,0 +1,175 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"iter"
+	"math/rand/v2"
+	"strings"
+)
+
+// GenSpec controls the output of [Generate].
+// Zero fields take default values.
+type GenSpec struct {
+	// Size is the approximate number of tokens to generate.
+	// Generation stops after the first top-level value that reaches it.
+	// The default is 1000.
+	Size int
+
+	// MaxDepth is the maximum nesting depth of arrays and objects.
+	// Top-level values are at depth 1.
+	// The default is 4.
+	MaxDepth int
+
+	// MaxLen is the maximum number of elements in an array or members in an object.
+	// The default is 8.
+	MaxLen int
+
+	// KeyAlphabet supplies the characters of object keys and string values.
+	// The default is the lowercase letters a through z.
+	KeyAlphabet string
+
+	// MaxStringLen is the maximum length, in characters, of object keys and string values.
+	// Keys are at least one character long.
+	// The default is 8.
+	MaxStringLen int
+
+	// MinNumber and MaxNumber bound the numbers generated.
+	// If both are zero, the range is [-1000, 1000).
+	MinNumber, MaxNumber float64
+}
+
+// Generate produces a pseudo-random sequence of JSON tokens according to spec.
+// The output consists of one or more top-level objects
+// and is suitable as input to [Values].
+// It is determined entirely by spec and the state of rng,
+// so a given seed always produces the same output.
+// Each iteration over the result generates a fresh sequence,
+// continuing from the state in which the previous one left rng.
+//
+// Generate is meant for producing large synthetic inputs
+// for benchmarks and fuzz corpora.
+func Generate(rng *rand.Rand, spec GenSpec) iter.Seq[jsontext.Token] {
+	spec = spec.withDefaults()
+	return func(yield func(jsontext.Token) bool) {
+		g := generator{rng: rng, spec: spec, yield: yield}
+		for g.n < g.spec.Size {
+			if !g.object(1) {
+				return
+			}
+		}
+	}
+}
+
+func (s GenSpec) withDefaults() GenSpec {
+	if s.Size <= 0 {
+		s.Size = 1000
+	}
+	if s.MaxDepth <= 0 {
+		s.MaxDepth = 4
+	}
+	if s.MaxLen <= 0 {
+		s.MaxLen = 8
+	}
+	if s.KeyAlphabet == "" {
+		s.KeyAlphabet = "abcdefghijklmnopqrstuvwxyz"
+	}
+	if s.MaxStringLen <= 0 {
+		s.MaxStringLen = 8
+	}
+	if s.MinNumber == 0 && s.MaxNumber == 0 {
+		s.MinNumber, s.MaxNumber = -1000, 1000
+	}
+	return s
+}
+
+type generator struct {
+	rng   *rand.Rand
+	spec  GenSpec
+	yield func(jsontext.Token) bool
+	n     int // tokens yielded so far
+}
+
+func (g *generator) emit(tok jsontext.Token) bool {
+	g.n++
+	return g.yield(tok)
+}
+
+func (g *generator) value(depth int) bool {
+	// Containers are possible only below the maximum depth.
+	choices := 6
+	if depth < g.spec.MaxDepth {
+		choices = 8
+	}
+	switch g.rng.IntN(choices) {
+	case 0:
+		return g.emit(jsontext.Null)
+	case 1:
+		return g.emit(jsontext.Bool(g.rng.IntN(2) == 0))
+	case 2, 3:
+		return g.emit(jsontext.String(g.randString(0)))
+	case 4, 5:
+		return g.emit(g.number())
+	case 6:
+		return g.array(depth + 1)
+	default:
+		return g.object(depth + 1)
+	}
+}
+
+func (g *generator) object(depth int) bool {
+	if !g.emit(jsontext.BeginObject) {
+		return false
+	}
+	var (
+		n    = g.rng.IntN(g.spec.MaxLen + 1)
+		seen = make(map[string]bool)
+	)
+	for range n {
+		key := g.randString(1)
+		if seen[key] {
+			// Keys must be unique. Rather than retrying, which may never succeed with a small alphabet, skip this member.
+			continue
+		}
+		seen[key] = true
+		if !g.emit(jsontext.String(key)) {
+			return false
+		}
+		if !g.value(depth) {
+			return false
+		}
+	}
+	return g.emit(jsontext.EndObject)
+}
+
+func (g *generator) array(depth int) bool {
+	if !g.emit(jsontext.BeginArray) {
+		return false
+	}
+	n := g.rng.IntN(g.spec.MaxLen + 1)
+	for range n {
+		if !g.value(depth) {
+			return false
+		}
+	}
+	return g.emit(jsontext.EndArray)
+}
+
+func (g *generator) randString(minLen int) string {
+	var (
+		alphabet = []rune(g.spec.KeyAlphabet)
+		n        = minLen + g.rng.IntN(g.spec.MaxStringLen-minLen+1)
+		buf      strings.Builder
+	)
+	for range n {
+		buf.WriteRune(alphabet[g.rng.IntN(len(alphabet))])
+	}
+	return buf.String()
+}
+
+func (g *generator) number() jsontext.Token {
+	f := g.spec.MinNumber + g.rng.Float64()*(g.spec.MaxNumber-g.spec.MinNumber)
+	if g.rng.IntN(2) == 0 {
+		return jsontext.Int(int64(f))
+	}
+	return jsontext.Float(f)
+}
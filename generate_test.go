@@ -0,0 +1,88 @@
+package jseq_test
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestGenerate(t *testing.T) {
+	spec := jseq.GenSpec{
+		Size:        500,
+		MaxDepth:    3,
+		KeyAlphabet: "xyz",
+		MinNumber:   10,
+		MaxNumber:   20,
+	}
+
+	gen := func(seed uint64) []byte {
+		buf := new(bytes.Buffer)
+		enc := jsontext.NewEncoder(buf)
+		checked, errptr := jseq.CheckTokens(jseq.Generate(rand.New(rand.NewPCG(seed, 0)), spec))
+		var n int
+		for tok := range checked {
+			if err := enc.WriteToken(tok); err != nil {
+				t.Fatal(err)
+			}
+			n++
+		}
+		if *errptr != nil {
+			t.Fatal(*errptr)
+		}
+		if n < spec.Size {
+			t.Errorf("got %d tokens, want at least %d", n, spec.Size)
+		}
+		return buf.Bytes()
+	}
+
+	out1 := gen(1)
+	if out2 := gen(1); !bytes.Equal(out1, out2) {
+		t.Error("same seed produced different output")
+	}
+	if out3 := gen(2); bytes.Equal(out1, out3) {
+		t.Error("different seeds produced the same output")
+	}
+
+	tokens, errptr1 := jseq.Tokens(bytes.NewReader(out1))
+	values, errptr2 := jseq.Values(tokens)
+	for ptr, val := range values {
+		if len(ptr) >= spec.MaxDepth {
+			if typ := jseq.TypeName(val); typ == "object" || typ == "array" {
+				t.Errorf("%s at depth %d exceeds MaxDepth", typ, len(ptr)+1)
+			}
+		}
+		if num, ok := val.(jseq.Number); ok {
+			if f := num.Float(); f < spec.MinNumber || f >= spec.MaxNumber {
+				t.Errorf("number %s out of range", num)
+			}
+		}
+		for _, tok := range ptr {
+			if key, ok := tok.(string); ok && strings.Trim(key, spec.KeyAlphabet) != "" {
+				t.Errorf("key %q not from alphabet", key)
+			}
+		}
+	}
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+}
+
+func TestGenerateReuse(t *testing.T) {
+	seq := jseq.Generate(rand.New(rand.NewPCG(1, 0)), jseq.GenSpec{Size: 50})
+	for i := range 2 {
+		var n int
+		for range seq {
+			n++
+		}
+		if n < 50 {
+			t.Errorf("iteration %d: got %d tokens, want at least 50", i, n)
+		}
+	}
+}
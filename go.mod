@@ -5,6 +5,7 @@ go 1.25.3
 require (
 	github.com/bobg/errors v1.1.0
 	github.com/bobg/seqs v1.8.0
+	golang.org/x/text v0.30.0
 )
 
 require github.com/bobg/go-generics/v4 v4.1.2 // indirect
@@ -0,0 +1,65 @@
+package jseq
+
+import (
+	"compress/gzip"
+	"encoding/json/jsontext"
+	"io"
+
+	"github.com/bobg/errors"
+)
+
+// GzipOptions controls the behavior of [GzipSink].
+// The zero value is a usable default.
+type GzipOptions struct {
+	// Level is the compression level,
+	// as for [gzip.NewWriterLevel].
+	// The default (zero) is [gzip.DefaultCompression].
+	Level int
+
+	// FlushEachRecord flushes the compressed output after each record,
+	// so that a consumer tailing the output can decompress every complete record
+	// without waiting for the stream to end.
+	// This costs some compression.
+	FlushEachRecord bool
+}
+
+// GzipSink returns a [Sink] that writes top-level values to w
+// in the given format, as for [FormatSink],
+// compressed with gzip.
+// Closing the sink completes the compressed stream
+// but does not close w.
+func GzipSink(w io.Writer, format OutputFormat, gopts GzipOptions, opts ...jsontext.Options) (Sink, error) {
+	level := gopts.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	gz, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating gzip writer")
+	}
+	return &gzipSink{
+		gz:    gz,
+		sink:  FormatSink(gz, format, opts...),
+		flush: gopts.FlushEachRecord,
+	}, nil
+}
+
+type gzipSink struct {
+	gz    *gzip.Writer
+	sink  Sink
+	flush bool
+}
+
+func (s *gzipSink) Consume(pointer Pointer, val any) error {
+	if err := s.sink.Consume(pointer, val); err != nil {
+		return err
+	}
+	if s.flush && len(pointer) == 0 {
+		return errors.Wrap(s.gz.Flush(), "flushing gzip writer")
+	}
+	return nil
+}
+
+func (s *gzipSink) Close() error {
+	return errors.Join(s.sink.Close(), s.gz.Close())
+}
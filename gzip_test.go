@@ -0,0 +1,82 @@
+package jseq_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestGzipSink(t *testing.T) {
+	const inp = `{"a": 1} [2, 3] "four"`
+
+	for _, format := range []jseq.OutputFormat{jseq.NDJSON, jseq.Array} {
+		buf := new(bytes.Buffer)
+		sink, err := jseq.GzipSink(buf, format, jseq.GzipOptions{Level: gzip.BestCompression})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), sink, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		want := new(bytes.Buffer)
+		if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), jseq.FormatSink(want, format), nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := gunzip(t, buf.Bytes()); got != want.String() {
+			t.Errorf("format %s: got %q, want %q", format, got, want)
+		}
+	}
+
+	if _, err := jseq.GzipSink(io.Discard, jseq.NDJSON, jseq.GzipOptions{Level: 42}); err == nil {
+		t.Error("got no error for bad level")
+	}
+}
+
+func TestGzipSinkFlush(t *testing.T) {
+	buf := new(bytes.Buffer)
+	sink, err := jseq.GzipSink(buf, jseq.NDJSON, jseq.GzipOptions{FlushEachRecord: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	var want string
+	for i := range 3 {
+		consume(t, sink, jseq.Int(int64(i)))
+		want += string(rune('0'+i)) + "\n"
+
+		// Everything written so far can be decompressed,
+		// though the stream is incomplete.
+		gz, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(gz)
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("got error %v, want %v", err, io.ErrUnexpectedEOF)
+		}
+		if string(got) != want {
+			t.Errorf("after record %d: got %q, want %q", i, got, want)
+		}
+	}
+}
+
+func gunzip(t *testing.T, data []byte) string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(result)
+}
@@ -0,0 +1,23 @@
+package jseq
+
+import "time"
+
+// Heartbeat is an [Option] that causes [Values] to call f
+// whenever its input has produced no tokens for the given interval,
+// and again after each further interval of silence.
+// The argument to f is the time since the last token (or since the start of iteration).
+//
+// This lets consumers of long-lived streams
+// distinguish a healthy but idle stream from a stalled one,
+// e.g. to send keepalives or update a health check.
+// The callback runs on the goroutine iterating over the output of Values,
+// never concurrently with the caller's loop body.
+//
+// As with [RecordTimeout],
+// this option causes Values to read its input tokens in a separate goroutine.
+func Heartbeat(interval time.Duration, f func(idle time.Duration)) Option {
+	return func(c *config) {
+		c.heartbeat = interval
+		c.onHeartbeat = f
+	}
+}
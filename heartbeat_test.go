@@ -0,0 +1,60 @@
+package jseq_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bobg/jseq"
+)
+
+func TestHeartbeat(t *testing.T) {
+	// The second record is written only after two heartbeats,
+	// so the test does not depend on how quickly they arrive.
+	var (
+		pr, pw = io.Pipe()
+		beats  = make(chan struct{}, 1)
+	)
+	go func() {
+		io.WriteString(pw, `{"a": 1}`)
+		<-beats
+		<-beats
+		io.WriteString(pw, `{"b": 2}`)
+		pw.Close()
+	}()
+
+	var idles []time.Duration
+	tokens, errptr1 := jseq.Tokens(pr)
+	values, errptr2 := jseq.Values(tokens, jseq.Heartbeat(10*time.Millisecond, func(idle time.Duration) {
+		idles = append(idles, idle)
+		select {
+		case beats <- struct{}{}:
+		default:
+		}
+	}))
+
+	var records int
+	for ptr := range values {
+		if len(ptr) == 0 {
+			records++
+		}
+	}
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+	if records != 2 {
+		t.Errorf("got %d records, want 2", records)
+	}
+
+	if len(idles) < 2 {
+		t.Fatalf("got %d heartbeats (%v), want at least 2", len(idles), idles)
+	}
+	for i := 1; i < len(idles); i++ {
+		if idles[i] <= idles[i-1] {
+			t.Errorf("idle times not increasing: %v", idles)
+		}
+	}
+}
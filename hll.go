@@ -0,0 +1,139 @@
+package jseq
+
+import (
+	"hash/fnv"
+	"iter"
+	"math"
+	"math/bits"
+)
+
+// HyperLogLog is a sketch for estimating the number of distinct items in a stream
+// using a small, fixed amount of memory.
+// The zero value is not usable; create one with [NewHyperLogLog].
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog creates a new [HyperLogLog] with 2^precision registers.
+// Higher precision gives better estimates at the cost of more memory:
+// the standard error is about 1.04/sqrt(2^precision).
+// The precision is clamped to the range [4, 18].
+func NewHyperLogLog(precision uint8) *HyperLogLog {
+	precision = min(max(precision, 4), 18)
+	return &HyperLogLog{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}
+}
+
+// Add adds an item to the sketch.
+func (h *HyperLogLog) Add(item []byte) {
+	hasher := fnv.New64a()
+	hasher.Write(item)
+	x := mix64(hasher.Sum64())
+
+	idx := x >> (64 - h.precision)
+	rest := x<<h.precision | 1<<(h.precision-1) // guard bit bounds the run of zeroes
+	rank := uint8(bits.LeadingZeros64(rest)) + 1
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// Estimate returns the estimated number of distinct items added to the sketch.
+func (h *HyperLogLog) Estimate() uint64 {
+	var (
+		m     = float64(len(h.registers))
+		sum   float64
+		zeros int
+	)
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	alpha := 0.7213 / (1 + 1.079/m)
+	switch len(h.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	}
+
+	est := alpha * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		// Small-range correction: linear counting.
+		est = m * math.Log(m/float64(zeros))
+	}
+	return uint64(est + 0.5)
+}
+
+// mix64 is the finalizer from MurmurHash3.
+// It spreads the bits of an FNV hash, whose high bits are poorly distributed.
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Cardinalities consumes a sequence of pointer/value pairs as produced by [Values]
+// and estimates the number of distinct scalar values seen at each path
+// (see [Pointer.Path]),
+// using a [HyperLogLog] sketch of the given precision per path.
+// Arrays and objects are not counted.
+//
+// This is useful for telling identifier fields (high cardinality)
+// from enumerations (low cardinality).
+func Cardinalities(values iter.Seq2[Pointer, any], precision uint8) map[string]uint64 {
+	sketches := make(map[string]*HyperLogLog)
+
+	for pointer, val := range values {
+		item, ok := scalarKey(val)
+		if !ok {
+			continue
+		}
+		path := pointer.Path()
+		h := sketches[path]
+		if h == nil {
+			h = NewHyperLogLog(precision)
+			sketches[path] = h
+		}
+		h.Add(item)
+	}
+
+	result := make(map[string]uint64, len(sketches))
+	for path, h := range sketches {
+		result[path] = h.Estimate()
+	}
+	return result
+}
+
+// scalarKey returns a byte representation of a scalar value
+// that distinguishes values of different types.
+// The boolean result is false for arrays and objects.
+func scalarKey(v any) ([]byte, bool) {
+	switch v := v.(type) {
+	case string:
+		return append([]byte{'s'}, v...), true
+	case Number:
+		return append([]byte{'n'}, v.raw...), true
+	case bool:
+		if v {
+			return []byte{'t'}, true
+		}
+		return []byte{'f'}, true
+	case nil, Null:
+		return []byte{'z'}, true
+	case Expanded:
+		return scalarKey(v.Value)
+	}
+	return nil, false
+}
@@ -0,0 +1,58 @@
+package jseq_test
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 100000} {
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			h := jseq.NewHyperLogLog(14)
+			for i := range n {
+				item := []byte(fmt.Sprintf("item-%d", i))
+				h.Add(item)
+				h.Add(item) // duplicates must not count
+			}
+			got := h.Estimate()
+			if diff := math.Abs(float64(got) - float64(n)); diff > 0.03*float64(n) {
+				t.Errorf("got estimate %d, want %d ± 3%%", got, n)
+			}
+		})
+	}
+}
+
+func TestCardinalities(t *testing.T) {
+	var values iter.Seq2[jseq.Pointer, any] = func(yield func(jseq.Pointer, any) bool) {
+		for i := range 5000 {
+			rec := map[string]any{
+				"id":   jseq.Int(int64(i)),
+				"kind": fmt.Sprintf("k%d", i%4),
+			}
+			if !yield(jseq.Pointer{"id"}, rec["id"]) {
+				return
+			}
+			if !yield(jseq.Pointer{"kind"}, rec["kind"]) {
+				return
+			}
+			if !yield(nil, rec) {
+				return
+			}
+		}
+	}
+
+	got := jseq.Cardinalities(values, 12)
+	if len(got) != 2 {
+		t.Fatalf("got %d paths, want 2", len(got))
+	}
+	if n := got["/kind"]; n != 4 {
+		t.Errorf("got %d for /kind, want 4", n)
+	}
+	if n := got["/id"]; n < 4500 || n > 5500 {
+		t.Errorf("got %d for /id, want about 5000", n)
+	}
+}
@@ -0,0 +1,177 @@
+package jseq
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bobg/errors"
+	"github.com/bobg/seqs"
+)
+
+// IncludeLoader opens the document with the given name for [Includes].
+type IncludeLoader func(name string) (io.ReadCloser, error)
+
+// FSLoader is an [IncludeLoader] that opens documents in fsys.
+func FSLoader(fsys fs.FS) IncludeLoader {
+	return func(name string) (io.ReadCloser, error) {
+		return fsys.Open(name)
+	}
+}
+
+// Includes is an [Option] that causes [Values] to process include directives.
+// An include directive is an object whose only member has the key "$include"
+// and a string value naming another JSON document,
+// as in:
+//
+//	{"database": {"$include": "db.json"}}
+//
+// The named document, which must contain a single JSON value, is read using load
+// and is parsed in place of the directive,
+// as if its text appeared there.
+// Its values are produced with pointers relative to the including document's root.
+//
+// Included documents may themselves contain include directives.
+// A name is resolved relative to the directory of the document that includes it
+// (using the [path] package),
+// the top-level input being in the directory ".".
+// An include cycle is an error.
+//
+// If origins is not nil,
+// it is filled in with a record of which document supplied which values.
+func Includes(load IncludeLoader, origins *IncludeOrigins) Option {
+	return func(c *config) {
+		c.include = &includeConfig{load: load, origins: origins}
+	}
+}
+
+type includeConfig struct {
+	load    IncludeLoader
+	origins *IncludeOrigins
+}
+
+// IncludeOrigins records where the values produced under [Includes] came from.
+type IncludeOrigins struct {
+	roots []includeRoot
+}
+
+type includeRoot struct {
+	pointer Pointer
+	name    string
+}
+
+// Source returns the name of the included document that supplied the value at ptr,
+// or "" if it came from the top-level input.
+// It describes the current top-level value
+// (or the last one, after [Values] is done):
+// the record is cleared when each new top-level value begins.
+func (o *IncludeOrigins) Source(ptr Pointer) string {
+	var (
+		best    string
+		bestLen = -1
+	)
+	for _, root := range slices.Backward(o.roots) { // most recent first
+		if len(root.pointer) > bestLen && len(root.pointer) <= len(ptr) && slices.Equal(root.pointer, ptr[:len(root.pointer)]) {
+			best, bestLen = root.name, len(root.pointer)
+		}
+	}
+	return best
+}
+
+// reset clears o for a new top-level value.
+func (o *IncludeOrigins) reset() {
+	clear(o.roots)
+	o.roots = o.roots[:0]
+}
+
+// includeKey is the key of an include directive.
+const includeKey = "$include"
+
+// maybeInclude is called after the opening brace of an object has been consumed.
+// If the object is an include directive,
+// maybeInclude consumes the rest of it,
+// parses the included document,
+// and returns true.
+func (p *parser) maybeInclude(pointer Pointer) (val any, ok, handled bool, err error) {
+	peeked, ok := p.peek()
+	if !ok || peeked.Kind() != '"' || peeked.String() != includeKey {
+		return nil, false, false, nil
+	}
+	p.next() // advance past key
+
+	tok, ok := p.next()
+	if !ok {
+		return nil, false, true, p.endOfInput(pointer, io.ErrUnexpectedEOF)
+	}
+	if tok.Kind() != '"' {
+		return nil, false, true, fmt.Errorf("at %q: got %s for %s, want string", pointer.Text(), tok.Kind(), includeKey)
+	}
+	name := tok.String()
+
+	tok, ok = p.next()
+	if !ok {
+		return nil, false, true, p.endOfInput(pointer, io.ErrUnexpectedEOF)
+	}
+	if tok.Kind() != '}' {
+		return nil, false, true, fmt.Errorf("at %q: %s must be the only member of its object", pointer.Text(), includeKey)
+	}
+
+	val, ok, err = p.includeDoc(pointer, name)
+	return val, ok, true, err
+}
+
+func (p *parser) includeDoc(pointer Pointer, name string) (any, bool, error) {
+	dir := "."
+	if len(p.includeStack) > 0 {
+		dir = path.Dir(p.includeStack[len(p.includeStack)-1])
+	}
+	name = path.Join(dir, name)
+
+	if slices.Contains(p.includeStack, name) {
+		cycle := append(slices.Clone(p.includeStack), name)
+		return nil, false, fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	r, err := p.include.load(name)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "loading %s", name)
+	}
+	defer r.Close()
+
+	if p.include.origins != nil {
+		p.include.origins.roots = append(p.include.origins.roots, includeRoot{pointer: slices.Clone(pointer), name: name})
+	}
+
+	tokens, errptr := Tokens(r)
+	next, peek, stop := seqs.Peeker(tokens)
+	defer stop()
+
+	sub := &parser{
+		config:       p.config,
+		next:         next,
+		peek:         peek,
+		yield:        p.yield,
+		record:       p.record,
+		includeStack: append(slices.Clip(p.includeStack), name),
+	}
+
+	val, ok, err := sub.nextValue(pointer)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	if err == nil && ok {
+		if _, more := next(); more {
+			err = fmt.Errorf("more than one top-level value")
+		}
+	}
+	if err == nil {
+		err = *errptr
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "in %s", name)
+	}
+	return val, ok, nil
+}
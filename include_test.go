@@ -0,0 +1,116 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/bobg/jseq"
+)
+
+func TestIncludes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"db.json":          {Data: []byte(`{"host": "localhost", "creds": {"$include": "secrets/db.json"}}`)},
+		"secrets/db.json":  {Data: []byte(`{"user": "admin"}`)},
+		"cycle/a.json":     {Data: []byte(`[{"$include": "b.json"}]`)},
+		"cycle/b.json":     {Data: []byte(`{"$include": "a.json"}`)},
+		"bad/multi.json":   {Data: []byte(`1 2`)},
+		"bad/members.json": {Data: []byte(`{"$include": "x", "y": 1}`)},
+		"a.json":           {Data: []byte(`{"v": 1}`)},
+		"b.json":           {Data: []byte(`{"v": 2}`)},
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		var origins jseq.IncludeOrigins
+		tokens, errptr1 := jseq.Tokens(strings.NewReader(`{"name": "svc", "database": {"$include": "db.json"}}`))
+		values, errptr2 := jseq.Values(tokens, jseq.Includes(jseq.FSLoader(fsys), &origins))
+
+		var (
+			pointers []string
+			last     any
+		)
+		for ptr, val := range values {
+			pointers = append(pointers, string(ptr.Text()))
+			last = val
+		}
+		if *errptr1 != nil {
+			t.Fatal(*errptr1)
+		}
+		if *errptr2 != nil {
+			t.Fatal(*errptr2)
+		}
+
+		want := map[string]any{
+			"name": "svc",
+			"database": map[string]any{
+				"host":  "localhost",
+				"creds": map[string]any{"user": "admin"},
+			},
+		}
+		if !reflect.DeepEqual(last, want) {
+			t.Errorf("got %v, want %v", last, want)
+		}
+
+		wantPointers := []string{"/name", "/database/host", "/database/creds/user", "/database/creds", "/database", ""}
+		if !reflect.DeepEqual(pointers, wantPointers) {
+			t.Errorf("got pointers %v, want %v", pointers, wantPointers)
+		}
+
+		for _, tc := range []struct {
+			ptr  jseq.Pointer
+			want string
+		}{
+			{ptr: jseq.Pointer{"name"}, want: ""},
+			{ptr: jseq.Pointer{"database", "host"}, want: "db.json"},
+			{ptr: jseq.Pointer{"database", "creds", "user"}, want: "secrets/db.json"},
+		} {
+			if got := origins.Source(tc.ptr); got != tc.want {
+				t.Errorf("Source(%v) = %q, want %q", tc.ptr, got, tc.want)
+			}
+		}
+	})
+
+	t.Run("records", func(t *testing.T) {
+		// Each record's origins are reported while it is being parsed,
+		// and don't linger into the next record.
+		var origins jseq.IncludeOrigins
+		tokens, errptr1 := jseq.Tokens(strings.NewReader(`{"cfg": {"$include": "a.json"}} {"cfg": {"$include": "b.json"}} {"cfg": {"v": 3}}`))
+		values, errptr2 := jseq.Values(tokens, jseq.Includes(jseq.FSLoader(fsys), &origins))
+
+		var got []string
+		for ptr := range values {
+			if len(ptr) == 0 {
+				got = append(got, origins.Source(jseq.Pointer{"cfg", "v"}))
+			}
+		}
+		if *errptr1 != nil {
+			t.Fatal(*errptr1)
+		}
+		if *errptr2 != nil {
+			t.Fatal(*errptr2)
+		}
+		if want := []string{"a.json", "b.json", ""}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	for _, tc := range []struct {
+		name, inp, wantErr string
+	}{
+		{name: "cycle", inp: `{"$include": "cycle/a.json"}`, wantErr: "include cycle: cycle/a.json -> cycle/b.json -> cycle/a.json"},
+		{name: "multi", inp: `{"$include": "bad/multi.json"}`, wantErr: "more than one top-level value"},
+		{name: "members", inp: `{"$include": "bad/members.json"}`, wantErr: "must be the only member"},
+		{name: "missing", inp: `{"$include": "nope.json"}`, wantErr: "loading nope.json"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(tc.inp))
+			values, errptr := jseq.Values(tokens, jseq.Includes(jseq.FSLoader(fsys), nil))
+			for range values {
+			}
+			if *errptr == nil || !strings.Contains((*errptr).Error(), tc.wantErr) {
+				t.Errorf("got error %v, want one containing %q", *errptr, tc.wantErr)
+			}
+		})
+	}
+}
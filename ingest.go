@@ -0,0 +1,263 @@
+package jseq
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"github.com/bobg/errors"
+)
+
+// Tagged is a record (top-level value) from one of several sources.
+// See [Ingest].
+type Tagged struct {
+	// Source is the index of the record's source.
+	Source int
+
+	// Record is the ordinal of the record within its source,
+	// counting from zero.
+	Record int
+
+	Value any
+}
+
+// IngestOrder is the order in which [Ingest] produces records.
+type IngestOrder int
+
+const (
+	// ArrivalOrder produces records as soon as they are parsed,
+	// interleaving the sources unpredictably.
+	// This is the fastest order.
+	ArrivalOrder IngestOrder = iota
+
+	// SourceOrder produces all the records of each source in turn,
+	// as if the sources were concatenated,
+	// while still parsing ahead in the later sources.
+	SourceOrder
+
+	// MergeOrder produces records in the order defined by [IngestOptions.Compare],
+	// merging the sources,
+	// each of which must already be in that order.
+	// All the sources are open at once, regardless of [IngestOptions.Concurrency].
+	MergeOrder
+)
+
+// IngestOptions controls the behavior of [Ingest].
+// The zero value is a usable default.
+type IngestOptions struct {
+	Order IngestOrder
+
+	// Compare is required for [MergeOrder].
+	// It returns a negative number, zero, or a positive number
+	// as a is less than, equal to, or greater than b.
+	// Ties are broken by source index.
+	Compare func(a, b Tagged) int
+
+	// Concurrency is the number of sources parsed at once.
+	// The default is all of them.
+	Concurrency int
+
+	// Buffer is the number of records that may be parsed ahead
+	// in each source.
+	// The default is 16.
+	Buffer int
+}
+
+// Ingest parses the records (top-level values) in all the given sources concurrently,
+// each with its own parser created by [Values] with the given options,
+// and merges them into a single sequence in the order given by iopts.
+// Each record is tagged with its source.
+// This is useful for bulk loading of many files or objects,
+// keeping both the network and the CPU busy.
+//
+// The first error from any source
+// stops the sequence and is placed in the returned error pointer.
+// The sequence also stops when ctx is canceled.
+func Ingest(ctx context.Context, sources []Source, iopts IngestOptions, opts ...Option) (iter.Seq[Tagged], *error) {
+	if iopts.Concurrency <= 0 || iopts.Order == MergeOrder {
+		iopts.Concurrency = len(sources)
+	}
+	if iopts.Buffer <= 0 {
+		iopts.Buffer = 16
+	}
+
+	var err error
+
+	f := func(yield func(Tagged) bool) {
+		if iopts.Order == MergeOrder && iopts.Compare == nil {
+			err = errors.New("merge order requires a Compare function")
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		in := &ingester{
+			ctx:     ctx,
+			cancel:  cancel,
+			sources: sources,
+			iopts:   iopts,
+			opts:    opts,
+		}
+		in.start()
+		defer in.wg.Wait()
+		defer cancel()
+
+		switch iopts.Order {
+		case ArrivalOrder:
+			for rec := range in.out {
+				if !yield(rec) {
+					return
+				}
+			}
+
+		case SourceOrder:
+			for _, ch := range in.chans {
+				for rec := range ch {
+					if !yield(rec) {
+						return
+					}
+				}
+			}
+
+		case MergeOrder:
+			heads := make([]*Tagged, len(in.chans))
+			for {
+				var best *Tagged
+				for i, ch := range in.chans {
+					if heads[i] == nil {
+						if rec, ok := <-ch; ok {
+							heads[i] = &rec
+						} else {
+							continue
+						}
+					}
+					if best == nil || iopts.Compare(*heads[i], *best) < 0 {
+						best = heads[i]
+					}
+				}
+				if best == nil {
+					break
+				}
+				heads[best.Source] = nil
+				if !yield(*best) {
+					return
+				}
+			}
+		}
+
+		in.wg.Wait()
+		in.mu.Lock()
+		err = in.err
+		in.mu.Unlock()
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	return f, &err
+}
+
+type ingester struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	sources []Source
+	iopts   IngestOptions
+	opts    []Option
+
+	out   chan Tagged   // for ArrivalOrder
+	chans []chan Tagged // for the other orders, one per source
+	wg    sync.WaitGroup
+
+	mu  sync.Mutex
+	err error // the first error
+}
+
+func (in *ingester) start() {
+	if in.iopts.Order == ArrivalOrder {
+		in.out = make(chan Tagged, in.iopts.Buffer)
+	} else {
+		in.chans = make([]chan Tagged, len(in.sources))
+		for i := range in.chans {
+			in.chans[i] = make(chan Tagged, in.iopts.Buffer)
+		}
+	}
+
+	// Sources start in order,
+	// so that with limited concurrency,
+	// SourceOrder never waits on a source that has not started.
+	sem := make(chan struct{}, in.iopts.Concurrency)
+
+	var workers sync.WaitGroup
+	in.wg.Add(1)
+	go func() {
+		defer in.wg.Done()
+		defer func() {
+			if in.out != nil {
+				workers.Wait()
+				close(in.out)
+			}
+		}()
+
+		for i, src := range in.sources {
+			dest := in.out
+			if in.chans != nil {
+				dest = in.chans[i]
+			}
+
+			select {
+			case <-in.ctx.Done():
+				if in.chans != nil {
+					for _, ch := range in.chans[i:] {
+						close(ch)
+					}
+				}
+				return
+			case sem <- struct{}{}:
+			}
+
+			workers.Add(1)
+			in.wg.Add(1)
+			go func() {
+				defer in.wg.Done()
+				defer workers.Done()
+				defer func() { <-sem }()
+				if in.chans != nil {
+					defer close(dest)
+				}
+
+				if err := in.parse(i, src, dest); err != nil {
+					in.fail(errors.Wrapf(err, "in source %d", i))
+				}
+			}()
+		}
+	}()
+}
+
+func (in *ingester) parse(i int, src Source, dest chan<- Tagged) error {
+	tokens, errptr1 := src.Tokens()
+	values, errptr2 := Values(tokens, in.opts...)
+
+	var n int
+	for pointer, val := range values {
+		if len(pointer) > 0 {
+			continue
+		}
+		select {
+		case <-in.ctx.Done():
+			return nil
+		case dest <- Tagged{Source: i, Record: n, Value: val}:
+		}
+		n++
+	}
+	return errors.Join(*errptr1, *errptr2)
+}
+
+func (in *ingester) fail(err error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.err == nil {
+		in.err = err
+		in.cancel()
+	}
+}
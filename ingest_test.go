@@ -0,0 +1,147 @@
+package jseq_test
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestIngest(t *testing.T) {
+	inputs := []string{
+		`{"t": 1} {"t": 4} {"t": 7}`,
+		`{"t": 2} {"t": 3}`,
+		``,
+		`{"t": 5} {"t": 6} {"t": 8} {"t": 9}`,
+	}
+
+	sources := func() []jseq.Source {
+		var result []jseq.Source
+		for _, inp := range inputs {
+			result = append(result, jseq.ReaderSource(strings.NewReader(inp)))
+		}
+		return result
+	}
+
+	type rec struct {
+		source, record int
+		t              string
+	}
+	collect := func(iopts jseq.IngestOptions) []rec {
+		t.Helper()
+		seq, errptr := jseq.Ingest(context.Background(), sources(), iopts)
+		var result []rec
+		for tagged := range seq {
+			n := tagged.Value.(map[string]any)["t"].(jseq.Number)
+			result = append(result, rec{source: tagged.Source, record: tagged.Record, t: n.String()})
+		}
+		if err := *errptr; err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	sourceOrder := []rec{
+		{0, 0, "1"}, {0, 1, "4"}, {0, 2, "7"},
+		{1, 0, "2"}, {1, 1, "3"},
+		{3, 0, "5"}, {3, 1, "6"}, {3, 2, "8"}, {3, 3, "9"},
+	}
+
+	for _, conc := range []int{0, 1, 2} {
+		got := collect(jseq.IngestOptions{Order: jseq.SourceOrder, Concurrency: conc, Buffer: 1})
+		if !reflect.DeepEqual(got, sourceOrder) {
+			t.Errorf("source order, concurrency %d: got %v, want %v", conc, got, sourceOrder)
+		}
+
+		got = collect(jseq.IngestOptions{Concurrency: conc})
+		slices.SortFunc(got, func(a, b rec) int {
+			return cmp.Or(cmp.Compare(a.source, b.source), cmp.Compare(a.record, b.record))
+		})
+		if !reflect.DeepEqual(got, sourceOrder) {
+			t.Errorf("arrival order, concurrency %d: got %v, want %v (after sorting)", conc, got, sourceOrder)
+		}
+	}
+
+	got := collect(jseq.IngestOptions{
+		Order: jseq.MergeOrder,
+		Compare: func(a, b jseq.Tagged) int {
+			at, _ := a.Value.(map[string]any)["t"].(jseq.Number).Int()
+			bt, _ := b.Value.(map[string]any)["t"].(jseq.Number).Int()
+			return cmp.Compare(at, bt)
+		},
+	})
+	var ts []string
+	for _, r := range got {
+		ts = append(ts, r.t)
+	}
+	if want := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}; !reflect.DeepEqual(ts, want) {
+		t.Errorf("merge order: got %v, want %v", ts, want)
+	}
+}
+
+func TestIngestErrors(t *testing.T) {
+	sources := []jseq.Source{
+		jseq.ReaderSource(strings.NewReader(`1 2 3`)),
+		jseq.ReaderSource(strings.NewReader(`4 {`)),
+	}
+	seq, errptr := jseq.Ingest(context.Background(), sources, jseq.IngestOptions{Order: jseq.SourceOrder})
+	for range seq {
+	}
+	if *errptr == nil {
+		t.Error("got no error, want one")
+	}
+
+	seq, errptr = jseq.Ingest(context.Background(), sources, jseq.IngestOptions{Order: jseq.MergeOrder})
+	for range seq {
+		t.Error("got a record, want none")
+	}
+	if *errptr == nil {
+		t.Error("got no error for merge order without Compare")
+	}
+
+	// Stopping early.
+	sources = []jseq.Source{
+		jseq.ReaderSource(strings.NewReader(strings.Repeat("1 ", 1000))),
+		jseq.ReaderSource(strings.NewReader(strings.Repeat("2 ", 1000))),
+	}
+	seq, errptr = jseq.Ingest(context.Background(), sources, jseq.IngestOptions{Buffer: 1})
+	var n int
+	for range seq {
+		n++
+		if n == 10 {
+			break
+		}
+	}
+	if err := *errptr; err != nil && !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v after stopping early", err)
+	}
+}
+
+func TestIngestStopEarlyLimited(t *testing.T) {
+	// Stopping early while later sources wait for a turn.
+	orders := []jseq.IngestOrder{jseq.ArrivalOrder, jseq.SourceOrder, jseq.MergeOrder}
+	for _, order := range orders {
+		var sources []jseq.Source
+		for range 3 {
+			sources = append(sources, jseq.ReaderSource(strings.NewReader(strings.Repeat("1 ", 100))))
+		}
+		iopts := jseq.IngestOptions{
+			Order:       order,
+			Concurrency: 1,
+			Buffer:      1,
+			Compare:     func(a, b jseq.Tagged) int { return 0 },
+		}
+		seq, errptr := jseq.Ingest(context.Background(), sources, iopts)
+		for range seq {
+			break
+		}
+		if err := *errptr; err != nil && !errors.Is(err, context.Canceled) {
+			t.Errorf("order %d: got error %v after stopping early", order, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package jseq
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bobg/errors"
+)
+
+// Job is a one-shot conversion job:
+// it reads from a source,
+// applies transforms,
+// and writes the result to an output file,
+// which appears only if the job succeeds.
+// Run it with [Job.Run].
+type Job struct {
+	Source     Source
+	Options    []Option // for [Values]
+	Transforms []Transform
+
+	// Output is the path of the output file.
+	Output string
+
+	// NewSink creates the sink that writes the output.
+	// The default writes NDJSON (see [FormatSink]).
+	NewSink func(io.Writer) Sink
+
+	// DiscardPartial causes the output of a failed job to be removed
+	// instead of preserved.
+	DiscardPartial bool
+
+	// Perm is the permission of the output file
+	// (and of the partial output of a failed job).
+	// The default is 0644.
+	// Unlike the permission of a newly created file,
+	// it is not reduced by the process's umask.
+	Perm os.FileMode
+}
+
+// JobResult describes the outcome of [Job.Run].
+type JobResult struct {
+	// Path is the path of the output:
+	// the job's Output on success,
+	// the partial output on failure
+	// (or empty if it was discarded).
+	Path string
+
+	// Manifest is the path of the manifest describing a failed job,
+	// or empty.
+	Manifest string
+
+	// Records is the number of top-level values written.
+	Records int
+
+	// Bytes is the size of the output.
+	Bytes int64
+
+	Duration time.Duration
+}
+
+// Run runs j.
+// It pipes j.Source through j.Transforms (see [Pipe])
+// into a temporary file in the same directory as j.Output,
+// and on success syncs it and atomically renames it to j.Output.
+// Readers of j.Output therefore never see incomplete output.
+//
+// On failure,
+// unless j.DiscardPartial is true,
+// the temporary file is renamed to j.Output plus ".partial",
+// and a JSON manifest describing the failure is written alongside it,
+// at j.Output plus ".partial.json".
+// The manifest has the members output, partial, records, bytes, duration (in seconds), and error.
+// Any previous file at j.Output is left alone.
+func (j Job) Run(ctx context.Context) (JobResult, error) {
+	start := time.Now()
+
+	f, err := os.CreateTemp(filepath.Dir(j.Output), "."+filepath.Base(j.Output)+".*.tmp")
+	if err != nil {
+		return JobResult{}, errors.Wrap(err, "creating temporary output")
+	}
+
+	// CreateTemp makes the file private (0600).
+	perm := j.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return JobResult{}, errors.Wrap(err, "setting output permission")
+	}
+
+	newSink := j.NewSink
+	if newSink == nil {
+		newSink = func(w io.Writer) Sink { return FormatSink(w, NDJSON) }
+	}
+
+	var (
+		cw     = &countingWriter{w: f}
+		result JobResult
+		sink   = newSink(cw)
+	)
+	counter := &recordCounter{Sink: sink, n: &result.Records}
+
+	err = Pipe(ctx, j.Source, counter, j.Options, j.Transforms...)
+	if err == nil {
+		err = f.Sync()
+	}
+	err = errors.Join(err, f.Close())
+	result.Bytes, result.Duration = cw.n, time.Since(start)
+
+	if err == nil {
+		if err = os.Rename(f.Name(), j.Output); err == nil {
+			err = syncDir(j.Output)
+		}
+		if err == nil {
+			result.Path = j.Output
+			return result, nil
+		}
+	}
+
+	return j.fail(f.Name(), result, err)
+}
+
+// fail disposes of the partial output in tmp after a failed job.
+func (j Job) fail(tmp string, result JobResult, jobErr error) (JobResult, error) {
+	if j.DiscardPartial {
+		return result, errors.Join(jobErr, os.Remove(tmp))
+	}
+
+	partial := j.Output + ".partial"
+	if err := os.Rename(tmp, partial); err != nil {
+		return result, errors.Join(jobErr, errors.Wrap(err, "preserving partial output"))
+	}
+	result.Path = partial
+
+	manifest := j.Output + ".partial.json"
+	mf, err := os.Create(manifest)
+	if err != nil {
+		return result, errors.Join(jobErr, errors.Wrap(err, "creating manifest"))
+	}
+	err = Encode(mf, map[string]any{
+		"output":   j.Output,
+		"partial":  partial,
+		"records":  Int(int64(result.Records)),
+		"bytes":    Int(result.Bytes),
+		"duration": Float(result.Duration.Seconds()),
+		"error":    jobErr.Error(),
+	})
+	if err = errors.Join(err, mf.Close()); err != nil {
+		return result, errors.Join(jobErr, errors.Wrap(err, "writing manifest"))
+	}
+	result.Manifest = manifest
+
+	return result, jobErr
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// recordCounter is a [Sink] that counts the top-level values it passes along.
+type recordCounter struct {
+	Sink
+	n *int
+}
+
+func (c *recordCounter) Consume(pointer Pointer, val any) error {
+	if err := c.Sink.Consume(pointer, val); err != nil {
+		return err
+	}
+	if len(pointer) == 0 {
+		*c.n++
+	}
+	return nil
+}
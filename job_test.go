@@ -0,0 +1,131 @@
+package jseq_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestJob(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.ndjson")
+
+	job := jseq.Job{
+		Source: jseq.ReaderSource(strings.NewReader(`{"a": 1} {"a": 2}`)),
+		Output: out,
+	}
+	res, err := job.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Path != out || res.Records != 2 || res.Bytes != 16 || res.Manifest != "" {
+		t.Errorf("got result %+v", res)
+	}
+	if got, want := readFile(t, out), "{\"a\":1}\n{\"a\":2}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("got %d files in output dir, want 1", len(entries))
+	}
+}
+
+func TestJobPerm(t *testing.T) {
+	cases := []struct {
+		perm, want os.FileMode
+	}{
+		{perm: 0, want: 0644},
+		{perm: 0640, want: 0640},
+	}
+	for _, tc := range cases {
+		out := filepath.Join(t.TempDir(), "out.ndjson")
+		job := jseq.Job{
+			Source: jseq.ReaderSource(strings.NewReader(`{"a": 1}`)),
+			Output: out,
+			Perm:   tc.perm,
+		}
+		if _, err := job.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != tc.want {
+			t.Errorf("with Perm %#o, got mode %#o, want %#o", tc.perm, got, tc.want)
+		}
+	}
+}
+
+func TestJobFailure(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	for _, discard := range []bool{false, true} {
+		dir := t.TempDir()
+		out := filepath.Join(dir, "out.ndjson")
+
+		// A previous output survives a failed job.
+		if err := os.WriteFile(out, []byte("old\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		job := jseq.Job{
+			Source: jseq.ReaderSource(strings.NewReader(`1 2 3`)),
+			Output: out,
+			NewSink: func(w io.Writer) jseq.Sink {
+				sink := jseq.FormatSink(w, jseq.NDJSON)
+				return jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+					if n, _ := val.(jseq.Number).Int(); n == 3 {
+						return errBoom
+					}
+					return sink.Consume(pointer, val)
+				})
+			},
+			DiscardPartial: discard,
+		}
+		res, err := job.Run(context.Background())
+		if !errors.Is(err, errBoom) {
+			t.Fatalf("got error %v, want %v", err, errBoom)
+		}
+		if got := readFile(t, out); got != "old\n" {
+			t.Errorf("previous output changed to %q", got)
+		}
+
+		if discard {
+			if res.Path != "" || res.Manifest != "" {
+				t.Errorf("got result %+v, want no paths", res)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+				t.Errorf("got %d files in output dir, want 1", len(entries))
+			}
+			continue
+		}
+
+		if res.Path != out+".partial" || res.Manifest != out+".partial.json" || res.Records != 2 {
+			t.Errorf("got result %+v", res)
+		}
+		if got := readFile(t, res.Path); got != "1\n2\n" {
+			t.Errorf("got partial output %q, want %q", got, "1\n2\n")
+		}
+
+		f, err := os.Open(res.Manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		recs := records(t, jseq.ReaderSource(f))
+		if len(recs) != 1 {
+			t.Fatalf("got %d manifest records, want 1", len(recs))
+		}
+		m := recs[0].(map[string]any)
+		if m["error"] != "boom" || m["partial"] != res.Path || !reflect.DeepEqual(m["records"], jseq.Int(2)) {
+			t.Errorf("got manifest %v", m)
+		}
+	}
+}
@@ -8,12 +8,15 @@
 package jseq
 
 import (
+	"bytes"
 	"encoding/json/jsontext"
 	"fmt"
 	"io"
 	"iter"
 	"math"
+	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/bobg/errors"
 	"github.com/bobg/seqs"
@@ -26,15 +29,11 @@ import (
 // the caller may check for errors by dereferencing the returned error pointer.
 func Tokens(r io.Reader, opts ...jsontext.Options) (iter.Seq[jsontext.Token], *error) {
 	var (
-		dec      = jsontext.NewDecoder(r, opts...)
+		tokens   = TokensE(r, opts...)
 		outerErr error
 	)
 	f := func(yield func(jsontext.Token) bool) {
-		for {
-			tok, err := dec.ReadToken()
-			if errors.Is(err, io.EOF) {
-				return
-			}
+		for tok, err := range tokens {
 			if err != nil {
 				outerErr = err
 				return
@@ -47,6 +46,72 @@ func Tokens(r io.Reader, opts ...jsontext.Options) (iter.Seq[jsontext.Token], *e
 	return f, &outerErr
 }
 
+// TokensE parses JSON tokens from r and returns them as an [iter.Seq2],
+// pairing each token with a nil error.
+// When an error occurs,
+// it is produced inline, paired with a zero token,
+// so that token-level consumers can decide for themselves
+// whether to continue.
+//
+// After a syntax error,
+// if the consumer continues,
+// tokens resume at the start of the next line of input,
+// as at the start of a new top-level value
+// (as with [Records]).
+// This suits newline-delimited JSON,
+// in which one bad line should not prevent reading the rest.
+// Other errors, such as failures reading r, end the sequence.
+func TokensE(r io.Reader, opts ...jsontext.Options) iter.Seq2[jsontext.Token, error] {
+	return func(yield func(jsontext.Token, error) bool) {
+		var (
+			rc              = &recoverer{r: r}
+			src   io.Reader = rc
+			start int64
+		)
+		for {
+			dec := jsontext.NewDecoder(src, opts...)
+			for {
+				if len(rc.buf) > tokensKeep {
+					// Bytes before the decoder's position are not needed for resyncing.
+					rc.trim(start + dec.InputOffset())
+				}
+				tok, err := dec.ReadToken()
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if err == nil {
+					if !yield(tok, nil) {
+						return
+					}
+					continue
+				}
+
+				if !yield(jsontext.Token{}, err) {
+					return
+				}
+				var serr *jsontext.SyntacticError
+				if !errors.As(err, &serr) {
+					return
+				}
+				_, rest, more, rerr := rc.resync(start + serr.ByteOffset)
+				if rerr != nil {
+					yield(jsontext.Token{}, rerr)
+					return
+				}
+				if !more {
+					return
+				}
+				src, start = io.MultiReader(bytes.NewReader(rest), rc), rc.base
+				break
+			}
+		}
+	}
+}
+
+// tokensKeep is the number of buffered bytes
+// beyond which [TokensE] discards those it no longer needs.
+const tokensKeep = 64 * 1024
+
 // Values consumes a sequence of JSON tokens and produces a sequence of JSON values,
 // each paired with the [Pointer] that can locate it within its top-level object.
 //
@@ -68,12 +133,14 @@ func Tokens(r io.Reader, opts ...jsontext.Options) (iter.Seq[jsontext.Token], *e
 //	"/world"    [3, 4]
 //	""          {"hello": [1, 2], "world": [3, 4]}
 //
+// (The [PreOrder] option produces arrays and objects before their children instead.)
+//
 // Note that object keys are not considered values to be separately emitted.
 //
 // Value types in the resulting sequence are:
 //
 //   - []any for arrays
-//   - map[string]any for objects
+//   - map[string]any for objects ([*Object] with the [OrderedObjects] option)
 //   - strings for strings
 //   - boolean for booleans
 //   - [Null] for null
@@ -84,23 +151,142 @@ func Tokens(r io.Reader, opts ...jsontext.Options) (iter.Seq[jsontext.Token], *e
 // If the input ends in the middle of a JSON value,
 // Values produces an [io.ErrUnexpectedEOF] error.
 //
+// The [Pointer] produced with each value may share storage
+// with pointers produced later.
+// A caller that retains pointers beyond the iteration that produced them
+// must copy them (e.g. with [slices.Clone])
+// or use the [ClonePointers] option.
+//
+// Options may be supplied to alter the behavior of Values.
+// See [Option].
+//
 // After consuming the resulting sequence,
 // the caller may check for errors by dereferencing the returned error pointer.
-func Values(tokens iter.Seq[jsontext.Token]) (iter.Seq2[Pointer, any], *error) {
+// Only fatal errors are reported that way;
+// non-fatal conditions are reported as warnings (see [OnWarning]).
+func Values(tokens iter.Seq[jsontext.Token], opts ...Option) (iter.Seq2[Pointer, any], *error) {
 	var err error
 
 	f := func(yield func(Pointer, any) bool) {
+		p := newParser(nil, nil, yield, opts)
+
+		tokens := tokens
+		if p.recordTimeout > 0 || p.heartbeat > 0 || p.ctx != nil {
+			var stop func()
+			tokens, stop = p.asyncTokens(tokens)
+			defer stop()
+		}
+
 		next, peek, stop := seqs.Peeker(tokens)
 		defer stop()
 
-		err = values(next, peek, yield)
+		p.next, p.peek = next, peek
+		err = p.values()
 	}
 	return f, &err
 }
 
-func values(next, peek func() (jsontext.Token, bool), yield func(Pointer, any) bool) error {
-	for {
-		_, ok, err := nextValue(next, peek, nil, yield)
+// Pair is a pointer/value pair as produced by [Values].
+type Pair struct {
+	Pointer Pointer
+	Value   any
+}
+
+// ValuesE is like [Values],
+// but it consumes a sequence of tokens paired with errors,
+// as from [TokensE],
+// and produces pairs along with errors in the same way,
+// instead of through an error pointer that is easy to forget to check.
+// Each [Pair] is accompanied by a nil error.
+// An error from the tokens or from parsing
+// is produced at the end of the sequence,
+// accompanied by a zero Pair.
+func ValuesE(tokens iter.Seq2[jsontext.Token, error], opts ...Option) iter.Seq2[Pair, error] {
+	return func(yield func(Pair, error) bool) {
+		var tokErr error
+		toks := func(yield func(jsontext.Token) bool) {
+			for tok, err := range tokens {
+				if err != nil {
+					tokErr = err
+					return
+				}
+				if !yield(tok) {
+					return
+				}
+			}
+		}
+
+		values, errptr := Values(toks, opts...)
+		for pointer, val := range values {
+			if !yield(Pair{Pointer: pointer, Value: val}, nil) {
+				return
+			}
+		}
+		if err := errors.Join(tokErr, *errptr); err != nil {
+			yield(Pair{}, err)
+		}
+	}
+}
+
+type parser struct {
+	config
+
+	next, peek func() (jsontext.Token, bool)
+	yield      func(Pointer, any) bool
+
+	record       int      // ordinal of the top-level value being parsed
+	includeStack []string // names of the documents being included (see Includes)
+	timer        *time.Timer
+	timerArmed   bool  // whether timer is running for the current record
+	timed        bool  // whether the record timeout has expired
+	canceled     bool  // whether the context from ValuesContext is done
+	held         int64 // approximate bytes held in the record being built (see MemoryBudget)
+}
+
+func newParser(next, peek func() (jsontext.Token, bool), yield func(Pointer, any) bool, opts []Option) *parser {
+	p := &parser{next: next, peek: peek, yield: yield}
+	for _, opt := range opts {
+		opt(&p.config)
+	}
+	if p.clonePointers {
+		p.yield = clonePointersYield(p.yield)
+	}
+	if p.leafOnly {
+		p.yield = leafYield(p.yield)
+	}
+	if p.summary != nil {
+		*p.summary = Summary{}
+		p.yield = p.countingYield(p.yield)
+	}
+	if p.onPresence != nil {
+		p.yield = p.presenceYield(p.yield)
+	}
+	return p
+}
+
+func (p *parser) values() (err error) {
+	if p.summary != nil {
+		start := time.Now()
+		defer func() {
+			p.summary.Duration = time.Since(start)
+			p.summary.Err = err
+		}()
+	}
+
+	for ; ; p.record++ {
+		if p.timer != nil {
+			// The clock restarts when the record's first token arrives,
+			// so that idle time between records does not count.
+			p.timer.Stop()
+			p.timerArmed = false
+		}
+		if p.include != nil && p.include.origins != nil {
+			if _, ok := p.peek(); ok { // keep the last record's origins at the end of the input
+				p.include.origins.reset()
+			}
+		}
+		p.held = 0
+		_, ok, err := p.nextValue(nil)
 		if errors.Is(err, io.EOF) {
 			return nil
 		}
@@ -113,53 +299,112 @@ func values(next, peek func() (jsontext.Token, bool), yield func(Pointer, any) b
 	}
 }
 
-func nextValue(next, peek func() (jsontext.Token, bool), pointer Pointer, yield func(Pointer, any) bool) (any, bool, error) {
-	token, ok := next()
+func (p *parser) nextValue(pointer Pointer) (any, bool, error) {
+	token, ok := p.next()
 	if !ok {
-		return nil, false, io.EOF
+		return nil, false, p.endOfInput(pointer, io.EOF)
 	}
 
 	kind := token.Kind()
 	switch kind {
 	case 'n':
-		ok := yield(pointer, Null{})
-		return Null{}, ok, nil
+		null := p.null()
+		ok := p.yield(pointer, null)
+		return null, ok, nil
 
 	case 'f':
-		ok := yield(pointer, false)
+		ok := p.yield(pointer, false)
 		return false, ok, nil
 
 	case 't':
-		ok := yield(pointer, true)
+		ok := p.yield(pointer, true)
 		return true, ok, nil
 
 	case '"':
-		s := token.String()
-		ok := yield(pointer, s)
+		s, err := p.expandVars(pointer, token.String())
+		if err != nil {
+			return nil, false, err
+		}
+		if p.shouldExpand(pointer, s) {
+			return p.expand(pointer, s)
+		}
+		ok := p.yield(pointer, s)
 		return s, ok, nil
 
 	case '0':
-		num := NewNumber(token)
-		ok := yield(pointer, num)
+		num, err := p.number(token)
+		if err != nil {
+			return nil, false, err
+		}
+		ok := p.yield(pointer, num)
 		return num, ok, nil
 
 	case '{':
-		result := make(map[string]any)
+		if err := p.checkDepth(pointer); err != nil {
+			return nil, false, err
+		}
+		if p.include != nil {
+			if val, ok, handled, err := p.maybeInclude(pointer); handled {
+				return val, ok, err
+			}
+		}
+		if p.preOrder && !p.yield(pointer, Container{Kind: '{', Len: -1}) {
+			return nil, false, nil
+		}
+		var (
+			result  = make(map[string]any)
+			seen    map[string]struct{} // keys seen, for the duplicate-key policy
+			order   []string            // keys in order, for OrderedObjects
+			builder = p.objectBuilder(pointer)
+			n       int
+		)
 		for {
-			peeked, ok := peek()
+			peeked, ok := p.peek()
 			if !ok {
-				return nil, false, io.ErrUnexpectedEOF
+				return nil, false, p.endOfInput(pointer, io.ErrUnexpectedEOF)
 			}
 			switch peeked.Kind() {
 			case '}':
-				next() // advance past close-brace
-				ok := yield(pointer, result)
-				return result, ok, nil
+				p.next() // advance past close-brace
+				if !p.materialize() {
+					return p.endContainer(pointer, '{', n)
+				}
+				if builder != nil {
+					val, err := builder.Build()
+					if err != nil {
+						return nil, false, errors.Wrap(err, "building object")
+					}
+					ok := p.yield(pointer, val)
+					return val, ok, nil
+				}
+				if a, ok := p.sparseArray(pointer, result); ok {
+					a := p.share(a)
+					ok := p.yield(pointer, a)
+					return a, ok, nil
+				}
+				val, err := p.convertKeys(pointer, result)
+				if err != nil {
+					return nil, false, err
+				}
+				if m, ok := val.(map[string]any); ok && p.orderedObjects {
+					val = &Object{keys: order, m: m}
+				}
+				val = p.share(val)
+				ok := p.yield(pointer, val)
+				return val, ok, nil
 
 			case '"':
-				next() // advance past key
-				key := peeked.String()
-				val, ok, err := nextValue(next, peek, append(pointer, key), yield)
+				p.next() // advance past key
+				orig := peeked.String()
+				key := p.canonicalKey(orig)
+				skipped, err := p.checkDuplicate(&seen, pointer, key)
+				if err != nil {
+					return nil, false, err
+				}
+				if skipped {
+					continue
+				}
+				val, ok, err := p.nextValue(append(pointer, key))
 				if errors.Is(err, io.EOF) {
 					err = io.ErrUnexpectedEOF
 				}
@@ -169,6 +414,30 @@ func nextValue(next, peek func() (jsontext.Token, bool), pointer Pointer, yield
 				if !ok {
 					return nil, false, nil
 				}
+				if !p.materialize() {
+					n++
+					continue
+				}
+				if _, dup := result[key]; dup {
+					if key == orig {
+						p.warn(SeverityWarning, append(pointer, key), "duplicate key %q; the later value wins", key)
+					} else {
+						p.warn(SeverityWarning, append(pointer, key), "key %q duplicates canonical key %q; the later value wins", orig, key)
+					}
+				}
+				if err := p.hold(append(pointer, key), key, val); err != nil {
+					return nil, false, err
+				}
+				if builder != nil {
+					if err := builder.Add(key, val); err != nil {
+						return nil, false, errors.Wrapf(err, "adding object key %q", key)
+					}
+					result[key] = nil // remember the key, for duplicate warnings
+					continue
+				}
+				if _, dup := result[key]; !dup && p.orderedObjects {
+					order = append(order, key)
+				}
 				result[key] = val
 
 			default:
@@ -180,28 +449,79 @@ func nextValue(next, peek func() (jsontext.Token, bool), pointer Pointer, yield
 		return nil, false, fmt.Errorf("unexpected close brace: stack empty")
 
 	case '[':
-		var result []any
-		for {
-			peeked, ok := peek()
+		if err := p.checkDepth(pointer); err != nil {
+			return nil, false, err
+		}
+		if p.preOrder && !p.yield(pointer, Container{Kind: '[', Len: -1}) {
+			return nil, false, nil
+		}
+		var (
+			result  []any
+			builder = p.arrayBuilder(pointer)
+			n       int
+			base    int
+		)
+		if p.arena != nil {
+			base = p.arena.mark()
+			defer p.arena.truncate(base) // in case of early return
+		}
+		for ; ; n++ {
+			peeked, ok := p.peek()
 			if !ok {
-				return nil, false, io.ErrUnexpectedEOF
+				return nil, false, p.endOfInput(pointer, io.ErrUnexpectedEOF)
 			}
 			if peeked.Kind() == ']' {
-				next() // advance past close-bracket
-				ok := yield(pointer, result)
-				return result, ok, nil
+				p.next() // advance past close-bracket
+				if !p.materialize() {
+					return p.endContainer(pointer, '[', n)
+				}
+				if builder != nil {
+					val, err := builder.Build()
+					if err != nil {
+						return nil, false, errors.Wrap(err, "building array")
+					}
+					ok := p.yield(pointer, val)
+					return val, ok, nil
+				}
+				if p.arena != nil {
+					result = p.arena.elements(base)
+				}
+				if m, ok := p.arrayAsObject(pointer, result); ok {
+					m := p.share(m)
+					ok := p.yield(pointer, m)
+					return m, ok, nil
+				}
+				val := p.share(result)
+				ok := p.yield(pointer, val)
+				return val, ok, nil
 			}
-			val, ok, err := nextValue(next, peek, append(pointer, len(result)), yield)
+			val, ok, err := p.nextValue(append(pointer, n))
+
 			if errors.Is(err, io.EOF) {
 				err = io.ErrUnexpectedEOF
 			}
 			if err != nil {
-				return nil, false, errors.Wrapf(err, "reading array value %d", len(result))
+				return nil, false, errors.Wrapf(err, "reading array value %d", n)
 			}
 			if !ok {
 				return nil, false, nil
 			}
-			result = append(result, val)
+			if p.materialize() {
+				if err := p.hold(append(pointer, n), "", val); err != nil {
+					return nil, false, err
+				}
+				if builder != nil {
+					if err := builder.Append(val); err != nil {
+						return nil, false, errors.Wrapf(err, "appending array value %d", n)
+					}
+					continue
+				}
+				if p.arena != nil {
+					p.arena.push(val)
+					continue
+				}
+				result = append(result, val)
+			}
 		}
 
 	case ']':
@@ -238,28 +558,78 @@ func (p Pointer) Text() jsontext.Pointer {
 }
 
 // Locate locates the element within val represented by p.
-func (p Pointer) Locate(val any) (any, error) {
+// Undecoded JSON values within val
+// (of type [jsontext.Value] or [encoding/json.RawMessage])
+// are decoded as needed.
+//
+// By default, object keys in p must match exactly.
+// This can be relaxed with [KeyOption]s.
+func (p Pointer) Locate(val any, opts ...KeyOption) (any, error) {
+	return p.locate(val, newKeyMatcher(opts))
+}
+
+func (p Pointer) locate(val any, keys keyMatcher) (any, error) {
+	result, _, err := p.find(val, keys)
+	return result, err
+}
+
+// find is like locate
+// but also tells whether the element exists,
+// distinguishing a missing object member from one whose value is nil
+// (as JSON null is with [V1Values]).
+func (p Pointer) find(val any, keys keyMatcher) (any, bool, error) {
 	if len(p) == 0 {
-		return val, nil
+		return val, true, nil
+	}
+	if e, ok := val.(Expanded); ok {
+		val = e.Value
+	}
+	if b, ok := rawBytes(val); ok {
+		decoded, err := decodeRaw(b)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "decoding raw JSON value")
+		}
+		val = decoded
 	}
 	switch first := p[0].(type) {
 	case string:
 		if m, ok := val.(map[string]any); ok {
-			return p[1:].Locate(m[first])
+			elt, ok := keys.lookup(m, first)
+			if !ok && len(p) == 1 {
+				return nil, false, nil
+			}
+			return p[1:].find(elt, keys)
 		}
-		return nil, fmt.Errorf("type mismatch: non-object %T for key %q", val, first)
+		if o, ok := val.(*Object); ok {
+			elt, ok := keys.lookup(o.m, first)
+			if !ok && len(p) == 1 {
+				return nil, false, nil
+			}
+			return p[1:].find(elt, keys)
+		}
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.Map {
+			// A map produced by the MapKeys option.
+			if elt, ok := mapIndex(rv, first); ok {
+				return p[1:].find(elt.Interface(), keys)
+			}
+			if len(p) == 1 {
+				return nil, false, nil
+			}
+			return p[1:].find(nil, keys)
+		}
+		return nil, false, fmt.Errorf("type mismatch: non-object %T for key %q", val, first)
 
 	case int:
 		if a, ok := val.([]any); ok {
 			if first >= 0 && first < len(a) {
-				return p[1:].Locate(a[first])
+				return p[1:].find(a[first], keys)
 			}
-			return nil, fmt.Errorf("array index %d out of bounds", first)
+			return nil, false, fmt.Errorf("array index %d out of bounds", first)
 		}
-		return nil, fmt.Errorf("type mismatch: non-array %T for index %d", val, first)
+		return nil, false, fmt.Errorf("type mismatch: non-array %T for index %d", val, first)
 
 	default:
-		return nil, fmt.Errorf("unexpected %T in Pointer", first)
+		return nil, false, fmt.Errorf("unexpected %T in Pointer", first)
 	}
 }
 
@@ -317,17 +687,31 @@ func Float(n float64) Number {
 // NewNumber produces a new [Number] from a [jsontext.Token].
 // The input must have [jsontext.Kind] '0' ("number").
 func NewNumber(tok jsontext.Token) Number {
-	f := tok.Float()
-	result := Number{raw: tok.String(), f: f}
+	return numberFromFloat(tok.String(), tok.Float())
+}
+
+// numberFromRaw produces a new [Number] from its JSON representation,
+// which must be valid.
+func numberFromRaw(raw string) Number {
+	f, _ := strconv.ParseFloat(raw, 64) // on a range error, f is ±Inf, as with jsontext.Token.Float
+	return numberFromFloat(raw, f)
+}
+
+func numberFromFloat(raw string, f float64) Number {
+	return numberIn(nil, raw, f)
+}
+
+// numberIn is like numberFromFloat
+// but allocates from a (if it is not nil).
+func numberIn(a *Arena, raw string, f float64) Number {
+	result := Number{raw: raw, f: f}
 	if !math.IsNaN(f) && !math.IsInf(f, 0) {
 		if r := math.Round(f); r == f {
 			if f >= math.MinInt64 && f <= math.MaxInt64 {
-				i := int64(f)
-				result.i = &i
+				result.i = a.int64(int64(f))
 			}
 			if f >= 0 && f <= math.MaxUint64 {
-				u := uint64(f)
-				result.u = &u
+				result.u = a.uint64(uint64(f))
 			}
 		}
 	}
@@ -338,3 +722,8 @@ func NewNumber(tok jsontext.Token) Number {
 func (n Number) String() string {
 	return n.raw
 }
+
+// String returns "null".
+func (Null) String() string {
+	return "null"
+}
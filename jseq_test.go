@@ -3,9 +3,10 @@ package jseq_test
 import (
 	"encoding/json/jsontext"
 	"errors"
+	"fmt"
 	"os"
-
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/bobg/jseq"
@@ -54,6 +55,114 @@ func TestValues(t *testing.T) {
 	}
 }
 
+func TestTokensE(t *testing.T) {
+	var (
+		kinds []jsontext.Kind
+		errs  []error
+	)
+	for tok, err := range jseq.TokensE(strings.NewReader(`[1, 2} 3`)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		kinds = append(kinds, tok.Kind())
+	}
+	if want := []jsontext.Kind{'[', '0', '0'}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("got kinds %v, want %v", kinds, want)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors, want 1", len(errs))
+	}
+	var syntaxErr *jsontext.SyntacticError
+	if !errors.As(errs[0], &syntaxErr) {
+		t.Errorf("got error %v, want a SyntacticError", errs[0])
+	}
+}
+
+func TestTokensEResume(t *testing.T) {
+	const inp = "[1, 2}\n\"a\"\n{\"b\": tru}\n{\"c\": 3}\n"
+
+	var (
+		got  []string
+		errs int
+	)
+	for tok, err := range jseq.TokensE(strings.NewReader(inp)) {
+		if err != nil {
+			var syntaxErr *jsontext.SyntacticError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("got error %v, want a SyntacticError", err)
+			}
+			errs++
+			got = append(got, "error")
+			continue
+		}
+		got = append(got, tok.String())
+	}
+	want := []string{"[", "1", "2", "error", "a", "{", "b", "error", "{", "c", "3", "}"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if errs != 2 {
+		t.Errorf("got %d errors, want 2", errs)
+	}
+
+	// Resuming works after enough input to discard buffered bytes.
+	long := strings.Repeat(`{"x": [1, 2, 3]}`+"\n", 10000) + "{]\n" + `"end"` + "\n"
+	var last string
+	errs = 0
+	for tok, err := range jseq.TokensE(strings.NewReader(long)) {
+		if err != nil {
+			errs++
+			continue
+		}
+		last = tok.String()
+	}
+	if errs != 1 || last != "end" {
+		t.Errorf("got %d errors and last token %q, want 1 and \"end\"", errs, last)
+	}
+}
+
+func TestValuesE(t *testing.T) {
+	cases := []struct {
+		inp      string
+		wantVals []any
+		wantErr  bool
+	}{{
+		inp:      `[1, "a"] true`,
+		wantVals: []any{jseq.Int(1), "a", []any{jseq.Int(1), "a"}, true},
+	}, {
+		inp:      `[1, 2} 3`,
+		wantVals: []any{jseq.Int(1), jseq.Int(2)},
+		wantErr:  true,
+	}, {
+		inp:      `[1, 2`,
+		wantVals: []any{jseq.Int(1), jseq.Int(2)},
+		wantErr:  true,
+	}}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("case_%d", i+1), func(t *testing.T) {
+			var (
+				vals []any
+				errs []error
+			)
+			for pair, err := range jseq.ValuesE(jseq.TokensE(strings.NewReader(tc.inp))) {
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				vals = append(vals, pair.Value)
+			}
+			if !reflect.DeepEqual(vals, tc.wantVals) {
+				t.Errorf("got %v, want %v", vals, tc.wantVals)
+			}
+			if tc.wantErr != (len(errs) == 1) || len(errs) > 1 {
+				t.Errorf("got errors %v, want error: %v", errs, tc.wantErr)
+			}
+		})
+	}
+}
+
 func TestPointer(t *testing.T) {
 	val := map[string]any{
 		"hello": map[string]any{
@@ -84,6 +193,45 @@ func TestPointer(t *testing.T) {
 	}
 }
 
+type pair struct {
+	p jseq.Pointer
+	v any
+}
+
+func collect(t *testing.T, r *strings.Reader, opts ...jseq.Option) []pair {
+	t.Helper()
+
+	tokens, errptr1 := jseq.Tokens(r)
+	values, errptr2 := jseq.Values(tokens, opts...)
+
+	var result []pair
+	for p, v := range values {
+		result = append(result, pair{p: p, v: v})
+	}
+	if err := errors.Join(*errptr1, *errptr2); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func records(t *testing.T, src jseq.Source) []any {
+	t.Helper()
+
+	tokens, errptr1 := src.Tokens()
+	values, errptr2 := jseq.Values(tokens)
+
+	var result []any
+	for p, v := range values {
+		if len(p) == 0 {
+			result = append(result, v)
+		}
+	}
+	if err := errors.Join(*errptr1, *errptr2); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
 var expectJSON = []struct {
 	p jseq.Pointer
 	v any
@@ -0,0 +1,92 @@
+package jseq
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// KeyOption is the type of an option controlling how object keys are compared
+// by [Pointer.Locate] and by [Pattern] matching.
+// By default, keys must match exactly.
+type KeyOption func(*keyMatcher)
+
+// FoldCase is a [KeyOption] that makes key comparisons case-insensitive,
+// under Unicode case folding.
+func FoldCase() KeyOption {
+	return func(m *keyMatcher) {
+		m.fold = true
+	}
+}
+
+// NormalizeKeys is a [KeyOption] that compares keys
+// after converting them to Unicode normalization form NFC,
+// so that e.g. "é" written as one code point
+// matches "é" written as "e" plus a combining accent.
+func NormalizeKeys() KeyOption {
+	return func(m *keyMatcher) {
+		m.nfc = true
+	}
+}
+
+type keyMatcher struct {
+	fold, nfc bool
+	aliases   [][]string
+}
+
+func newKeyMatcher(opts []KeyOption) keyMatcher {
+	var m keyMatcher
+	for _, opt := range opts {
+		opt(&m)
+	}
+	return m
+}
+
+// exact tells whether m requires keys to match exactly.
+func (m keyMatcher) exact() bool {
+	return !m.fold && !m.nfc && len(m.aliases) == 0
+}
+
+// canon returns the form of s used in comparisons,
+// apart from case folding.
+func (m keyMatcher) canon(s string) string {
+	if m.nfc {
+		s = norm.NFC.String(s)
+	}
+	return s
+}
+
+// equal tells whether keys a and b match.
+func (m keyMatcher) equal(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if m.exact() {
+		return false
+	}
+	ca, cb := m.canon(a), m.canon(b)
+	if ca == cb || (m.fold && strings.EqualFold(ca, cb)) {
+		return true
+	}
+	if ga, ok := m.group(a); ok {
+		if gb, ok := m.group(b); ok && ga == gb {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup finds the member of obj whose key matches key.
+// An exact match is preferred.
+// Otherwise, among multiple matches, the one with the lowest key (see [SortedKeys]) wins.
+func (m keyMatcher) lookup(obj map[string]any, key string) (any, bool) {
+	if val, ok := obj[key]; ok || m.exact() {
+		return val, ok
+	}
+	for _, k := range SortedKeys(obj) {
+		if m.equal(k, key) {
+			return obj[k], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,89 @@
+package jseq_test
+
+import (
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestKeyOptions(t *testing.T) {
+	const (
+		composed   = "caf\u00e9"
+		decomposed = "cafe\u0301"
+	)
+
+	val := map[string]any{
+		"User": map[string]any{
+			composed: "yes",
+		},
+		"user": "exact",
+	}
+
+	cases := []struct {
+		name string
+		ptr  jseq.Pointer
+		opts []jseq.KeyOption
+		want any
+	}{{
+		name: "exact",
+		ptr:  jseq.Pointer{"USER", composed},
+		want: nil,
+	}, {
+		name: "fold",
+		ptr:  jseq.Pointer{"USER", composed},
+		opts: []jseq.KeyOption{jseq.FoldCase()},
+		want: "yes",
+	}, {
+		name: "fold_prefers_exact",
+		ptr:  jseq.Pointer{"user"},
+		opts: []jseq.KeyOption{jseq.FoldCase()},
+		want: "exact",
+	}, {
+		name: "nfc_without_option",
+		ptr:  jseq.Pointer{"User", decomposed},
+		want: nil,
+	}, {
+		name: "nfc",
+		ptr:  jseq.Pointer{"User", decomposed},
+		opts: []jseq.KeyOption{jseq.NormalizeKeys()},
+		want: "yes",
+	}, {
+		name: "both",
+		ptr:  jseq.Pointer{"USER", "CAFE\u0301"},
+		opts: []jseq.KeyOption{jseq.FoldCase(), jseq.NormalizeKeys()},
+		want: "yes",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.ptr.Locate(val, tc.opts...)
+			if tc.want == nil {
+				// Either a nil value or a type-mismatch error is acceptable.
+				if err == nil && got != nil {
+					t.Errorf("got %v, want nothing", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPatternKeyOptions(t *testing.T) {
+	ptr := jseq.Pointer{"Users", 0, "E\u0301mail"} // decomposed
+
+	if jseq.MustParsePattern("/users/*/\u00e9mail").Match(ptr) {
+		t.Error("exact pattern matched")
+	}
+	if jseq.MustParsePattern("/users/*/\u00e9mail", jseq.FoldCase()).Match(ptr) {
+		t.Error("case-folding pattern matched without normalization")
+	}
+	if !jseq.MustParsePattern("/users/*/\u00e9mail", jseq.FoldCase(), jseq.NormalizeKeys()).Match(ptr) {
+		t.Error("case-folding, normalizing pattern did not match")
+	}
+}
@@ -0,0 +1,110 @@
+package jseq
+
+import "encoding/json/jsontext"
+
+// LeafOnly is an [Option] that causes [Values] to produce only leaves:
+// strings, numbers, booleans, and nulls,
+// each with its pointer.
+// Arrays and objects,
+// including the top-level values that contain the leaves,
+// are not produced,
+// and are never built in memory,
+// which saves time and space when only a flat stream of leaves is wanted.
+//
+// Since containers are not built,
+// options that transform them
+// (such as [SparseArrays], [ArraysAsObjects], [ShareSubtrees], and [IntKeys])
+// have no effect,
+// and duplicate object keys are not detected.
+// A top-level value that is itself a leaf is produced with the empty pointer as usual.
+// Empty arrays and objects produce nothing.
+//
+// See also [Unmaterialized].
+func LeafOnly() Option {
+	return func(c *config) {
+		c.leafOnly = true
+	}
+}
+
+// Unmaterialized is an [Option] that causes [Values]
+// to produce a [Container] in place of each array and object,
+// instead of building a []any or map[string]any
+// holding its (already-produced) children.
+// Parsing then uses memory proportional to the nesting depth of the input,
+// not its size,
+// even for a single huge top-level array.
+//
+// The same caveats apply as for [LeafOnly].
+// Note also that by default the tokenizer itself remembers the keys of each object
+// in order to reject duplicates;
+// for truly constant memory on huge objects,
+// pass [jsontext.AllowDuplicateNames] to [Tokens]
+// (and do not use a [DuplicateKeyPolicy] other than the default,
+// which also remembers keys).
+func Unmaterialized() Option {
+	return func(c *config) {
+		c.unmaterialized = true
+	}
+}
+
+// PreOrder is an [Option] that causes [Values]
+// to produce each array and object before its children,
+// instead of after,
+// so that callers can react to entering a container
+// before seeing its contents.
+// Since the container's contents are not yet known at that point,
+// it is produced as a [Container] with a Len of -1,
+// and it is not produced again afterward.
+//
+// As with [Unmaterialized],
+// arrays and objects are not built,
+// and the same caveats apply.
+// In addition, the functions in this package that rely on
+// the usual children-before-parents order
+// (such as [WithPresence], [Backfill], and [Pivot])
+// must not be used with PreOrder.
+func PreOrder() Option {
+	return func(c *config) {
+		c.preOrder = true
+	}
+}
+
+// Container stands in for an array or object
+// under the [Unmaterialized] and [PreOrder] options.
+type Container struct {
+	// Kind is '[' for an array or '{' for an object.
+	Kind jsontext.Kind
+
+	// Len is the number of elements or members,
+	// or -1 if not yet known.
+	Len int
+}
+
+// materialize tells whether p builds arrays and objects.
+func (p *parser) materialize() bool {
+	return !p.leafOnly && !p.unmaterialized && !p.preOrder
+}
+
+// endContainer finishes an unbuilt container with n children,
+// producing it unless it was already produced by PreOrder.
+func (p *parser) endContainer(pointer Pointer, kind jsontext.Kind, n int) (any, bool, error) {
+	c := Container{Kind: kind, Len: n}
+	if p.preOrder {
+		return c, true, nil
+	}
+	ok := p.yield(pointer, c)
+	return c, ok, nil
+}
+
+// leafYield wraps yield to suppress containers.
+// Other yield wrappers that need to see every value,
+// such as the one maintaining a [Summary],
+// go outside it.
+func leafYield(yield func(Pointer, any) bool) func(Pointer, any) bool {
+	return func(pointer Pointer, val any) bool {
+		if _, ok := val.(Container); ok {
+			return true
+		}
+		return yield(pointer, val)
+	}
+}
@@ -0,0 +1,127 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestLeafOnly(t *testing.T) {
+	const inp = `{"a": [1, {"b": "x"}, []], "c": {}, "d": null} "top" [true]`
+
+	var summary jseq.Summary
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens, jseq.LeafOnly(), jseq.WithSummary(&summary))
+
+	type pair struct {
+		pointer string
+		val     any
+	}
+	var got []pair
+	for pointer, val := range values {
+		got = append(got, pair{pointer: string(pointer.Text()), val: val})
+	}
+	if err := *errptr1; err != nil {
+		t.Fatal(err)
+	}
+	if err := *errptr2; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []pair{
+		{pointer: "/a/0", val: jseq.Int(1)},
+		{pointer: "/a/1/b", val: "x"},
+		{pointer: "/d", val: jseq.Null{}},
+		{pointer: "", val: "top"},
+		{pointer: "/0", val: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// The summary still counts everything.
+	if summary.Records != 3 || summary.Values != 11 {
+		t.Errorf("got summary %s, want 3 records and 11 values", summary)
+	}
+}
+
+func TestUnmaterialized(t *testing.T) {
+	const inp = `{"a": [1, {"b": "x"}, []], "a": 2} [true]`
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp), jsontext.AllowDuplicateNames(true))
+	values, errptr2 := jseq.Values(tokens, jseq.Unmaterialized())
+
+	type pair struct {
+		pointer string
+		val     any
+	}
+	var got []pair
+	for pointer, val := range values {
+		got = append(got, pair{pointer: string(pointer.Text()), val: val})
+	}
+	if err := *errptr1; err != nil {
+		t.Fatal(err)
+	}
+	if err := *errptr2; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []pair{
+		{pointer: "/a/0", val: jseq.Int(1)},
+		{pointer: "/a/1/b", val: "x"},
+		{pointer: "/a/1", val: jseq.Container{Kind: '{', Len: 1}},
+		{pointer: "/a/2", val: jseq.Container{Kind: '[', Len: 0}},
+		{pointer: "/a", val: jseq.Container{Kind: '[', Len: 3}},
+		{pointer: "/a", val: jseq.Int(2)},
+		{pointer: "", val: jseq.Container{Kind: '{', Len: 2}},
+		{pointer: "/0", val: true},
+		{pointer: "", val: jseq.Container{Kind: '[', Len: 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := jseq.TypeName(want[2].val); got != "object" {
+		t.Errorf("got type name %q, want object", got)
+	}
+}
+
+func TestPreOrder(t *testing.T) {
+	const inp = `{"a": [1, {"b": "x"}]} 7`
+
+	var summary jseq.Summary
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens, jseq.PreOrder(), jseq.WithSummary(&summary))
+
+	type pair struct {
+		pointer string
+		val     any
+	}
+	var got []pair
+	for pointer, val := range values {
+		got = append(got, pair{pointer: string(pointer.Text()), val: val})
+	}
+	if err := *errptr1; err != nil {
+		t.Fatal(err)
+	}
+	if err := *errptr2; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []pair{
+		{pointer: "", val: jseq.Container{Kind: '{', Len: -1}},
+		{pointer: "/a", val: jseq.Container{Kind: '[', Len: -1}},
+		{pointer: "/a/0", val: jseq.Int(1)},
+		{pointer: "/a/1", val: jseq.Container{Kind: '{', Len: -1}},
+		{pointer: "/a/1/b", val: "x"},
+		{pointer: "", val: jseq.Int(7)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if summary.Records != 2 || summary.Values != 6 {
+		t.Errorf("got summary %s, want 2 records and 6 values", summary)
+	}
+}
@@ -0,0 +1,200 @@
+package jseq
+
+import (
+	"fmt"
+	"iter"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// Rule is a lint rule for JSON values.
+// See [Lint].
+type Rule interface {
+	// Name is a short identifier for the rule,
+	// such as "duplicate-keys".
+	Name() string
+
+	// Check examines one pointer/value pair from a stream produced by [Values],
+	// calling report for each problem it finds.
+	// A rule may keep state across calls,
+	// e.g. to compare records with one another;
+	// a pair with the empty pointer marks the end of a record.
+	Check(pointer Pointer, val any, report func(pointer Pointer, msg string))
+}
+
+// RuleFunc returns a [Rule] with the given name whose Check method calls f.
+func RuleFunc(name string, f func(pointer Pointer, val any, report func(Pointer, string))) Rule {
+	return ruleFunc{name: name, f: f}
+}
+
+type ruleFunc struct {
+	name string
+	f    func(Pointer, any, func(Pointer, string))
+}
+
+func (r ruleFunc) Name() string { return r.name }
+func (r ruleFunc) Check(pointer Pointer, val any, report func(Pointer, string)) {
+	r.f(pointer, val, report)
+}
+
+// Lint consumes a sequence of pointer/value pairs as produced by [Values]
+// and checks each one against the given rules,
+// producing a [Report] of the problems found.
+// If no rules are given,
+// the rules from [DefaultRules] are used.
+//
+// The problems in the report have no offsets or line numbers.
+func Lint(values iter.Seq2[Pointer, any], rules ...Rule) Report {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+
+	var (
+		result Report
+		record int
+	)
+	for pointer, val := range values {
+		for _, rule := range rules {
+			rule.Check(pointer, val, func(p Pointer, msg string) {
+				result = append(result, Problem{
+					Record:  record,
+					Offset:  -1,
+					Pointer: slices.Clone(p),
+					Message: msg,
+					Rule:    rule.Name(),
+				})
+			})
+		}
+		if len(pointer) == 0 {
+			record++
+		}
+	}
+	return result
+}
+
+// DefaultRules returns the built-in lint rules:
+// [DuplicateKeysRule], [InconsistentTypesRule], [MaxDepthRule] with a depth of 32,
+// [HugeNumbersRule], and [InvalidUTF8Rule].
+// Each call produces new rules, with fresh state.
+func DefaultRules() []Rule {
+	return []Rule{
+		DuplicateKeysRule(),
+		InconsistentTypesRule(),
+		MaxDepthRule(32),
+		HugeNumbersRule(),
+		InvalidUTF8Rule(),
+	}
+}
+
+// DuplicateKeysRule returns a [Rule] named "duplicate-keys"
+// that reports objects with more than one member with the same key.
+// Such input can only be parsed
+// with the [jsontext.AllowDuplicateNames] option
+// (or a [Dialect] that allows duplicates).
+func DuplicateKeysRule() Rule {
+	seen := make(map[string]bool)
+	return RuleFunc("duplicate-keys", func(pointer Pointer, val any, report func(Pointer, string)) {
+		if len(pointer) == 0 {
+			clear(seen)
+			return
+		}
+		// Within a record, only a duplicate key can produce the same pointer twice.
+		text := string(pointer.Text())
+		if seen[text] {
+			report(pointer, fmt.Sprintf("duplicate key %q", pointer[len(pointer)-1]))
+		}
+		seen[text] = true
+	})
+}
+
+// InconsistentTypesRule returns a [Rule] named "inconsistent-types"
+// that reports values whose JSON type (see [TypeName])
+// differs from that of the first value at the same path (see [Pointer.Path])
+// in this or an earlier record.
+// Nulls are not reported.
+func InconsistentTypesRule() Rule {
+	types := make(map[string]string)
+	return RuleFunc("inconsistent-types", func(pointer Pointer, val any, report func(Pointer, string)) {
+		if len(pointer) == 0 {
+			return
+		}
+		typ := TypeName(val)
+		if typ == "null" {
+			return
+		}
+		path := pointer.Path()
+		prev, ok := types[path]
+		if !ok {
+			types[path] = typ
+			return
+		}
+		if typ != prev {
+			report(pointer, fmt.Sprintf("%s here, but %s elsewhere at %s", typ, prev, path))
+		}
+	})
+}
+
+// MaxDepthRule returns a [Rule] named "max-depth"
+// that reports values nested more than depth levels deep.
+// Only the outermost such values are reported,
+// not their descendants.
+func MaxDepthRule(depth int) Rule {
+	return RuleFunc("max-depth", func(pointer Pointer, val any, report func(Pointer, string)) {
+		if len(pointer) == depth+1 {
+			report(pointer, fmt.Sprintf("nested more than %d levels deep", depth))
+		}
+	})
+}
+
+// maxSafeInteger is the largest integer that an IEEE 754 double,
+// and therefore a JavaScript number,
+// can represent along with all smaller integers.
+var maxSafeInteger = big.NewRat(1<<53-1, 1)
+
+// HugeNumbersRule returns a [Rule] named "huge-numbers"
+// that reports integers too large in magnitude to be represented exactly
+// as IEEE 754 double-precision numbers
+// (as in JavaScript and many JSON decoders),
+// beyond ±(2^53-1).
+// Such numbers are usually IDs that should have been encoded as strings.
+func HugeNumbersRule() Rule {
+	return RuleFunc("huge-numbers", func(pointer Pointer, val any, report func(Pointer, string)) {
+		if e, ok := val.(Expanded); ok {
+			val = e.Value
+		}
+		n, ok := val.(Number)
+		if !ok {
+			return
+		}
+		r, ok := n.rat()
+		if !ok || !r.IsInt() {
+			return
+		}
+		if new(big.Rat).Abs(r).Cmp(maxSafeInteger) > 0 {
+			report(pointer, fmt.Sprintf("integer %s cannot be represented exactly as a double; use a string", n))
+		}
+	})
+}
+
+// InvalidUTF8Rule returns a [Rule] named "invalid-utf8"
+// that reports strings and object keys containing the Unicode replacement character U+FFFD.
+// That character usually marks invalid UTF-8 in the input,
+// which can only be parsed
+// with the [jsontext.AllowInvalidUTF8] option
+// (or a [Dialect] that allows it).
+func InvalidUTF8Rule() Rule {
+	return RuleFunc("invalid-utf8", func(pointer Pointer, val any, report func(Pointer, string)) {
+		if len(pointer) > 0 {
+			if key, ok := pointer[len(pointer)-1].(string); ok && strings.ContainsRune(key, '\uFFFD') {
+				report(pointer, "object key contains invalid UTF-8")
+			}
+		}
+		if e, ok := val.(Expanded); ok {
+			val = e.Value
+		}
+		if s, ok := val.(string); ok && strings.ContainsRune(s, '\uFFFD') {
+			report(pointer, "string contains invalid UTF-8")
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package jseq_test
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestLint(t *testing.T) {
+	const inp = "{\"id\": 12345678901234567890, \"name\": \"a\", \"name\": \"b\", \"x\": {\"y\": {\"z\": 1}}}\n" +
+		"{\"id\": \"42\", \"name\": \"c\xff\", \"tags\": [\"ok\", \"bad\"], \"x\": null}\n"
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp), jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true))
+	values, errptr2 := jseq.Values(tokens)
+
+	noBad := jseq.RuleFunc("no-bad", func(pointer jseq.Pointer, val any, report func(jseq.Pointer, string)) {
+		if val == "bad" {
+			report(pointer, "bad value")
+		}
+	})
+
+	rules := []jseq.Rule{
+		jseq.DuplicateKeysRule(),
+		jseq.InconsistentTypesRule(),
+		jseq.MaxDepthRule(2),
+		jseq.HugeNumbersRule(),
+		jseq.InvalidUTF8Rule(),
+		noBad,
+	}
+	report := jseq.Lint(values, rules...)
+	if err := errors.Join(*errptr1, *errptr2); err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		record  int
+		pointer string
+		rule    string
+	}
+	var got []summary
+	for _, p := range report {
+		got = append(got, summary{record: p.Record, pointer: string(p.Pointer.Text()), rule: p.Rule})
+	}
+	want := []summary{
+		{record: 0, pointer: "/id", rule: "huge-numbers"},
+		{record: 0, pointer: "/name", rule: "duplicate-keys"},
+		{record: 0, pointer: "/x/y/z", rule: "max-depth"},
+		{record: 1, pointer: "/id", rule: "inconsistent-types"},
+		{record: 1, pointer: "/name", rule: "invalid-utf8"},
+		{record: 1, pointer: "/tags/1", rule: "no-bad"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := report.WriteText(buf); err != nil {
+		t.Fatal(err)
+	}
+	if first, _, _ := strings.Cut(buf.String(), "\n"); !strings.HasSuffix(first, " [huge-numbers]") {
+		t.Errorf("got first line %q, want rule suffix", first)
+	}
+}
+
+func TestLintDefaultRules(t *testing.T) {
+	tokens, _ := jseq.Tokens(strings.NewReader(`{"a": 1} {"a": "x"}`))
+	values, _ := jseq.Values(tokens)
+	report := jseq.Lint(values)
+	if len(report) != 1 || report[0].Rule != "inconsistent-types" {
+		t.Errorf("got %v, want one inconsistent-types problem", report)
+	}
+}
@@ -0,0 +1,386 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/bobg/errors"
+)
+
+// EditKind is the kind of an [Edit].
+type EditKind string
+
+// Values for EditKind,
+// named as in JSON Patch (RFC 6902).
+const (
+	EditAdd     EditKind = "add"
+	EditReplace EditKind = "replace"
+	EditRemove  EditKind = "remove"
+)
+
+// Edit is a change to a JSON document at a [Pointer],
+// as applied by [LiveDocument.Apply].
+type Edit struct {
+	Kind    EditKind
+	Pointer Pointer
+	Value   any // for EditAdd and EditReplace
+}
+
+// Notification is what a [LiveDocument] subscriber receives
+// when an [Edit] changes the value at a location matched by its pattern.
+type Notification struct {
+	Edit Edit
+
+	// Pointer is the matched location.
+	// It is the edit's pointer,
+	// or the part of it matched by the pattern
+	// when the edit is inside a matched location,
+	// or a location inside the edit's
+	// when the edit replaces or removes an ancestor of matched locations.
+	Pointer Pointer
+
+	// Old and New are the values at Pointer before and after the edit,
+	// nil where there is none.
+	Old, New any
+
+	// Doc is the whole document after the edit.
+	Doc any
+}
+
+// LiveDocument is a JSON document maintained by applying a stream of [Edit]s,
+// with notifications to subscribers interested in particular locations.
+// It is the stateful counterpart of streaming parsing:
+// a [Values] stream of change events,
+// consumed by the LiveDocument as a [Sink],
+// keeps the document up to date.
+//
+// Changes never modify values in place
+// (see [Pointer.Set]),
+// so the document returned by [LiveDocument.Doc]
+// and the ones passed to subscribers
+// remain valid snapshots.
+// A LiveDocument is safe for concurrent use.
+// Create one with [NewLiveDocument].
+type LiveDocument struct {
+	mu      sync.Mutex
+	doc     any
+	version int64 // the number of edits applied
+	subs    []*liveSub
+
+	// Notifications not yet delivered,
+	// and whether some call to Apply is delivering them.
+	pending    []liveDelivery
+	delivering bool
+}
+
+// liveDelivery is an applied edit whose subscribers have not been notified.
+type liveDelivery struct {
+	e         Edit
+	prev, doc any
+	subs      []*liveSub
+}
+
+type liveSub struct {
+	pattern Pattern
+	f       func(Notification)
+}
+
+// NewLiveDocument creates a [LiveDocument] starting with the given document.
+func NewLiveDocument(doc any) *LiveDocument {
+	return &LiveDocument{doc: doc}
+}
+
+// Doc returns the current document.
+func (d *LiveDocument) Doc() any {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.doc
+}
+
+// Subscribe arranges for f to be called
+// whenever an edit changes the value at a location matched by pattern,
+// whether the edit is at such a location,
+// inside one,
+// or to one of its ancestors.
+// There is one call for each matched location whose value changes,
+// with its old and new values
+// (see [Notification]).
+// An insertion into an array is reported only at the inserted element,
+// not at the elements it shifts.
+// Calls to f happen one at a time, in the order of the edits,
+// before the call to [LiveDocument.Apply] for the edit returns
+// (unless f itself calls Apply,
+// in which case the nested edit is reported after the current one).
+// When edits are applied concurrently,
+// a call to Apply may deliver the notifications for others.
+//
+// The result is a function that ends the subscription.
+func (d *LiveDocument) Subscribe(pattern Pattern, f func(Notification)) (cancel func()) {
+	sub := &liveSub{pattern: pattern, f: f}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subs = append(d.subs, sub)
+
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.subs = slices.DeleteFunc(d.subs, func(s *liveSub) bool { return s == sub })
+	}
+}
+
+// Apply applies a change to the document
+// and notifies the subscribers interested in it.
+// The rules are those of JSON Patch:
+// adding to an object member sets it,
+// adding to an array index inserts an element there
+// (and the index may equal the array's length, to append),
+// and replacing and removing require the target to exist.
+func (d *LiveDocument) Apply(e Edit) error {
+	d.mu.Lock()
+	prev := d.doc
+	_, found, err := e.Pointer.find(prev, keyMatcher{})
+	if err != nil {
+		found = false
+	}
+	doc, err := applyEdit(prev, found, e)
+	if err != nil {
+		d.mu.Unlock()
+		return errors.Wrapf(err, "applying %s at %q", e.Kind, e.Pointer.Text())
+	}
+	d.doc = doc
+	d.version++
+	d.pending = append(d.pending, liveDelivery{e: e, prev: prev, doc: doc, subs: slices.Clone(d.subs)})
+	if d.delivering {
+		// Another call (possibly an outer one in this goroutine)
+		// delivers notifications in order, including these.
+		d.mu.Unlock()
+		return nil
+	}
+	d.delivering = true
+	for len(d.pending) > 0 {
+		batch := d.pending
+		d.pending = nil
+		d.mu.Unlock()
+		for _, dl := range batch {
+			dl.deliver()
+		}
+		d.mu.Lock()
+	}
+	d.delivering = false
+	d.mu.Unlock()
+	return nil
+}
+
+// deliver notifies the subscribers of dl.
+func (dl liveDelivery) deliver() {
+	prev := dl.prev
+	if dl.e.Kind == EditAdd && len(dl.e.Pointer) > 0 {
+		if _, ok := dl.e.Pointer[len(dl.e.Pointer)-1].(int); ok {
+			// An insertion replaces nothing.
+			// Compare the inserted element with nothing,
+			// rather than with the element it shifts.
+			prev, _ = dl.e.Pointer.Remove(dl.doc)
+		}
+	}
+	for _, sub := range dl.subs {
+		sub.notify(dl.e, prev, dl.doc)
+	}
+}
+
+// notify calls s.f for each location matched by s.pattern
+// whose value differs between prev and doc because of e.
+func (s *liveSub) notify(e Edit, prev, doc any) {
+	for n := range len(e.Pointer) + 1 {
+		if s.pattern.Match(e.Pointer[:n]) {
+			// The edit is at or inside a matched location.
+			s.notifyAt(e, e.Pointer[:n], prev, doc)
+			return
+		}
+	}
+	if !s.pattern.MatchBelow(e.Pointer) {
+		return
+	}
+
+	// The edit is above matched locations;
+	// find them in the old and new values.
+	var (
+		pointers []Pointer
+		seen     = make(map[string]bool)
+	)
+	for _, root := range []any{prev, doc} {
+		val, err := e.Pointer.Locate(root)
+		if err != nil || val == nil {
+			continue
+		}
+		for sub := range Walk(val) {
+			pointer := append(slices.Clip(e.Pointer), sub...)
+			if !s.pattern.Match(pointer) {
+				continue
+			}
+			if key := string(pointer.Text()); !seen[key] {
+				seen[key] = true
+				pointers = append(pointers, pointer)
+			}
+		}
+	}
+	for _, pointer := range pointers {
+		s.notifyAt(e, pointer, prev, doc)
+	}
+}
+
+func (s *liveSub) notifyAt(e Edit, pointer Pointer, prev, doc any) {
+	old, hadOld, err := pointer.find(prev, keyMatcher{})
+	if err != nil {
+		old, hadOld = nil, false
+	}
+	cur, hasCur, err := pointer.find(doc, keyMatcher{})
+	if err != nil {
+		cur, hasCur = nil, false
+	}
+	if hadOld == hasCur && len(Diff(old, cur)) == 0 {
+		return
+	}
+	s.f(Notification{Edit: e, Pointer: slices.Clone(pointer), Old: old, New: cur, Doc: doc})
+}
+
+// applyEdit applies e to doc,
+// in which the target of e exists if found is true.
+func applyEdit(doc any, found bool, e Edit) (any, error) {
+	switch e.Kind {
+	case EditAdd:
+		if len(e.Pointer) > 0 {
+			if i, ok := e.Pointer[len(e.Pointer)-1].(int); ok {
+				return insertElement(doc, e.Pointer[:len(e.Pointer)-1], i, e.Value)
+			}
+		}
+		return e.Pointer.Set(doc, e.Value)
+
+	case EditReplace:
+		if !found {
+			return nil, errors.New("no value to replace")
+		}
+		return e.Pointer.Set(doc, e.Value)
+
+	case EditRemove:
+		if !found {
+			return nil, errors.New("no value to remove")
+		}
+		return e.Pointer.Remove(doc)
+
+	default:
+		return nil, fmt.Errorf("unknown edit kind %q", e.Kind)
+	}
+}
+
+// insertElement inserts val at index i of the array at parent in doc.
+func insertElement(doc any, parent Pointer, i int, val any) (any, error) {
+	v, err := parent.Locate(doc)
+	if err != nil {
+		return nil, err
+	}
+	a, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: non-array %T for index %d", v, i)
+	}
+	if i < 0 || i > len(a) {
+		return nil, fmt.Errorf("array index %d out of bounds", i)
+	}
+	return parent.Set(doc, slices.Insert(slices.Clone(a), i, val))
+}
+
+// Consume implements [Sink],
+// applying each record (top-level value) as a change event.
+// Pairs other than records are ignored.
+// See [LiveDocument.ParseEdit] for the form of a change event.
+func (d *LiveDocument) Consume(pointer Pointer, val any) error {
+	if len(pointer) > 0 {
+		return nil
+	}
+	e, err := d.ParseEdit(val)
+	if err != nil {
+		return err
+	}
+	return d.Apply(e)
+}
+
+// Close implements [Sink].
+// It does nothing.
+func (d *LiveDocument) Close() error {
+	return nil
+}
+
+// ParseEdit interprets a change event in the style of a JSON Patch operation,
+// such as {"op": "add", "path": "/a/0", "value": 7},
+// as an [Edit] to the current document.
+// The op must be "add", "replace", or "remove".
+// The path is a JSON pointer string,
+// whose segments are taken as array indexes or object keys
+// according to the document's current contents.
+// The path segment "-" denotes the end of an array.
+func (d *LiveDocument) ParseEdit(event any) (Edit, error) {
+	op, err := Pointer{"op"}.Locate(event)
+	if err != nil {
+		return Edit{}, errors.Wrap(err, "locating op")
+	}
+	opStr, ok := op.(string)
+	if !ok {
+		return Edit{}, fmt.Errorf("change event op is %s, want string", TypeName(op))
+	}
+	path, err := Pointer{"path"}.Locate(event)
+	if err != nil {
+		return Edit{}, errors.Wrap(err, "locating path")
+	}
+	pathStr, ok := path.(string)
+	if !ok {
+		return Edit{}, fmt.Errorf("change event path is %s, want string", TypeName(path))
+	}
+	val, err := Pointer{"value"}.Locate(event)
+	if err != nil {
+		return Edit{}, errors.Wrap(err, "locating value")
+	}
+
+	pointer, err := resolvePointer(d.Doc(), jsontext.Pointer(pathStr))
+	if err != nil {
+		return Edit{}, errors.Wrapf(err, "resolving path %q", pathStr)
+	}
+	return Edit{Kind: EditKind(opStr), Pointer: pointer, Value: val}, nil
+}
+
+// resolvePointer converts text to a [Pointer]
+// by consulting doc to tell array indexes from object keys.
+func resolvePointer(doc any, text jsontext.Pointer) (Pointer, error) {
+	if !text.IsValid() {
+		return nil, fmt.Errorf("invalid JSON pointer")
+	}
+	var (
+		result Pointer
+		val    = doc
+	)
+	for tok := range text.Tokens() {
+		a, isArray := decodedForm(val).([]any)
+		if !isArray {
+			result = append(result, tok)
+			val, _ = Pointer{tok}.Locate(val)
+			continue
+		}
+		if tok == "-" {
+			result = append(result, len(a))
+			val = nil
+			continue
+		}
+		i, err := strconv.Atoi(tok)
+		if err != nil || i < 0 || strconv.Itoa(i) != tok {
+			return nil, fmt.Errorf("invalid array index %q", tok)
+		}
+		result = append(result, i)
+		val = nil
+		if i < len(a) {
+			val = a[i]
+		}
+	}
+	return result, nil
+}
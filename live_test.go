@@ -0,0 +1,228 @@
+package jseq_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestLiveDocument(t *testing.T) {
+	const events = `
+{"op": "add", "path": "/users/1", "value": {"name": "b"}}
+{"op": "add", "path": "/list/1", "value": "x"}
+{"op": "add", "path": "/list/-", "value": "z"}
+{"op": "replace", "path": "/users/0/name", "value": "a2"}
+{"op": "add", "path": "/users/0/email", "value": "a@example.com"}
+{"op": "remove", "path": "/list/0"}
+{"op": "replace", "path": "/title", "value": "new"}
+`
+
+	doc := map[string]any{
+		"users": []any{map[string]any{"name": "a"}},
+		"list":  []any{"w", "y"},
+		"title": "old",
+	}
+	live := jseq.NewLiveDocument(doc)
+
+	var names []string
+	cancel := live.Subscribe(mustParsePattern(t, "/users/*/name"), func(n jseq.Notification) {
+		names = append(names, fmt.Sprintf("%s: %v -> %v", n.Pointer.Text(), n.Old, n.New))
+	})
+	var titles []any
+	live.Subscribe(mustParsePattern(t, "/title"), func(n jseq.Notification) {
+		titles = append(titles, n.Old)
+		cancel()
+	})
+
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(events)), live, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"users": []any{
+			map[string]any{"name": "a2", "email": "a@example.com"},
+			map[string]any{"name": "b"},
+		},
+		"list":  []any{"x", "y", "z"},
+		"title": "new",
+	}
+	if got := live.Doc(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(doc["title"], "old") {
+		t.Error("original document was modified")
+	}
+
+	// The add at /users/1 creates a location matched by the pattern.
+	// The add at /users/0/email does not match.
+	if want := []string{"/users/1/name: <nil> -> b", "/users/0/name: a -> a2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got name notifications %v, want %v", names, want)
+	}
+	if want := []any{"old"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("got title notifications %v, want %v", titles, want)
+	}
+
+	for _, bad := range []jseq.Edit{
+		{Kind: jseq.EditReplace, Pointer: jseq.Pointer{"missing"}, Value: true},
+		{Kind: jseq.EditRemove, Pointer: jseq.Pointer{"list", 9}},
+		{Kind: jseq.EditAdd, Pointer: jseq.Pointer{"list", 9}, Value: true},
+		{Kind: "move", Pointer: jseq.Pointer{"title"}},
+	} {
+		if err := live.Apply(bad); err == nil {
+			t.Errorf("got no error for %s at %q, want one", bad.Kind, bad.Pointer.Text())
+		}
+	}
+}
+
+func mustParsePattern(t *testing.T, s string) jseq.Pattern {
+	t.Helper()
+	p, err := jseq.ParsePattern(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLiveDocumentSubscribe(t *testing.T) {
+	live := jseq.NewLiveDocument(map[string]any{
+		"config": map[string]any{
+			"db":    map[string]any{"host": "h1", "port": jseq.Int(5432)},
+			"cache": map[string]any{"host": "c1"},
+		},
+	})
+
+	var got []string
+	live.Subscribe(mustParsePattern(t, "/config/{name}/host"), func(n jseq.Notification) {
+		got = append(got, fmt.Sprintf("%s: %v -> %v", n.Pointer.Text(), n.Old, n.New))
+	})
+	var db []string
+	live.Subscribe(mustParsePattern(t, "/config/db"), func(n jseq.Notification) {
+		db = append(db, fmt.Sprintf("%s at %s", n.Pointer.Text(), n.Edit.Pointer.Text()))
+	})
+
+	edits := []jseq.Edit{
+		{Kind: jseq.EditReplace, Pointer: jseq.Pointer{"config", "db", "port"}, Value: jseq.Int(5433)},
+		{Kind: jseq.EditReplace, Pointer: jseq.Pointer{"config", "db", "host"}, Value: "h1"}, // no change
+		{Kind: jseq.EditReplace, Pointer: jseq.Pointer{"config"}, Value: map[string]any{
+			"db":    map[string]any{"host": "h2"},
+			"cache": map[string]any{"host": "c1"},
+			"queue": map[string]any{"host": "q1"},
+		}},
+		{Kind: jseq.EditRemove, Pointer: jseq.Pointer{"config", "queue"}},
+	}
+	for _, e := range edits {
+		if err := live.Apply(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		"/config/db/host: h1 -> h2",
+		"/config/queue/host: <nil> -> q1",
+		"/config/queue/host: q1 -> <nil>",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	wantDB := []string{
+		"/config/db at /config/db/port",
+		"/config/db at /config",
+	}
+	if !reflect.DeepEqual(db, wantDB) {
+		t.Errorf("got %v, want %v", db, wantDB)
+	}
+}
+
+func TestLiveDocumentNull(t *testing.T) {
+	// With V1Values, a JSON null member has the value nil,
+	// but it is present and may be replaced and removed.
+	live := jseq.NewLiveDocument(map[string]any{"a": nil, "b": nil})
+
+	var got []string
+	live.Subscribe(mustParsePattern(t, "/*"), func(n jseq.Notification) {
+		got = append(got, fmt.Sprintf("%s %s: %v -> %v", n.Edit.Kind, n.Pointer.Text(), n.Old, n.New))
+	})
+
+	edits := []jseq.Edit{
+		{Kind: jseq.EditReplace, Pointer: jseq.Pointer{"a"}, Value: "x"},
+		{Kind: jseq.EditRemove, Pointer: jseq.Pointer{"b"}},
+	}
+	for _, e := range edits {
+		if err := live.Apply(e); err != nil {
+			t.Fatalf("applying %s at %q: %s", e.Kind, e.Pointer.Text(), err)
+		}
+	}
+	if want := map[string]any{"a": "x"}; !reflect.DeepEqual(live.Doc(), want) {
+		t.Errorf("got %v, want %v", live.Doc(), want)
+	}
+	want := []string{
+		"replace /a: <nil> -> x",
+		"remove /b: <nil> -> <nil>",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLiveDocumentNotificationOrder(t *testing.T) {
+	live := jseq.NewLiveDocument(map[string]any{"list": []any{}})
+
+	// Each edit adds to the list,
+	// so the list in each notification must be one longer than in the one before.
+	var lens []int
+	live.Subscribe(mustParsePattern(t, "/list/*"), func(n jseq.Notification) {
+		list, _ := jseq.Pointer{"list"}.Locate(n.Doc)
+		lens = append(lens, len(list.([]any)))
+	})
+
+	const n = 200
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e := jseq.Edit{Kind: jseq.EditAdd, Pointer: jseq.Pointer{"list", 0}, Value: jseq.Int(int64(i))}
+			if err := live.Apply(e); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(lens) != n {
+		t.Fatalf("got %d notifications, want %d", len(lens), n)
+	}
+	for i, l := range lens {
+		if l != i+1 {
+			t.Fatalf("notification %d has list length %d, want %d", i, l, i+1)
+		}
+	}
+}
+
+func TestLiveDocumentNestedApply(t *testing.T) {
+	live := jseq.NewLiveDocument(map[string]any{"a": jseq.Int(0), "b": jseq.Int(0)})
+
+	var got []string
+	live.Subscribe(mustParsePattern(t, "/*"), func(n jseq.Notification) {
+		got = append(got, fmt.Sprintf("%s: %v", n.Pointer.Text(), n.New))
+	})
+	live.Subscribe(mustParsePattern(t, "/a"), func(n jseq.Notification) {
+		// A subscriber may apply further edits,
+		// reported after the current one.
+		if err := live.Apply(jseq.Edit{Kind: jseq.EditReplace, Pointer: jseq.Pointer{"b"}, Value: n.New}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	if err := live.Apply(jseq.Edit{Kind: jseq.EditReplace, Pointer: jseq.Pointer{"a"}, Value: jseq.Int(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/a: 1", "/b: 1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package jseq
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/bobg/errors"
+)
+
+// LiveSnapshot is the state of a [LiveDocument] at some moment,
+// as returned by [LiveDocument.Snapshot].
+type LiveSnapshot struct {
+	// Version is the number of edits applied to the document
+	// (counting those applied before any restore).
+	// A change-data-capture consumer can use it
+	// to tell where to resume its feed.
+	Version int64
+
+	// Doc is the document.
+	Doc any
+}
+
+// Snapshot returns the current state of d.
+// It takes constant time,
+// because edits never modify the document in place:
+// the snapshot's document is unaffected by later edits,
+// and it may be saved (e.g. with [LiveSnapshot.WriteJSON])
+// while edits continue to be applied.
+// This relies on callers not modifying in place
+// the initial document or the values in edits,
+// which d shares.
+func (d *LiveDocument) Snapshot() LiveSnapshot {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return LiveSnapshot{Version: d.version, Doc: d.doc}
+}
+
+// Restore replaces the state of d with s.
+// Subscribers are not notified.
+func (d *LiveDocument) Restore(s LiveSnapshot) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.doc, d.version = s.Doc, s.Version
+}
+
+// WriteJSON writes s to w as a JSON document
+// of the form {"version": N, "doc": ...}.
+// It can be read back with [ReadLiveSnapshot].
+func (s LiveSnapshot) WriteJSON(w io.Writer) error {
+	return Encode(w, map[string]any{
+		"version": Int(s.Version),
+		"doc":     s.Doc,
+	})
+}
+
+// ReadLiveSnapshot reads a snapshot written by [LiveSnapshot.WriteJSON].
+func ReadLiveSnapshot(r io.Reader) (LiveSnapshot, error) {
+	val, err := decodeValue(r)
+	if err != nil {
+		return LiveSnapshot{}, errors.Wrap(err, "reading snapshot")
+	}
+
+	version, err := Pointer{"version"}.Locate(val)
+	if err != nil {
+		return LiveSnapshot{}, errors.Wrap(err, "locating version")
+	}
+	num, ok := version.(Number)
+	if !ok {
+		return LiveSnapshot{}, fmt.Errorf("snapshot version is %s, want number", TypeName(version))
+	}
+	v, ok := num.Int()
+	if !ok {
+		return LiveSnapshot{}, fmt.Errorf("snapshot version %s is not an integer", num)
+	}
+
+	doc, err := Pointer{"doc"}.Locate(val)
+	if err != nil {
+		return LiveSnapshot{}, errors.Wrap(err, "locating doc")
+	}
+	return LiveSnapshot{Version: v, Doc: doc}, nil
+}
@@ -0,0 +1,62 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestLiveSnapshot(t *testing.T) {
+	live := jseq.NewLiveDocument(map[string]any{"a": []any{jseq.Int(1)}})
+
+	apply := func(e jseq.Edit) {
+		t.Helper()
+		if err := live.Apply(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	apply(jseq.Edit{Kind: jseq.EditAdd, Pointer: jseq.Pointer{"a", 1}, Value: jseq.Int(2)})
+	apply(jseq.Edit{Kind: jseq.EditAdd, Pointer: jseq.Pointer{"b"}, Value: "x"})
+
+	snap := live.Snapshot()
+	if snap.Version != 2 {
+		t.Errorf("got version %d, want 2", snap.Version)
+	}
+	wantDoc := map[string]any{"a": []any{jseq.Int(1), jseq.Int(2)}, "b": "x"}
+
+	// Later edits do not affect the snapshot.
+	apply(jseq.Edit{Kind: jseq.EditReplace, Pointer: jseq.Pointer{"a", 0}, Value: jseq.Int(9)})
+	apply(jseq.Edit{Kind: jseq.EditRemove, Pointer: jseq.Pointer{"b"}})
+	if !reflect.DeepEqual(snap.Doc, wantDoc) {
+		t.Errorf("snapshot changed to %v, want %v", snap.Doc, wantDoc)
+	}
+
+	var buf strings.Builder
+	if err := snap.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), `{"doc":{"a":[1,2],"b":"x"},"version":2}`+"\n"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	restored, err := jseq.ReadLiveSnapshot(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	live.Restore(restored)
+	if got := live.Snapshot(); got.Version != 2 || !reflect.DeepEqual(got.Doc, wantDoc) {
+		t.Errorf("got %v after restore, want version 2 and %v", got, wantDoc)
+	}
+
+	apply(jseq.Edit{Kind: jseq.EditRemove, Pointer: jseq.Pointer{"b"}})
+	if got := live.Snapshot().Version; got != 3 {
+		t.Errorf("got version %d after restore and edit, want 3", got)
+	}
+
+	if _, err := jseq.ReadLiveSnapshot(strings.NewReader(`{"version": "x", "doc": {}}`)); err == nil {
+		t.Error("got no error for bad version, want one")
+	}
+}
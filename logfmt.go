@@ -0,0 +1,96 @@
+package jseq
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Logfmt renders v on a single line in the style of logfmt,
+// as a space-separated list of key=value pairs,
+// one for each scalar within v:
+//
+//	a.b=1 a.c[0]=x a.c[1]="hello world"
+//
+// It is meant for putting JSON payloads in plain-text logs
+// without multiline noise.
+//
+// Keys are rendered by [Pointer.Dotted] with [BracketIndexes],
+// plus any other given options.
+// Object members appear in sorted key order (see [Walk]).
+// Empty objects and arrays appear as {} and [].
+// Strings are quoted only when they are empty
+// or contain spaces, quotation marks, equal signs, or nonprinting characters.
+// A scalar v is rendered with an empty key, as in "=7".
+func Logfmt(v any, opts ...PathOption) string {
+	opts = append([]PathOption{BracketIndexes()}, opts...)
+
+	var (
+		buf  strings.Builder
+		prev Pointer
+	)
+	for ptr, val := range Walk(v) {
+		hadChildren := len(prev) > len(ptr)
+		prev = ptr
+
+		var text string
+		switch TypeName(val) {
+		case "object":
+			if hadChildren {
+				continue
+			}
+			text = "{}"
+		case "array":
+			if hadChildren {
+				continue
+			}
+			text = "[]"
+		default:
+			text = logfmtScalar(val)
+		}
+
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(logfmtQuote(ptr.Dotted(opts...), false))
+		buf.WriteByte('=')
+		buf.WriteString(text)
+	}
+	return buf.String()
+}
+
+func logfmtScalar(v any) string {
+	if e, ok := v.(Expanded); ok {
+		v = e.Value
+	}
+	if b, ok := rawBytes(v); ok {
+		if decoded, err := decodeRaw(b); err == nil {
+			v = decoded
+		}
+	}
+	if s, ok := v.(string); ok {
+		return logfmtQuote(s, true)
+	}
+	b, err := Marshal(v)
+	if err != nil {
+		return strconv.Quote(err.Error())
+	}
+	return string(b)
+}
+
+// logfmtQuote quotes s if necessary.
+// An empty string needs quoting only if it is a value.
+func logfmtQuote(s string, isValue bool) string {
+	if s == "" {
+		if isValue {
+			return `""`
+		}
+		return s
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		return r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r)
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package jseq_test
+
+import (
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestLogfmt(t *testing.T) {
+	cases := []struct {
+		name string
+		val  any
+		opts []jseq.PathOption
+		want string
+	}{{
+		name: "nested",
+		val: map[string]any{
+			"a": map[string]any{
+				"b": jseq.Int(1),
+				"c": []any{"x", "hello world", ""},
+			},
+			"d": true,
+			"e": jseq.Null{},
+		},
+		want: `a.b=1 a.c[0]=x a.c[1]="hello world" a.c[2]="" d=true e=null`,
+	}, {
+		name: "empty_containers",
+		val:  map[string]any{"a": map[string]any{}, "b": []any{}, "c": []any{[]any{}}},
+		want: `a={} b=[] c[0]=[]`,
+	}, {
+		name: "index_base",
+		val:  map[string]any{"a": []any{jseq.Float(1.5)}},
+		opts: []jseq.PathOption{jseq.IndexBase(1)},
+		want: `a[1]=1.5`,
+	}, {
+		name: "quoted_key",
+		val:  map[string]any{"a b": "x=y"},
+		want: `"a b"="x=y"`,
+	}, {
+		name: "scalar",
+		val:  jseq.Int(7),
+		want: `=7`,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := jseq.Logfmt(tc.val, tc.opts...); got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package jseq
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/bobg/errors"
+)
+
+// Manifest describes a set of output files,
+// such as those produced by rotating a [FileSink].
+// Bulk loaders commonly want one alongside the data files.
+// See [FileSinkOptions.Manifest].
+type Manifest struct {
+	Files []ManifestFile
+}
+
+// ManifestFile describes one file in a [Manifest].
+type ManifestFile struct {
+	Path string
+
+	// Records is the number of records (lines) in the file.
+	Records int
+
+	// Bytes is the size of the file.
+	Bytes int64
+
+	// SHA256 is the hex-encoded SHA-256 hash of the file's contents.
+	SHA256 string
+
+	// MinKey and MaxKey are the least and greatest key values
+	// among the file's records,
+	// as located by [FileSinkOptions.Key].
+	// They are nil if there is no key,
+	// or if no record has a string or number there.
+	// Numbers sort before strings.
+	MinKey, MaxKey any
+}
+
+// WriteJSON writes m to w as a JSON document
+// of the form {"files": [...]},
+// in which each file is an object with the members path, records, bytes, and sha256,
+// plus min_key and max_key if known.
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	files := make([]any, 0, len(m.Files))
+	for _, f := range m.Files {
+		obj := map[string]any{
+			"path":    f.Path,
+			"records": Int(int64(f.Records)),
+			"bytes":   Int(f.Bytes),
+			"sha256":  f.SHA256,
+		}
+		if f.MinKey != nil {
+			obj["min_key"] = f.MinKey
+			obj["max_key"] = f.MaxKey
+		}
+		files = append(files, obj)
+	}
+	return Encode(w, map[string]any{"files": files})
+}
+
+// fileStats accumulates the information in a [ManifestFile].
+type fileStats struct {
+	key            Pointer
+	hash           hash.Hash
+	records        int
+	bytes          int64
+	minKey, maxKey any
+}
+
+func newFileStats(key Pointer) *fileStats {
+	return &fileStats{key: key, hash: sha256.New()}
+}
+
+// scan adds the existing contents of a file, in r, to s.
+func (s *fileStats) scan(r io.Reader) error {
+	tee := io.TeeReader(r, s)
+	for val, err := range Records(tee) {
+		var recErr *RecordError
+		if err != nil && !errors.As(err, &recErr) {
+			return err
+		}
+		s.records++
+		s.addKey(val)
+	}
+	_, err := io.Copy(io.Discard, tee)
+	return err
+}
+
+// Write adds to the hash and size of the file.
+func (s *fileStats) Write(p []byte) (int, error) {
+	s.hash.Write(p)
+	s.bytes += int64(len(p))
+	return len(p), nil
+}
+
+// add adds a record, written as line, to s.
+func (s *fileStats) add(line []byte, val any) {
+	s.Write(line)
+	s.records++
+	s.addKey(val)
+}
+
+func (s *fileStats) addKey(val any) {
+	if s.key == nil || val == nil {
+		return
+	}
+	key, err := s.key.Locate(val)
+	if err != nil {
+		return
+	}
+	switch key.(type) {
+	case string, Number:
+	default:
+		return
+	}
+	if s.minKey == nil || compareKeys(key, s.minKey) < 0 {
+		s.minKey = key
+	}
+	if s.maxKey == nil || compareKeys(key, s.maxKey) > 0 {
+		s.maxKey = key
+	}
+}
+
+func (s *fileStats) file(path string) ManifestFile {
+	return ManifestFile{
+		Path:    path,
+		Records: s.records,
+		Bytes:   s.bytes,
+		SHA256:  hex.EncodeToString(s.hash.Sum(nil)),
+		MinKey:  s.minKey,
+		MaxKey:  s.maxKey,
+	}
+}
+
+// compareKeys compares two key values,
+// each a string or a [Number].
+func compareKeys(a, b any) int {
+	switch a := a.(type) {
+	case Number:
+		bn, ok := b.(Number)
+		if !ok {
+			return -1
+		}
+		ar, aok := a.rat()
+		br, bok := bn.rat()
+		if aok && bok {
+			return ar.Cmp(br)
+		}
+		return strings.Compare(a.String(), bn.String())
+
+	case string:
+		bs, ok := b.(string)
+		if !ok {
+			return 1
+		}
+		return strings.Compare(a, bs)
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package jseq_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.ndjson")
+
+	// Existing content, with a malformed line, is included.
+	if err := os.WriteFile(path, []byte("{\"id\": 7}\n{bad\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var m jseq.Manifest
+	s, err := jseq.OpenFileSink(path, jseq.FileSinkOptions{Manifest: &m, Key: jseq.Pointer{"id"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	consume(t, s, map[string]any{"id": jseq.Int(10)})
+	consume(t, s, map[string]any{"id": jseq.Int(3)})
+	if err := s.Rotate(filepath.Join(dir, "events.1.ndjson")); err != nil {
+		t.Fatal(err)
+	}
+	consume(t, s, map[string]any{"id": "b"})
+	consume(t, s, map[string]any{"id": jseq.Int(5)})
+	consume(t, s, map[string]any{"id": "a"})
+	consume(t, s, map[string]any{"other": true})
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []jseq.ManifestFile{{
+		Path:    filepath.Join(dir, "events.1.ndjson"),
+		Records: 4,
+		Bytes:   34,
+		MinKey:  jseq.Int(3),
+		MaxKey:  jseq.Int(10),
+	}, {
+		Path:    path,
+		Records: 4,
+		Bytes:   46,
+		MinKey:  jseq.Int(5),
+		MaxKey:  "b",
+	}}
+	for i := range want {
+		want[i].SHA256 = sha256File(t, want[i].Path)
+	}
+	if !reflect.DeepEqual(m.Files, want) {
+		t.Errorf("got %+v, want %+v", m.Files, want)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := m.WriteJSON(buf); err != nil {
+		t.Fatal(err)
+	}
+	recs := records(t, jseq.ReaderSource(buf))
+	files := recs[0].(map[string]any)["files"].([]any)
+	if len(files) != 2 {
+		t.Fatalf("got %d files in manifest document, want 2", len(files))
+	}
+	f := files[1].(map[string]any)
+	if f["path"] != path || f["sha256"] != want[1].SHA256 || f["max_key"] != "b" || !reflect.DeepEqual(f["records"], jseq.Int(4)) {
+		t.Errorf("got manifest entry %v", f)
+	}
+}
+
+func sha256File(t *testing.T, path string) string {
+	t.Helper()
+	sum := sha256.Sum256([]byte(readFile(t, path)))
+	return hex.EncodeToString(sum[:])
+}
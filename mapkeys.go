@@ -0,0 +1,96 @@
+package jseq
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// MapKeys is an [Option] that causes [Values] to produce the objects at the given pointers
+// as map[K]any instead of map[string]any,
+// converting each key with the given function.
+// If conversion fails for any key,
+// Values produces an error.
+//
+// Pointers to the members of such an object still use the original string keys.
+func MapKeys[K comparable](convert func(string) (K, error), pointers ...Pointer) Option {
+	return func(c *config) {
+		c.keyConverters = append(c.keyConverters, keyConverter{
+			pointers: pointers,
+			convert: func(m map[string]any) (any, error) {
+				result := make(map[K]any, len(m))
+				for key, val := range m {
+					k, err := convert(key)
+					if err != nil {
+						return nil, fmt.Errorf("converting object key %q: %w", key, err)
+					}
+					result[k] = val
+				}
+				return result, nil
+			},
+		})
+	}
+}
+
+// IntKeys is an [Option] that causes [Values] to produce the objects at the given pointers
+// as map[int64]any instead of map[string]any.
+// It is shorthand for [MapKeys] with a function that parses base-10 integers.
+func IntKeys(pointers ...Pointer) Option {
+	return MapKeys(func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) }, pointers...)
+}
+
+type keyConverter struct {
+	pointers []Pointer
+	convert  func(map[string]any) (any, error)
+}
+
+func (p *parser) convertKeys(pointer Pointer, m map[string]any) (any, error) {
+	for _, kc := range p.keyConverters {
+		if slices.ContainsFunc(kc.pointers, func(q Pointer) bool { return slices.Equal(q, pointer) }) {
+			return kc.convert(m)
+		}
+	}
+	return m, nil
+}
+
+// mapIndex looks up the member of the map m whose key has the string form key.
+// It is for maps produced by [MapKeys],
+// whose key types are not known statically.
+func mapIndex(m reflect.Value, key string) (reflect.Value, bool) {
+	kt := m.Type().Key()
+
+	var k reflect.Value
+	switch kt.Kind() {
+	case reflect.String:
+		k = reflect.ValueOf(key).Convert(kt)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, kt.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		k = reflect.New(kt).Elem()
+		k.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(key, 10, kt.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		k = reflect.New(kt).Elem()
+		k.SetUint(n)
+
+	default:
+		iter := m.MapRange()
+		for iter.Next() {
+			if fmt.Sprint(iter.Key().Interface()) == key {
+				return iter.Value(), true
+			}
+		}
+		return reflect.Value{}, false
+	}
+
+	result := m.MapIndex(k)
+	return result, result.IsValid()
+}
@@ -0,0 +1,58 @@
+package jseq_test
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestIntKeys(t *testing.T) {
+	const inp = `{"byID": {"1": "a", "22": {"x": true}}, "other": {"3": "c"}}`
+
+	got := collect(t, strings.NewReader(inp), jseq.IntKeys(jseq.Pointer{"byID"}))
+	last := got[len(got)-1].v
+
+	want := map[string]any{
+		"byID":  map[int64]any{1: "a", 22: map[string]any{"x": true}},
+		"other": map[string]any{"3": "c"},
+	}
+	if !reflect.DeepEqual(last, want) {
+		t.Fatalf("got %v, want %v", last, want)
+	}
+
+	x, err := jseq.Pointer{"byID", "22", "x"}.Locate(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x != true {
+		t.Errorf("got %v, want true", x)
+	}
+
+	b, err := jseq.Marshal(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"byID":{"1":"a","22":{"x":true}},"other":{"3":"c"}}` {
+		t.Errorf("got %s", b)
+	}
+}
+
+func TestMapKeysError(t *testing.T) {
+	type id uint8
+	conv := func(s string) (id, error) {
+		n, err := strconv.ParseUint(s, 10, 8)
+		return id(n), err
+	}
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(`{"1": 1, "300": 2}`))
+	values, errptr2 := jseq.Values(tokens, jseq.MapKeys(conv, jseq.Pointer{}))
+	for range values {
+	}
+	if err := errors.Join(*errptr1, *errptr2); err == nil {
+		t.Error("got no error, want one")
+	}
+}
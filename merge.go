@@ -0,0 +1,76 @@
+package jseq
+
+import "slices"
+
+// Merge returns the result of overlaying overlay onto base.
+// Where both are objects,
+// the result is an object with the members of both,
+// merged recursively where they share a key.
+// Otherwise the result is overlay,
+// so that arrays, scalars, and nulls in overlay replace whatever is in base.
+//
+// Neither input is modified,
+// but the result may share parts with them
+// (see [Clone]).
+func Merge(base, overlay any) any {
+	return merge(base, overlay, nil, nil)
+}
+
+// merge implements [Merge].
+// If replaced is non-nil,
+// it is called with the pointer of each value in overlay that replaces (or adds to) base wholesale,
+// and with the pointer of each object that overlay merges into
+// (in which case merged is true).
+func merge(base, overlay any, pointer Pointer, replaced func(pointer Pointer, val any, merged bool)) any {
+	baseObj, ok1 := asObject(base)
+	overObj, ok2 := asObject(overlay)
+	if !ok1 || !ok2 {
+		if replaced != nil {
+			replaced(pointer, overlay, false)
+		}
+		return overlay
+	}
+
+	if replaced != nil {
+		replaced(pointer, overlay, true)
+	}
+	result := make(map[string]any, len(baseObj)+len(overObj))
+	for key, val := range baseObj {
+		result[key] = val
+	}
+	for _, key := range SortedKeys(overObj) {
+		val := overObj[key]
+		if baseVal, ok := baseObj[key]; ok {
+			result[key] = merge(baseVal, val, append(slices.Clip(pointer), key), replaced)
+			continue
+		}
+		if replaced != nil {
+			replaced(append(slices.Clip(pointer), key), val, false)
+		}
+		result[key] = val
+	}
+	return result
+}
+
+// asObject returns v as a map[string]any if it is an object,
+// decoding it first if it is an undecoded JSON value.
+func asObject(v any) (map[string]any, bool) {
+	if e, ok := v.(Expanded); ok {
+		v = e.Value
+	}
+	if b, ok := rawBytes(v); ok {
+		if rawTypeName(b) != "object" {
+			return nil, false
+		}
+		decoded, err := decodeRaw(b)
+		if err != nil {
+			return nil, false
+		}
+		v = decoded
+	}
+	if o, ok := v.(*Object); ok {
+		return o.Map(), true
+	}
+	m, ok := v.(map[string]any)
+	return m, ok
+}
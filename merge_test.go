@@ -0,0 +1,41 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestMerge(t *testing.T) {
+	base := map[string]any{
+		"a": map[string]any{"x": jseq.Int(1), "y": jseq.Int(2)},
+		"b": []any{jseq.Int(1)},
+		"c": "keep",
+	}
+	overlay := map[string]any{
+		"a": jsontext.Value(`{"y": 20, "z": 30}`),
+		"b": []any{jseq.Int(2)},
+		"d": jseq.Null{},
+	}
+
+	got := jseq.Merge(base, overlay)
+	want := map[string]any{
+		"a": map[string]any{"x": jseq.Int(1), "y": jseq.Int(20), "z": jseq.Int(30)},
+		"b": []any{jseq.Int(2)},
+		"c": "keep",
+		"d": jseq.Null{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, ok := base["a"].(map[string]any)["z"]; ok {
+		t.Error("base was modified")
+	}
+
+	if got := jseq.Merge(base, "scalar"); got != "scalar" {
+		t.Errorf("got %v, want scalar", got)
+	}
+}
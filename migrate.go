@@ -0,0 +1,161 @@
+package jseq
+
+import (
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/bobg/errors"
+)
+
+// Migration transforms a document from one schema version to the next.
+// It must not modify its input;
+// the pointer-editing methods [Pointer.Set] and [Pointer.Remove] help with that.
+type Migration func(doc any) (any, error)
+
+// Migrator holds a set of [Migration]s,
+// one for each schema version,
+// for migrating documents to newer versions.
+// The zero Migrator is ready to use.
+// A Migrator is safe for concurrent use.
+type Migrator struct {
+	mu    sync.Mutex
+	steps map[int]Migration
+}
+
+// Register registers f as the migration from version from to version from+1.
+// Registering a version that is already registered replaces it.
+func (m *Migrator) Register(from int, f Migration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.steps == nil {
+		m.steps = make(map[int]Migration)
+	}
+	m.steps[from] = f
+}
+
+// Migrate migrates doc from version from to version to,
+// applying the registered migrations in order.
+// It is an error if any step is missing,
+// or if to is less than from.
+func (m *Migrator) Migrate(doc any, from, to int) (any, error) {
+	if to < from {
+		return nil, fmt.Errorf("cannot migrate from version %d down to %d", from, to)
+	}
+
+	m.mu.Lock()
+	steps := make([]Migration, 0, to-from)
+	for v := from; v < to; v++ {
+		f, ok := m.steps[v]
+		if !ok {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("no migration registered from version %d", v)
+		}
+		steps = append(steps, f)
+	}
+	m.mu.Unlock()
+
+	for i, f := range steps {
+		var err error
+		doc, err = f(doc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "migrating from version %d", from+i)
+		}
+	}
+	return doc, nil
+}
+
+// Records migrates each top-level value in values
+// (a sequence of pointer/value pairs as produced by [Values])
+// from version from to version to,
+// producing the results one at a time,
+// each paired with any error migrating it.
+func (m *Migrator) Records(values iter.Seq2[Pointer, any], from, to int) iter.Seq2[any, error] {
+	return func(yield func(any, error) bool) {
+		var record int
+		for pointer, val := range values {
+			if len(pointer) > 0 {
+				continue
+			}
+			doc, err := m.Migrate(val, from, to)
+			if err != nil {
+				err = errors.Wrapf(err, "record %d", record)
+			}
+			if !yield(doc, err) {
+				return
+			}
+			record++
+		}
+	}
+}
+
+var defaultMigrator Migrator
+
+// RegisterMigration registers f as the migration from version from to version from+1
+// for use by [Migrate].
+// See [Migrator.Register].
+func RegisterMigration(from int, f Migration) {
+	defaultMigrator.Register(from, f)
+}
+
+// Migrate migrates doc from version from to version to
+// using the migrations registered with [RegisterMigration].
+// See [Migrator.Migrate].
+func Migrate(doc any, from, to int) (any, error) {
+	return defaultMigrator.Migrate(doc, from, to)
+}
+
+// Rename is a [Migration] that moves the value at from to to.
+// It does nothing if from does not locate a value.
+// A null value
+// (which is nil with [V1Values])
+// counts as a value.
+func Rename(from, to Pointer) Migration {
+	return func(doc any) (any, error) {
+		val, found, err := from.find(doc, keyMatcher{})
+		if err != nil || !found {
+			return doc, nil
+		}
+		doc, err = from.Remove(doc)
+		if err != nil {
+			return nil, err
+		}
+		return to.Set(doc, val)
+	}
+}
+
+// Delete is a [Migration] that removes the value at ptr.
+func Delete(ptr Pointer) Migration {
+	return func(doc any) (any, error) {
+		return ptr.Remove(doc)
+	}
+}
+
+// Default is a [Migration] that sets the value at ptr to val
+// if ptr does not already locate a value.
+// A null value
+// (which is nil with [V1Values])
+// counts as a value and is left alone.
+func Default(ptr Pointer, val any) Migration {
+	return func(doc any) (any, error) {
+		if _, found, err := ptr.find(doc, keyMatcher{}); err == nil && found {
+			return doc, nil
+		}
+		return ptr.Set(doc, val)
+	}
+}
+
+// Steps is a [Migration] that applies each of the given migrations in turn.
+func Steps(migrations ...Migration) Migration {
+	return func(doc any) (any, error) {
+		for _, f := range migrations {
+			var err error
+			doc, err = f(doc)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return doc, nil
+	}
+}
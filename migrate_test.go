@@ -0,0 +1,84 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestMigrator(t *testing.T) {
+	var m jseq.Migrator
+	m.Register(1, jseq.Rename(jseq.Pointer{"userId"}, jseq.Pointer{"user", "id"}))
+	m.Register(2, jseq.Steps(
+		jseq.Delete(jseq.Pointer{"legacy"}),
+		jseq.Default(jseq.Pointer{"user", "role"}, "member"),
+	))
+
+	const inp = `{"userId": 7, "legacy": true} {"user": {"role": "admin"}}`
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+	var got []any
+	for doc, err := range m.Records(values, 1, 3) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, doc)
+	}
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+
+	want := []any{
+		map[string]any{"user": map[string]any{"id": jseq.Int(7), "role": "member"}},
+		map[string]any{"user": map[string]any{"role": "admin"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := m.Migrate(want[0], 1, 4); err == nil {
+		t.Error("missing step: got no error")
+	}
+	if _, err := m.Migrate(want[0], 2, 1); err == nil {
+		t.Error("downgrade: got no error")
+	}
+	if doc, err := m.Migrate(want[0], 3, 3); err != nil || !reflect.DeepEqual(doc, want[0]) {
+		t.Errorf("no-op migration: got %v, %v", doc, err)
+	}
+}
+
+func TestMigrateNull(t *testing.T) {
+	// With V1Values, JSON null is nil,
+	// but a null member is still present.
+	tokens, _ := jseq.Tokens(strings.NewReader(`{"old": null, "role": null}`))
+	values, errptr := jseq.Values(tokens, jseq.V1Values())
+	var doc any
+	for pointer, val := range values {
+		if len(pointer) == 0 {
+			doc = val
+		}
+	}
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := jseq.Steps(
+		jseq.Rename(jseq.Pointer{"old"}, jseq.Pointer{"new"}),
+		jseq.Default(jseq.Pointer{"role"}, "member"),
+		jseq.Rename(jseq.Pointer{"missing"}, jseq.Pointer{"other"}),
+		jseq.Default(jseq.Pointer{"team"}, "none"),
+	)(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"new": nil, "role": nil, "team": "none"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package jseq
+
+import (
+	"encoding/json"
+	"encoding/json/jsontext"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/bobg/errors"
+)
+
+// NumberMode says how [Values] represents JSON numbers.
+// See [Numbers].
+type NumberMode int
+
+const (
+	// NumbersAsNumber produces a [Number] for each number.
+	// This is the default.
+	// A Number keeps the number's text
+	// and converts to other types on demand.
+	NumbersAsNumber NumberMode = iota
+
+	// NumbersAsFloat64 produces a float64 for each number,
+	// as [encoding/json.Unmarshal] does when decoding into an any.
+	// A number too large for a float64 is an error,
+	// and a number too precise for one is rounded.
+	NumbersAsFloat64
+
+	// NumbersAsString produces a [json.Number] for each number,
+	// holding its text unchanged,
+	// as [encoding/json.Decoder.UseNumber] does.
+	NumbersAsString
+
+	// NumbersAsBig produces a *[big.Int] for each number written as an integer
+	// (with no fraction or exponent),
+	// and a *[big.Float] for each other number,
+	// with at least enough precision for the digits it is written with.
+	// Integers are exact no matter their size.
+	NumbersAsBig
+)
+
+// Numbers is an [Option] that sets how [Values] represents JSON numbers.
+// Consumers with different needs for precision and speed
+// can get numbers in the form they want
+// without converting every value afterwards.
+//
+// [Marshal], [TypeName], and [Clone] accept numbers in any of these forms,
+// but other functions in this package that inspect numbers,
+// such as [Expr.Eval] and [Rules.Apply],
+// expect [Number].
+func Numbers(mode NumberMode) Option {
+	return func(c *config) {
+		c.numberMode = mode
+	}
+}
+
+// number returns the value produced for a JSON number token.
+func (p *parser) number(tok jsontext.Token) (any, error) {
+	switch p.numberMode {
+	case NumbersAsFloat64:
+		f, err := strconv.ParseFloat(tok.String(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("number %s out of range for float64", tok.String())
+		}
+		return f, nil
+
+	case NumbersAsString:
+		return json.Number(tok.String()), nil
+
+	case NumbersAsBig:
+		return bigNumber(tok.String())
+
+	default:
+		if p.arena != nil {
+			return numberIn(p.arena, tok.String(), tok.Float()), nil
+		}
+		return NewNumber(tok), nil
+	}
+}
+
+// bigNumber parses the JSON number s as a *big.Int or *big.Float.
+func bigNumber(s string) (any, error) {
+	if !strings.ContainsAny(s, ".eE") {
+		i, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %s", s)
+		}
+		return i, nil
+	}
+	prec := max(64, 4*uint(len(s))) // over log2(10) bits per digit
+	f, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing number %s", s)
+	}
+	return f, nil
+}
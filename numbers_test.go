@@ -0,0 +1,104 @@
+package jseq_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestNumbers(t *testing.T) {
+	const inp = `[1, -2.5, 12345678901234567890123, 1e2, 0.1]`
+
+	bigFloat := func(s string) *big.Float {
+		f, _, err := big.ParseFloat(s, 10, 64, big.ToNearestEven)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	bigInt := func(s string) *big.Int {
+		i, _ := new(big.Int).SetString(s, 10)
+		return i
+	}
+
+	cases := []struct {
+		name string
+		mode jseq.NumberMode
+		want []any
+	}{{
+		name: "number",
+		mode: jseq.NumbersAsNumber,
+		want: []any{float64(1), -2.5, 12345678901234567890123.0, float64(100), 0.1}, // compared via Number.Float
+	}, {
+		name: "float64",
+		mode: jseq.NumbersAsFloat64,
+		want: []any{float64(1), -2.5, 12345678901234567890123.0, float64(100), 0.1},
+	}, {
+		name: "string",
+		mode: jseq.NumbersAsString,
+		want: []any{json.Number("1"), json.Number("-2.5"), json.Number("12345678901234567890123"), json.Number("1e2"), json.Number("0.1")},
+	}, {
+		name: "big",
+		mode: jseq.NumbersAsBig,
+		want: []any{bigInt("1"), bigFloat("-2.5"), bigInt("12345678901234567890123"), bigFloat("1e2"), bigFloat("0.1")},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(inp))
+			values, errptr := jseq.Values(tokens, jseq.Numbers(tc.mode))
+
+			var rec []any
+			for pointer, val := range values {
+				if len(pointer) == 0 {
+					rec = val.([]any)
+				}
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+			if len(rec) != len(tc.want) {
+				t.Fatalf("got %d numbers, want %d", len(rec), len(tc.want))
+			}
+
+			for i, got := range rec {
+				if jseq.TypeName(got) != "number" {
+					t.Errorf("element %d: got type %T, want a number", i, got)
+				}
+				switch want := tc.want[i].(type) {
+				case *big.Int:
+					if g, ok := got.(*big.Int); !ok || g.Cmp(want) != 0 {
+						t.Errorf("element %d: got %v, want %v", i, got, want)
+					}
+				case *big.Float:
+					if g, ok := got.(*big.Float); !ok || g.Cmp(want) != 0 {
+						t.Errorf("element %d: got %v, want %v", i, got, want)
+					}
+				default:
+					if n, ok := got.(jseq.Number); ok {
+						got = n.Float()
+					}
+					if !reflect.DeepEqual(got, want) {
+						t.Errorf("element %d: got %v, want %v", i, got, want)
+					}
+				}
+			}
+
+			b, err := jseq.Marshal(jseq.Clone(rec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var roundTrip []float64
+			if err := json.Unmarshal(b, &roundTrip); err != nil {
+				t.Fatal(err)
+			}
+			if want := []float64{1, -2.5, 12345678901234567890123, 100, 0.1}; !reflect.DeepEqual(roundTrip, want) {
+				t.Errorf("got %v after encoding, want %v", roundTrip, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package jseq
+
+import (
+	"iter"
+	"math"
+	"math/big"
+)
+
+// NumberStats accumulates summary statistics over a stream of [Number] values.
+// The zero value is ready to use.
+//
+// While every value added is an integer,
+// the sum is accumulated exactly, with arbitrary precision.
+// Once a non-integer is added,
+// the sum falls back to float64 arithmetic
+// (using compensated summation to limit rounding error)
+// and is no longer exact.
+// Min and Max are always exact,
+// since they are chosen by comparing the numbers' decimal representations.
+type NumberStats struct {
+	Count int
+	Min   Number
+	Max   Number
+
+	inexact bool
+	intSum  big.Int
+	fSum    float64
+	fComp   float64 // Kahan compensation term
+}
+
+// Add adds n to the statistics.
+func (s *NumberStats) Add(n Number) {
+	r, ok := n.rat()
+	if !ok {
+		// Not a finite number. It cannot be compared or summed exactly.
+		s.Count++
+		s.addFloat(n.f)
+		return
+	}
+
+	if s.Count == 0 {
+		s.Min, s.Max = n, n
+	} else {
+		if minR, ok := s.Min.rat(); !ok || r.Cmp(minR) < 0 {
+			s.Min = n
+		}
+		if maxR, ok := s.Max.rat(); !ok || r.Cmp(maxR) > 0 {
+			s.Max = n
+		}
+	}
+	s.Count++
+
+	if !s.inexact && r.IsInt() {
+		s.intSum.Add(&s.intSum, r.Num())
+		return
+	}
+	s.addFloat(n.f)
+}
+
+func (s *NumberStats) addFloat(f float64) {
+	if !s.inexact {
+		s.inexact = true
+		s.fSum, _ = new(big.Float).SetInt(&s.intSum).Float64()
+	}
+	y := f - s.fComp
+	t := s.fSum + y
+	s.fComp = (t - s.fSum) - y
+	s.fSum = t
+}
+
+// Exact tells whether [NumberStats.Sum] is exact,
+// which is the case as long as only integers have been added.
+func (s *NumberStats) Exact() bool {
+	return !s.inexact
+}
+
+// Sum returns the sum of the numbers added.
+// See [NumberStats.Exact].
+func (s *NumberStats) Sum() Number {
+	if s.inexact {
+		return Float(s.fSum)
+	}
+	return numberFromRaw(s.intSum.String())
+}
+
+// Mean returns the arithmetic mean of the numbers added,
+// or NaN if there are none.
+func (s *NumberStats) Mean() float64 {
+	if s.Count == 0 {
+		return math.NaN()
+	}
+	if s.inexact {
+		return s.fSum / float64(s.Count)
+	}
+	q := new(big.Rat).SetFrac(&s.intSum, big.NewInt(int64(s.Count)))
+	f, _ := q.Float64()
+	return f
+}
+
+// rat returns n as an exact rational number.
+// The boolean result is false if n is not finite.
+func (n Number) rat() (*big.Rat, bool) {
+	return new(big.Rat).SetString(n.raw)
+}
+
+// NumericSummaries consumes a sequence of pointer/value pairs as produced by [Values]
+// and accumulates a [NumberStats] for the numbers at each of the given paths
+// (see [Pointer.Path]).
+// If no paths are given,
+// every path at which a number appears is summarized.
+// Values other than numbers are ignored.
+func NumericSummaries(values iter.Seq2[Pointer, any], paths ...string) map[string]*NumberStats {
+	result := make(map[string]*NumberStats)
+	for _, path := range paths {
+		result[path] = new(NumberStats)
+	}
+
+	for pointer, val := range values {
+		if e, ok := val.(Expanded); ok {
+			val = e.Value
+		}
+		n, ok := val.(Number)
+		if !ok {
+			continue
+		}
+		path := pointer.Path()
+		s := result[path]
+		if s == nil {
+			if len(paths) > 0 {
+				continue
+			}
+			s = new(NumberStats)
+			result[path] = s
+		}
+		s.Add(n)
+	}
+
+	return result
+}
@@ -0,0 +1,61 @@
+package jseq_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestNumberStats(t *testing.T) {
+	var s jseq.NumberStats
+	for _, n := range []jseq.Number{jseq.Uint(18446744073709551615), jseq.Int(-5), jseq.Uint(18446744073709551615)} {
+		s.Add(n)
+	}
+	if !s.Exact() {
+		t.Error("got inexact sum, want exact")
+	}
+	if got := s.Sum().String(); got != "36893488147419103225" {
+		t.Errorf("got sum %s, want 36893488147419103225", got)
+	}
+	if got := s.Min.String(); got != "-5" {
+		t.Errorf("got min %s, want -5", got)
+	}
+	if got := s.Max.String(); got != "18446744073709551615" {
+		t.Errorf("got max %s, want 18446744073709551615", got)
+	}
+
+	s.Add(jseq.Float(0.5))
+	if s.Exact() {
+		t.Error("got exact sum, want inexact")
+	}
+	if got, want := s.Mean(), 36893488147419103225.5/4; got != want {
+		t.Errorf("got mean %v, want %v", got, want)
+	}
+}
+
+func TestNumericSummaries(t *testing.T) {
+	const inp = `{"price": 3, "qty": 1} {"price": 1.5, "qty": 2} {"price": "n/a", "qty": 3}`
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+	got := jseq.NumericSummaries(values)
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("got %d paths, want 2", len(got))
+	}
+	price := got["/price"]
+	if price.Count != 2 || price.Exact() || price.Sum().Float() != 4.5 || price.Min.String() != "1.5" {
+		t.Errorf("got /price stats count=%d exact=%v sum=%s min=%s", price.Count, price.Exact(), price.Sum(), price.Min)
+	}
+	qty := got["/qty"]
+	if qty.Count != 3 || !qty.Exact() || qty.Sum().String() != "6" || qty.Mean() != 2 {
+		t.Errorf("got /qty stats count=%d exact=%v sum=%s mean=%v", qty.Count, qty.Exact(), qty.Sum(), qty.Mean())
+	}
+}
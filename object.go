@@ -0,0 +1,96 @@
+package jseq
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Object is a JSON object that remembers the order of its members.
+// [Values] produces objects of this type,
+// in place of map[string]any,
+// when given the [OrderedObjects] option.
+//
+// The zero Object is empty and ready to use.
+// Functions in this package that handle map[string]any
+// (such as [Marshal], [Walk], [Clone], [Diff], and [Pointer.Locate])
+// also handle *Object,
+// visiting its members in order.
+type Object struct {
+	keys []string
+	m    map[string]any
+}
+
+// OrderedObjects is an [Option] that causes [Values] to produce objects as [*Object]
+// instead of map[string]any,
+// preserving the order of their keys in the input.
+// When a key appears more than once,
+// it keeps the position of its first appearance.
+//
+// Objects converted by [MapKeys] or [SparseArrays] are not affected.
+func OrderedObjects() Option {
+	return func(c *config) {
+		c.orderedObjects = true
+	}
+}
+
+// Len returns the number of members in o.
+func (o *Object) Len() int {
+	return len(o.keys)
+}
+
+// Keys returns the keys of o in order.
+// The caller must not modify the result.
+func (o *Object) Keys() []string {
+	return o.keys
+}
+
+// Get returns the value of the member of o with the given key,
+// and whether there is one.
+func (o *Object) Get(key string) (any, bool) {
+	val, ok := o.m[key]
+	return val, ok
+}
+
+// Set sets the value of the member of o with the given key.
+// A new key goes at the end.
+func (o *Object) Set(key string, val any) {
+	if o.m == nil {
+		o.m = make(map[string]any)
+	}
+	if _, ok := o.m[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.m[key] = val
+}
+
+// Delete removes the member of o with the given key, if there is one.
+func (o *Object) Delete(key string) {
+	if _, ok := o.m[key]; !ok {
+		return
+	}
+	delete(o.m, key)
+	o.keys = slices.DeleteFunc(o.keys, func(k string) bool { return k == key })
+}
+
+// All produces the members of o in order.
+func (o *Object) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for _, key := range o.keys {
+			if !yield(key, o.m[key]) {
+				return
+			}
+		}
+	}
+}
+
+// Map returns the members of o as a map.
+// The caller must not modify the result.
+func (o *Object) Map() map[string]any {
+	return o.m
+}
+
+// clone returns a shallow copy of o.
+func (o *Object) clone() *Object {
+	return &Object{keys: slices.Clone(o.keys), m: maps.Clone(o.m)}
+}
@@ -0,0 +1,121 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestOrderedObjects(t *testing.T) {
+	const inp = `{"zeta": 1, "alpha": {"y": true, "x": null}, "mid": [{"b": 2, "a": 3}], "zeta": 4}`
+
+	tokens, _ := jseq.Tokens(strings.NewReader(inp), jsontext.AllowDuplicateNames(true))
+	values, errptr := jseq.Values(tokens, jseq.OrderedObjects())
+
+	var (
+		rec      any
+		pointers []string
+	)
+	for pointer, val := range values {
+		pointers = append(pointers, string(pointer.Text()))
+		rec = val
+	}
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+
+	obj, ok := rec.(*jseq.Object)
+	if !ok {
+		t.Fatalf("got %T, want *jseq.Object", rec)
+	}
+	if got, want := obj.Keys(), []string{"zeta", "alpha", "mid"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got keys %v, want %v", got, want)
+	}
+	if got, ok := obj.Get("zeta"); !ok || !reflect.DeepEqual(got, jseq.Int(4)) {
+		t.Errorf("got zeta %v, want 4", got)
+	}
+	if got := jseq.TypeName(obj); got != "object" {
+		t.Errorf("got type name %q, want object", got)
+	}
+
+	b, err := jseq.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"zeta":4,"alpha":{"y":true,"x":null},"mid":[{"b":2,"a":3}]}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	val, err := jseq.Pointer{"mid", 0, "a"}.Locate(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val, jseq.Int(3)) {
+		t.Errorf("got %v, want 3", val)
+	}
+
+	var walked []string
+	for pointer := range jseq.Walk(obj) {
+		walked = append(walked, string(pointer.Text()))
+	}
+	if want := []string{"/zeta", "/alpha/y", "/alpha/x", "/alpha", "/mid/0/b", "/mid/0/a", "/mid/0", "/mid", ""}; !reflect.DeepEqual(walked, want) {
+		t.Errorf("got walk order %v, want %v", walked, want)
+	}
+
+	if want := []string{"/zeta", "/alpha/y", "/alpha/x", "/alpha", "/mid/0/b", "/mid/0/a", "/mid/0", "/mid", "/zeta", ""}; !reflect.DeepEqual(pointers, want) {
+		t.Errorf("got parse order %v, want %v", pointers, want)
+	}
+}
+
+func TestObjectEdit(t *testing.T) {
+	var obj jseq.Object
+	obj.Set("b", jseq.Int(1))
+	obj.Set("a", jseq.Int(2))
+	obj.Set("c", jseq.Int(3))
+	obj.Set("b", jseq.Int(4))
+
+	if got, want := obj.Keys(), []string{"b", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got keys %v, want %v", got, want)
+	}
+
+	updated, err := jseq.Pointer{"a"}.Set(&obj, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err = jseq.Pointer{"d", "e"}.Set(updated, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err = jseq.Pointer{"b"}.Remove(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := jseq.Marshal(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"a":"new","c":3,"d":{"e":true}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// The original is unchanged.
+	if got, want := obj.Len(), 3; got != want {
+		t.Errorf("got length %d, want %d", got, want)
+	}
+	if got, _ := obj.Get("a"); !reflect.DeepEqual(got, jseq.Int(2)) {
+		t.Errorf("got a %v, want 2", got)
+	}
+
+	clone := jseq.Clone(&obj).(*jseq.Object)
+	clone.Delete("c")
+	if obj.Len() != 3 || clone.Len() != 2 {
+		t.Errorf("got lengths %d and %d, want 3 and 2", obj.Len(), clone.Len())
+	}
+
+	if changes := jseq.Diff(&obj, map[string]any{"b": jseq.Int(4), "a": jseq.Int(2), "c": jseq.Int(3)}); len(changes) != 0 {
+		t.Errorf("got changes %v, want none", changes)
+	}
+}
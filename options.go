@@ -0,0 +1,46 @@
+package jseq
+
+import (
+	"context"
+	"time"
+)
+
+// Option is the type of an option that can be passed to [Values].
+type Option func(*config)
+
+type config struct {
+	expandStrings  bool
+	expandAt       []Pointer
+	keepOriginal   bool
+	vars           *varConfig
+	include        *includeConfig
+	share          *SubtreeCache
+	summary        *Summary
+	onPresence     func(Presence)
+	onWarning      func(Warning)
+	deadLetters    *deadLetterConfig
+	keyConverters  []keyConverter
+	canonicalKeys  map[string]string
+	leafOnly       bool
+	unmaterialized bool
+	preOrder       bool
+	maxDepth       int
+	memoryBudget   int64
+	duplicateKeys  DuplicateKeyPolicy
+	orderedObjects bool
+	v1Values       bool
+	numberMode     NumberMode
+	clonePointers  bool
+	arena          *Arena
+	ctx            context.Context // from ValuesContext
+	objectFactory  func(Pointer) ObjectBuilder
+	arrayFactory   func(Pointer) ArrayBuilder
+
+	sparse          *sparseConfig
+	arraysAsObjects []Pointer
+
+	recordTimeout time.Duration
+
+	heartbeat   time.Duration
+	onHeartbeat func(idle time.Duration)
+}
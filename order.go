@@ -0,0 +1,86 @@
+package jseq
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// KeyOrder is a comparison function for object keys,
+// returning a negative number when a sorts before b,
+// a positive number when a sorts after b,
+// and zero when they are equal.
+// It determines the order of object members
+// in [KeyOrder.Encode], [KeyOrder.Walk], and so on.
+type KeyOrder func(a, b string) int
+
+var (
+	// LexicalOrder sorts keys bytewise.
+	// It is the order used by [SortedKeys], [Encode], and [Walk].
+	LexicalOrder KeyOrder = strings.Compare
+
+	// NaturalOrder sorts runs of decimal digits within keys by numeric value,
+	// and everything else bytewise,
+	// so that "2" sorts before "10" and "item9" before "item10".
+	// It is suitable for human-sensible output of objects keyed by IDs.
+	NaturalOrder KeyOrder = compareNatural
+)
+
+// Keys returns the keys of obj sorted in order o.
+func (o KeyOrder) Keys(obj map[string]any) []string {
+	return slices.SortedFunc(maps.Keys(obj), o)
+}
+
+func compareNatural(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			var da, db string
+			da, i = digitRun(a, i)
+			db, j = digitRun(b, j)
+			if c := compareDigits(da, db); c != 0 {
+				return c
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	// One is a prefix of the other, modulo leading zeros in digit runs.
+	if c := (len(a) - i) - (len(b) - j); c != 0 {
+		return c
+	}
+	// Numerically equal with different leading zeros.
+	// Fall back to bytewise order for a total ordering.
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// digitRun returns the run of digits in s starting at i,
+// and the position after it.
+func digitRun(s string, i int) (string, int) {
+	j := i
+	for j < len(s) && isDigit(s[j]) {
+		j++
+	}
+	return s[i:j], j
+}
+
+// compareDigits compares two nonempty strings of decimal digits by numeric value.
+func compareDigits(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,48 @@
+package jseq_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestNaturalOrder(t *testing.T) {
+	keys := []string{"10", "2", "item10", "item9", "b", "a", "002", "1", "item09x", "item9a"}
+	slices.SortFunc(keys, jseq.NaturalOrder)
+	want := []string{"1", "002", "2", "10", "a", "b", "item9", "item9a", "item09x", "item10"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("got %v, want %v", keys, want)
+	}
+}
+
+func TestKeyOrderMarshal(t *testing.T) {
+	val := map[string]any{
+		"10": "x",
+		"9":  map[string]any{"b2": true, "b10": false},
+	}
+
+	got, err := jseq.NaturalOrder.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"9":{"b2":true,"b10":false},"10":"x"}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	got, err = jseq.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"10":"x","9":{"b10":false,"b2":true}}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	var pointers []string
+	for ptr := range jseq.NaturalOrder.Walk(val) {
+		pointers = append(pointers, string(ptr.Text()))
+	}
+	if want := []string{"/9/b2", "/9/b10", "/9", "/10", ""}; !slices.Equal(pointers, want) {
+		t.Errorf("got %v, want %v", pointers, want)
+	}
+}
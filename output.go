@@ -0,0 +1,164 @@
+package jseq
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// OutputFormat is an output format for [FormatSink].
+type OutputFormat int
+
+const (
+	// NDJSON is newline-delimited JSON:
+	// each top-level value on a single line.
+	NDJSON OutputFormat = iota
+
+	// Array is a single JSON array containing all the top-level values.
+	Array
+
+	// Concatenated is a sequence of top-level values,
+	// each beginning on a new line
+	// but possibly spanning several
+	// (e.g. with [jsontext.Multiline]).
+	Concatenated
+
+	// JSONSeq is a JSON text sequence, RFC 7464.
+	JSONSeq
+
+	// Dict is the dictionary-compressed format of [DictSink].
+	Dict
+
+	// Auto stands for the format matching an input format.
+	// Resolve it with [OutputFormat.Match].
+	// If unresolved, it is the same as NDJSON.
+	Auto
+)
+
+// String returns the name of f.
+func (f OutputFormat) String() string {
+	switch f {
+	case NDJSON:
+		return "ndjson"
+	case Array:
+		return "array"
+	case Concatenated:
+		return "concatenated"
+	case JSONSeq:
+		return "json-seq"
+	case Dict:
+		return "jseq-dict"
+	case Auto:
+		return "auto"
+	}
+	return fmt.Sprintf("OutputFormat(%d)", int(f))
+}
+
+// Match resolves [Auto] to the output format matching input,
+// the name of an input format as returned by [Open].
+// Other formats are returned unchanged.
+//
+// This makes a format-converting tool a matter of opening the input with [Open]
+// and passing f.Match(name) to [FormatSink]
+// for whatever f the user chooses.
+func (f OutputFormat) Match(input string) OutputFormat {
+	if f != Auto {
+		return f
+	}
+	switch input {
+	case "json-seq":
+		return JSONSeq
+	case "jseq-dict":
+		return Dict
+	}
+	return NDJSON
+}
+
+// FormatSink returns a [Sink] that writes each top-level value it consumes to w
+// in the given format,
+// using [Encode] with the given options.
+// Values that are not top-level are ignored.
+//
+// For NDJSON and JSONSeq,
+// the options may not make a value span multiple lines;
+// [jsontext.Multiline] is overridden.
+// For Dict, the options are ignored.
+//
+// Closing the sink completes the output
+// (e.g. with the closing bracket of an Array)
+// but does not close w.
+func FormatSink(w io.Writer, format OutputFormat, opts ...jsontext.Options) Sink {
+	switch format {
+	case Array:
+		return &arraySink{enc: jsontext.NewEncoder(w, opts...)}
+	case Concatenated:
+		return EncoderSink(w, opts...)
+	case JSONSeq:
+		return &lineSink{w: w, prefix: []byte{recordSeparator}, opts: opts}
+	case Dict:
+		return DictSink(w)
+	}
+	return &lineSink{w: w, opts: opts}
+}
+
+// lineSink writes top-level values one per line,
+// each with an optional prefix.
+type lineSink struct {
+	w      io.Writer
+	prefix []byte
+	opts   []jsontext.Options
+	buf    bytes.Buffer
+	enc    *jsontext.Encoder
+}
+
+func (s *lineSink) Consume(pointer Pointer, val any) error {
+	if len(pointer) > 0 {
+		return nil
+	}
+	if s.enc == nil {
+		s.enc = jsontext.NewEncoder(&s.buf, append(slices.Clip(s.opts), jsontext.Multiline(false))...)
+	}
+	s.buf.Reset()
+	s.buf.Write(s.prefix)
+	if err := encodeValue(s.enc, val); err != nil {
+		return err
+	}
+	_, err := s.w.Write(s.buf.Bytes())
+	return err
+}
+
+func (s *lineSink) Close() error {
+	return nil
+}
+
+type arraySink struct {
+	enc     *jsontext.Encoder
+	started bool
+}
+
+func (s *arraySink) Consume(pointer Pointer, val any) error {
+	if len(pointer) > 0 {
+		return nil
+	}
+	if err := s.start(); err != nil {
+		return err
+	}
+	return encodeValue(s.enc, val)
+}
+
+func (s *arraySink) Close() error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	return s.enc.WriteToken(jsontext.EndArray)
+}
+
+func (s *arraySink) start() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+	return s.enc.WriteToken(jsontext.BeginArray)
+}
@@ -0,0 +1,111 @@
+package jseq_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json/jsontext"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestFormatSink(t *testing.T) {
+	const inp = `{"a": [1, 2]} "x" {"b": {}}`
+
+	cases := []struct {
+		format jseq.OutputFormat
+		opts   []jsontext.Options
+		want   string
+	}{{
+		format: jseq.NDJSON,
+		want:   "{\"a\":[1,2]}\n\"x\"\n{\"b\":{}}\n",
+	}, {
+		format: jseq.NDJSON,
+		opts:   []jsontext.Options{jsontext.Multiline(true)},
+		want:   "{\"a\":[1,2]}\n\"x\"\n{\"b\":{}}\n",
+	}, {
+		format: jseq.Array,
+		want:   "[{\"a\":[1,2]},\"x\",{\"b\":{}}]\n",
+	}, {
+		format: jseq.Concatenated,
+		opts:   []jsontext.Options{jsontext.Multiline(true), jsontext.WithIndent("  ")},
+		want:   "{\n  \"a\": [\n    1,\n    2\n  ]\n}\n\"x\"\n{\n  \"b\": {}\n}\n",
+	}, {
+		format: jseq.JSONSeq,
+		want:   "\x1e{\"a\":[1,2]}\n\x1e\"x\"\n\x1e{\"b\":{}}\n",
+	}, {
+		format: jseq.Auto,
+		want:   "{\"a\":[1,2]}\n\"x\"\n{\"b\":{}}\n",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.format.String(), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			sink := jseq.FormatSink(buf, tc.format, tc.opts...)
+			if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), sink, nil); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.want {
+				t.Errorf("got %q, want %q", buf.String(), tc.want)
+			}
+
+			// The output reads back as the same records.
+			src, _, err := jseq.Open(buf, "", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := records(t, src)
+			if tc.format == jseq.Array {
+				got = got[0].([]any)
+			}
+			if want := records(t, jseq.ReaderSource(strings.NewReader(inp))); !reflect.DeepEqual(got, want) {
+				t.Errorf("read back %v, want %v", got, want)
+			}
+		})
+	}
+
+	buf := new(bytes.Buffer)
+	if err := jseq.FormatSink(buf, jseq.Array).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("got %q for empty array, want %q", got, "[]\n")
+	}
+}
+
+func TestFormatSinkOptionsUnchanged(t *testing.T) {
+	// The caller's options must not be overwritten
+	// by the Multiline option that line-oriented formats add.
+	opts := make([]jsontext.Options, 1, 2)
+	opts[0] = jsontext.SpaceAfterColon(true)
+
+	var buf bytes.Buffer
+	sink := jseq.FormatSink(&buf, jseq.JSONSeq, opts...)
+	if err := sink.Consume(nil, map[string]any{"a": jseq.Int(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if extra := opts[:2][1]; extra != nil {
+		t.Errorf("caller's options slice was modified: %v", extra)
+	}
+}
+
+func TestOutputFormatMatch(t *testing.T) {
+	cases := []struct {
+		format jseq.OutputFormat
+		input  string
+		want   jseq.OutputFormat
+	}{
+		{jseq.Auto, "json", jseq.NDJSON},
+		{jseq.Auto, "json-seq", jseq.JSONSeq},
+		{jseq.Auto, "jseq-dict", jseq.Dict},
+		{jseq.Auto, "unknown", jseq.NDJSON},
+		{jseq.Array, "json-seq", jseq.Array},
+	}
+	for _, tc := range cases {
+		if got := tc.format.Match(tc.input); got != tc.want {
+			t.Errorf("%s.Match(%q): got %s, want %s", tc.format, tc.input, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package jseq
+
+import (
+	"os"
+	"strings"
+
+	"github.com/bobg/errors"
+)
+
+// ConfigSource is one layer of configuration for [LoadOverlay].
+type ConfigSource struct {
+	// Name identifies the source in provenance reports.
+	Name string
+
+	// Load produces the source's value.
+	// A nil result with a nil error means the source is absent and is skipped.
+	Load func() (any, error)
+}
+
+// FileSource is a [ConfigSource] that reads a JSON document from the named file.
+// A nonexistent file is treated as an absent source.
+func FileSource(path string) ConfigSource {
+	return ConfigSource{
+		Name: path,
+		Load: func() (any, error) {
+			f, err := os.Open(path)
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return decodeValue(f)
+		},
+	}
+}
+
+// EnvSource is a [ConfigSource] that parses the JSON document in the named environment variable.
+// An unset or empty variable is treated as an absent source.
+func EnvSource(name string) ConfigSource {
+	return ConfigSource{
+		Name: "$" + name,
+		Load: func() (any, error) {
+			s := os.Getenv(name)
+			if s == "" {
+				return nil, nil
+			}
+			return decodeValue(strings.NewReader(s))
+		},
+	}
+}
+
+// ValueSource is a [ConfigSource] that supplies v,
+// e.g. an object built from command-line flags.
+func ValueSource(name string, v any) ConfigSource {
+	return ConfigSource{
+		Name: name,
+		Load: func() (any, error) { return v, nil },
+	}
+}
+
+// Overlay is the result of [LoadOverlay].
+type Overlay struct {
+	// Value is the merged configuration.
+	Value any
+
+	names  []string
+	supply map[string]int // pointer text -> index into names
+}
+
+// LoadOverlay loads the given sources in order
+// and merges them (see [Merge]),
+// so that later sources take precedence over earlier ones.
+// It keeps track of which source supplied each part of the result;
+// see [Overlay.Source].
+func LoadOverlay(sources ...ConfigSource) (*Overlay, error) {
+	result := &Overlay{supply: make(map[string]int)}
+
+	for _, src := range sources {
+		val, err := src.Load()
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading %s", src.Name)
+		}
+		if val == nil {
+			continue
+		}
+
+		idx := len(result.names)
+		result.names = append(result.names, src.Name)
+
+		if len(result.names) == 1 {
+			result.Value = val
+			result.record(nil, val, idx)
+			continue
+		}
+
+		result.Value = merge(result.Value, val, nil, func(pointer Pointer, val any, merged bool) {
+			if merged {
+				// An object merged from more than one source.
+				result.supply[string(pointer.Text())] = idx
+				return
+			}
+			result.record(pointer, val, idx)
+		})
+	}
+
+	return result, nil
+}
+
+// record notes that source idx supplied val at pointer,
+// and all of its parts.
+func (o *Overlay) record(pointer Pointer, val any, idx int) {
+	prefix := string(pointer.Text())
+
+	// Forget what earlier sources supplied within a replaced value.
+	for k := range o.supply {
+		if strings.HasPrefix(k, prefix+"/") {
+			delete(o.supply, k)
+		}
+	}
+
+	for sub := range Walk(val) {
+		o.supply[prefix+string(sub.Text())] = idx
+	}
+}
+
+// Source returns the name of the source that supplied the value at ptr,
+// and false if the value does not exist.
+// For an object merged from more than one source,
+// that is the last source to contribute to it.
+func (o *Overlay) Source(ptr Pointer) (string, bool) {
+	if v, err := ptr.Locate(o.Value); err != nil || (v == nil && len(ptr) > 0) {
+		return "", false
+	}
+	for n := len(ptr); n >= 0; n-- {
+		if idx, ok := o.supply[string(ptr[:n].Text())]; ok {
+			return o.names[idx], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,58 @@
+package jseq_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestLoadOverlay(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(file, []byte(`{"db": {"host": "localhost", "port": 5432}, "tags": ["a"], "debug": false}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_CONFIG_JSON", `{"db": {"host": "db.example.com"}, "tags": ["b", "c"]}`)
+
+	ov, err := jseq.LoadOverlay(
+		jseq.FileSource(file),
+		jseq.FileSource(filepath.Join(dir, "missing.json")),
+		jseq.EnvSource("TEST_CONFIG_JSON"),
+		jseq.ValueSource("flags", map[string]any{"debug": true}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"db":    map[string]any{"host": "db.example.com", "port": jseq.Int(5432)},
+		"tags":  []any{"b", "c"},
+		"debug": true,
+	}
+	if !reflect.DeepEqual(ov.Value, want) {
+		t.Errorf("got %v, want %v", ov.Value, want)
+	}
+
+	cases := []struct {
+		ptr    jseq.Pointer
+		want   string
+		wantOK bool
+	}{
+		{ptr: jseq.Pointer{"db", "host"}, want: "$TEST_CONFIG_JSON", wantOK: true},
+		{ptr: jseq.Pointer{"db", "port"}, want: file, wantOK: true},
+		{ptr: jseq.Pointer{"db"}, want: "$TEST_CONFIG_JSON", wantOK: true},
+		{ptr: jseq.Pointer{"tags", 1}, want: "$TEST_CONFIG_JSON", wantOK: true},
+		{ptr: jseq.Pointer{"debug"}, want: "flags", wantOK: true},
+		{ptr: nil, want: "flags", wantOK: true},
+		{ptr: jseq.Pointer{"nope"}},
+	}
+	for _, tc := range cases {
+		got, ok := ov.Source(tc.ptr)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("Source(%v) = %q, %v; want %q, %v", tc.ptr, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
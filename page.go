@@ -0,0 +1,286 @@
+package jseq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bobg/errors"
+)
+
+// ArrayIndex records where the elements of a JSON array begin in its input,
+// so that a range of them can be read without scanning all those before it.
+// Create one with [IndexArray],
+// and use it with [ArrayIndex.Page].
+//
+// Its fields are exported so that it can be saved
+// (e.g. with [Marshal] or [encoding/json.Marshal])
+// and reused as long as the input does not change.
+type ArrayIndex struct {
+	// Len is the number of elements in the array.
+	Len int `json:"len"`
+
+	// Stride is the number of elements per entry in Offsets.
+	Stride int `json:"stride"`
+
+	// Offsets[i] is the input offset just after the element preceding element i*Stride
+	// (or after the open bracket, for element 0).
+	Offsets []int64 `json:"offsets"`
+}
+
+// IndexArray reads the JSON document in r
+// and produces an [ArrayIndex] for the array at the given pointer in it,
+// recording the position of every stride'th element.
+// A larger stride makes a smaller index
+// at the cost of scanning up to stride-1 elements on each lookup.
+//
+// IndexArray does not build the array's elements,
+// so it uses memory proportional to the size of the index,
+// not of the input.
+// Offsets are relative to the start of r.
+func IndexArray(r io.Reader, arrayPtr Pointer, stride int) (*ArrayIndex, error) {
+	dec := jsontext.NewDecoder(r)
+	if err := seekArray(dec, arrayPtr); err != nil {
+		return nil, err
+	}
+
+	idx := &ArrayIndex{Stride: max(stride, 1)}
+	for {
+		kind, err := peekKind(dec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading array element %d", idx.Len)
+		}
+		if kind == ']' {
+			return idx, nil
+		}
+		if idx.Len%idx.Stride == 0 {
+			idx.Offsets = append(idx.Offsets, dec.InputOffset())
+		}
+		if err := dec.SkipValue(); err != nil {
+			return nil, errors.Wrapf(err, "skipping array element %d", idx.Len)
+		}
+		idx.Len++
+	}
+}
+
+// Page reads the JSON document in rs from its beginning
+// and returns up to limit elements of the array at the given pointer,
+// starting with the element at position offset.
+// It returns fewer than limit elements (possibly none)
+// if the array ends first.
+// The opts are applied when decoding each element,
+// as in [Values].
+//
+// Page builds only the elements it returns,
+// so it can browse an array too large to load,
+// but it must scan all the elements before offset.
+// For repeated access to the same array,
+// build an [ArrayIndex] and use [ArrayIndex.Page].
+func Page(rs io.ReadSeeker, arrayPtr Pointer, offset, limit int, opts ...Option) ([]any, error) {
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "seeking to start")
+	}
+	dec := jsontext.NewDecoder(rs)
+	if err := seekArray(dec, arrayPtr); err != nil {
+		return nil, err
+	}
+	return readElements(dec, 0, offset, limit, opts)
+}
+
+// Page is like the function [Page],
+// but uses idx to find the requested elements of the array in rs
+// without scanning all those before them.
+// The contents of rs must be the ones from which idx was built.
+func (idx *ArrayIndex) Page(rs io.ReadSeeker, offset, limit int, opts ...Option) ([]any, error) {
+	if offset < 0 || offset >= idx.Len || limit <= 0 {
+		return nil, nil
+	}
+	dec, err := idx.decoderAt(rs, offset/idx.Stride)
+	if err != nil {
+		return nil, err
+	}
+	first := offset - offset%idx.Stride
+	return readElements(dec, first, offset%idx.Stride, limit, opts)
+}
+
+// rawPage is like [ArrayIndex.Page]
+// but does not decode the elements.
+func (idx *ArrayIndex) rawPage(rs io.ReadSeeker, offset, limit int) ([]jsontext.Value, error) {
+	if offset < 0 || offset >= idx.Len || limit <= 0 {
+		return nil, nil
+	}
+	dec, err := idx.decoderAt(rs, offset/idx.Stride)
+	if err != nil {
+		return nil, err
+	}
+	first := offset - offset%idx.Stride
+	return readRawElements(dec, first, offset%idx.Stride, limit)
+}
+
+// decoderAt returns a decoder positioned at element n*idx.Stride of the array in rs.
+func (idx *ArrayIndex) decoderAt(rs io.ReadSeeker, n int) (*jsontext.Decoder, error) {
+	if n >= len(idx.Offsets) {
+		return nil, fmt.Errorf("index has %d entries, want at least %d", len(idx.Offsets), n+1)
+	}
+	if _, err := rs.Seek(idx.Offsets[n], io.SeekStart); err != nil {
+		return nil, errors.Wrapf(err, "seeking to offset %d", idx.Offsets[n])
+	}
+
+	// Skip the separator before the element,
+	// then resume decoding as if at the start of an array.
+	br := bufio.NewReader(rs)
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading at offset %d", idx.Offsets[n])
+		}
+		if c == ',' {
+			break
+		}
+		if !isJSONSpace(c) {
+			if err := br.UnreadByte(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	dec := jsontext.NewDecoder(io.MultiReader(strings.NewReader("["), br))
+	if _, err := dec.ReadToken(); err != nil {
+		return nil, err
+	}
+	return dec, nil
+}
+
+// seekArray advances dec to just inside the array at pointer.
+func seekArray(dec *jsontext.Decoder, pointer Pointer) error {
+	for i, elt := range pointer {
+		tok, err := dec.ReadToken()
+		if err != nil {
+			return errors.Wrapf(err, "reading %q", pointer[:i].Text())
+		}
+		switch elt := elt.(type) {
+		case string:
+			if tok.Kind() != '{' {
+				return fmt.Errorf("%q is not an object", pointer[:i].Text())
+			}
+			for {
+				kind, err := peekKind(dec)
+				if err != nil {
+					return errors.Wrapf(err, "reading %q", pointer[:i].Text())
+				}
+				if kind == '}' {
+					return fmt.Errorf("no value at %q", pointer[:i+1].Text())
+				}
+				key, err := dec.ReadToken()
+				if err != nil {
+					return errors.Wrapf(err, "reading %q", pointer[:i].Text())
+				}
+				if key.String() == elt {
+					break
+				}
+				if err := dec.SkipValue(); err != nil {
+					return errors.Wrapf(err, "skipping %q", pointer[:i].Text().AppendToken(key.String()))
+				}
+			}
+
+		case int:
+			if tok.Kind() != '[' {
+				return fmt.Errorf("%q is not an array", pointer[:i].Text())
+			}
+			for range elt {
+				kind, err := peekKind(dec)
+				if err != nil {
+					return errors.Wrapf(err, "reading %q", pointer[:i].Text())
+				}
+				if kind == ']' {
+					return fmt.Errorf("no value at %q", pointer[:i+1].Text())
+				}
+				if err := dec.SkipValue(); err != nil {
+					return errors.Wrapf(err, "skipping element of %q", pointer[:i].Text())
+				}
+			}
+		}
+	}
+
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return errors.Wrapf(err, "reading %q", pointer.Text())
+	}
+	if tok.Kind() != '[' {
+		return fmt.Errorf("%q is not an array", pointer.Text())
+	}
+	return nil
+}
+
+// readElements skips n elements of the array that dec is in,
+// then reads and decodes up to limit more.
+// The next element in dec is element number first of the array.
+func readElements(dec *jsontext.Decoder, first, n, limit int, opts []Option) ([]any, error) {
+	raws, err := readRawElements(dec, first, n, limit)
+	if err != nil {
+		return nil, err
+	}
+	var result []any
+	for i, raw := range raws {
+		val, err := decodeValue(bytes.NewReader(raw), opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding array element %d", first+n+i)
+		}
+		result = append(result, val)
+	}
+	return result, nil
+}
+
+// readRawElements is like readElements
+// but does not decode the elements.
+func readRawElements(dec *jsontext.Decoder, first, n, limit int) ([]jsontext.Value, error) {
+	for i := range n {
+		kind, err := peekKind(dec)
+		if err != nil {
+			return nil, err
+		}
+		if kind == ']' {
+			return nil, nil
+		}
+		if err := dec.SkipValue(); err != nil {
+			return nil, errors.Wrapf(err, "skipping array element %d", first+i)
+		}
+	}
+
+	var result []jsontext.Value
+	for len(result) < limit {
+		kind, err := peekKind(dec)
+		if err != nil {
+			return nil, err
+		}
+		if kind == ']' {
+			break
+		}
+		raw, err := dec.ReadValue()
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading array element %d", first+n+len(result))
+		}
+		result = append(result, raw.Clone())
+	}
+	return result, nil
+}
+
+// peekKind is like [jsontext.Decoder.PeekKind]
+// but reports the error when there is no next token.
+func peekKind(dec *jsontext.Decoder) (jsontext.Kind, error) {
+	if kind := dec.PeekKind(); kind != 0 {
+		return kind, nil
+	}
+	_, err := dec.ReadToken()
+	if err == nil || errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return 0, err
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
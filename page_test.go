@@ -0,0 +1,80 @@
+package jseq_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestPage(t *testing.T) {
+	var buf strings.Builder
+	buf.WriteString(`{"meta": {"n": [1, 2]}, "data": [`)
+	for i := range 50 {
+		if i > 0 {
+			buf.WriteString(",\n  ")
+		}
+		fmt.Fprintf(&buf, `{"id": %d, "tags": ["t%d"]}`, i, i)
+	}
+	buf.WriteString(`], "after": true}`)
+	inp := buf.String()
+
+	want := func(offset, limit int) []any {
+		var result []any
+		for i := offset; i < min(offset+limit, 50); i++ {
+			result = append(result, map[string]any{"id": jseq.Int(int64(i)), "tags": []any{fmt.Sprintf("t%d", i)}})
+		}
+		return result
+	}
+
+	cases := []struct{ offset, limit int }{
+		{0, 5}, {3, 4}, {45, 10}, {49, 1}, {50, 3}, {7, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("page_%d_%d", tc.offset, tc.limit), func(t *testing.T) {
+			got, err := jseq.Page(strings.NewReader(inp), jseq.Pointer{"data"}, tc.offset, tc.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want(tc.offset, tc.limit)) {
+				t.Errorf("got %v, want %v", got, want(tc.offset, tc.limit))
+			}
+		})
+
+		for _, stride := range []int{1, 7} {
+			t.Run(fmt.Sprintf("index_%d_%d_%d", stride, tc.offset, tc.limit), func(t *testing.T) {
+				idx, err := jseq.IndexArray(strings.NewReader(inp), jseq.Pointer{"data"}, stride)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if idx.Len != 50 {
+					t.Errorf("got length %d, want 50", idx.Len)
+				}
+				got, err := idx.Page(strings.NewReader(inp), tc.offset, tc.limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want(tc.offset, tc.limit)) {
+					t.Errorf("got %v, want %v", got, want(tc.offset, tc.limit))
+				}
+			})
+		}
+	}
+
+	got, err := jseq.Page(strings.NewReader(inp), jseq.Pointer{"meta", "n"}, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []any{jseq.Int(2)}) {
+		t.Errorf("got %v, want [2]", got)
+	}
+
+	for _, pointer := range []jseq.Pointer{{"meta"}, {"missing"}, {"data", 99}} {
+		if _, err := jseq.Page(strings.NewReader(inp), pointer, 0, 1); err == nil {
+			t.Errorf("got no error for %q, want one", pointer.Text())
+		}
+	}
+}
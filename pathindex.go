@@ -0,0 +1,89 @@
+package jseq
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// PathIndex records the paths (see [Pointer.Path]) observed in a JSON stream
+// and how often each occurred,
+// and answers prefix queries against them.
+// It is meant for autocompleting pointer patterns (see [ParsePattern])
+// in editors and command-line tools.
+//
+// The zero PathIndex is empty and ready to use.
+// See also [IndexPaths].
+type PathIndex struct {
+	counts map[string]int
+	sorted []string // the keys of counts, in order; nil when stale
+}
+
+// PathCount is an entry in the result of [PathIndex.Complete].
+type PathCount struct {
+	Path  string
+	Count int
+}
+
+// IndexPaths consumes a sequence of pointer/value pairs as produced by [Values]
+// and returns a [PathIndex] of the paths in it.
+// Typically the sequence is a sample of a larger stream.
+func IndexPaths(values iter.Seq2[Pointer, any]) *PathIndex {
+	var x PathIndex
+	for pointer := range values {
+		x.Add(pointer)
+	}
+	return &x
+}
+
+// Add records one occurrence of the path of pointer.
+// The empty pointer, denoting a whole record, is ignored.
+func (x *PathIndex) Add(pointer Pointer) {
+	if len(pointer) == 0 {
+		return
+	}
+	path := pointer.Path()
+	if x.counts == nil {
+		x.counts = make(map[string]int)
+	}
+	if _, ok := x.counts[path]; !ok {
+		x.sorted = nil
+	}
+	x.counts[path]++
+}
+
+// Len returns the number of distinct paths in x.
+func (x *PathIndex) Len() int {
+	return len(x.counts)
+}
+
+// Count returns the number of occurrences of the given path.
+func (x *PathIndex) Count(path string) int {
+	return x.counts[path]
+}
+
+// Complete returns the paths in x beginning with prefix,
+// most frequent first,
+// with ties in path order.
+// The prefix need not end at a segment boundary,
+// so "/us" completes to "/user" and "/users/*/id".
+func (x *PathIndex) Complete(prefix string) []PathCount {
+	if x.sorted == nil && len(x.counts) > 0 {
+		x.sorted = slices.Sorted(maps.Keys(x.counts))
+	}
+	start, _ := slices.BinarySearch(x.sorted, prefix)
+
+	var result []PathCount
+	for _, path := range x.sorted[start:] {
+		if !strings.HasPrefix(path, prefix) {
+			break
+		}
+		result = append(result, PathCount{Path: path, Count: x.counts[path]})
+	}
+	slices.SortStableFunc(result, func(a, b PathCount) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	return result
+}
@@ -0,0 +1,73 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestPathIndex(t *testing.T) {
+	const inp = `
+{"user": {"id": 1, "name": "a"}, "tags": ["x", "y"]}
+{"user": {"id": 2}, "users": [{"id": 3}]}
+`
+
+	tokens, _ := jseq.Tokens(strings.NewReader(inp))
+	values, errptr := jseq.Values(tokens)
+	x := jseq.IndexPaths(values)
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+
+	if got := x.Len(); got != 8 {
+		t.Errorf("got %d paths, want 8", got)
+	}
+	if got := x.Count("/tags/*"); got != 2 {
+		t.Errorf("got count %d for /tags/*, want 2", got)
+	}
+
+	cases := []struct {
+		prefix string
+		want   []jseq.PathCount
+	}{{
+		prefix: "/us",
+		want: []jseq.PathCount{
+			{Path: "/user", Count: 2},
+			{Path: "/user/id", Count: 2},
+			{Path: "/user/name", Count: 1},
+			{Path: "/users", Count: 1},
+			{Path: "/users/*", Count: 1},
+			{Path: "/users/*/id", Count: 1},
+		},
+	}, {
+		prefix: "/users/",
+		want: []jseq.PathCount{
+			{Path: "/users/*", Count: 1},
+			{Path: "/users/*/id", Count: 1},
+		},
+	}, {
+		prefix: "/tags",
+		want: []jseq.PathCount{
+			{Path: "/tags/*", Count: 2},
+			{Path: "/tags", Count: 1},
+		},
+	}, {
+		prefix: "/nope",
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.prefix, func(t *testing.T) {
+			got := x.Complete(tc.prefix)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	var empty jseq.PathIndex
+	if got := empty.Complete(""); got != nil {
+		t.Errorf("got %v from empty index, want nil", got)
+	}
+}
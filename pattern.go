@@ -0,0 +1,264 @@
+package jseq
+
+import (
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// Pattern is a pattern that matches [Pointer] values.
+// Create one with [ParsePattern].
+//
+// The syntax of a pattern is that of a JSON pointer
+// (e.g. "/users/0/email"),
+// in which some kinds of path segment have special meaning:
+//
+//   - "*" matches any single object key or array index
+//   - "**" matches any sequence of zero or more keys and indexes
+//   - "{name}" is like "*" but also captures the key or index it matches
+//     under the given name (see [Pattern.Captures])
+//
+// Other segments match an object key with the same text,
+// or an array index with the same decimal representation.
+// As in a JSON pointer,
+// "~1" in a segment stands for a literal "/" and "~0" for a literal "~".
+//
+// By default, object keys must match segments exactly.
+// This can be relaxed with [KeyOption]s passed to [ParsePattern].
+type Pattern struct {
+	text string
+	segs []patternSeg
+	keys keyMatcher
+}
+
+type patternSeg struct {
+	kind segKind
+	lit  string
+	name string // for a capturing segAny
+}
+
+type segKind int
+
+const (
+	segLiteral segKind = iota
+	segAny             // *
+	segAnySeq          // **
+)
+
+// ParsePattern parses a [Pattern].
+// The input must be empty or begin with "/".
+// The options control how object keys are compared with pattern segments.
+func ParsePattern(s string, opts ...KeyOption) (Pattern, error) {
+	result := Pattern{text: s, keys: newKeyMatcher(opts)}
+	if s == "" {
+		return result, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return Pattern{}, fmt.Errorf("pattern %q does not begin with /", s)
+	}
+	names := make(map[string]bool)
+	for _, part := range strings.Split(s[1:], "/") {
+		switch {
+		case part == "*":
+			result.segs = append(result.segs, patternSeg{kind: segAny})
+		case part == "**":
+			result.segs = append(result.segs, patternSeg{kind: segAnySeq})
+		case len(part) > 2 && strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+			name := part[1 : len(part)-1]
+			if names[name] {
+				return Pattern{}, fmt.Errorf("pattern %q captures %q more than once", s, name)
+			}
+			names[name] = true
+			result.segs = append(result.segs, patternSeg{kind: segAny, name: name})
+		default:
+			lit := strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+			result.segs = append(result.segs, patternSeg{kind: segLiteral, lit: lit})
+		}
+	}
+	return result, nil
+}
+
+// MustParsePattern is like [ParsePattern] but panics on error.
+func MustParsePattern(s string, opts ...KeyOption) Pattern {
+	p, err := ParsePattern(s, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the text from which p was parsed.
+func (p Pattern) String() string {
+	return p.text
+}
+
+// Match tells whether p matches ptr.
+func (p Pattern) Match(ptr Pointer) bool {
+	states := p.run(ptr, nil)
+	return states.has(len(p.segs))
+}
+
+// MatchPrefix tells whether p matches ptr or any prefix of ptr;
+// that is, whether ptr is inside a subtree matched by p.
+func (p Pattern) MatchPrefix(ptr Pointer) bool {
+	var found bool
+	p.run(ptr, func(states stateSet) bool {
+		found = states.has(len(p.segs))
+		return !found
+	})
+	return found
+}
+
+// MatchBelow tells whether p could match some pointer that extends ptr;
+// that is, whether ptr is an ancestor of a location p might match.
+func (p Pattern) MatchBelow(ptr Pointer) bool {
+	states := p.run(ptr, nil)
+	for s := range states {
+		if s < len(p.segs) {
+			return true
+		}
+	}
+	return false
+}
+
+// Captures matches p against ptr
+// and, if it matches,
+// returns the keys and indexes matched by the capturing segments of p,
+// such as "{id}" in "/users/{id}/email",
+// mapped by name.
+// An array index is captured as its decimal representation.
+//
+// When a pointer can match in more than one way,
+// because of "**" segments,
+// each "**" matches as few elements as possible.
+//
+// The result is nil if p has no capturing segments.
+func (p Pattern) Captures(ptr Pointer) (map[string]string, bool) {
+	var caps map[string]string
+	for _, seg := range p.segs {
+		if seg.name != "" {
+			caps = make(map[string]string)
+			break
+		}
+	}
+	if !p.capture(p.segs, ptr, caps) {
+		return nil, false
+	}
+	return caps, true
+}
+
+// capture is the backtracking matcher behind [Pattern.Captures].
+func (p Pattern) capture(segs []patternSeg, ptr Pointer, caps map[string]string) bool {
+	if len(segs) == 0 {
+		return len(ptr) == 0
+	}
+	seg := segs[0]
+	if seg.kind == segAnySeq {
+		for i := 0; i <= len(ptr); i++ {
+			if p.capture(segs[1:], ptr[i:], caps) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(ptr) == 0 {
+		return false
+	}
+	switch seg.kind {
+	case segAny:
+		if seg.name != "" {
+			caps[seg.name] = fmt.Sprint(ptr[0])
+		}
+	case segLiteral:
+		if !p.segMatches(seg.lit, ptr[0]) {
+			return false
+		}
+	}
+	return p.capture(segs[1:], ptr[1:], caps)
+}
+
+// Select consumes a sequence of pointer/value pairs,
+// as from [Values],
+// and produces the values whose pointers match p,
+// each paired with its captures (see [Pattern.Captures]).
+func (p Pattern) Select(values iter.Seq2[Pointer, any]) iter.Seq2[map[string]string, any] {
+	return func(yield func(map[string]string, any) bool) {
+		for pointer, val := range values {
+			caps, ok := p.Captures(pointer)
+			if !ok {
+				continue
+			}
+			if !yield(caps, val) {
+				return
+			}
+		}
+	}
+}
+
+type stateSet map[int]struct{}
+
+func (s stateSet) has(n int) bool {
+	_, ok := s[n]
+	return ok
+}
+
+// run simulates p as a nondeterministic automaton on the elements of ptr,
+// returning the set of states reached.
+// State i means the first i segments of p have been matched.
+// If step is non-nil,
+// it is called with the initial states and after each element,
+// and run stops early if it returns false.
+func (p Pattern) run(ptr Pointer, step func(stateSet) bool) stateSet {
+	states := p.closure(stateSet{0: {}})
+	if step != nil && !step(states) {
+		return states
+	}
+	for _, elt := range ptr {
+		next := make(stateSet)
+		for s := range states {
+			if s >= len(p.segs) {
+				continue
+			}
+			seg := p.segs[s]
+			switch seg.kind {
+			case segAnySeq:
+				next[s] = struct{}{}
+			case segAny:
+				next[s+1] = struct{}{}
+			case segLiteral:
+				if p.segMatches(seg.lit, elt) {
+					next[s+1] = struct{}{}
+				}
+			}
+		}
+		states = p.closure(next)
+		if len(states) == 0 {
+			break
+		}
+		if step != nil && !step(states) {
+			break
+		}
+	}
+	return states
+}
+
+// closure adds to states every state reachable by skipping "**" segments.
+func (p Pattern) closure(states stateSet) stateSet {
+	for s := range states {
+		for ; s < len(p.segs) && p.segs[s].kind == segAnySeq; s++ {
+			states[s+1] = struct{}{}
+		}
+	}
+	return states
+}
+
+func (p Pattern) segMatches(lit string, elt any) bool {
+	switch elt := elt.(type) {
+	case string:
+		return p.keys.equal(elt, lit)
+	case int:
+		return strconv.Itoa(elt) == lit
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package jseq_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestPattern(t *testing.T) {
+	cases := []struct {
+		pattern              string
+		ptr                  jseq.Pointer
+		match, prefix, below bool
+	}{
+		{"", nil, true, true, false},
+		{"", jseq.Pointer{"a"}, false, true, false},
+		{"/a/b", jseq.Pointer{"a", "b"}, true, true, false},
+		{"/a/b", jseq.Pointer{"a"}, false, false, true},
+		{"/a/b", jseq.Pointer{"a", "b", "c"}, false, true, false},
+		{"/a/0", jseq.Pointer{"a", 0}, true, true, false},
+		{"/a/0", jseq.Pointer{"a", "0"}, true, true, false},
+		{"/a/*/c", jseq.Pointer{"a", 7, "c"}, true, true, false},
+		{"/a/*/c", jseq.Pointer{"a", 7, "d"}, false, false, false},
+		{"/a/**", jseq.Pointer{"a"}, true, true, true},
+		{"/a/**/z", jseq.Pointer{"a", 1, "b", "z"}, true, true, true},
+		{"/**/z", jseq.Pointer{"z"}, true, true, true},
+		{"/**/z", jseq.Pointer{"y"}, false, false, true},
+		{"/x~1y/~0", jseq.Pointer{"x/y", "~"}, true, true, false},
+	}
+
+	for _, tc := range cases {
+		p := jseq.MustParsePattern(tc.pattern)
+		if got := p.Match(tc.ptr); got != tc.match {
+			t.Errorf("%q.Match(%v) = %v, want %v", tc.pattern, tc.ptr, got, tc.match)
+		}
+		if got := p.MatchPrefix(tc.ptr); got != tc.prefix {
+			t.Errorf("%q.MatchPrefix(%v) = %v, want %v", tc.pattern, tc.ptr, got, tc.prefix)
+		}
+		if got := p.MatchBelow(tc.ptr); got != tc.below {
+			t.Errorf("%q.MatchBelow(%v) = %v, want %v", tc.pattern, tc.ptr, got, tc.below)
+		}
+	}
+
+	if _, err := jseq.ParsePattern("a/b"); err == nil {
+		t.Error("got no error for pattern without leading slash")
+	}
+}
+
+func TestPatternCaptures(t *testing.T) {
+	cases := []struct {
+		pattern string
+		ptr     jseq.Pointer
+		want    map[string]string
+		wantOK  bool
+	}{
+		{"/users/{id}/email", jseq.Pointer{"users", "42", "email"}, map[string]string{"id": "42"}, true},
+		{"/users/{id}/email", jseq.Pointer{"users", 7, "email"}, map[string]string{"id": "7"}, true},
+		{"/users/{id}/email", jseq.Pointer{"users", 7, "name"}, nil, false},
+		{"/{group}/**/{leaf}", jseq.Pointer{"g", "a", "b", "c"}, map[string]string{"group": "g", "leaf": "c"}, true},
+		{"/a/*", jseq.Pointer{"a", "b"}, nil, true},
+	}
+
+	for _, tc := range cases {
+		got, ok := jseq.MustParsePattern(tc.pattern).Captures(tc.ptr)
+		if ok != tc.wantOK || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q.Captures(%v) = %v, %v; want %v, %v", tc.pattern, tc.ptr, got, ok, tc.want, tc.wantOK)
+		}
+	}
+
+	if _, err := jseq.ParsePattern("/{x}/{x}"); err == nil {
+		t.Error("got no error for pattern capturing the same name twice")
+	}
+}
+
+func TestPatternSelect(t *testing.T) {
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(`{"users": {"42": {"email": "a@x"}, "43": {"email": "b@x", "name": "B"}}}`))
+	values, errptr2 := jseq.Values(tokens)
+
+	var got []string
+	for caps, val := range jseq.MustParsePattern("/users/{id}/email").Select(values) {
+		got = append(got, caps["id"]+"="+val.(string))
+	}
+	if err := errors.Join(*errptr1, *errptr2); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"42=a@x", "43=b@x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
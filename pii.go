@@ -0,0 +1,246 @@
+package jseq
+
+import (
+	"iter"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Detector finds personally identifying information (PII) in strings.
+// See [PIIScanner].
+type Detector interface {
+	// Name is a short name for the kind of PII detected, e.g. "email".
+	Name() string
+
+	// Detect returns the byte ranges within s that look like PII,
+	// as [start, end) pairs in increasing order.
+	Detect(s string) [][2]int
+}
+
+// RegexpDetector returns a [Detector] that reports matches of re.
+func RegexpDetector(name string, re *regexp.Regexp) Detector {
+	return FuncDetector(name, func(s string) [][2]int {
+		var result [][2]int
+		for _, m := range re.FindAllStringIndex(s, -1) {
+			result = append(result, [2]int{m[0], m[1]})
+		}
+		return result
+	})
+}
+
+// FuncDetector returns a [Detector] that calls f.
+// See [Detector.Detect].
+func FuncDetector(name string, f func(string) [][2]int) Detector {
+	return funcDetector{name: name, f: f}
+}
+
+type funcDetector struct {
+	name string
+	f    func(string) [][2]int
+}
+
+func (d funcDetector) Name() string             { return d.name }
+func (d funcDetector) Detect(s string) [][2]int { return d.f(s) }
+
+var (
+	emailRegex = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phoneRegex = regexp.MustCompile(`(?:\+?\b1[-. ]?)?(?:\(\d{3}\)\s?|\b\d{3}[-. ]?)\d{3}[-. ]?\d{4}\b`)
+	cardRegex  = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	ssnRegex   = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+
+	// EmailDetector detects email addresses.
+	EmailDetector = RegexpDetector("email", emailRegex)
+
+	// PhoneDetector detects North American-style phone numbers.
+	PhoneDetector = RegexpDetector("phone", phoneRegex)
+
+	// CreditCardDetector detects 13- to 19-digit card numbers
+	// (optionally grouped with spaces or hyphens)
+	// that pass the Luhn checksum.
+	CreditCardDetector = FuncDetector("credit_card", func(s string) [][2]int {
+		var result [][2]int
+		for _, m := range cardRegex.FindAllStringIndex(s, -1) {
+			if luhn(s[m[0]:m[1]]) {
+				result = append(result, [2]int{m[0], m[1]})
+			}
+		}
+		return result
+	})
+
+	// SSNDetector detects US Social Security numbers in the form 123-45-6789.
+	SSNDetector = RegexpDetector("ssn", ssnRegex)
+)
+
+// DefaultDetectors returns the built-in detectors:
+// [EmailDetector], [PhoneDetector], [CreditCardDetector], and [SSNDetector].
+func DefaultDetectors() []Detector {
+	return []Detector{EmailDetector, PhoneDetector, CreditCardDetector, SSNDetector}
+}
+
+// luhn tells whether the digits in s pass the Luhn checksum.
+// Non-digits are ignored.
+func luhn(s string) bool {
+	var (
+		sum    int
+		double bool
+	)
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Finding is a report of possible PII found by a [PIIScanner].
+type Finding struct {
+	// Pointer locates the string containing the PII.
+	Pointer Pointer
+
+	// Detector is the name of the [Detector] that reported it.
+	Detector string
+
+	// Start and End are the byte range of the PII within the string.
+	Start, End int
+}
+
+// PIIScanner looks for personally identifying information (PII) in the string values of JSON records.
+type PIIScanner struct {
+	// Detectors are the detectors to use.
+	// If this is empty, [DefaultDetectors] is used.
+	Detectors []Detector
+
+	// Redact, if true, causes the scanner to replace each piece of PII it finds
+	// with Replacement.
+	Redact bool
+
+	// Replacement is the text that replaces redacted PII.
+	// If this is empty, "[REDACTED]" is used.
+	Replacement string
+}
+
+// Scan looks for PII in the strings within v.
+// It returns the findings in depth-first order,
+// with object members visited in key order.
+//
+// If s.Redact is true,
+// the first return value is a copy of v with PII redacted.
+// Otherwise it is v itself.
+func (s *PIIScanner) Scan(v any) (any, []Finding) {
+	var findings []Finding
+	result := s.scan(v, nil, &findings)
+	return result, findings
+}
+
+// Records consumes a sequence of pointer/value pairs as produced by [Values]
+// and scans each top-level value with [PIIScanner.Scan],
+// yielding the (possibly redacted) value together with its findings.
+func (s *PIIScanner) Records(values iter.Seq2[Pointer, any]) iter.Seq2[any, []Finding] {
+	return func(yield func(any, []Finding) bool) {
+		for pointer, val := range values {
+			if len(pointer) > 0 {
+				continue
+			}
+			if !yield(s.Scan(val)) {
+				return
+			}
+		}
+	}
+}
+
+func (s *PIIScanner) scan(v any, pointer Pointer, findings *[]Finding) any {
+	switch v := v.(type) {
+	case string:
+		return s.scanString(v, pointer, findings)
+
+	case []any:
+		result := v
+		if s.Redact {
+			result = make([]any, len(v))
+		}
+		for i, elt := range v {
+			newElt := s.scan(elt, append(slices.Clip(pointer), i), findings)
+			if s.Redact {
+				result[i] = newElt
+			}
+		}
+		return result
+
+	case map[string]any:
+		result := v
+		if s.Redact {
+			result = make(map[string]any, len(v))
+		}
+		for _, key := range SortedKeys(v) {
+			newVal := s.scan(v[key], append(slices.Clip(pointer), key), findings)
+			if s.Redact {
+				result[key] = newVal
+			}
+		}
+		return result
+
+	case Expanded:
+		newVal := s.scan(v.Value, pointer, findings)
+		if s.Redact {
+			return newVal
+		}
+		return v
+	}
+
+	return v
+}
+
+func (s *PIIScanner) scanString(str string, pointer Pointer, findings *[]Finding) string {
+	detectors := s.Detectors
+	if len(detectors) == 0 {
+		detectors = DefaultDetectors()
+	}
+
+	var ranges [][2]int
+	for _, d := range detectors {
+		for _, r := range d.Detect(str) {
+			*findings = append(*findings, Finding{Pointer: pointer, Detector: d.Name(), Start: r[0], End: r[1]})
+			ranges = append(ranges, r)
+		}
+	}
+	if !s.Redact || len(ranges) == 0 {
+		return str
+	}
+
+	replacement := s.Replacement
+	if replacement == "" {
+		replacement = "[REDACTED]"
+	}
+
+	// Merge overlapping ranges, then replace each.
+	slices.SortFunc(ranges, func(a, b [2]int) int { return a[0] - b[0] })
+	var (
+		buf  strings.Builder
+		prev int
+	)
+	for _, r := range ranges {
+		if r[1] <= prev {
+			continue
+		}
+		if r[0] > prev {
+			buf.WriteString(str[prev:r[0]])
+		}
+		if r[0] >= prev {
+			buf.WriteString(replacement)
+		}
+		prev = r[1]
+	}
+	buf.WriteString(str[prev:])
+	return buf.String()
+}
@@ -0,0 +1,80 @@
+package jseq_test
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestDetectors(t *testing.T) {
+	cases := []struct {
+		d    jseq.Detector
+		s    string
+		want [][2]int
+	}{
+		{jseq.EmailDetector, "write to bob@example.com today", [][2]int{{9, 24}}},
+		{jseq.PhoneDetector, "call (555) 123-4567 or 555.765.4321", [][2]int{{5, 19}, {23, 35}}},
+		{jseq.PhoneDetector, "card 4111111111111111", nil},
+		{jseq.CreditCardDetector, "card 4111 1111 1111 1111", [][2]int{{5, 24}}},
+		{jseq.CreditCardDetector, "card 4111 1111 1111 1112", nil},
+		{jseq.SSNDetector, "ssn: 123-45-6789.", [][2]int{{5, 16}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.d.Name(), func(t *testing.T) {
+			got := tc.d.Detect(tc.s)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("on %q got %v, want %v", tc.s, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPIIScanner(t *testing.T) {
+	const inp = `{"user": {"email": "a@b.io", "notes": ["ssn 123-45-6789", "none"]}, "id": "emp-7"}`
+
+	s := &jseq.PIIScanner{
+		Detectors: append(jseq.DefaultDetectors(), jseq.RegexpDetector("employee", regexp.MustCompile(`emp-\d+`))),
+		Redact:    true,
+	}
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+
+	var n int
+	for got, findings := range s.Records(values) {
+		n++
+
+		want := map[string]any{
+			"user": map[string]any{
+				"email": "[REDACTED]",
+				"notes": []any{"ssn [REDACTED]", "none"},
+			},
+			"id": "[REDACTED]",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+
+		wantFindings := []jseq.Finding{
+			{Pointer: jseq.Pointer{"id"}, Detector: "employee", Start: 0, End: 5},
+			{Pointer: jseq.Pointer{"user", "email"}, Detector: "email", Start: 0, End: 6},
+			{Pointer: jseq.Pointer{"user", "notes", 0}, Detector: "ssn", Start: 4, End: 15},
+		}
+		if !reflect.DeepEqual(findings, wantFindings) {
+			t.Errorf("got findings %v, want %v", findings, wantFindings)
+		}
+	}
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+	if n != 1 {
+		t.Errorf("got %d records, want 1", n)
+	}
+}
@@ -0,0 +1,110 @@
+package jseq
+
+import (
+	"context"
+	"encoding/json/jsontext"
+	"io"
+	"iter"
+
+	"github.com/bobg/errors"
+)
+
+// Source is a source of JSON tokens,
+// the input to a pipeline run by [Pipe].
+type Source interface {
+	// Tokens produces a sequence of tokens
+	// (as from [Tokens]),
+	// together with a pointer to an error
+	// that the caller may check after consuming the sequence.
+	Tokens() (iter.Seq[jsontext.Token], *error)
+}
+
+// Sink is a consumer of JSON values,
+// the output of a pipeline run by [Pipe].
+type Sink interface {
+	// Consume consumes one pointer/value pair
+	// (as from [Values]).
+	Consume(Pointer, any) error
+
+	// Close is called after the last pair has been consumed,
+	// or when the pipeline ends in error.
+	Close() error
+}
+
+// Transform is a stage in a pipeline run by [Pipe].
+// It transforms one sequence of pointer/value pairs into another.
+type Transform func(iter.Seq2[Pointer, any]) iter.Seq2[Pointer, any]
+
+// Pipe reads tokens from src,
+// parses them into values with [Values] and the given options,
+// passes the values through the transforms in order,
+// and delivers the results to sink.
+// The sink is closed when Pipe returns.
+//
+// Pipe stops early if ctx is canceled,
+// returning ctx.Err().
+// Any errors from the source, the parser, the sink, and the sink's Close method
+// are joined together in the result.
+func Pipe(ctx context.Context, src Source, sink Sink, opts []Option, transforms ...Transform) (err error) {
+	defer func() {
+		err = errors.Join(err, sink.Close())
+	}()
+
+	tokens, errptr1 := src.Tokens()
+	values, errptr2 := Values(tokens, opts...)
+	for _, t := range transforms {
+		values = t(values)
+	}
+
+	for pointer, val := range values {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := sink.Consume(pointer, val); err != nil {
+			return errors.Join(err, *errptr1, *errptr2)
+		}
+	}
+
+	return errors.Join(*errptr1, *errptr2)
+}
+
+// ReaderSource returns a [Source] that parses tokens from r,
+// using [Tokens] with the given options.
+func ReaderSource(r io.Reader, opts ...jsontext.Options) Source {
+	return readerSource{r: r, opts: opts}
+}
+
+type readerSource struct {
+	r    io.Reader
+	opts []jsontext.Options
+}
+
+func (s readerSource) Tokens() (iter.Seq[jsontext.Token], *error) {
+	return Tokens(s.r, s.opts...)
+}
+
+// SinkFunc returns a [Sink] whose Consume method calls f
+// and whose Close method does nothing.
+func SinkFunc(f func(Pointer, any) error) Sink {
+	return sinkFunc(f)
+}
+
+type sinkFunc func(Pointer, any) error
+
+func (f sinkFunc) Consume(pointer Pointer, val any) error { return f(pointer, val) }
+func (sinkFunc) Close() error                             { return nil }
+
+// EncoderSink returns a [Sink] that writes each top-level value it consumes to w
+// using [Encode] with the given options,
+// one value per line.
+// Values that are not top-level are ignored.
+// Closing the sink does not close w.
+func EncoderSink(w io.Writer, opts ...jsontext.Options) Sink {
+	enc := jsontext.NewEncoder(w, opts...)
+	return SinkFunc(func(pointer Pointer, val any) error {
+		if len(pointer) > 0 {
+			return nil
+		}
+		return encodeValue(enc, val)
+	})
+}
@@ -0,0 +1,65 @@
+package jseq_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestPipe(t *testing.T) {
+	const inp = `{"b": 1, "a": [true]} "x" {"c": null}`
+
+	dropStrings := func(values iter.Seq2[jseq.Pointer, any]) iter.Seq2[jseq.Pointer, any] {
+		return func(yield func(jseq.Pointer, any) bool) {
+			for p, v := range values {
+				if _, ok := v.(string); ok {
+					continue
+				}
+				if !yield(p, v) {
+					return
+				}
+			}
+		}
+	}
+
+	buf := new(strings.Builder)
+	err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), jseq.EncoderSink(buf), nil, dropStrings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "{\"a\":[true],\"b\":1}\n{\"c\":null}\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPipeErrors(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(`[1, `)), jseq.SinkFunc(func(jseq.Pointer, any) error { return nil }), nil)
+		if err == nil {
+			t.Error("got no error")
+		}
+	})
+
+	t.Run("sink", func(t *testing.T) {
+		errSink := errors.New("sink")
+		err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(`[1]`)), jseq.SinkFunc(func(jseq.Pointer, any) error { return errSink }), nil)
+		if !errors.Is(err, errSink) {
+			t.Errorf("got %v, want %v", err, errSink)
+		}
+	})
+
+	t.Run("context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := jseq.Pipe(ctx, jseq.ReaderSource(strings.NewReader(`[1]`)), jseq.SinkFunc(func(jseq.Pointer, any) error { return nil }), nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	})
+}
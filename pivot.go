@@ -0,0 +1,161 @@
+package jseq
+
+import (
+	"iter"
+	"slices"
+)
+
+// Pivot returns a [Transform] that converts arrays of key/value objects
+// into flat objects.
+// Each array at a location matching pattern,
+// whose elements are all objects with a string member named keyField,
+// becomes an object mapping each element's key
+// to the element's member named valueField
+// (or null if it has none).
+// For example, with keyField "name" and valueField "value",
+//
+//	[{"name": "color", "value": "red"}, {"name": "size", "value": 9}]
+//
+// becomes
+//
+//	{"color": "red", "size": 9}
+//
+// Other members of the elements are dropped,
+// and if two elements have the same key, the last one wins.
+// Values at matching locations that do not have the right form
+// pass through unchanged.
+//
+// The transform replaces the pairs for the converted subtree
+// with pairs for its new form,
+// and updates the values of the containers that include it,
+// preserving the children-before-parents order of [Values].
+func Pivot(pattern Pattern, keyField, valueField string) Transform {
+	return reshape(pattern, func(val any) (any, bool) {
+		arr, ok := decodedForm(val).([]any)
+		if !ok {
+			return nil, false
+		}
+		result := make(map[string]any)
+		for _, elt := range arr {
+			obj, ok := asObject(elt)
+			if !ok {
+				return nil, false
+			}
+			key, ok := obj[keyField].(string)
+			if !ok {
+				return nil, false
+			}
+			result[key] = obj[valueField]
+			if result[key] == nil {
+				result[key] = Null{}
+			}
+		}
+		return result, true
+	})
+}
+
+// Unpivot returns a [Transform] that does the reverse of [Pivot].
+// Each object at a location matching pattern
+// becomes an array of objects,
+// one for each member,
+// in sorted key order (see [SortedKeys]),
+// with the member's key under keyField
+// and its value under valueField.
+// Values at matching locations that are not objects
+// pass through unchanged.
+func Unpivot(pattern Pattern, keyField, valueField string) Transform {
+	return reshape(pattern, func(val any) (any, bool) {
+		obj, ok := asObject(val)
+		if !ok {
+			return nil, false
+		}
+		result := make([]any, 0, len(obj))
+		for _, key := range SortedKeys(obj) {
+			result = append(result, map[string]any{keyField: key, valueField: obj[key]})
+		}
+		return result, true
+	})
+}
+
+// reshape returns a [Transform] that replaces the values at locations matching pattern
+// with the result of f, when f reports success,
+// adjusting the surrounding pairs to match.
+func reshape(pattern Pattern, f func(any) (any, bool)) Transform {
+	type pair struct {
+		pointer Pointer
+		val     any
+	}
+
+	return func(values iter.Seq2[Pointer, any]) iter.Seq2[Pointer, any] {
+		return func(yield func(Pointer, any) bool) {
+			var (
+				held     []pair // pairs inside a matching location, awaiting its value
+				replaced []pair // replacements made in the current record
+			)
+
+			// inside tells whether ptr is strictly inside a matching location.
+			inside := func(ptr Pointer) bool {
+				return len(ptr) > 0 && pattern.MatchPrefix(ptr[:len(ptr)-1])
+			}
+			hold := func(ptr Pointer, val any) bool {
+				held = append(held, pair{pointer: slices.Clone(ptr), val: val})
+				return true
+			}
+			// dest is where the pairs for the subtree at ptr go.
+			dest := func(ptr Pointer) func(Pointer, any) bool {
+				if inside(ptr) {
+					return hold
+				}
+				return yield
+			}
+
+			for pointer, val := range values {
+				// Bring val up to date with replacements inside it.
+				for _, r := range replaced {
+					if len(r.pointer) > len(pointer) && slices.Equal(r.pointer[:len(pointer)], pointer) {
+						if updated, err := r.pointer[len(pointer):].Set(val, r.val); err == nil {
+							val = updated
+						}
+					}
+				}
+
+				if pattern.Match(pointer) {
+					// Find the held pairs for val's descendants, which are last.
+					i := len(held)
+					for i > 0 && len(held[i-1].pointer) > len(pointer) && slices.Equal(held[i-1].pointer[:len(pointer)], pointer) {
+						i--
+					}
+
+					if newVal, ok := f(val); ok {
+						held = held[:i]
+						replaced = append(replaced, pair{pointer: slices.Clone(pointer), val: newVal})
+						emit := dest(pointer)
+						for sub, v := range Walk(newVal) {
+							if len(sub) == 0 {
+								break
+							}
+							if !emit(append(slices.Clip(pointer), sub...), v) {
+								return
+							}
+						}
+						val = newVal
+					} else if !inside(pointer) {
+						for _, h := range held[i:] {
+							if !yield(h.pointer, h.val) {
+								return
+							}
+						}
+						held = held[:i]
+					}
+				}
+
+				if !dest(pointer)(pointer, val) {
+					return
+				}
+				if len(pointer) == 0 {
+					replaced = replaced[:0]
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestPivot(t *testing.T) {
+	cases := []struct {
+		name      string
+		transform jseq.Transform
+		inp       string
+		want      string
+	}{{
+		name:      "pivot",
+		transform: jseq.Pivot(jseq.MustParsePattern("/items/*/attrs"), "name", "value"),
+		inp:       `{"items": [{"id": 1, "attrs": [{"name": "color", "value": "red"}, {"name": "size", "value": [9]}, {"name": "x"}]}, {"id": 2, "attrs": "none"}]}`,
+		want:      `{"items": [{"id": 1, "attrs": {"color": "red", "size": [9], "x": null}}, {"id": 2, "attrs": "none"}]}`,
+	}, {
+		name:      "pivot not applicable",
+		transform: jseq.Pivot(jseq.MustParsePattern("/attrs"), "name", "value"),
+		inp:       `{"attrs": [{"name": "a", "value": 1}, {"value": 2}]} {"attrs": []}`,
+		want:      `{"attrs": [{"name": "a", "value": 1}, {"value": 2}]} {"attrs": {}}`,
+	}, {
+		name:      "unpivot",
+		transform: jseq.Unpivot(jseq.MustParsePattern("/tags"), "Key", "Value"),
+		inp:       `{"tags": {"b": {"x": 1}, "a": "one"}, "n": 1} {"tags": 7}`,
+		want:      `{"tags": [{"Key": "a", "Value": "one"}, {"Key": "b", "Value": {"x": 1}}], "n": 1} {"tags": 7}`,
+	}, {
+		name:      "nested",
+		transform: jseq.Unpivot(jseq.MustParsePattern("/**/m"), "k", "v"),
+		inp:       `{"m": {"a": {"m": {"b": 1}}}}`,
+		want:      `{"m": [{"k": "a", "v": {"m": [{"k": "b", "v": 1}]}}]}`,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, errptr1 := jseq.Tokens(strings.NewReader(tc.inp))
+			values, errptr2 := jseq.Values(tokens)
+
+			var (
+				got   []any
+				pairs = make(map[string]any)
+			)
+			for pointer, val := range tc.transform(values) {
+				if len(pointer) > 0 {
+					pairs[string(pointer.Text())] = val
+					continue
+				}
+				got = append(got, val)
+
+				// The pairs for the record agree with the record.
+				want := make(map[string]any)
+				for p, v := range jseq.Walk(val) {
+					if len(p) > 0 {
+						want[string(p.Text())] = v
+					}
+				}
+				if !reflect.DeepEqual(pairs, want) {
+					t.Errorf("got pairs %v, want %v", pairs, want)
+				}
+				clear(pairs)
+			}
+			if err := *errptr1; err != nil {
+				t.Fatal(err)
+			}
+			if err := *errptr2; err != nil {
+				t.Fatal(err)
+			}
+
+			want := records(t, jseq.ReaderSource(strings.NewReader(tc.want)))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPivotOrdered(t *testing.T) {
+	cases := []struct {
+		name      string
+		transform jseq.Transform
+		inp       string
+		want      string
+	}{{
+		name:      "pivot",
+		transform: jseq.Pivot(jseq.MustParsePattern("/attrs"), "name", "value"),
+		inp:       `{"z": 1, "attrs": [{"value": "red", "name": "color"}]}`,
+		want:      `{"z":1,"attrs":{"color":"red"}}`,
+	}, {
+		name:      "unpivot",
+		transform: jseq.Unpivot(jseq.MustParsePattern("/tags"), "k", "v"),
+		inp:       `{"tags": {"b": 2, "a": 1}}`,
+		want:      `{"tags":[{"k":"a","v":1},{"k":"b","v":2}]}`,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(tc.inp))
+			values, errptr := jseq.Values(tokens, jseq.OrderedObjects())
+			var got []byte
+			for pointer, val := range tc.transform(values) {
+				if len(pointer) == 0 {
+					b, err := jseq.Marshal(val)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got = b
+				}
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package jseq
+
+import (
+	"maps"
+	"slices"
+)
+
+// Presence records which locations are present in a JSON value,
+// and which of those hold null.
+// It lets a consumer distinguish an absent field from a null one
+// and from one holding a zero value,
+// even after the value has been converted to Go types that cannot express the difference.
+//
+// Obtain a Presence with [WithPresence] or [PresenceOf].
+type Presence struct {
+	paths map[string]bool // pointer text -> whether the value is null
+}
+
+// WithPresence is an [Option] that causes [Values] to call f
+// with the [Presence] of each top-level value,
+// just before that value is produced.
+func WithPresence(f func(Presence)) Option {
+	return func(c *config) {
+		c.onPresence = f
+	}
+}
+
+// PresenceOf computes the [Presence] of v.
+func PresenceOf(v any) Presence {
+	result := Presence{paths: make(map[string]bool)}
+	for pointer, val := range Walk(v) {
+		result.add(pointer, val)
+	}
+	return result
+}
+
+func (p Presence) add(pointer Pointer, val any) {
+	p.paths[string(pointer.Text())] = isNull(val)
+}
+
+// Has tells whether there is a value at ptr, including null.
+func (p Presence) Has(ptr Pointer) bool {
+	_, ok := p.paths[string(ptr.Text())]
+	return ok
+}
+
+// IsNull tells whether there is a null value at ptr.
+func (p Presence) IsNull(ptr Pointer) bool {
+	return p.paths[string(ptr.Text())]
+}
+
+// Paths returns the locations present,
+// as JSON pointer strings (see [Pointer.Text]),
+// in sorted order.
+func (p Presence) Paths() []string {
+	return slices.Sorted(maps.Keys(p.paths))
+}
+
+func (p *parser) presenceYield(yield func(Pointer, any) bool) func(Pointer, any) bool {
+	current := Presence{paths: make(map[string]bool)}
+	return func(pointer Pointer, val any) bool {
+		current.add(pointer, val)
+		if len(pointer) == 0 {
+			p.onPresence(current)
+			current = Presence{paths: make(map[string]bool)}
+		}
+		return yield(pointer, val)
+	}
+}
@@ -0,0 +1,68 @@
+package jseq_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestWithPresence(t *testing.T) {
+	const inp = `{"a": 0, "b": null, "c": {"d": ""}} {"c": [false]}`
+
+	var got []jseq.Presence
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens, jseq.WithPresence(func(p jseq.Presence) {
+		got = append(got, p)
+	}))
+
+	var records []any
+	for pointer, val := range values {
+		if len(pointer) == 0 {
+			if len(got) != len(records)+1 {
+				t.Fatalf("got %d presence callbacks before record %d", len(got), len(records))
+			}
+			records = append(records, val)
+		}
+	}
+	if err := errors.Join(*errptr1, *errptr2); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"", "/a", "/b", "/c", "/c/d"},
+		{"", "/c", "/c/0"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d presence values, want %d", len(got), len(want))
+	}
+	for i, p := range got {
+		if !reflect.DeepEqual(p.Paths(), want[i]) {
+			t.Errorf("record %d: got paths %v, want %v", i, p.Paths(), want[i])
+		}
+		if !reflect.DeepEqual(p.Paths(), jseq.PresenceOf(records[i]).Paths()) {
+			t.Errorf("record %d: PresenceOf differs", i)
+		}
+	}
+
+	p := got[0]
+	cases := []struct {
+		ptr           jseq.Pointer
+		has, wantNull bool
+	}{
+		{jseq.Pointer{"a"}, true, false},
+		{jseq.Pointer{"b"}, true, true},
+		{jseq.Pointer{"c", "d"}, true, false},
+		{jseq.Pointer{"e"}, false, false},
+	}
+	for _, tc := range cases {
+		if got := p.Has(tc.ptr); got != tc.has {
+			t.Errorf("Has(%v) = %v, want %v", tc.ptr, got, tc.has)
+		}
+		if got := p.IsNull(tc.ptr); got != tc.wantNull {
+			t.Errorf("IsNull(%v) = %v, want %v", tc.ptr, got, tc.wantNull)
+		}
+	}
+}
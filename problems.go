@@ -0,0 +1,190 @@
+package jseq
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/bobg/errors"
+)
+
+// Problem is a problem found in JSON input.
+// See [Problems] and [Lint].
+type Problem struct {
+	// Record is the ordinal of the top-level value containing the problem,
+	// counting from zero.
+	Record int
+
+	// Offset is the byte offset of the problem in the input,
+	// or -1 if unknown.
+	Offset int64
+
+	// Line is the line number of the problem in the input,
+	// counting from one,
+	// or 0 if unknown.
+	Line int
+
+	// Pointer locates the problem within its top-level value.
+	Pointer Pointer
+
+	Message string
+
+	// Rule is the name of the [Rule] that found the problem,
+	// if any.
+	Rule string
+}
+
+// String renders p as a single line of text.
+func (p Problem) String() string {
+	result := fmt.Sprintf("%d:%d:%s: %s", p.Line, p.Offset, p.Pointer.Text(), p.Message)
+	if p.Rule != "" {
+		result += " [" + p.Rule + "]"
+	}
+	return result
+}
+
+// Report is a list of [Problem]s.
+type Report []Problem
+
+// WriteText writes r to w, one problem per line,
+// in the form LINE:OFFSET:POINTER: MESSAGE [RULE]
+// (where the rule is omitted if there is none).
+func (r Report) WriteText(w io.Writer) error {
+	for _, p := range r {
+		if _, err := fmt.Fprintln(w, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes r to w as newline-delimited JSON,
+// one object per problem,
+// with the members record, offset, line, pointer, message,
+// and (if there is one) rule.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := jsontext.NewEncoder(w)
+	for _, p := range r {
+		obj := map[string]any{
+			"record":  Int(int64(p.Record)),
+			"offset":  Int(p.Offset),
+			"line":    Int(int64(p.Line)),
+			"pointer": string(p.Pointer.Text()),
+			"message": p.Message,
+		}
+		if p.Rule != "" {
+			obj["rule"] = p.Rule
+		}
+		if err := encodeValue(enc, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Problems reads the JSON input in r,
+// which may contain multiple top-level values,
+// and reports all of the syntax problems it finds,
+// not just the first.
+// The options are passed to [jsontext.NewDecoder];
+// for example, [jsontext.AllowDuplicateNames] controls
+// whether duplicate object keys are a problem.
+//
+// After a problem,
+// Problems resumes at the start of the next line of input,
+// so that one malformed value in newline-delimited JSON
+// does not hide problems in the ones after it.
+// (In other input, resuming in mid-value may produce some spurious problems.)
+//
+// The error result is for failures in reading r.
+func Problems(r io.Reader, opts ...jsontext.Options) (Report, error) {
+	s := &problemScanner{recoverer: recoverer{r: r}}
+	return s.scan(opts)
+}
+
+type problemScanner struct {
+	recoverer
+	report Report
+}
+
+func (s *problemScanner) scan(opts []jsontext.Options) (Report, error) {
+	var (
+		src    io.Reader = s
+		start  int64
+		record int
+	)
+
+	for {
+		var (
+			dec = jsontext.NewDecoder(src, opts...)
+			c   checker
+		)
+		for {
+			tok, err := dec.ReadToken()
+			if errors.Is(err, io.EOF) {
+				return s.report, nil
+			}
+			if err != nil {
+				var (
+					offset  = start + dec.InputOffset()
+					pointer = slices.Clone(c.pointer)
+					serr    *jsontext.SyntacticError
+				)
+				switch {
+				case errors.As(err, &serr):
+					offset = start + serr.ByteOffset
+					pointer = c.convert(serr.JSONPointer)
+					err = serr.Err
+				case errors.Is(err, io.ErrUnexpectedEOF):
+				default:
+					return s.report, err
+				}
+				s.report = append(s.report, Problem{
+					Record:  record,
+					Offset:  offset,
+					Line:    s.lineAt(offset),
+					Pointer: pointer,
+					Message: err.Error(),
+				})
+				record++
+
+				_, rest, ok, err := s.resync(offset)
+				if err != nil {
+					return s.report, err
+				}
+				if !ok {
+					return s.report, nil
+				}
+				src, start = io.MultiReader(bytes.NewReader(rest), s), s.base
+				break
+			}
+
+			c.check(tok)
+			if len(c.stack) == 0 {
+				record++
+				s.trim(start + dec.InputOffset())
+			}
+		}
+	}
+}
+
+// convert converts jp, a location in the value c is checking,
+// to a [Pointer],
+// using the types of the containers on c's stack
+// to tell array indexes from object keys.
+func (c *checker) convert(jp jsontext.Pointer) Pointer {
+	var result Pointer
+	for tok := range jp.Tokens() {
+		if i := len(result); i < len(c.stack) && c.stack[i].kind == '[' {
+			if n, err := strconv.Atoi(tok); err == nil {
+				result = append(result, n)
+				continue
+			}
+		}
+		result = append(result, tok)
+	}
+	return result
+}
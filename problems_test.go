@@ -0,0 +1,77 @@
+package jseq_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestProblems(t *testing.T) {
+	const inp = `{"a": 1}
+{"b": [1, 2,, 3]}
+{"c": {"d": "ok"}}
+{"e": 1, "e": 2}
+{"f": tru}
+[1, 2
+`
+
+	report, err := jseq.Problems(strings.NewReader(inp))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		record, line int
+		pointer      string
+	}
+	var got []summary
+	for _, p := range report {
+		got = append(got, summary{record: p.Record, line: p.Line, pointer: string(p.Pointer.Text())})
+		if p.Message == "" {
+			t.Errorf("empty message for %v", p)
+		}
+		if p.Offset < 0 || p.Offset > int64(len(inp)) {
+			t.Errorf("offset %d out of range", p.Offset)
+		}
+	}
+	want := []summary{
+		{record: 1, line: 2, pointer: "/b/2"},
+		{record: 3, line: 4, pointer: "/e"},
+		{record: 4, line: 5, pointer: "/f"},
+		{record: 5, line: 7, pointer: ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := report.WriteText(buf); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != len(want) || !strings.HasPrefix(lines[0], "2:") {
+		t.Errorf("unexpected text report:\n%s", buf)
+	}
+
+	buf.Reset()
+	if err := report.WriteJSON(buf); err != nil {
+		t.Fatal(err)
+	}
+	tokens, errptr1 := jseq.Tokens(buf)
+	values, errptr2 := jseq.Values(tokens)
+	var n int
+	for pointer, val := range values {
+		if len(pointer) > 0 {
+			continue
+		}
+		if m, ok := val.(map[string]any); !ok || m["pointer"] != string(report[n].Pointer.Text()) {
+			t.Errorf("JSON report entry %d: got %v", n, val)
+		}
+		n++
+	}
+	if *errptr1 != nil || *errptr2 != nil || n != len(report) {
+		t.Errorf("got %d JSON report entries (errors %v, %v), want %d", n, *errptr1, *errptr2, len(report))
+	}
+}
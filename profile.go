@@ -0,0 +1,134 @@
+package jseq
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// FieldProfile describes how often a [Pointer.Path] is present, null, or empty
+// across the records in a stream.
+// See [FieldProfiles].
+type FieldProfile struct {
+	Path string
+
+	// Records is the total number of records in the stream.
+	Records int
+
+	// Present is the number of records in which some value appeared at Path.
+	Present int
+
+	// Null is the number of records in which a null appeared at Path.
+	Null int
+
+	// Empty is the number of records in which an empty string, array, or object
+	// appeared at Path.
+	Empty int
+}
+
+// MissingRate is the fraction of records in which f.Path is absent.
+func (f FieldProfile) MissingRate() float64 {
+	return rate(f.Records-f.Present, f.Records)
+}
+
+// NullRate is the fraction of records in which f.Path is null.
+func (f FieldProfile) NullRate() float64 {
+	return rate(f.Null, f.Records)
+}
+
+// EmptyRate is the fraction of records in which f.Path is an empty string, array, or object.
+func (f FieldProfile) EmptyRate() float64 {
+	return rate(f.Empty, f.Records)
+}
+
+func rate(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// FieldProfiles consumes a sequence of pointer/value pairs as produced by [Values]
+// and reports, for each path (see [Pointer.Path]) seen in any record,
+// how many records have it present, null, or empty.
+// A path that appears more than once in a record
+// (because it contains an array wildcard)
+// counts at most once toward each total for that record.
+//
+// The result is sorted by path.
+func FieldProfiles(values iter.Seq2[Pointer, any]) []FieldProfile {
+	var (
+		profiles = make(map[string]*FieldProfile)
+		records  int
+		current  = make(map[string]fieldFlags)
+	)
+
+	for pointer, val := range values {
+		if len(pointer) > 0 {
+			path := pointer.Path()
+			flags := current[path]
+			switch {
+			case isNull(val):
+				flags |= fieldNull
+			case isEmpty(val):
+				flags |= fieldEmpty
+			}
+			current[path] = flags
+			continue
+		}
+
+		records++
+		for path, flags := range current {
+			prof := profiles[path]
+			if prof == nil {
+				prof = &FieldProfile{Path: path}
+				profiles[path] = prof
+			}
+			prof.Present++
+			if flags&fieldNull != 0 {
+				prof.Null++
+			}
+			if flags&fieldEmpty != 0 {
+				prof.Empty++
+			}
+		}
+		clear(current)
+	}
+
+	var result []FieldProfile
+	for _, path := range slices.Sorted(maps.Keys(profiles)) {
+		prof := profiles[path]
+		prof.Records = records
+		result = append(result, *prof)
+	}
+	return result
+}
+
+type fieldFlags int
+
+const (
+	fieldNull fieldFlags = 1 << iota
+	fieldEmpty
+)
+
+func isNull(v any) bool {
+	return TypeName(v) == "null"
+}
+
+func isEmpty(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	case *Object:
+		return v.Len() == 0
+	case Expanded:
+		return isEmpty(v.Value)
+	case Container:
+		return v.Len == 0
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestFieldProfiles(t *testing.T) {
+	const inp = `
+{"name": "a", "tags": ["x", null], "note": null}
+{"name": "", "tags": []}
+{"name": "c", "note": "hi"}
+{"tags": [null, null]}
+`
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+	got := jseq.FieldProfiles(values)
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+
+	want := []jseq.FieldProfile{
+		{Path: "/name", Records: 4, Present: 3, Empty: 1},
+		{Path: "/note", Records: 4, Present: 2, Null: 1},
+		{Path: "/tags", Records: 4, Present: 3, Empty: 1},
+		{Path: "/tags/*", Records: 4, Present: 2, Null: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if r := got[1].MissingRate(); r != 0.5 {
+		t.Errorf("got missing rate %v for /note, want 0.5", r)
+	}
+	if r := got[3].NullRate(); r != 0.5 {
+		t.Errorf("got null rate %v for /tags/*, want 0.5", r)
+	}
+	if r := got[0].EmptyRate(); r != 0.25 {
+		t.Errorf("got empty rate %v for /name, want 0.25", r)
+	}
+}
@@ -0,0 +1,139 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"io"
+	"iter"
+	"slices"
+)
+
+// Parser is a pull-based alternative to [Values]:
+// instead of looping over a sequence,
+// the caller asks for each pointer/value pair with [Parser.Next].
+// This suits state machines and hand-written protocol handlers,
+// which can read pairs from wherever they need them.
+// Create one with [NewParser],
+// and call [Parser.Close] when done with it.
+//
+// The pairs and their order are the same as from Values with the same options,
+// but the pointers returned by Next are not shared with later ones,
+// so they may be retained
+// (see [ClonePointers]).
+type Parser struct {
+	next     func() (Pointer, any, bool)
+	stop     func()
+	errptr   *error
+	preOrder bool
+
+	peeked  bool
+	pointer Pointer // the peeked pair, if peeked
+	val     any
+
+	last    Pointer // the pair most recently returned by Next
+	lastVal any
+	err     error
+}
+
+// NewParser creates a [Parser] reading the given tokens,
+// as from [Tokens],
+// with the given options as for [Values].
+func NewParser(tokens iter.Seq[jsontext.Token], opts ...Option) *Parser {
+	opts = append(opts[:len(opts):len(opts)], ClonePointers())
+	values, errptr := Values(tokens, opts...)
+	next, stop := iter.Pull2(values)
+
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &Parser{next: next, stop: stop, errptr: errptr, preOrder: c.preOrder}
+}
+
+// Next returns the next pointer/value pair.
+// At the end of the input it returns [io.EOF].
+// After an error,
+// Next keeps returning the same error.
+func (p *Parser) Next() (Pointer, any, error) {
+	pointer, val, err := p.peek()
+	if err != nil {
+		return nil, nil, err
+	}
+	p.peeked = false
+	p.last, p.lastVal = pointer, val
+	return pointer, val, nil
+}
+
+// Skip discards the rest of the innermost array or object
+// that is open after the pair most recently returned by [Parser.Next],
+// so that the following call to Next returns the pair after it.
+// In the usual order,
+// that is the array or object containing the most recent value,
+// and Skip discards its remaining members
+// and the array or object itself.
+// With the [PreOrder] option,
+// if the most recent value was the start of an array or object,
+// Skip discards that array or object's contents.
+//
+// After a top-level scalar,
+// or at the start of the input,
+// Skip does nothing.
+func (p *Parser) Skip() error {
+	var open Pointer
+	switch c, ok := p.lastVal.(Container); {
+	case ok && p.preOrder && c.Len < 0:
+		open = p.last // the start of a container produced by PreOrder
+	case len(p.last) > 0:
+		open = p.last[:len(p.last)-1]
+	default:
+		return nil
+	}
+
+	// Afterwards, the skipped container counts as the most recent value,
+	// so that another Skip skips the rest of its parent.
+	p.last, p.lastVal = open, nil
+
+	for {
+		pointer, _, err := p.peek()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(pointer) < len(open) || !slices.Equal(pointer[:len(open)], open) {
+			return nil
+		}
+		if len(pointer) == len(open) {
+			if !p.preOrder {
+				p.peeked = false // the container itself
+			}
+			return nil
+		}
+		p.peeked = false
+	}
+}
+
+// peek returns the next pair without consuming it.
+func (p *Parser) peek() (Pointer, any, error) {
+	if p.err != nil {
+		return nil, nil, p.err
+	}
+	if !p.peeked {
+		pointer, val, ok := p.next()
+		if !ok {
+			p.err = io.EOF
+			if err := *p.errptr; err != nil {
+				p.err = err
+			}
+			return nil, nil, p.err
+		}
+		p.pointer, p.val, p.peeked = pointer, val, true
+	}
+	return p.pointer, p.val, nil
+}
+
+// Close releases the resources used by p.
+// It is safe to call more than once.
+func (p *Parser) Close() {
+	p.stop()
+}
@@ -0,0 +1,106 @@
+package jseq_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestParser(t *testing.T) {
+	const inp = `{"a": [1, 2, 3], "b": {"c": 4, "d": [5, 6]}, "e": 7} 8`
+
+	type step struct {
+		skip bool
+		want string // pointer text of the pair expected from Next
+	}
+
+	cases := []struct {
+		name  string
+		opts  []jseq.Option
+		steps []step
+	}{{
+		name: "plain",
+		steps: []step{
+			{want: "/a/0"}, {want: "/a/1"}, {want: "/a/2"}, {want: "/a"},
+			{want: "/b/c"}, {want: "/b/d/0"}, {want: "/b/d/1"}, {want: "/b/d"}, {want: "/b"},
+			{want: "/e"}, {want: ""}, {want: ""},
+		},
+	}, {
+		name: "skip",
+		steps: []step{
+			{want: "/a/0"},
+			{skip: true, want: "/b/c"}, // skips /a/1, /a/2, and /a
+			{want: "/b/d/0"},
+			{skip: true, want: "/b"}, // skips /b/d/1 and /b/d
+			{skip: true, want: ""},   // skips /e and the first record, returning the second
+		},
+	}, {
+		name: "preorder",
+		opts: []jseq.Option{jseq.PreOrder()},
+		steps: []step{
+			{want: ""}, {want: "/a"},
+			{skip: true, want: "/b"}, // skips the contents of /a
+			{want: "/b/c"},
+			{skip: true, want: "/e"}, // skips the rest of /b
+			{want: ""},
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(inp))
+			p := jseq.NewParser(tokens, tc.opts...)
+			defer p.Close()
+
+			var retained []jseq.Pointer
+			for i, s := range tc.steps {
+				if s.skip {
+					if err := p.Skip(); err != nil {
+						t.Fatalf("step %d: %s", i, err)
+					}
+				}
+				pointer, _, err := p.Next()
+				if err != nil {
+					t.Fatalf("step %d: %s", i, err)
+				}
+				if got := string(pointer.Text()); got != s.want {
+					t.Errorf("step %d: got %q, want %q", i, got, s.want)
+				}
+				retained = append(retained, pointer)
+			}
+			if len(tc.steps) > 0 {
+				var got []string
+				for _, pointer := range retained {
+					got = append(got, string(pointer.Text()))
+				}
+				var want []string
+				for _, s := range tc.steps {
+					want = append(want, s.want)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("retained pointers changed: got %v, want %v", got, want)
+				}
+			}
+		})
+	}
+
+	tokens, _ := jseq.Tokens(strings.NewReader(`[1, 2`))
+	p := jseq.NewParser(tokens)
+	defer p.Close()
+	for {
+		_, _, err := p.Next()
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("got error %v, want %v", err, io.ErrUnexpectedEOF)
+		}
+	}
+	if _, _, err := p.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v after the end, want %v again", err, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,134 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"io"
+	"sync"
+
+	"github.com/bobg/errors"
+)
+
+// RangeOptions controls the behavior of [RangeReader].
+// The zero value is a usable default.
+type RangeOptions struct {
+	// BlockSize is the size of each range read.
+	// Reads start at multiples of BlockSize.
+	// The default is 8 MiB.
+	BlockSize int
+
+	// Prefetch is the number of blocks to read ahead of the consumer.
+	// The default is 1,
+	// which double-buffers:
+	// one block is read while the previous one is parsed.
+	Prefetch int
+}
+
+// RangeReader returns a reader of the size bytes in ra,
+// which it reads in large aligned blocks,
+// prefetching ahead of the consumer in a separate goroutine.
+// This suits inputs with high per-request latency,
+// such as objects in cloud storage accessed with range requests.
+//
+// The caller must close the reader to release the prefetching goroutine
+// if it does not read to the end.
+func RangeReader(ra io.ReaderAt, size int64, ropts RangeOptions) io.ReadCloser {
+	if ropts.BlockSize <= 0 {
+		ropts.BlockSize = 8 << 20
+	}
+	if ropts.Prefetch <= 0 {
+		ropts.Prefetch = 1
+	}
+
+	r := &rangeReader{
+		blocks: make(chan rangeBlock, ropts.Prefetch),
+		free:   make(chan []byte, ropts.Prefetch+1),
+		done:   make(chan struct{}),
+	}
+	for range ropts.Prefetch + 1 {
+		r.free <- make([]byte, ropts.BlockSize)
+	}
+	go r.fill(ra, size, ropts.BlockSize)
+	return r
+}
+
+// RangeSource returns a [Source] that parses tokens,
+// using [Tokens] with the given options,
+// from a [RangeReader] on ra.
+func RangeSource(ra io.ReaderAt, size int64, ropts RangeOptions, opts ...jsontext.Options) Source {
+	return ReaderSource(RangeReader(ra, size, ropts), opts...)
+}
+
+type rangeReader struct {
+	blocks chan rangeBlock // filled blocks, in order
+	free   chan []byte     // buffers available for filling
+	done   chan struct{}
+	once   sync.Once
+
+	cur rangeBlock
+	pos int
+	err error
+}
+
+type rangeBlock struct {
+	buf []byte
+	err error
+}
+
+func (r *rangeReader) fill(ra io.ReaderAt, size int64, blockSize int) {
+	defer close(r.blocks)
+
+	for off := int64(0); off < size; off += int64(blockSize) {
+		var buf []byte
+		select {
+		case <-r.done:
+			return
+		case buf = <-r.free:
+		}
+
+		buf = buf[:min(int64(blockSize), size-off)]
+		n, err := ra.ReadAt(buf, off)
+		if n == len(buf) {
+			err = nil
+		} else if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		block := rangeBlock{buf: buf[:n]}
+		if err != nil {
+			block.err = errors.Wrapf(err, "reading range at offset %d", off)
+		}
+
+		select {
+		case <-r.done:
+			return
+		case r.blocks <- block:
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	for r.pos == len(r.cur.buf) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.cur.buf != nil {
+			r.free <- r.cur.buf[:cap(r.cur.buf)]
+		}
+		block, ok := <-r.blocks
+		if !ok {
+			r.cur, r.pos, r.err = rangeBlock{}, 0, io.EOF
+			continue
+		}
+		r.cur, r.pos, r.err = block, 0, block.err
+	}
+	n := copy(p, r.cur.buf[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+func (r *rangeReader) Close() error {
+	r.once.Do(func() { close(r.done) })
+	return nil
+}
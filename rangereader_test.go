@@ -0,0 +1,70 @@
+package jseq_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestRangeReader(t *testing.T) {
+	inp := strings.Repeat(`{"a": [1, 2, 3], "b": "hello"}`+"\n", 100)
+
+	for _, blockSize := range []int{1, 7, 64, 1000, 100000} {
+		for _, prefetch := range []int{0, 1, 3} {
+			ra := &countingReaderAt{r: strings.NewReader(inp)}
+			r := jseq.RangeReader(ra, int64(len(inp)), jseq.RangeOptions{BlockSize: blockSize, Prefetch: prefetch})
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != inp {
+				t.Errorf("block size %d, prefetch %d: output differs from input", blockSize, prefetch)
+			}
+			if want := (len(inp) + blockSize - 1) / blockSize; int(ra.n.Load()) != want {
+				t.Errorf("block size %d, prefetch %d: got %d range reads, want %d", blockSize, prefetch, ra.n.Load(), want)
+			}
+			r.Close()
+		}
+	}
+
+	src := jseq.RangeSource(strings.NewReader(inp), int64(len(inp)), jseq.RangeOptions{BlockSize: 64})
+	if got := records(t, src); len(got) != 100 {
+		t.Errorf("got %d records, want 100", len(got))
+	}
+}
+
+func TestRangeReaderErrors(t *testing.T) {
+	const inp = `{"a": 1} {"b": 2}`
+
+	// Size claims more than there is.
+	r := jseq.RangeReader(strings.NewReader(inp), 100, jseq.RangeOptions{BlockSize: 8})
+	got, err := io.ReadAll(r)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if string(got) != inp {
+		t.Errorf("got %q, want %q", got, inp)
+	}
+
+	// Closing early does not block.
+	r = jseq.RangeReader(strings.NewReader(inp), int64(len(inp)), jseq.RangeOptions{BlockSize: 1})
+	var buf [1]byte
+	if _, err := r.Read(buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+}
+
+type countingReaderAt struct {
+	r io.ReaderAt
+	n atomic.Int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.n.Add(1)
+	return c.r.ReadAt(p, off)
+}
@@ -0,0 +1,85 @@
+package jseq
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/bobg/errors"
+)
+
+// rawBytes tells whether v is an undecoded JSON value,
+// a [jsontext.Value]
+// (or [encoding/json.RawMessage], which is the same type),
+// and if so returns its bytes.
+//
+// The utilities in this package that operate on decoded values
+// treat such a value as the JSON it contains,
+// decoding it on demand.
+func rawBytes(v any) ([]byte, bool) {
+	v2, ok := v.(jsontext.Value)
+	return v2, ok
+}
+
+// decodeRaw decodes the single JSON value in b.
+func decodeRaw(b []byte) (any, error) {
+	return decodeValue(bytes.NewReader(b))
+}
+
+// decodeValue reads the single JSON value in r.
+func decodeValue(r io.Reader, opts ...Option) (any, error) {
+	tokens, errptr1 := Tokens(r)
+	values, errptr2 := Values(tokens, opts...)
+	result, n, err := lastTopLevel(values)
+	if err == nil {
+		err = errors.Join(*errptr1, *errptr2)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch n {
+	case 0:
+		return nil, io.ErrUnexpectedEOF
+	case 1:
+		return result, nil
+	default:
+		return nil, fmt.Errorf("got %d top-level values, want 1", n)
+	}
+}
+
+// lastTopLevel consumes values and returns the last top-level value in it,
+// together with the number of top-level values seen.
+func lastTopLevel(values iter.Seq2[Pointer, any]) (any, int, error) {
+	var (
+		result any
+		n      int
+	)
+	for pointer, val := range values {
+		if len(pointer) == 0 {
+			result = val
+			n++
+		}
+	}
+	return result, n, nil
+}
+
+// rawTypeName returns the JSON type name of the raw value b.
+func rawTypeName(b []byte) string {
+	switch jsontext.Value(bytes.TrimLeft(b, " \t\r\n")).Kind() {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case '0':
+		return "number"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	return ""
+}
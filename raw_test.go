@@ -0,0 +1,62 @@
+package jseq_test
+
+import (
+	"encoding/json"
+	"encoding/json/jsontext"
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestRawValues(t *testing.T) {
+	val := map[string]any{
+		"lazy": jsontext.Value(`{"b": [1, "two"]}`),
+		"v1":   json.RawMessage(`true`),
+	}
+
+	got, err := jseq.Pointer{"lazy", "b", 1}.Locate(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "two" {
+		t.Errorf("got %v, want two", got)
+	}
+
+	if typ := jseq.TypeName(val["lazy"]); typ != "object" {
+		t.Errorf("got type %s, want object", typ)
+	}
+	if typ := jseq.TypeName(val["v1"]); typ != "boolean" {
+		t.Errorf("got type %s, want boolean", typ)
+	}
+
+	b, err := jseq.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"lazy":{"b":[1,"two"]},"v1":true}` {
+		t.Errorf("got %s", b)
+	}
+
+	cloned := jseq.Clone(val).(map[string]any)
+	cloned["lazy"].(jsontext.Value)[0] = '['
+	if val["lazy"].(jsontext.Value)[0] != '{' {
+		t.Error("raw value was shared by Clone")
+	}
+
+	var pointers []jseq.Pointer
+	for p := range jseq.Walk(val) {
+		pointers = append(pointers, p)
+	}
+	want := []jseq.Pointer{
+		{"lazy", "b", 0},
+		{"lazy", "b", 1},
+		{"lazy", "b"},
+		{"lazy"},
+		{"v1"},
+		nil,
+	}
+	if !reflect.DeepEqual(pointers, want) {
+		t.Errorf("got pointers %v, want %v", pointers, want)
+	}
+}
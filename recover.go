@@ -0,0 +1,293 @@
+package jseq
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/bobg/errors"
+)
+
+// RecordError is the error produced by [Records] for a malformed record.
+type RecordError struct {
+	// Record is the ordinal of the record, counting from zero.
+	Record int
+
+	// Offset is the byte offset in the input of the start of the record.
+	Offset int64
+
+	// Raw holds the bytes of the record,
+	// through the end of the line on which the problem was found,
+	// with surrounding whitespace removed.
+	Raw []byte
+
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d at offset %d: %s", e.Record, e.Offset, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Records reads the top-level JSON values ("records") in r,
+// producing each one decoded as by [Values] with the given options,
+// paired with a nil error.
+//
+// A malformed record does not end the sequence.
+// Instead it produces a [*RecordError] (with a nil value)
+// holding the record's raw bytes,
+// and reading resumes at the start of the next line of input.
+// This suits newline-delimited JSON, such as logs,
+// in which one bad line should not prevent reading the rest.
+//
+// Other errors, such as failures reading r, end the sequence.
+//
+// To capture the raw bytes of malformed records separately,
+// use the [DeadLetters] option.
+// To count the records read and skipped,
+// use [WithSummary].
+func Records(r io.Reader, opts ...Option) iter.Seq2[any, error] {
+	var conf config
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	return func(yield func(any, error) bool) {
+		var (
+			rc               = &recoverer{r: r}
+			src    io.Reader = rc
+			start  int64
+			record int
+			pos    int64 // input offset reached
+		)
+
+		var (
+			s          = conf.summary
+			recSummary Summary
+			recOpts    = opts
+		)
+		if s != nil {
+			*s = Summary{}
+			begin := time.Now()
+			defer func() {
+				s.Bytes = pos
+				s.Duration = time.Since(begin)
+			}()
+
+			// Each record is decoded with a summary of its own,
+			// which is added to the overall one.
+			recOpts = append(slices.Clip(opts), WithSummary(&recSummary))
+		}
+		fail := func(err error) {
+			if s != nil {
+				s.Err = err
+			}
+			yield(nil, err)
+		}
+
+		for {
+			dec := jsontext.NewDecoder(src)
+			for {
+				recStart := start + dec.InputOffset()
+				raw, err := dec.ReadValue()
+				pos = start + dec.InputOffset()
+				if errors.Is(err, io.EOF) {
+					return
+				}
+
+				if err == nil {
+					val, err := decodeValue(bytes.NewReader(raw), recOpts...)
+					if err != nil {
+						recErr := &RecordError{Record: record, Offset: recStart, Raw: bytes.Clone(raw), Err: err}
+						if dlErr := conf.deadLetter(recErr); dlErr != nil {
+							fail(dlErr)
+							return
+						}
+						err, val = recErr, nil
+					}
+					if s != nil {
+						if err != nil {
+							s.Skipped++
+						} else {
+							s.Records++
+							s.Values += recSummary.Values
+						}
+					}
+					rc.trim(start + dec.InputOffset())
+					record++
+					if !yield(val, err) {
+						return
+					}
+					continue
+				}
+
+				offset := start + dec.InputOffset()
+				var serr *jsontext.SyntacticError
+				switch {
+				case errors.As(err, &serr):
+					offset = start + serr.ByteOffset
+				case errors.Is(err, io.ErrUnexpectedEOF):
+				default:
+					fail(err)
+					return
+				}
+
+				rc.trim(recStart)
+				skipped, rest, more, rerr := rc.resync(offset)
+				if rerr != nil {
+					fail(rerr)
+					return
+				}
+				pos = rc.base
+				recErr := &RecordError{Record: record, Offset: recStart, Raw: bytes.TrimSpace(skipped), Err: err}
+				if dlErr := conf.deadLetter(recErr); dlErr != nil {
+					fail(dlErr)
+					return
+				}
+				if s != nil {
+					s.Skipped++
+					s.Recovered++
+				}
+				record++
+				if !yield(nil, recErr) || !more {
+					return
+				}
+				src, start = io.MultiReader(bytes.NewReader(rest), rc), rc.base
+				break
+			}
+		}
+	}
+}
+
+// DeadLetter holds the raw bytes of a malformed record.
+// See [DeadLetters].
+type DeadLetter struct {
+	// Record is the ordinal of the record, counting from zero.
+	Record int
+
+	// Offset is the byte offset in the input of the start of the record.
+	Offset int64
+
+	// Raw holds the bytes of the record, as in [RecordError],
+	// but limited in size.
+	Raw []byte
+
+	// Truncated tells whether Raw was cut short.
+	Truncated bool
+
+	// Err is the reason the record was rejected.
+	Err error
+}
+
+type deadLetterConfig struct {
+	f   func(DeadLetter) error
+	max int
+}
+
+// DeadLetters is an [Option] for [Records]
+// that delivers the raw bytes of each malformed record to f,
+// so that no input is silently lost.
+// At most max bytes of each record are delivered
+// (or all of them, if max is not positive).
+// An error from f ends the sequence produced by Records.
+//
+// This option has no effect on [Values].
+func DeadLetters(f func(DeadLetter) error, max int) Option {
+	return func(c *config) {
+		c.deadLetters = &deadLetterConfig{f: f, max: max}
+	}
+}
+
+// DeadLetterWriter is like [DeadLetters]
+// but writes the raw bytes of each malformed record to w,
+// each followed by a newline.
+func DeadLetterWriter(w io.Writer, max int) Option {
+	return DeadLetters(func(dl DeadLetter) error {
+		if _, err := w.Write(dl.Raw); err != nil {
+			return errors.Wrap(err, "writing dead letter")
+		}
+		_, err := w.Write([]byte{'\n'})
+		return errors.Wrap(err, "writing dead letter")
+	}, max)
+}
+
+func (c *config) deadLetter(e *RecordError) error {
+	if c.deadLetters == nil {
+		return nil
+	}
+	dl := DeadLetter{Record: e.Record, Offset: e.Offset, Raw: e.Raw, Err: e.Err}
+	if max := c.deadLetters.max; max > 0 && len(dl.Raw) > max {
+		dl.Raw, dl.Truncated = dl.Raw[:max], true
+	}
+	return c.deadLetters.f(dl)
+}
+
+// recoverer is an [io.Reader] that keeps the bytes it reads
+// so that a failed parse can resume at the next line.
+type recoverer struct {
+	r     io.Reader // the underlying input
+	buf   []byte    // bytes read from r, starting at offset base
+	base  int64
+	lines int // number of newlines before base
+}
+
+func (rc *recoverer) Read(p []byte) (int, error) {
+	n, err := rc.r.Read(p)
+	rc.buf = append(rc.buf, p[:n]...)
+	return n, err
+}
+
+// lineAt returns the line number of offset,
+// which must be at or after rc.base.
+func (rc *recoverer) lineAt(offset int64) int {
+	n := min(int(offset-rc.base), len(rc.buf))
+	return rc.lines + bytes.Count(rc.buf[:n], []byte{'\n'}) + 1
+}
+
+// trim discards the buffered bytes before offset.
+func (rc *recoverer) trim(offset int64) {
+	n := min(int(offset-rc.base), len(rc.buf))
+	rc.lines += bytes.Count(rc.buf[:n], []byte{'\n'})
+	rc.buf = slices.Delete(rc.buf, 0, n)
+	rc.base += int64(n)
+}
+
+// resync finds the start of the first line after offset,
+// reading more input if needed,
+// and discards the buffered bytes before it,
+// returning them as skipped.
+// It also returns a copy of the remaining buffered bytes,
+// or false if the input ends first.
+func (rc *recoverer) resync(offset int64) (skipped, rest []byte, more bool, err error) {
+	from := min(int(offset-rc.base), len(rc.buf))
+	for {
+		if i := bytes.IndexByte(rc.buf[from:], '\n'); i >= 0 {
+			n := from + i + 1
+			skipped = append(skipped, rc.buf[:n]...)
+			rc.trim(rc.base + int64(n))
+			return skipped, bytes.Clone(rc.buf), true, nil
+		}
+		skipped = append(skipped, rc.buf...)
+		rc.trim(rc.base + int64(len(rc.buf)))
+		from = 0
+
+		var chunk [4096]byte
+		_, err := rc.Read(chunk[:])
+		if errors.Is(err, io.EOF) {
+			if len(rc.buf) == 0 {
+				return skipped, nil, false, nil
+			}
+			continue
+		}
+		if err != nil {
+			return skipped, nil, false, errors.Wrap(err, "reading input")
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package jseq_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestRecords(t *testing.T) {
+	const inp = `{"a": 1}
+{"b": oops}
+  ["c"]
+{"d": "${UNSET}"}
+{"e": [1,
+2]}
+{"f": 
+`
+
+	type result struct {
+		val    string
+		record int
+		raw    string
+	}
+	var got []result
+	for val, err := range jseq.Records(strings.NewReader(inp), jseq.ExpandVars(jseq.MapResolver(nil), jseq.MissingVarError)) {
+		if err != nil {
+			var rerr *jseq.RecordError
+			if !errors.As(err, &rerr) {
+				t.Fatalf("got error %v, want RecordError", err)
+			}
+			got = append(got, result{record: rerr.Record, raw: string(rerr.Raw)})
+			continue
+		}
+		b, err := jseq.Marshal(val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, result{val: string(b)})
+	}
+
+	want := []result{
+		{val: `{"a":1}`},
+		{record: 1, raw: `{"b": oops}`},
+		{val: `["c"]`},
+		{record: 3, raw: `{"d": "${UNSET}"}`},
+		{val: `{"e":[1,2]}`},
+		{record: 5, raw: `{"f":`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDeadLetters(t *testing.T) {
+	const inp = `{"a": 1}
+{"b": "this record is malformed and long" x}
+[2]
+`
+
+	var (
+		buf     strings.Builder
+		letters []jseq.DeadLetter
+		n       int
+	)
+	for _, err := range jseq.Records(strings.NewReader(inp), jseq.DeadLetters(func(dl jseq.DeadLetter) error {
+		letters = append(letters, dl)
+		return nil
+	}, 10)) {
+		if err == nil {
+			n++
+		}
+	}
+	if n != 2 {
+		t.Errorf("got %d good records, want 2", n)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("got %d dead letters, want 1", len(letters))
+	}
+	if dl := letters[0]; dl.Record != 1 || string(dl.Raw) != `{"b": "thi` || !dl.Truncated || dl.Err == nil {
+		t.Errorf("got %+v", dl)
+	}
+
+	for range jseq.Records(strings.NewReader(inp), jseq.DeadLetterWriter(&buf, 0)) {
+	}
+	if want := `{"b": "this record is malformed and long" x}` + "\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	errStop := errors.New("stop")
+	var lastErr error
+	for _, err := range jseq.Records(strings.NewReader(inp), jseq.DeadLetters(func(jseq.DeadLetter) error { return errStop }, 0)) {
+		lastErr = err
+	}
+	if !errors.Is(lastErr, errStop) {
+		t.Errorf("got final error %v, want %v", lastErr, errStop)
+	}
+}
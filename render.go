@@ -0,0 +1,88 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"text/template"
+
+	"github.com/bobg/errors"
+)
+
+// Render executes tmpl once for each top-level value in values,
+// which is a sequence of pointer/value pairs as produced by [Values].
+// The value is the template's data ("dot").
+// Output is written to w.
+//
+// Templates may use the functions in [TemplateFuncs],
+// which must be added to the template before it is parsed.
+func Render(values iter.Seq2[Pointer, any], tmpl *template.Template, w io.Writer) error {
+	var record int
+	for pointer, val := range values {
+		if len(pointer) > 0 {
+			continue
+		}
+		if err := tmpl.Execute(w, val); err != nil {
+			return errors.Wrapf(err, "rendering record %d", record)
+		}
+		record++
+	}
+	return nil
+}
+
+// TemplateFuncs returns functions for use in templates executed by [Render].
+// Add them to a template with [template.Template.Funcs] before parsing it.
+// The functions are:
+//
+//   - locate VALUE POINTER:
+//     the part of VALUE located by POINTER,
+//     a JSON pointer string such as "/items/0/name",
+//     or nil if there is no such part
+//   - json VALUE:
+//     the JSON encoding of VALUE (see [Marshal])
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"locate": locateText,
+		"json": func(val any) (string, error) {
+			b, err := Marshal(val)
+			return string(b), err
+		},
+	}
+}
+
+// locateText is like [Pointer.Locate],
+// but takes a JSON pointer string,
+// interpreting each of its tokens as an object key or array index
+// according to the value it is applied to.
+// It returns nil if ptr locates nothing in val.
+func locateText(val any, ptr string) (any, error) {
+	jp := jsontext.Pointer(ptr)
+	if !jp.IsValid() {
+		return nil, fmt.Errorf("invalid JSON pointer %q", ptr)
+	}
+	for tok := range jp.Tokens() {
+		if e, ok := val.(Expanded); ok {
+			val = e.Value
+		}
+		switch v := val.(type) {
+		case map[string]any:
+			val = v[tok]
+
+		case *Object:
+			val, _ = v.Get(tok)
+
+		case []any:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, nil
+			}
+			val = v[i]
+
+		default:
+			return nil, nil
+		}
+	}
+	return val, nil
+}
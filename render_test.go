@@ -0,0 +1,41 @@
+package jseq_test
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/bobg/jseq"
+)
+
+func TestRender(t *testing.T) {
+	const inp = `
+{"name": "web", "ports": [80, 443], "tags": {"env": "prod"}}
+{"name": "db", "ports": [5432], "owner": null}
+`
+
+	tmpl := template.Must(template.New("").Funcs(jseq.TemplateFuncs()).Parse(
+		`{{.name}} port={{locate . "/ports/0"}} env={{locate . "/tags/env"}} owner={{.owner}} ports={{json .ports}}` + "\n",
+	))
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+
+	buf := new(strings.Builder)
+	if err := jseq.Render(values, tmpl, buf); err != nil {
+		t.Fatal(err)
+	}
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+
+	const want = `web port=80 env=prod owner=<no value> ports=[80,443]
+db port=5432 env=<no value> owner=null ports=[5432]
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
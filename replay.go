@@ -0,0 +1,170 @@
+package jseq
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/bobg/errors"
+)
+
+// MaxSpeed is a [ReplaySpec] speed that replays records without waiting.
+const MaxSpeed = -1
+
+// ReplaySpec describes the pacing performed by [Replay].
+type ReplaySpec struct {
+	// Timestamp locates the time of each record:
+	// a string in RFC 3339 format,
+	// or a number of seconds since the Unix epoch.
+	// Records without a timestamp are produced without waiting.
+	Timestamp Pointer
+
+	// Speed is the rate of replay relative to the original:
+	// 1 for real time,
+	// 10 for ten times as fast,
+	// 0.5 for half speed,
+	// and so on.
+	// The default, zero, means 1.
+	// [MaxSpeed] (or any negative number) means as fast as possible.
+	Speed float64
+
+	// MaxDelay, if positive, caps the wait before any one record,
+	// so that long gaps in an archive do not stall a replay.
+	MaxDelay time.Duration
+
+	// Now and Sleep, if not nil,
+	// replace [time.Now] and waiting for a duration (or until ctx is canceled),
+	// e.g. to replay in simulated time.
+	Now   func() time.Time
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Replay returns a [Transform] that paces the records (top-level values)
+// passing through it
+// according to their timestamps,
+// as described by spec.
+// This reproduces the temporal pattern of an archived stream,
+// e.g. for load-testing its consumers.
+//
+// The first record is produced at once.
+// Each later record is produced when the time elapsed since the first,
+// multiplied by the speed,
+// reaches the difference between their timestamps.
+// A record whose timestamp is earlier than one before it is produced without waiting.
+// Waiting does not drift:
+// if the consumer is slow,
+// later records are produced without waiting until the replay catches up.
+//
+// The pairs inside a record are produced together with it,
+// after the wait.
+//
+// The sequence ends early if ctx is canceled
+// or a timestamp cannot be parsed.
+// The error is placed in the returned error pointer,
+// which the caller may check after consuming the sequence.
+func Replay(ctx context.Context, spec ReplaySpec) (Transform, *error) {
+	var err error
+
+	if spec.Speed == 0 {
+		spec.Speed = 1
+	}
+	if spec.Now == nil {
+		spec.Now = time.Now
+	}
+	if spec.Sleep == nil {
+		spec.Sleep = sleepFor
+	}
+
+	f := func(values iter.Seq2[Pointer, any]) iter.Seq2[Pointer, any] {
+		return func(yield func(Pointer, any) bool) {
+			var (
+				held       []enrichPair
+				record     int
+				started    bool
+				start      time.Time // wall time of the first timestamped record
+				startTS    time.Time // its timestamp
+				lastTarget time.Time
+			)
+
+			for pointer, val := range values {
+				if len(pointer) > 0 {
+					held = append(held, enrichPair{pointer: slices.Clone(pointer), val: val})
+					continue
+				}
+
+				if spec.Speed > 0 {
+					if ts, ok, e := replayTimestamp(val, spec.Timestamp); e != nil {
+						err = errors.Wrapf(e, "in record %d", record)
+						return
+					} else if ok {
+						if !started {
+							started, start, startTS = true, spec.Now(), ts
+							lastTarget = start
+						} else {
+							offset := time.Duration(float64(ts.Sub(startTS)) / spec.Speed)
+							target := start.Add(offset)
+							if spec.MaxDelay > 0 && target.Sub(lastTarget) > spec.MaxDelay {
+								// Skip over the rest of a long gap.
+								skipped := target.Sub(lastTarget) - spec.MaxDelay
+								start = start.Add(-skipped)
+								target = target.Add(-skipped)
+							}
+							if target.After(lastTarget) {
+								lastTarget = target
+							}
+							if e := spec.sleepUntil(ctx, target); e != nil {
+								err = e
+								return
+							}
+						}
+					}
+				}
+
+				for _, h := range held {
+					if !yield(h.pointer, h.val) {
+						return
+					}
+				}
+				held = nil
+				if !yield(nil, val) {
+					return
+				}
+				record++
+			}
+		}
+	}
+
+	return f, &err
+}
+
+// replayTimestamp returns the timestamp of rec at ptr,
+// and false if there is none.
+func replayTimestamp(rec any, ptr Pointer) (time.Time, bool, error) {
+	if val, err := ptr.Locate(rec); err != nil || val == nil {
+		return time.Time{}, false, nil
+	}
+	ts, err := timestampAt(rec, ptr)
+	return ts, err == nil, err
+}
+
+// sleepUntil waits until t or until ctx is canceled.
+func (spec ReplaySpec) sleepUntil(ctx context.Context, t time.Time) error {
+	d := t.Sub(spec.Now())
+	if d <= 0 {
+		return ctx.Err()
+	}
+	return spec.Sleep(ctx, d)
+}
+
+// sleepFor waits for d or until ctx is canceled.
+func sleepFor(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
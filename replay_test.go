@@ -0,0 +1,115 @@
+package jseq_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobg/jseq"
+)
+
+func TestReplay(t *testing.T) {
+	// Timestamps span 2 seconds, with a 1-second gap in the middle.
+	const inp = `
+{"t": "2024-01-01T00:00:00Z", "n": 1}
+{"t": "2024-01-01T00:00:00.5Z", "n": 2}
+{"n": 3}
+{"t": "2024-01-01T00:00:01.5Z", "n": 4}
+{"t": "2024-01-01T00:00:01Z", "n": 5}
+{"t": 1704067202, "n": 6}
+`
+
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+	cases := []struct {
+		name string
+		spec jseq.ReplaySpec
+		want []time.Duration // the requested waits
+	}{
+		{name: "fast", spec: jseq.ReplaySpec{Speed: 10}, want: []time.Duration{ms(50), ms(100), ms(50)}},
+		{name: "capped", spec: jseq.ReplaySpec{Speed: 10, MaxDelay: ms(10)}, want: []time.Duration{ms(10), ms(10), ms(10)}},
+		{name: "max", spec: jseq.ReplaySpec{Speed: jseq.MaxSpeed}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Simulated time, advanced only by waiting.
+			var (
+				now   = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+				waits []time.Duration
+			)
+			tc.spec.Timestamp = jseq.Pointer{"t"}
+			tc.spec.Now = func() time.Time { return now }
+			tc.spec.Sleep = func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				now = now.Add(d)
+				return nil
+			}
+			transform, errptr := jseq.Replay(context.Background(), tc.spec)
+
+			var n int64
+			sink := jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+				if len(pointer) == 0 {
+					n++
+					got, _ := val.(map[string]any)["n"].(jseq.Number).Int()
+					if got != n {
+						t.Errorf("got record %d, want %d", got, n)
+					}
+				}
+				return nil
+			})
+			if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), sink, nil, transform); err != nil {
+				t.Fatal(err)
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+
+			if n != 6 {
+				t.Errorf("got %d records, want 6", n)
+			}
+			if !reflect.DeepEqual(waits, tc.want) {
+				t.Errorf("got waits %v, want %v", waits, tc.want)
+			}
+		})
+	}
+}
+
+func TestReplayCanceled(t *testing.T) {
+	const inp = `{"t": 0} {"t": 3600}`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	transform, errptr := jseq.Replay(ctx, jseq.ReplaySpec{Timestamp: jseq.Pointer{"t"}})
+
+	var n int
+	sink := jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+		if len(pointer) == 0 {
+			n++
+		}
+		return nil
+	})
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), sink, nil, transform); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("got %d records, want 1", n)
+	}
+	if *errptr != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", *errptr, context.DeadlineExceeded)
+	}
+}
+
+func TestReplayBadTimestamp(t *testing.T) {
+	transform, errptr := jseq.Replay(context.Background(), jseq.ReplaySpec{Timestamp: jseq.Pointer{"t"}})
+	sink := jseq.SinkFunc(func(jseq.Pointer, any) error { return nil })
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(`{"t": "yesterday"}`)), sink, nil, transform); err != nil {
+		t.Fatal(err)
+	}
+	if *errptr == nil {
+		t.Error("got no error, want one")
+	}
+}
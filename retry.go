@@ -0,0 +1,231 @@
+package jseq
+
+import (
+	"context"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/bobg/errors"
+)
+
+// Opener opens an input starting at the given byte offset.
+// See [RetryReader].
+type Opener func(ctx context.Context, offset int64) (io.ReadCloser, error)
+
+// RetryPolicy controls the behavior of [RetryReader].
+// The zero value is a usable default.
+type RetryPolicy struct {
+	// MaxRetries is the number of consecutive failed attempts allowed
+	// before giving up.
+	// The count resets whenever data is read successfully.
+	// The default is 5.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry.
+	// It doubles for each consecutive failure,
+	// up to MaxBackoff.
+	// The defaults are 100ms and 10s.
+	Backoff, MaxBackoff time.Duration
+
+	// IsTransient tells whether an error is worth retrying.
+	// The default, [IsTransient], recognizes timeouts, resets, and truncated responses.
+	IsTransient func(error) bool
+}
+
+// IsTransient tells whether err is likely to be a transient condition
+// that may succeed on retry:
+// a network timeout,
+// a reset or aborted connection,
+// an unexpected end of input,
+// or a server error response from an [HTTPRangeOpener].
+func IsTransient(err error) bool {
+	var (
+		nerr net.Error
+		terr transientError
+	)
+	switch {
+	case errors.As(err, &nerr) && nerr.Timeout():
+		return true
+	case errors.As(err, &terr):
+		return true
+	case errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, syscall.EPIPE):
+		return true
+	}
+	return false
+}
+
+// transientError marks an error as transient.
+type transientError struct {
+	err error
+}
+
+func (e transientError) Error() string { return e.err.Error() }
+func (e transientError) Unwrap() error { return e.err }
+
+// RetryReader returns a reader of the input opened by open,
+// which transparently recovers from transient errors
+// by reopening the input at the offset where reading left off.
+// Retries back off exponentially according to policy,
+// and stop early if ctx is canceled.
+//
+// Because the reader delivers an uninterrupted stream of bytes,
+// a token stream parsed from it (see [RetrySource])
+// resumes exactly where it left off.
+//
+// Closing the reader closes the currently open input.
+func RetryReader(ctx context.Context, open Opener, policy RetryPolicy) io.ReadCloser {
+	if policy.MaxRetries <= 0 {
+		policy.MaxRetries = 5
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = 100 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 10 * time.Second
+	}
+	if policy.IsTransient == nil {
+		policy.IsTransient = IsTransient
+	}
+	return &retryReader{ctx: ctx, open: open, policy: policy}
+}
+
+// RetrySource returns a [Source] that parses tokens,
+// using [Tokens] with the given options,
+// from a [RetryReader] on the input opened by open.
+func RetrySource(ctx context.Context, open Opener, policy RetryPolicy, opts ...jsontext.Options) Source {
+	return ReaderSource(RetryReader(ctx, open, policy), opts...)
+}
+
+type retryReader struct {
+	ctx      context.Context
+	open     Opener
+	policy   RetryPolicy
+	rc       io.ReadCloser
+	offset   int64
+	failures int // consecutive
+}
+
+func (r *retryReader) Read(p []byte) (int, error) {
+	for {
+		if r.rc == nil {
+			if err := r.backoff(); err != nil {
+				return 0, err
+			}
+			rc, err := r.open(r.ctx, r.offset)
+			if err != nil {
+				if err := r.fail(err); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			r.rc = rc
+		}
+
+		n, err := r.rc.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			r.failures = 0
+		}
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+
+		r.rc.Close()
+		r.rc = nil
+		if err := r.fail(err); err != nil {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// fail records a failure,
+// returning err if it is not to be retried.
+func (r *retryReader) fail(err error) error {
+	if !r.policy.IsTransient(err) {
+		return err
+	}
+	r.failures++
+	if r.failures > r.policy.MaxRetries {
+		return errors.Wrapf(err, "giving up after %d retries at offset %d", r.policy.MaxRetries, r.offset)
+	}
+	return nil
+}
+
+// backoff waits before a retry.
+func (r *retryReader) backoff() error {
+	if r.failures == 0 {
+		return nil
+	}
+	d := r.policy.Backoff
+	for i := 1; i < r.failures && d < r.policy.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, r.policy.MaxBackoff)
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (r *retryReader) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+	err := r.rc.Close()
+	r.rc = nil
+	return err
+}
+
+// HTTPRangeOpener returns an [Opener] that fetches url with client
+// (or [http.DefaultClient] if client is nil),
+// using an HTTP Range request to start at a nonzero offset.
+// Server errors (status 5xx) and 429 Too Many Requests are treated as transient.
+// It is an error if the server does not honor the range request.
+func HTTPRangeOpener(client *http.Client, url string) Opener {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating request")
+		}
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetching %s", url)
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusPartialContent && offset > 0:
+			return resp.Body, nil
+		case resp.StatusCode == http.StatusOK && offset == 0:
+			return resp.Body, nil
+		}
+
+		resp.Body.Close()
+		err = fmt.Errorf("fetching %s at offset %d: status %s", url, offset, resp.Status)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return nil, transientError{err: err}
+		}
+		return nil, err
+	}
+}
@@ -0,0 +1,102 @@
+package jseq_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobg/jseq"
+)
+
+func TestRetryReader(t *testing.T) {
+	inp := []byte(strings.Repeat(`{"a": [1, 2, 3], "b": "hello"}`+"\n", 50))
+
+	var opens int
+	open := func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		opens++
+		if opens == 3 {
+			return nil, os.ErrDeadlineExceeded // a transient failure to open
+		}
+		// Each connection fails with a timeout after delivering some bytes.
+		return io.NopCloser(&flakyReader{r: bytes.NewReader(inp[offset:]), n: 97}), nil
+	}
+
+	src := jseq.RetrySource(context.Background(), open, jseq.RetryPolicy{Backoff: time.Millisecond})
+	got := records(t, src)
+	if len(got) != 50 {
+		t.Errorf("got %d records, want 50", len(got))
+	}
+	if opens < 10 {
+		t.Errorf("got %d opens, want many", opens)
+	}
+}
+
+func TestRetryReaderGivesUp(t *testing.T) {
+	open := func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		return nil, os.ErrDeadlineExceeded
+	}
+	r := jseq.RetryReader(context.Background(), open, jseq.RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond})
+	if _, err := io.ReadAll(r); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+
+	errPermanent := errors.New("permanent")
+	open = func(ctx context.Context, offset int64) (io.ReadCloser, error) {
+		return nil, errPermanent
+	}
+	r = jseq.RetryReader(context.Background(), open, jseq.RetryPolicy{})
+	if _, err := io.ReadAll(r); !errors.Is(err, errPermanent) {
+		t.Errorf("got error %v, want %v", err, errPermanent)
+	}
+}
+
+func TestHTTPRangeOpener(t *testing.T) {
+	inp := strings.Repeat(`{"x": "yyyyyyyyyy"}`+"\n", 1000)
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if requests == 1 {
+			// Promise everything, deliver half, and hang up.
+			w.Header().Set("Content-Length", "20000")
+			w.Write([]byte(inp[:len(inp)/2]))
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, req, "data.json", time.Time{}, strings.NewReader(inp))
+	}))
+	defer srv.Close()
+
+	src := jseq.RetrySource(context.Background(), jseq.HTTPRangeOpener(srv.Client(), srv.URL), jseq.RetryPolicy{Backoff: time.Millisecond})
+	if got := records(t, src); len(got) != 1000 {
+		t.Errorf("got %d records, want 1000", len(got))
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+}
+
+// flakyReader reads at most n bytes from r,
+// then fails with a timeout.
+type flakyReader struct {
+	r io.Reader
+	n int
+}
+
+func (f *flakyReader) Read(p []byte) (int, error) {
+	if f.n == 0 {
+		return 0, os.ErrDeadlineExceeded
+	}
+	if len(p) > f.n {
+		p = p[:f.n]
+	}
+	n, err := f.r.Read(p)
+	f.n -= n
+	return n, err
+}
@@ -0,0 +1,117 @@
+package jseq
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/bobg/errors"
+)
+
+// Router dispatches top-level JSON values to handlers according to their kind,
+// as when a single stream interleaves different types of event.
+//
+// If Discriminator is nil,
+// the kind of a value is its JSON type (see [TypeName]).
+// Otherwise the kind is the string found at Discriminator within the value,
+// such as the "type" field of an event.
+// A non-string discriminator is converted to its JSON encoding;
+// a missing one yields the empty kind.
+//
+// Router implements [Sink],
+// so it can be the output of a pipeline run by [Pipe].
+type Router struct {
+	Discriminator Pointer
+
+	// Handlers maps each kind to its handler.
+	Handlers map[string]func(any) error
+
+	// Unknown, if not nil, handles values whose kinds have no handler,
+	// e.g. by writing them to a dead-letter queue.
+	// If it is nil,
+	// such values produce an [*UnknownKindError].
+	Unknown func(kind string, val any) error
+}
+
+// UnknownKindError is the error produced by a [Router]
+// for a value whose kind has no handler.
+type UnknownKindError struct {
+	Kind string
+}
+
+func (e *UnknownKindError) Error() string {
+	return fmt.Sprintf("no handler for kind %q", e.Kind)
+}
+
+// Kind returns the kind of val (see [Router]).
+func (r *Router) Kind(val any) (string, error) {
+	if r.Discriminator == nil {
+		return TypeName(val), nil
+	}
+	d, err := r.Discriminator.Locate(val)
+	if err != nil {
+		return "", nil
+	}
+	switch d := d.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return d, nil
+	case Expanded:
+		if s, ok := d.Value.(string); ok {
+			return s, nil
+		}
+	}
+	b, err := Marshal(d)
+	if err != nil {
+		return "", errors.Wrap(err, "encoding discriminator")
+	}
+	return string(b), nil
+}
+
+// Route dispatches val to its handler.
+func (r *Router) Route(val any) error {
+	kind, err := r.Kind(val)
+	if err != nil {
+		return err
+	}
+	if h, ok := r.Handlers[kind]; ok {
+		return h(val)
+	}
+	if r.Unknown != nil {
+		return r.Unknown(kind, val)
+	}
+	return &UnknownKindError{Kind: kind}
+}
+
+// Records consumes a sequence of pointer/value pairs as produced by [Values]
+// and dispatches each top-level value with [Router.Route],
+// stopping at the first error.
+func (r *Router) Records(values iter.Seq2[Pointer, any]) error {
+	var record int
+	for pointer, val := range values {
+		if len(pointer) > 0 {
+			continue
+		}
+		if err := r.Route(val); err != nil {
+			return errors.Wrapf(err, "in record %d", record)
+		}
+		record++
+	}
+	return nil
+}
+
+// Consume implements [Sink.Consume].
+// It dispatches top-level values with [Router.Route]
+// and ignores others.
+func (r *Router) Consume(pointer Pointer, val any) error {
+	if len(pointer) > 0 {
+		return nil
+	}
+	return r.Route(val)
+}
+
+// Close implements [Sink.Close].
+// It does nothing.
+func (r *Router) Close() error {
+	return nil
+}
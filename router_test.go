@@ -0,0 +1,88 @@
+package jseq_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestRouter(t *testing.T) {
+	const inp = `
+{"type": "click", "x": 1}
+{"type": "view", "page": "/"}
+{"type": "scroll"}
+{"type": 7}
+{"other": true}
+[1, 2]
+`
+
+	var got []string
+	handler := func(name string) func(any) error {
+		return func(any) error {
+			got = append(got, name)
+			return nil
+		}
+	}
+
+	r := &jseq.Router{
+		Discriminator: jseq.Pointer{"type"},
+		Handlers: map[string]func(any) error{
+			"click": handler("click"),
+			"view":  handler("view"),
+			"7":     handler("seven"),
+		},
+		Unknown: func(kind string, val any) error {
+			got = append(got, "unknown "+kind)
+			return nil
+		},
+	}
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), r, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"click", "view", "unknown scroll", "seven", "unknown ", "unknown "}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRouterByType(t *testing.T) {
+	const inp = `{"a": 1} [2] "three" 4`
+
+	var got []string
+	handler := func(name string) func(any) error {
+		return func(any) error {
+			got = append(got, name)
+			return nil
+		}
+	}
+
+	r := &jseq.Router{
+		Handlers: map[string]func(any) error{
+			"object": handler("object"),
+			"array":  handler("array"),
+			"string": handler("string"),
+		},
+	}
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+	err := r.Records(values)
+
+	var uerr *jseq.UnknownKindError
+	if !errors.As(err, &uerr) || uerr.Kind != "number" {
+		t.Errorf("got error %v, want UnknownKindError for number", err)
+	}
+	if err := errors.Join(*errptr1, *errptr2); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"object", "array", "string"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,469 @@
+package jseq
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bobg/errors"
+)
+
+// Rules is a compiled set of routing rules,
+// which decide where each record (top-level value) in a stream goes.
+// Rules are written as a JSON document,
+// so that pipelines can be adjusted without writing Go.
+// See [CompileRules] for the format.
+type Rules struct {
+	// Default is the destination of records that no rule routes or drops.
+	// If it is empty, such records are dropped.
+	Default string
+
+	rules []rule
+}
+
+type rule struct {
+	name    string
+	conds   []ruleCond
+	action  string // "route", "drop", or "transform"
+	to      string
+	compute []ComputedField
+	cont    bool
+}
+
+type ruleCond struct {
+	pointer string // JSON pointer syntax, for locateText
+	op      string
+	value   any
+	re      *regexp.Regexp // for "matches"
+}
+
+// Routed is a record and the destination to which [Rules] routed it.
+type Routed struct {
+	To    string
+	Value any
+}
+
+// ReadRules reads a rules document from r and compiles it.
+// See [CompileRules].
+func ReadRules(r io.Reader) (*Rules, error) {
+	doc, err := decodeValue(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading rules")
+	}
+	return CompileRules(doc)
+}
+
+// CompileRules compiles a rules document,
+// a value of the kind produced by [Values].
+// It looks like this:
+//
+//	{
+//	  "default": "other",
+//	  "rules": [
+//	    {
+//	      "name": "drop-tests",
+//	      "match": [{"pointer": "/env", "op": "==", "value": "test"}],
+//	      "action": "drop"
+//	    },
+//	    {
+//	      "match": [{"pointer": "/price", "op": "exists"}],
+//	      "action": "transform",
+//	      "compute": ["total = price * quantity"]
+//	    },
+//	    {
+//	      "name": "big-orders",
+//	      "match": [{"pointer": "/total", "op": ">=", "value": 100}],
+//	      "action": "route",
+//	      "to": "big"
+//	    }
+//	  ]
+//	}
+//
+// The rules are tried in order against each record.
+// A rule applies when all of its match conditions hold
+// (so a rule with no conditions always applies).
+// Each condition locates a value in the record with a JSON pointer
+// and tests it with one of these operators:
+//
+//   - "==", "!=": whether the value equals "value"
+//   - "<", "<=", ">", ">=": how the value compares with "value";
+//     both must be numbers or both strings
+//   - "exists", "missing": whether the pointer locates a value at all
+//     (a null value exists)
+//   - "contains": whether the value, a string, contains "value" as a substring,
+//     or, an array, contains an element equal to "value"
+//   - "matches": whether the value, a string, matches the regular expression "value"
+//   - "in": whether the value equals an element of "value", an array
+//
+// A condition on a missing value is false,
+// except for "missing" and "!=".
+//
+// When a rule applies, its action happens:
+//
+//   - "route" sends the record to the destination named by "to"
+//     and stops trying rules,
+//     unless "continue" is true
+//   - "drop" discards the record and stops trying rules
+//     (destinations chosen by earlier rules still get it)
+//   - "transform" adds computed fields to the record,
+//     given by "compute" as definitions for [ParseComputedField],
+//     and goes on to the next rule
+//
+// A destination gets the record as it is when routed there.
+// If no rule routes or drops a record,
+// it goes to the destination named by "default",
+// or is dropped if there is none.
+func CompileRules(doc any) (*Rules, error) {
+	top, ok := asObject(decodedForm(doc))
+	if !ok {
+		return nil, fmt.Errorf("rules document is %s, want object", TypeName(doc))
+	}
+	if err := checkRuleKeys(top, "default", "rules"); err != nil {
+		return nil, err
+	}
+
+	result := new(Rules)
+	if d, ok := top["default"]; ok {
+		s, ok := decodedForm(d).(string)
+		if !ok {
+			return nil, fmt.Errorf("default is %s, want string", TypeName(d))
+		}
+		result.Default = s
+	}
+
+	list, ok := decodedForm(top["rules"]).([]any)
+	if !ok && top["rules"] != nil {
+		return nil, fmt.Errorf("rules is %s, want array", TypeName(top["rules"]))
+	}
+	for i, elt := range list {
+		r, err := compileRule(elt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "in rule %d", i)
+		}
+		if r.name == "" {
+			r.name = fmt.Sprintf("#%d", i)
+		}
+		result.rules = append(result.rules, r)
+	}
+
+	return result, nil
+}
+
+func compileRule(doc any) (rule, error) {
+	m, ok := asObject(decodedForm(doc))
+	if !ok {
+		return rule{}, fmt.Errorf("rule is %s, want object", TypeName(doc))
+	}
+	if err := checkRuleKeys(m, "name", "match", "action", "to", "compute", "continue"); err != nil {
+		return rule{}, err
+	}
+
+	var (
+		r   rule
+		err error
+	)
+	if r.name, err = ruleString(m, "name"); err != nil {
+		return rule{}, err
+	}
+	if r.action, err = ruleString(m, "action"); err != nil {
+		return rule{}, err
+	}
+	if r.to, err = ruleString(m, "to"); err != nil {
+		return rule{}, err
+	}
+	if c, ok := m["continue"]; ok {
+		if r.cont, ok = decodedForm(c).(bool); !ok {
+			return rule{}, fmt.Errorf("continue is %s, want boolean", TypeName(c))
+		}
+	}
+
+	switch r.action {
+	case "route":
+		if _, ok := m["to"]; !ok {
+			return rule{}, fmt.Errorf("route action has no destination")
+		}
+	case "drop":
+	case "transform":
+		defs, ok := decodedForm(m["compute"]).([]any)
+		if !ok {
+			return rule{}, fmt.Errorf("transform action needs a compute array")
+		}
+		for _, def := range defs {
+			s, ok := decodedForm(def).(string)
+			if !ok {
+				return rule{}, fmt.Errorf("compute element is %s, want string", TypeName(def))
+			}
+			f, err := ParseComputedField(s)
+			if err != nil {
+				return rule{}, err
+			}
+			r.compute = append(r.compute, f)
+		}
+	case "":
+		return rule{}, fmt.Errorf("no action")
+	default:
+		return rule{}, fmt.Errorf("unknown action %q", r.action)
+	}
+
+	if match, ok := m["match"]; ok {
+		conds, ok := decodedForm(match).([]any)
+		if !ok {
+			return rule{}, fmt.Errorf("match is %s, want array", TypeName(match))
+		}
+		for j, c := range conds {
+			cond, err := compileRuleCond(c)
+			if err != nil {
+				return rule{}, errors.Wrapf(err, "in condition %d", j)
+			}
+			r.conds = append(r.conds, cond)
+		}
+	}
+
+	return r, nil
+}
+
+func compileRuleCond(doc any) (ruleCond, error) {
+	m, ok := asObject(decodedForm(doc))
+	if !ok {
+		return ruleCond{}, fmt.Errorf("condition is %s, want object", TypeName(doc))
+	}
+	if err := checkRuleKeys(m, "pointer", "op", "value"); err != nil {
+		return ruleCond{}, err
+	}
+
+	var (
+		c   ruleCond
+		err error
+	)
+	if c.pointer, err = ruleString(m, "pointer"); err != nil {
+		return ruleCond{}, err
+	}
+	if c.pointer != "" && !strings.HasPrefix(c.pointer, "/") {
+		return ruleCond{}, fmt.Errorf("pointer %q does not begin with /", c.pointer)
+	}
+	if c.op, err = ruleString(m, "op"); err != nil {
+		return ruleCond{}, err
+	}
+	c.value = decodedForm(m["value"])
+
+	switch c.op {
+	case "exists", "missing":
+	case "==", "!=", "<", "<=", ">", ">=", "contains":
+		if _, ok := m["value"]; !ok {
+			return ruleCond{}, fmt.Errorf("operator %s needs a value", c.op)
+		}
+	case "matches":
+		s, ok := c.value.(string)
+		if !ok {
+			return ruleCond{}, fmt.Errorf("operator matches needs a string value")
+		}
+		if c.re, err = regexp.Compile(s); err != nil {
+			return ruleCond{}, errors.Wrap(err, "compiling regular expression")
+		}
+	case "in":
+		if _, ok := c.value.([]any); !ok {
+			return ruleCond{}, fmt.Errorf("operator in needs an array value")
+		}
+	case "":
+		return ruleCond{}, fmt.Errorf("no operator")
+	default:
+		return ruleCond{}, fmt.Errorf("unknown operator %q", c.op)
+	}
+
+	return c, nil
+}
+
+// checkRuleKeys reports an error if m has a key not in allowed,
+// so that misspellings in a rules document do not go unnoticed.
+func checkRuleKeys(m map[string]any, allowed ...string) error {
+	for _, key := range SortedKeys(m) {
+		if !slices.Contains(allowed, key) {
+			return fmt.Errorf("unknown field %q", key)
+		}
+	}
+	return nil
+}
+
+// ruleString returns the string at m[key],
+// or the empty string if there is none.
+func ruleString(m map[string]any, key string) (string, error) {
+	v, ok := m[key]
+	if !ok {
+		return "", nil
+	}
+	s, ok := decodedForm(v).(string)
+	if !ok {
+		return "", fmt.Errorf("%s is %s, want string", key, TypeName(v))
+	}
+	return s, nil
+}
+
+// Apply applies the rules to rec,
+// a top-level value of the kind produced by [Values],
+// and returns the destinations to which it is routed,
+// in order,
+// each with the record as it was when routed.
+// The result is empty if the record is dropped.
+func (r *Rules) Apply(rec any) ([]Routed, error) {
+	var result []Routed
+
+	for _, rl := range r.rules {
+		if !slices.ContainsFunc(rl.conds, func(c ruleCond) bool { return !c.holds(rec) }) {
+			switch rl.action {
+			case "route":
+				result = append(result, Routed{To: rl.to, Value: rec})
+				if !rl.cont {
+					return result, nil
+				}
+
+			case "drop":
+				return result, nil
+
+			case "transform":
+				for _, f := range rl.compute {
+					v, err := f.Expr.Eval(rec)
+					if err != nil {
+						return nil, errors.Wrapf(err, "computing %s in rule %s", f.Pointer.Text(), rl.name)
+					}
+					if rec, err = f.Pointer.Set(rec, v); err != nil {
+						return nil, errors.Wrapf(err, "setting %s in rule %s", f.Pointer.Text(), rl.name)
+					}
+				}
+			}
+		}
+	}
+
+	if len(result) == 0 && r.Default != "" {
+		result = append(result, Routed{To: r.Default, Value: rec})
+	}
+	return result, nil
+}
+
+func (c ruleCond) holds(rec any) bool {
+	val, err := locateText(rec, c.pointer)
+	if err != nil {
+		return false
+	}
+
+	switch c.op {
+	case "exists":
+		return val != nil
+	case "missing":
+		return val == nil
+	}
+
+	if val == nil {
+		return c.op == "!="
+	}
+	val = decodedForm(val)
+
+	switch c.op {
+	case "==":
+		return exprEqual(val, c.value)
+	case "!=":
+		return !exprEqual(val, c.value)
+
+	case "<", "<=", ">", ">=":
+		cmp, err := exprCompare(val, c.value)
+		if err != nil {
+			return false
+		}
+		switch c.op {
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case ">":
+			return cmp > 0
+		default:
+			return cmp >= 0
+		}
+
+	case "contains":
+		switch val := val.(type) {
+		case string:
+			sub, ok := c.value.(string)
+			return ok && strings.Contains(val, sub)
+		case []any:
+			return slices.ContainsFunc(val, func(elt any) bool { return exprEqual(decodedForm(elt), c.value) })
+		}
+		return false
+
+	case "matches":
+		s, ok := val.(string)
+		return ok && c.re.MatchString(s)
+
+	case "in":
+		return slices.ContainsFunc(c.value.([]any), func(elt any) bool { return exprEqual(val, decodedForm(elt)) })
+	}
+
+	return false
+}
+
+// Destinations returns the names of the destinations
+// that r may route records to,
+// including the default,
+// in sorted order.
+func (r *Rules) Destinations() []string {
+	dests := make(map[string]struct{})
+	if r.Default != "" {
+		dests[r.Default] = struct{}{}
+	}
+	for _, rl := range r.rules {
+		if rl.action == "route" {
+			dests[rl.to] = struct{}{}
+		}
+	}
+	return slices.Sorted(maps.Keys(dests))
+}
+
+// Sink returns a [Sink] that applies r to each top-level value it consumes
+// and passes the results to the sinks for their destinations,
+// with the empty pointer.
+// Other values are ignored.
+// It is an error if sinks lacks a destination in [Rules.Destinations].
+//
+// Closing the result closes each of the given sinks.
+func (r *Rules) Sink(sinks map[string]Sink) (Sink, error) {
+	for _, dest := range r.Destinations() {
+		if _, ok := sinks[dest]; !ok {
+			return nil, fmt.Errorf("no sink for destination %q", dest)
+		}
+	}
+	return &rulesSink{rules: r, sinks: sinks}, nil
+}
+
+type rulesSink struct {
+	rules  *Rules
+	sinks  map[string]Sink
+	record int
+}
+
+func (s *rulesSink) Consume(pointer Pointer, val any) error {
+	if len(pointer) > 0 {
+		return nil
+	}
+	defer func() { s.record++ }()
+
+	routed, err := s.rules.Apply(val)
+	if err != nil {
+		return errors.Wrapf(err, "in record %d", s.record)
+	}
+	for _, rt := range routed {
+		if err := s.sinks[rt.To].Consume(nil, rt.Value); err != nil {
+			return errors.Wrapf(err, "sending record %d to %s", s.record, rt.To)
+		}
+	}
+	return nil
+}
+
+func (s *rulesSink) Close() error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(s.sinks)) {
+		errs = append(errs, errors.Wrapf(s.sinks[name].Close(), "closing %s", name))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,145 @@
+package jseq_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+const testRules = `{
+  "default": "other",
+  "rules": [
+    {"name": "drop-tests", "match": [{"pointer": "/env", "op": "==", "value": "test"}], "action": "drop"},
+    {"match": [{"pointer": "/price", "op": "exists"}, {"pointer": "/quantity", "op": "exists"}], "action": "transform", "compute": ["total = price * quantity"]},
+    {"name": "audit", "match": [{"pointer": "/tags", "op": "contains", "value": "audit"}], "action": "route", "to": "audit", "continue": true},
+    {"name": "big", "match": [{"pointer": "/total", "op": ">=", "value": 100}], "action": "route", "to": "big"},
+    {"match": [{"pointer": "/region", "op": "in", "value": ["eu", "uk"]}, {"pointer": "/id", "op": "matches", "value": "^E-\\d+$"}], "action": "route", "to": "europe"}
+  ]
+}`
+
+func TestRules(t *testing.T) {
+	rules, err := jseq.ReadRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := rules.Destinations(), []string{"audit", "big", "europe", "other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got destinations %v, want %v", got, want)
+	}
+
+	const inp = `
+{"env": "test", "price": 1000, "quantity": 1}
+{"price": 50, "quantity": 3}
+{"price": 5, "quantity": 3, "tags": ["audit"]}
+{"price": 50, "quantity": 3, "tags": ["audit"]}
+{"region": "uk", "id": "E-17"}
+{"region": "uk", "id": "X-17"}
+{"tags": "audit log"}
+`
+
+	got := make(map[string][]string)
+	sinks := make(map[string]jseq.Sink)
+	for _, dest := range rules.Destinations() {
+		sinks[dest] = jseq.SinkFunc(func(pointer jseq.Pointer, val any) error {
+			b, err := jseq.Marshal(val)
+			if err != nil {
+				return err
+			}
+			got[dest] = append(got[dest], string(b))
+			return nil
+		})
+	}
+	sink, err := rules.Sink(sinks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), sink, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"big": {
+			`{"price":50,"quantity":3,"total":150}`,
+			`{"price":50,"quantity":3,"tags":["audit"],"total":150}`,
+		},
+		"audit": {
+			`{"price":5,"quantity":3,"tags":["audit"],"total":15}`,
+			`{"price":50,"quantity":3,"tags":["audit"],"total":150}`,
+			`{"tags":"audit log"}`,
+		},
+		"europe": {
+			`{"id":"E-17","region":"uk"}`,
+		},
+		"other": {
+			`{"id":"X-17","region":"uk"}`,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRulesMissing(t *testing.T) {
+	rules, err := jseq.ReadRules(strings.NewReader(`{"rules": [
+		{"match": [{"pointer": "/a", "op": "!=", "value": 1}], "action": "route", "to": "not-one"},
+		{"match": [{"pointer": "/a", "op": "missing"}], "action": "route", "to": "never"},
+		{"action": "route", "to": "one"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		rec  any
+		want string
+	}{
+		{rec: map[string]any{"a": jseq.Int(1)}, want: "one"},
+		{rec: map[string]any{"a": jseq.Float(1.0)}, want: "one"},
+		{rec: map[string]any{"a": jseq.Int(2)}, want: "not-one"},
+		{rec: map[string]any{}, want: "not-one"},
+	}
+	for _, tc := range cases {
+		routed, err := rules.Apply(tc.rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(routed) != 1 || routed[0].To != tc.want {
+			t.Errorf("%v: got %v, want %s", tc.rec, routed, tc.want)
+		}
+	}
+}
+
+func TestRulesErrors(t *testing.T) {
+	cases := []string{
+		`[]`,
+		`{"rulez": []}`,
+		`{"rules": [{"action": "route"}]}`,
+		`{"rules": [{"action": "explode"}]}`,
+		`{"rules": [{}]}`,
+		`{"rules": [{"action": "drop", "match": [{"pointer": "/a", "op": "~"}]}]}`,
+		`{"rules": [{"action": "drop", "match": [{"pointer": "a", "op": "exists"}]}]}`,
+		`{"rules": [{"action": "drop", "match": [{"pointer": "/a", "op": "=="}]}]}`,
+		`{"rules": [{"action": "drop", "match": [{"pointer": "/a", "op": "matches", "value": "("}]}]}`,
+		`{"rules": [{"action": "drop", "match": [{"pointer": "/a", "op": "in", "value": 1}]}]}`,
+		`{"rules": [{"action": "transform", "compute": ["x = "]}]}`,
+		`{"rules": [{"action": "drop", "continue": "yes"}]}`,
+	}
+	for _, doc := range cases {
+		if _, err := jseq.ReadRules(strings.NewReader(doc)); err == nil {
+			t.Errorf("%s: got no error, want one", doc)
+		}
+	}
+}
+
+func TestRulesSinkMissingDestination(t *testing.T) {
+	rules, err := jseq.ReadRules(strings.NewReader(`{"default": "x", "rules": [{"action": "route", "to": "y"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rules.Sink(map[string]jseq.Sink{"x": jseq.SinkFunc(func(jseq.Pointer, any) error { return nil })}); err == nil {
+		t.Error("got no error, want one")
+	}
+}
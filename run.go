@@ -0,0 +1,125 @@
+package jseq
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"github.com/bobg/errors"
+)
+
+// Consumer is a consumer of pointer/value pairs in a pipeline run by [Run].
+// It should consume values until it is done with them
+// and return nil,
+// or return an error to stop the whole pipeline.
+// The context is canceled when the pipeline stops early.
+type Consumer func(ctx context.Context, values iter.Seq2[Pointer, any]) error
+
+// Run parses tokens from src with [Values]
+// and delivers every pointer/value pair to each of the consumers,
+// each running in its own goroutine.
+// Consumers may share the work of a single parse in this way,
+// e.g. with one consumer computing statistics
+// while another routes records to handlers with [Demux].
+//
+// A consumer that returns early (with a nil error)
+// receives no more values,
+// while the others continue.
+// The first error from any consumer, or from src or the parser,
+// cancels the context passed to all the consumers
+// and stops the pipeline.
+// Run returns after all the consumers have returned,
+// with the first error, or with ctx.Err() if ctx was canceled.
+//
+// Values and pointers are shared among consumers,
+// which therefore must not modify them.
+// (Use [Clone] to get a modifiable copy.)
+func Run(ctx context.Context, src Source, consumers ...Consumer) error {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	type pair struct {
+		pointer Pointer
+		val     any
+	}
+
+	var (
+		wg    sync.WaitGroup
+		chans = make([]chan pair, len(consumers))
+		dones = make([]chan struct{}, len(consumers))
+	)
+	for i, c := range consumers {
+		chans[i] = make(chan pair, 16)
+		dones[i] = make(chan struct{})
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(dones[i])
+
+			values := func(yield func(Pointer, any) bool) {
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case p, ok := <-chans[i]:
+						if !ok || !yield(p.pointer, p.val) {
+							return
+						}
+					}
+				}
+			}
+			if err := c(ctx, values); err != nil {
+				fail(errors.Wrapf(err, "in consumer %d", i))
+			}
+		}()
+	}
+
+	tokens, errptr1 := src.Tokens()
+	values, errptr2 := Values(tokens, ClonePointers()) // consumers run concurrently with the parser
+
+	finished := make([]bool, len(consumers))
+
+pairs:
+	for pointer, val := range values {
+		for i, ch := range chans {
+			if finished[i] {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				break pairs
+			case <-dones[i]:
+				finished[i] = true
+			case ch <- pair{pointer: pointer, val: val}:
+			}
+		}
+	}
+	if err := errors.Join(*errptr1, *errptr2); err != nil {
+		fail(err)
+	}
+	for _, ch := range chans {
+		close(ch)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return parent.Err()
+}
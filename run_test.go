@@ -0,0 +1,146 @@
+package jseq_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobg/jseq"
+)
+
+const runInput = `
+{"user": {"email": "a@x"}, "n": 1}
+{"user": {"email": "b@x"}, "n": 2}
+{"user": {"email": "c@x"}, "n": 3}
+`
+
+func TestRun(t *testing.T) {
+	var (
+		records int
+		emails  []string
+		first   any
+	)
+
+	err := jseq.Run(context.Background(), jseq.ReaderSource(strings.NewReader(runInput)),
+		func(ctx context.Context, values iter.Seq2[jseq.Pointer, any]) error {
+			for pointer := range values {
+				if len(pointer) == 0 {
+					records++
+				}
+			}
+			return nil
+		},
+		func(ctx context.Context, values iter.Seq2[jseq.Pointer, any]) error {
+			routes := map[string]jseq.Pattern{"email": jseq.MustParsePattern("/user/email")}
+			for _, val := range jseq.Demux(values, routes) {
+				emails = append(emails, val.(string))
+			}
+			return nil
+		},
+		func(ctx context.Context, values iter.Seq2[jseq.Pointer, any]) error {
+			// Stop after the first record.
+			for pointer, val := range values {
+				if len(pointer) == 0 {
+					first = val
+					return nil
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if records != 3 {
+		t.Errorf("got %d records, want 3", records)
+	}
+	if want := []string{"a@x", "b@x", "c@x"}; !reflect.DeepEqual(emails, want) {
+		t.Errorf("got emails %v, want %v", emails, want)
+	}
+	if m, ok := first.(map[string]any); !ok || !reflect.DeepEqual(m["n"], jseq.Int(1)) {
+		t.Errorf("got first record %v", first)
+	}
+}
+
+func TestRunError(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	var canceled bool
+	err := jseq.Run(context.Background(), jseq.ReaderSource(strings.NewReader(runInput)),
+		func(ctx context.Context, values iter.Seq2[jseq.Pointer, any]) error {
+			for range values {
+			}
+			select {
+			case <-ctx.Done():
+				canceled = true
+			case <-time.After(time.Second):
+			}
+			return nil
+		},
+		func(ctx context.Context, values iter.Seq2[jseq.Pointer, any]) error {
+			for range values {
+				return errBoom
+			}
+			return nil
+		},
+	)
+	if !errors.Is(err, errBoom) {
+		t.Errorf("got error %v, want %v", err, errBoom)
+	}
+	if !canceled {
+		t.Error("other consumer was not canceled")
+	}
+}
+
+func TestRunParseError(t *testing.T) {
+	err := jseq.Run(context.Background(), jseq.ReaderSource(strings.NewReader(`{"a": `)),
+		func(ctx context.Context, values iter.Seq2[jseq.Pointer, any]) error {
+			for range values {
+			}
+			return nil
+		},
+	)
+	if err == nil {
+		t.Error("got no error, want one")
+	}
+}
+
+func TestRunPointers(t *testing.T) {
+	// Consumers lag behind the parser,
+	// so the pointers they receive must not share storage with later ones.
+	const inp = `{"a": [[{"x": 1, "y": 2, "z": 3, "w": 4}]]} {"a": [[{"x": 5, "y": 6}], [{"z": 7}]]}`
+
+	want := []string{
+		"/a/0/0/x", "/a/0/0/y", "/a/0/0/z", "/a/0/0/w", "/a/0/0", "/a/0", "/a", "",
+		"/a/0/0/x", "/a/0/0/y", "/a/0/0", "/a/0", "/a/1/0/z", "/a/1/0", "/a/1", "/a", "",
+	}
+
+	consumer := func(got *[]string) jseq.Consumer {
+		return func(ctx context.Context, values iter.Seq2[jseq.Pointer, any]) error {
+			var pointers []jseq.Pointer
+			for pointer := range values {
+				pointers = append(pointers, pointer)
+				time.Sleep(time.Millisecond) // let the parser run ahead
+			}
+			for _, pointer := range pointers {
+				*got = append(*got, string(pointer.Text()))
+			}
+			return nil
+		}
+	}
+
+	var got1, got2 []string
+	if err := jseq.Run(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), consumer(&got1), consumer(&got2)); err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range [][]string{got1, got2} {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
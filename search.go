@@ -0,0 +1,86 @@
+package jseq
+
+import (
+	"iter"
+	"regexp"
+)
+
+// Match is a search result produced by [Search].
+type Match struct {
+	// Record is the zero-based ordinal of the top-level value containing the match.
+	Record int
+
+	// Pointer locates the matching string value within its record.
+	// If Key is true,
+	// the match is in the object key that is the last element of Pointer.
+	Pointer Pointer
+
+	// Text is the matched text.
+	Text string
+
+	// Key tells whether the match is in an object key
+	// rather than a string value.
+	Key bool
+}
+
+// SearchOption is the type of an option that can be passed to [Search] and [SearchString].
+type SearchOption func(*searchConfig)
+
+type searchConfig struct {
+	keys bool
+}
+
+// SearchKeys is an option for [Search] and [SearchString]
+// that causes object keys to be searched
+// in addition to string values.
+func SearchKeys() SearchOption {
+	return func(c *searchConfig) {
+		c.keys = true
+	}
+}
+
+// Search consumes a sequence of pointer/value pairs,
+// as from [Values],
+// and produces a [Match] for each string value matching re.
+// The Text of the match is the leftmost match of re in the string.
+//
+// Search works in a streaming fashion,
+// producing matches as the values containing them are encountered.
+func Search(values iter.Seq2[Pointer, any], re *regexp.Regexp, opts ...SearchOption) iter.Seq[Match] {
+	var conf searchConfig
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	return func(yield func(Match) bool) {
+		var record int
+		for pointer, val := range values {
+			if conf.keys && len(pointer) > 0 {
+				if key, ok := pointer[len(pointer)-1].(string); ok {
+					if loc := re.FindStringIndex(key); loc != nil {
+						if !yield(Match{Record: record, Pointer: pointer, Text: key[loc[0]:loc[1]], Key: true}) {
+							return
+						}
+					}
+				}
+			}
+			if s, ok := val.(string); ok {
+				if loc := re.FindStringIndex(s); loc != nil {
+					if !yield(Match{Record: record, Pointer: pointer, Text: s[loc[0]:loc[1]]}) {
+						return
+					}
+				}
+			}
+			if len(pointer) == 0 {
+				record++
+			}
+		}
+	}
+}
+
+// SearchString is like [Search]
+// but matches the literal substring substr
+// instead of a regular expression.
+func SearchString(values iter.Seq2[Pointer, any], substr string, opts ...SearchOption) iter.Seq[Match] {
+	return Search(values, regexp.MustCompile(regexp.QuoteMeta(substr)), opts...)
+}
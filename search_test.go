@@ -0,0 +1,77 @@
+package jseq_test
+
+import (
+	"iter"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestSearch(t *testing.T) {
+	const inp = `
+{"name": "Alice", "email": "alice@example.com", "phones": ["555-1234"]}
+{"name": "Bob", "email_work": "bob@example.org"}
+"carol@example.net"
+`
+
+	cases := []struct {
+		name   string
+		search func(iter.Seq2[jseq.Pointer, any]) []jseq.Match
+		want   []jseq.Match
+	}{{
+		name: "regexp",
+		search: func(values iter.Seq2[jseq.Pointer, any]) []jseq.Match {
+			return collectMatches(jseq.Search(values, regexp.MustCompile(`[a-z]+@example\.[a-z]+`)))
+		},
+		want: []jseq.Match{
+			{Record: 0, Pointer: jseq.Pointer{"email"}, Text: "alice@example.com"},
+			{Record: 1, Pointer: jseq.Pointer{"email_work"}, Text: "bob@example.org"},
+			{Record: 2, Pointer: nil, Text: "carol@example.net"},
+		},
+	}, {
+		name: "keys",
+		search: func(values iter.Seq2[jseq.Pointer, any]) []jseq.Match {
+			return collectMatches(jseq.Search(values, regexp.MustCompile(`^email`), jseq.SearchKeys()))
+		},
+		want: []jseq.Match{
+			{Record: 0, Pointer: jseq.Pointer{"email"}, Text: "email", Key: true},
+			{Record: 1, Pointer: jseq.Pointer{"email_work"}, Text: "email", Key: true},
+		},
+	}, {
+		name: "substring",
+		search: func(values iter.Seq2[jseq.Pointer, any]) []jseq.Match {
+			return collectMatches(jseq.SearchString(values, "555-"))
+		},
+		want: []jseq.Match{
+			{Record: 0, Pointer: jseq.Pointer{"phones", 0}, Text: "555-"},
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+			values, errptr2 := jseq.Values(tokens)
+			got := tc.search(values)
+			if err := *errptr1; err != nil {
+				t.Fatal(err)
+			}
+			if err := *errptr2; err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func collectMatches(seq iter.Seq[jseq.Match]) []jseq.Match {
+	var result []jseq.Match
+	for m := range seq {
+		result = append(result, m)
+	}
+	return result
+}
@@ -0,0 +1,219 @@
+package jseq
+
+import (
+	"container/list"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SubtreeCache holds decoded arrays and objects for sharing among the values produced by [Values].
+// See [ShareSubtrees].
+// A SubtreeCache is safe for concurrent use.
+type SubtreeCache struct {
+	max int
+
+	mu      sync.Mutex
+	lru     *list.List               // of *shareEntry, most recently used first
+	byKey   map[string]*list.Element // content key -> entry
+	byIdent map[shareIdent]uint64    // identity of a cached value -> its id
+	nextID  uint64
+
+	hits, misses int
+}
+
+type shareEntry struct {
+	key   string
+	val   any
+	ident shareIdent
+}
+
+// shareIdent identifies a particular map or slice (not its contents).
+type shareIdent struct {
+	ptr uintptr
+	len int
+}
+
+// NewSubtreeCache creates a [SubtreeCache] holding at most maxEntries arrays and objects.
+// When it is full, the least recently used entry is evicted.
+func NewSubtreeCache(maxEntries int) *SubtreeCache {
+	return &SubtreeCache{
+		max:     maxEntries,
+		lru:     list.New(),
+		byKey:   make(map[string]*list.Element),
+		byIdent: make(map[shareIdent]uint64),
+	}
+}
+
+// ShareSubtrees is an [Option] that causes [Values] to look up each array and object it decodes in c.
+// If c already holds an identical one, that is produced instead,
+// and the newly decoded one is discarded;
+// otherwise the new one is added to c.
+//
+// When a stream contains many records with identical parts,
+// such as repeated embedded configuration objects,
+// this lets them share memory.
+// The cost is the lookup,
+// which is proportional to the number of elements or members in each array or object
+// (plus the size of any scalars among them).
+//
+// Because values may be shared,
+// callers must not modify the arrays and objects produced by Values with this option.
+// (Use [Clone] to get a modifiable copy.)
+func ShareSubtrees(c *SubtreeCache) Option {
+	return func(conf *config) {
+		conf.share = c
+	}
+}
+
+// Len returns the number of entries in c.
+func (c *SubtreeCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns the number of lookups in c that found an existing entry (hits)
+// and that did not (misses).
+func (c *SubtreeCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// intern returns the cached equivalent of v, adding v to the cache if necessary.
+// Values that cannot be cached are returned unchanged.
+func (c *SubtreeCache) intern(v any) any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key, ok := c.key(v)
+	if !ok {
+		return v
+	}
+	if elt, ok := c.byKey[key]; ok {
+		c.hits++
+		c.lru.MoveToFront(elt)
+		return elt.Value.(*shareEntry).val
+	}
+	c.misses++
+
+	ident, _ := identOf(v)
+	entry := &shareEntry{key: key, val: v, ident: ident}
+	c.byKey[key] = c.lru.PushFront(entry)
+	c.byIdent[ident] = c.nextID
+	c.nextID++
+
+	for c.lru.Len() > c.max {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		e := oldest.Value.(*shareEntry)
+		delete(c.byKey, e.key)
+		delete(c.byIdent, e.ident)
+	}
+	return v
+}
+
+// key computes the content key of an array or object.
+// Nonempty arrays and objects within it are represented by the ids of their cached instances,
+// so it is not necessary to look at their contents.
+// The boolean result is false if v cannot be cached
+// (including when it contains an array or object not in the cache).
+func (c *SubtreeCache) key(v any) (string, bool) {
+	var buf strings.Builder
+
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return "", false
+		}
+		buf.WriteByte('{')
+		for _, k := range SortedKeys(v) {
+			buf.WriteString(strconv.Quote(k))
+			buf.WriteByte(':')
+			if !c.writeRef(&buf, v[k]) {
+				return "", false
+			}
+			buf.WriteByte(',')
+		}
+
+	case []any:
+		if len(v) == 0 {
+			return "", false
+		}
+		buf.WriteByte('[')
+		for _, elt := range v {
+			if !c.writeRef(&buf, elt) {
+				return "", false
+			}
+			buf.WriteByte(',')
+		}
+
+	default:
+		return "", false
+	}
+
+	return buf.String(), true
+}
+
+func (c *SubtreeCache) writeRef(buf *strings.Builder, v any) bool {
+	switch v := v.(type) {
+	case string:
+		buf.WriteString(strconv.Quote(v))
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case Null:
+		buf.WriteString("null")
+	case Number:
+		buf.WriteByte('n')
+		buf.WriteString(v.raw)
+	case map[string]any:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return true
+		}
+		return c.writeID(buf, v)
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return true
+		}
+		return c.writeID(buf, v)
+	default:
+		return false
+	}
+	return true
+}
+
+func (c *SubtreeCache) writeID(buf *strings.Builder, v any) bool {
+	ident, ok := identOf(v)
+	if !ok {
+		return false
+	}
+	id, ok := c.byIdent[ident]
+	if !ok {
+		return false
+	}
+	buf.WriteByte('#')
+	buf.WriteString(strconv.FormatUint(id, 10))
+	return true
+}
+
+func identOf(v any) (shareIdent, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		return shareIdent{ptr: rv.Pointer()}, true
+	case reflect.Slice:
+		return shareIdent{ptr: rv.Pointer(), len: rv.Len()}, true
+	}
+	return shareIdent{}, false
+}
+
+func (p *parser) share(v any) any {
+	if p.config.share == nil {
+		return v
+	}
+	return p.config.share.intern(v)
+}
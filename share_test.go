@@ -0,0 +1,60 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestShareSubtrees(t *testing.T) {
+	const inp = `
+{"id": 1, "config": {"retries": 3, "hosts": ["a", "b"]}}
+{"id": 2, "config": {"retries": 3, "hosts": ["a", "b"]}}
+{"id": 3, "config": {"retries": 4, "hosts": ["a", "b"]}}
+`
+
+	cache := jseq.NewSubtreeCache(100)
+	got := collect(t, strings.NewReader(inp), jseq.ShareSubtrees(cache))
+
+	var records []map[string]any
+	for _, pr := range got {
+		if len(pr.p) == 0 {
+			records = append(records, pr.v.(map[string]any))
+		}
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	same := func(a, b any) bool {
+		return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+	}
+
+	c0, c1, c2 := records[0]["config"], records[1]["config"], records[2]["config"]
+	if !same(c0, c1) {
+		t.Error("identical config objects are not shared")
+	}
+	if same(c0, c2) {
+		t.Error("different config objects are shared")
+	}
+	if h0, h2 := c0.(map[string]any)["hosts"], c2.(map[string]any)["hosts"]; !same(h0, h2) {
+		t.Error("identical hosts arrays are not shared")
+	}
+	if !reflect.DeepEqual(c2, map[string]any{"retries": jseq.Int(4), "hosts": []any{"a", "b"}}) {
+		t.Errorf("got %v", c2)
+	}
+
+	// Misses: hosts, config 0, record 0, config 2, records 1 and 2.
+	// Hits: hosts twice, config 1.
+	if hits, misses := cache.Stats(); hits != 3 || misses != 6 {
+		t.Errorf("got %d hits and %d misses, want 3 and 6", hits, misses)
+	}
+
+	small := jseq.NewSubtreeCache(2)
+	collect(t, strings.NewReader(inp), jseq.ShareSubtrees(small))
+	if n := small.Len(); n != 2 {
+		t.Errorf("got %d entries, want 2", n)
+	}
+}
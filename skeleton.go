@@ -0,0 +1,243 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/bobg/errors"
+)
+
+// SkeletonNode describes the values found at one location in a JSON stream.
+// It is produced by [Skeleton].
+type SkeletonNode struct {
+	// Count is the number of values found here.
+	Count int
+
+	// Types lists the JSON types of those values (see [TypeName]),
+	// in sorted order.
+	Types []string
+
+	// Objects is the number of the values that were objects.
+	Objects int
+
+	// Keys lists the keys of the objects found here,
+	// in the order first seen,
+	// and Members describes the values for each key.
+	// They are empty beyond the maximum depth given to [Skeleton].
+	Keys    []string
+	Members map[string]*SkeletonNode
+
+	// MinLen and MaxLen are the smallest and largest lengths
+	// of the arrays found here.
+	MinLen, MaxLen int
+
+	// Elements describes the elements of the arrays found here,
+	// merged together.
+	// It is nil if there are none,
+	// or beyond the maximum depth given to [Skeleton].
+	Elements *SkeletonNode
+}
+
+// Skeleton consumes a sequence of JSON tokens,
+// as from [Tokens],
+// and produces the structural outline of the values in it:
+// the object keys and array lengths found at each location,
+// and the types of the values there,
+// but no leaf values.
+// The elements of each array are merged into a single outline,
+// as are all the top-level values,
+// so the result stays small even for huge inputs,
+// making it a quick way to understand an unfamiliar file.
+// See [SkeletonNode.WriteText].
+//
+// Arrays and objects nested more deeply than maxDepth
+// (where a top-level array or object is at depth 1)
+// are counted but not described.
+// A maxDepth of zero or less means no limit.
+//
+// Skeleton does not build the values it outlines,
+// so it uses memory proportional to the size of the outline,
+// not of the input.
+// It returns nil if there are no values.
+func Skeleton(tokens iter.Seq[jsontext.Token], maxDepth int) (*SkeletonNode, error) {
+	next, stop := iter.Pull(tokens)
+	defer stop()
+
+	var root *SkeletonNode
+	for {
+		tok, ok := next()
+		if !ok {
+			return root, nil
+		}
+		if root == nil {
+			root = new(SkeletonNode)
+		}
+		if err := root.add(tok, next, 1, maxDepth); err != nil {
+			return root, err
+		}
+	}
+}
+
+// add records the value beginning with tok,
+// reading the rest of its tokens with next.
+func (n *SkeletonNode) add(tok jsontext.Token, next func() (jsontext.Token, bool), depth, maxDepth int) error {
+	describe := maxDepth <= 0 || depth <= maxDepth
+
+	var typ string
+	switch tok.Kind() {
+	case 'n':
+		typ = "null"
+	case 'f', 't':
+		typ = "boolean"
+	case '"':
+		typ = "string"
+	case '0':
+		typ = "number"
+
+	case '{':
+		typ = "object"
+		n.Objects++
+		for {
+			tok, ok := next()
+			if !ok {
+				return io.ErrUnexpectedEOF
+			}
+			if tok.Kind() == '}' {
+				break
+			}
+			if tok.Kind() != '"' {
+				return fmt.Errorf("unexpected %s token reading object key, want string", tok.Kind())
+			}
+			key := tok.String()
+			val, ok := next()
+			if !ok {
+				return io.ErrUnexpectedEOF
+			}
+			var member *SkeletonNode
+			if describe {
+				if n.Members == nil {
+					n.Members = make(map[string]*SkeletonNode)
+				}
+				member = n.Members[key]
+				if member == nil {
+					member = new(SkeletonNode)
+					n.Members[key] = member
+					n.Keys = append(n.Keys, key)
+				}
+			} else {
+				member = new(SkeletonNode) // discarded
+			}
+			if err := member.add(val, next, depth+1, maxDepth); err != nil {
+				return errors.Wrapf(err, "in object member %q", key)
+			}
+		}
+
+	case '[':
+		typ = "array"
+		var length int
+		for ; ; length++ {
+			tok, ok := next()
+			if !ok {
+				return io.ErrUnexpectedEOF
+			}
+			if tok.Kind() == ']' {
+				break
+			}
+			elt := n.Elements
+			if !describe {
+				elt = new(SkeletonNode) // discarded
+			} else if elt == nil {
+				elt = new(SkeletonNode)
+				n.Elements = elt
+			}
+			if err := elt.add(tok, next, depth+1, maxDepth); err != nil {
+				return errors.Wrapf(err, "in array element %d", length)
+			}
+		}
+		if !slices.Contains(n.Types, "array") {
+			n.MinLen, n.MaxLen = length, length
+		} else {
+			n.MinLen, n.MaxLen = min(n.MinLen, length), max(n.MaxLen, length)
+		}
+
+	default:
+		return fmt.Errorf("unexpected %s token", tok.Kind())
+	}
+
+	n.Count++
+	if i, found := slices.BinarySearch(n.Types, typ); !found {
+		n.Types = slices.Insert(n.Types, i, typ)
+	}
+	return nil
+}
+
+// WriteText writes n to w as an indented outline,
+// one line per location.
+// Each line gives the types found there,
+// with the range of lengths for arrays.
+// Object members are introduced by their keys,
+// followed by "?" if some of the objects lack them,
+// and array elements by "[]".
+// For example:
+//
+//	object
+//	  id: number
+//	  name?: null|string
+//	  tags: array[0..3]
+//	    []: string
+func (n *SkeletonNode) WriteText(w io.Writer) error {
+	return n.writeText(w, "", 0)
+}
+
+// String returns the outline produced by [SkeletonNode.WriteText].
+func (n *SkeletonNode) String() string {
+	var buf strings.Builder
+	n.WriteText(&buf)
+	return buf.String()
+}
+
+func (n *SkeletonNode) writeText(w io.Writer, label string, indent int) error {
+	types := slices.Clone(n.Types)
+	if i := slices.Index(types, "array"); i >= 0 {
+		if n.MinLen == n.MaxLen {
+			types[i] = fmt.Sprintf("array[%d]", n.MinLen)
+		} else {
+			types[i] = fmt.Sprintf("array[%d..%d]", n.MinLen, n.MaxLen)
+		}
+	}
+	if _, err := fmt.Fprintf(w, "%s%s%s\n", strings.Repeat("  ", indent), label, strings.Join(types, "|")); err != nil {
+		return err
+	}
+
+	for _, key := range n.Keys {
+		member := n.Members[key]
+		label := skeletonKey(key)
+		if member.Count < n.Objects {
+			label += "?"
+		}
+		if err := member.writeText(w, label+": ", indent+1); err != nil {
+			return err
+		}
+	}
+	if n.Elements != nil {
+		return n.Elements.writeText(w, "[]: ", indent+1)
+	}
+	return nil
+}
+
+// skeletonKey returns key as is,
+// or in JSON string syntax if it might be confused with the rest of an outline line.
+func skeletonKey(key string) string {
+	if key != "" && key != "[]" && !strings.ContainsAny(key, ":?\"\n\t ") {
+		return key
+	}
+	b, err := jsontext.AppendQuote(nil, key)
+	if err != nil {
+		return fmt.Sprintf("%q", key)
+	}
+	return string(b)
+}
@@ -0,0 +1,86 @@
+package jseq_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestSkeleton(t *testing.T) {
+	const inp = `
+{"id": 1, "name": "a", "tags": ["x", "y"], "geo": {"lat": 1.5, "lon": 2}, "odd key": true}
+{"id": 2, "name": null, "tags": [], "geo": {"lat": 0, "lon": 0, "alt": 9}, "items": [{"sku": "s", "qty": 1}, {"sku": "t"}]}
+{"id": 3, "tags": ["z", 4, "w"], "geo": null}
+`
+
+	cases := []struct {
+		maxDepth int
+		want     string
+	}{
+		{
+			maxDepth: 0,
+			want: `object
+  id: number
+  name?: null|string
+  tags: array[0..3]
+    []: number|string
+  geo: null|object
+    lat: number
+    lon: number
+    alt?: number
+  "odd key"?: boolean
+  items?: array[2]
+    []: object
+      sku: string
+      qty?: number
+`,
+		},
+		{
+			maxDepth: 1,
+			want: `object
+  id: number
+  name?: null|string
+  tags: array[0..3]
+  geo: null|object
+  "odd key"?: boolean
+  items?: array[2]
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		tokens, errptr := jseq.Tokens(strings.NewReader(inp))
+		skel, err := jseq.Skeleton(tokens, tc.maxDepth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := *errptr; err != nil {
+			t.Fatal(err)
+		}
+		if skel.Count != 3 || skel.Objects != 3 {
+			t.Errorf("max depth %d: got count %d and objects %d, want 3 and 3", tc.maxDepth, skel.Count, skel.Objects)
+		}
+		if got := skel.String(); got != tc.want {
+			t.Errorf("max depth %d: got:\n%s\nwant:\n%s", tc.maxDepth, got, tc.want)
+		}
+	}
+}
+
+func TestSkeletonEmpty(t *testing.T) {
+	tokens, _ := jseq.Tokens(strings.NewReader(""))
+	skel, err := jseq.Skeleton(tokens, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skel != nil {
+		t.Errorf("got %v, want nil", skel)
+	}
+}
+
+func TestSkeletonTruncated(t *testing.T) {
+	tokens, _ := jseq.Tokens(strings.NewReader(`{"a": [1, 2`))
+	if _, err := jseq.Skeleton(tokens, 0); err == nil {
+		t.Error("got no error, want one")
+	}
+}
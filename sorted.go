@@ -0,0 +1,98 @@
+package jseq
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+
+	"github.com/bobg/errors"
+)
+
+// sortedChunk is the number of elements read at a time
+// when collecting the matches found by [ArrayIndex.SearchSorted].
+const sortedChunk = 16
+
+// SearchSorted finds the elements of the array indexed by idx
+// whose values at keyPtr equal key,
+// when the elements are sorted in ascending order by those values.
+// This turns a huge sorted export into a simple read-only database table.
+// The contents of rs must be the ones from which idx was built.
+//
+// It returns the position of the first matching element
+// and the matching elements,
+// decoded with the given options as in [Values].
+// If there are no matches,
+// the position is where such an element would go
+// (as with [slices.BinarySearch]).
+//
+// Keys are compared as in [Expr]:
+// numbers numerically
+// and strings in byte order.
+// The key argument must therefore be a string or a [Number].
+// An element whose value at keyPtr is missing or of some other type
+// is an error.
+//
+// Finding the first match takes O(log n) reads of single elements,
+// each of which may skip up to idx.Stride-1 elements.
+func (idx *ArrayIndex) SearchSorted(rs io.ReadSeeker, keyPtr Pointer, key any, opts ...Option) (int, []any, error) {
+	lo, hi := 0, idx.Len
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		raws, err := idx.rawPage(rs, mid, 1)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(raws) == 0 {
+			return 0, nil, fmt.Errorf("array ended before element %d", mid)
+		}
+		c, err := compareSortKey(raws[0], keyPtr, key)
+		if err != nil {
+			return 0, nil, errors.Wrapf(err, "in array element %d", mid)
+		}
+		if c < 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	var result []any
+	for start := lo; start < idx.Len; start += sortedChunk {
+		raws, err := idx.rawPage(rs, start, sortedChunk)
+		if err != nil {
+			return 0, nil, err
+		}
+		for i, raw := range raws {
+			c, err := compareSortKey(raw, keyPtr, key)
+			if err != nil {
+				return 0, nil, errors.Wrapf(err, "in array element %d", start+i)
+			}
+			if c != 0 {
+				return lo, result, nil
+			}
+			val, err := decodeValue(bytes.NewReader(raw), opts...)
+			if err != nil {
+				return 0, nil, errors.Wrapf(err, "decoding array element %d", start+i)
+			}
+			result = append(result, val)
+		}
+	}
+	return lo, result, nil
+}
+
+// compareSortKey compares the value at keyPtr in the encoded element raw with key.
+func compareSortKey(raw jsontext.Value, keyPtr Pointer, key any) (int, error) {
+	elt, err := decodeRaw(raw)
+	if err != nil {
+		return 0, err
+	}
+	val, err := keyPtr.Locate(elt)
+	if err != nil {
+		return 0, errors.Wrapf(err, "locating %q", keyPtr.Text())
+	}
+	if val == nil {
+		return 0, fmt.Errorf("no key at %q", keyPtr.Text())
+	}
+	return exprCompare(val, key)
+}
@@ -0,0 +1,74 @@
+package jseq_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestSearchSorted(t *testing.T) {
+	// Records sorted by "k", with some keys repeated and some missing.
+	keys := []int{1, 3, 3, 3, 4, 8, 8, 10, 11, 15, 15, 15, 15, 20}
+
+	var buf strings.Builder
+	buf.WriteString("[")
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, `{"k": %d, "i": %d}`, k, i)
+	}
+	buf.WriteString("]")
+	inp := buf.String()
+
+	cases := []struct {
+		key     int
+		wantPos int
+		wantN   int
+	}{
+		{key: 0, wantPos: 0},
+		{key: 1, wantPos: 0, wantN: 1},
+		{key: 3, wantPos: 1, wantN: 3},
+		{key: 5, wantPos: 5},
+		{key: 15, wantPos: 9, wantN: 4},
+		{key: 20, wantPos: 13, wantN: 1},
+		{key: 21, wantPos: 14},
+	}
+
+	for _, stride := range []int{1, 4} {
+		idx, err := jseq.IndexArray(strings.NewReader(inp), nil, stride)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range cases {
+			t.Run(fmt.Sprintf("stride_%d_key_%d", stride, tc.key), func(t *testing.T) {
+				pos, got, err := idx.SearchSorted(strings.NewReader(inp), jseq.Pointer{"k"}, jseq.Int(int64(tc.key)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if pos != tc.wantPos {
+					t.Errorf("got position %d, want %d", pos, tc.wantPos)
+				}
+				var want []any
+				for i := tc.wantPos; i < tc.wantPos+tc.wantN; i++ {
+					want = append(want, map[string]any{"k": jseq.Int(int64(tc.key)), "i": jseq.Int(int64(i))})
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("got %v, want %v", got, want)
+				}
+			})
+		}
+	}
+
+	const unkeyed = `[{"k": 1}, {"x": 2}, {"k": 3}]`
+	idx, err := jseq.IndexArray(strings.NewReader(unkeyed), nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := idx.SearchSorted(strings.NewReader(unkeyed), jseq.Pointer{"k"}, jseq.Int(2)); err == nil {
+		t.Error("got no error for element without key, want one")
+	}
+}
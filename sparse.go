@@ -0,0 +1,128 @@
+package jseq
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// MaxSparseIndex is the largest object key that [SparseArrays] will convert to an array index.
+// It limits the memory that a small object can cause to be allocated.
+const MaxSparseIndex = 1<<20 - 1
+
+// SparseArrays is an [Option] that causes [Values] to produce objects whose keys are all array indexes
+// (e.g. {"0": "a", "3": "d"})
+// as arrays,
+// with fill in the positions that have no corresponding key.
+// The fill value is shared among all such positions.
+//
+// With no pointers,
+// this applies to every qualifying object.
+// Otherwise it applies only to objects at the given pointers.
+// Either way, an object qualifies only if it is non-empty
+// and every key is a non-negative decimal integer without leading zeroes
+// no larger than [MaxSparseIndex].
+// Other objects are left alone.
+//
+// Pointers to the members of such an array still use the original string keys.
+//
+// See also [SparseArrayCandidates] and [ArraysAsObjects].
+func SparseArrays(fill any, pointers ...Pointer) Option {
+	return func(c *config) {
+		c.sparse = &sparseConfig{fill: fill, pointers: pointers}
+	}
+}
+
+// ArraysAsObjects is an [Option] that causes [Values] to produce the arrays at the given pointers
+// as objects whose keys are the array indexes in decimal.
+// It is the inverse of [SparseArrays].
+//
+// Pointers to the members of such an object still use int indexes.
+func ArraysAsObjects(pointers ...Pointer) Option {
+	return func(c *config) {
+		c.arraysAsObjects = append(c.arraysAsObjects, pointers...)
+	}
+}
+
+type sparseConfig struct {
+	fill     any
+	pointers []Pointer
+}
+
+func (p *parser) sparseArray(pointer Pointer, m map[string]any) ([]any, bool) {
+	if p.sparse == nil {
+		return nil, false
+	}
+	if len(p.sparse.pointers) > 0 && !slices.ContainsFunc(p.sparse.pointers, func(q Pointer) bool { return slices.Equal(q, pointer) }) {
+		return nil, false
+	}
+	maxIndex, ok := sparseIndexes(m)
+	if !ok {
+		return nil, false
+	}
+	result := make([]any, maxIndex+1)
+	for i := range result {
+		result[i] = p.sparse.fill
+	}
+	for key, val := range m {
+		i, _ := strconv.Atoi(key)
+		result[i] = val
+	}
+	return result, true
+}
+
+func (p *parser) arrayAsObject(pointer Pointer, a []any) (map[string]any, bool) {
+	if !slices.ContainsFunc(p.arraysAsObjects, func(q Pointer) bool { return slices.Equal(q, pointer) }) {
+		return nil, false
+	}
+	result := make(map[string]any, len(a))
+	for i, val := range a {
+		result[strconv.Itoa(i)] = val
+	}
+	return result, true
+}
+
+// sparseIndexes tells whether m qualifies for conversion by [SparseArrays],
+// and if so, returns its largest index.
+func sparseIndexes(m map[string]any) (int, bool) {
+	if len(m) == 0 {
+		return 0, false
+	}
+	maxIndex := -1
+	for key := range m {
+		if key == "" || (key[0] == '0' && len(key) > 1) {
+			return 0, false
+		}
+		for j := 0; j < len(key); j++ {
+			if key[j] < '0' || key[j] > '9' { // Atoi also accepts a sign
+				return 0, false
+			}
+		}
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i > MaxSparseIndex {
+			return 0, false
+		}
+		maxIndex = max(maxIndex, i)
+	}
+	return maxIndex, true
+}
+
+// SparseArrayCandidates consumes a sequence of pointer/value pairs as produced by [Values]
+// and reports the paths (see [Pointer.Path])
+// of objects that qualify for conversion to arrays by [SparseArrays].
+//
+// The result is sorted.
+func SparseArrayCandidates(values iter.Seq2[Pointer, any]) []string {
+	paths := make(map[string]struct{})
+	for pointer, val := range values {
+		m, ok := asObject(val)
+		if !ok {
+			continue
+		}
+		if _, ok := sparseIndexes(m); ok {
+			paths[pointer.Path()] = struct{}{}
+		}
+	}
+	return slices.Sorted(maps.Keys(paths))
+}
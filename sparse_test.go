@@ -0,0 +1,98 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestSparseArrays(t *testing.T) {
+	const inp = `{"a": {"0": "x", "2": "z"}, "b": {"1": true}, "c": {"01": 1}, "d": ["p", "q"]}`
+
+	cases := []struct {
+		name string
+		opts []jseq.Option
+		want map[string]any
+	}{{
+		name: "global",
+		opts: []jseq.Option{jseq.SparseArrays(jseq.Null{})},
+		want: map[string]any{
+			"a": []any{"x", jseq.Null{}, "z"},
+			"b": []any{jseq.Null{}, true},
+			"c": map[string]any{"01": jseq.Int(1)},
+			"d": []any{"p", "q"},
+		},
+	}, {
+		name: "per_pointer",
+		opts: []jseq.Option{jseq.SparseArrays(false, jseq.Pointer{"b"})},
+		want: map[string]any{
+			"a": map[string]any{"0": "x", "2": "z"},
+			"b": []any{false, true},
+			"c": map[string]any{"01": jseq.Int(1)},
+			"d": []any{"p", "q"},
+		},
+	}, {
+		name: "vice_versa",
+		opts: []jseq.Option{jseq.ArraysAsObjects(jseq.Pointer{"d"})},
+		want: map[string]any{
+			"a": map[string]any{"0": "x", "2": "z"},
+			"b": map[string]any{"1": true},
+			"c": map[string]any{"01": jseq.Int(1)},
+			"d": map[string]any{"0": "p", "1": "q"},
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := collect(t, strings.NewReader(inp), tc.opts...)
+			last := got[len(got)-1].v
+			if !reflect.DeepEqual(last, tc.want) {
+				t.Errorf("got %v, want %v", last, tc.want)
+			}
+		})
+	}
+}
+
+func TestSparseArraysSignedKeys(t *testing.T) {
+	// Keys with signs are not array indexes.
+	const inp = `{"5": "a", "+5": "b", "-0": "c"}`
+
+	got := collect(t, strings.NewReader(inp), jseq.SparseArrays(jseq.Null{}))
+	want := map[string]any{"5": "a", "+5": "b", "-0": "c"}
+	if last := got[len(got)-1].v; !reflect.DeepEqual(last, want) {
+		t.Errorf("got %v, want %v", last, want)
+	}
+}
+
+func TestSparseArrayCandidates(t *testing.T) {
+	const inp = `{"a": {"0": "x", "2": "z"}, "b": {"1": true}, "c": {"01": 1}, "d": [{"5": 5}, {}]} {"b": {"x": 1}}`
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+	got := jseq.SparseArrayCandidates(values)
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+
+	want := []string{"/a", "/b", "/d/*"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSparseArrayCandidatesOrdered(t *testing.T) {
+	tokens, _ := jseq.Tokens(strings.NewReader(`{"a": {"2": "z", "0": "x"}, "b": {"x": 1}}`))
+	values, errptr := jseq.Values(tokens, jseq.OrderedObjects())
+	got := jseq.SparseArrayCandidates(values)
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,320 @@
+package jseq
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/bobg/errors"
+)
+
+// Table is a relational table definition
+// mapping locations in JSON records to columns.
+// Infer one from sample records with [InferTable].
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+// Column is a column of a [Table].
+type Column struct {
+	Name string
+
+	// Pointer locates the column's value in a record.
+	Pointer Pointer
+
+	// Type is the column's SQL type:
+	// BIGINT, DOUBLE PRECISION, BOOLEAN, TEXT, or JSON.
+	// A JSON column holds the encoded JSON value.
+	Type string
+
+	// Nullable tells whether the value may be null or absent.
+	Nullable bool
+}
+
+// The SQL types of columns inferred by [InferTable].
+const (
+	SQLBigint = "BIGINT"
+	SQLDouble = "DOUBLE PRECISION"
+	SQLBool   = "BOOLEAN"
+	SQLText   = "TEXT"
+	SQLJSON   = "JSON"
+)
+
+// InferTable consumes a sequence of pointer/value pairs as produced by [Values]
+// and infers a [Table] with the given name
+// having a column for each location in the records
+// reachable through objects only.
+// Nested objects are flattened,
+// with a column named for the path of keys leading to each member
+// (e.g. "user_address_city" for /user/address/city);
+// arrays, and values with conflicting object and non-object types,
+// go in JSON columns.
+// Locations holding only strings, only booleans, or only numbers
+// get TEXT, BOOLEAN, and BIGINT or DOUBLE PRECISION columns;
+// a mix of those gets a TEXT column.
+//
+// Columns appear in the order their locations are first seen.
+func InferTable(name string, values iter.Seq2[Pointer, any]) Table {
+	var (
+		order   []string
+		stats   = make(map[string]*columnStats)
+		current = make(map[string]bool) // paths present in the current record
+		records int
+	)
+
+	for pointer, val := range values {
+		if len(pointer) == 0 {
+			records++
+			for path := range current {
+				stats[path].present++
+			}
+			clear(current)
+			continue
+		}
+		if pointer.hasIndex() {
+			continue
+		}
+
+		path := string(pointer.Text())
+		st := stats[path]
+		if st == nil {
+			st = &columnStats{pointer: slices.Clone(pointer)} // pointer's storage may be reused
+			stats[path] = st
+			order = append(order, path)
+		}
+		current[path] = true
+
+		switch TypeName(val) {
+		case "object":
+			st.kinds |= colObject
+		case "array":
+			st.kinds |= colArray
+		case "string":
+			st.kinds |= colString
+		case "boolean":
+			st.kinds |= colBool
+		case "number":
+			if n, ok := val.(Number); ok {
+				if _, ok := n.Int(); ok {
+					st.kinds |= colInt
+					break
+				}
+			}
+			st.kinds |= colFloat
+		case "null":
+			st.nulls++
+		}
+	}
+
+	var (
+		result   = Table{Name: name}
+		jsonCols []Pointer
+		names    = make(map[string]bool)
+	)
+	for _, path := range order {
+		st := stats[path]
+		typ := st.sqlType()
+		if typ == "" {
+			continue // an object whose members have their own columns
+		}
+		if typ == SQLJSON {
+			jsonCols = append(jsonCols, st.pointer)
+		}
+		result.Columns = append(result.Columns, Column{
+			Pointer:  st.pointer,
+			Type:     typ,
+			Nullable: st.present < records || st.nulls > 0,
+		})
+	}
+
+	// Locations inside a JSON column need no columns of their own.
+	result.Columns = slices.DeleteFunc(result.Columns, func(c Column) bool {
+		return slices.ContainsFunc(jsonCols, func(p Pointer) bool {
+			return len(p) < len(c.Pointer) && slices.Equal(p, c.Pointer[:len(p)])
+		})
+	})
+	for i, c := range result.Columns {
+		result.Columns[i].Name = columnName(c.Pointer, names)
+	}
+
+	return result
+}
+
+type columnStats struct {
+	pointer Pointer
+	kinds   columnKinds
+	present int // number of records
+	nulls   int
+}
+
+type columnKinds int
+
+const (
+	colObject columnKinds = 1 << iota
+	colArray
+	colString
+	colBool
+	colInt
+	colFloat
+)
+
+func (st *columnStats) sqlType() string {
+	switch k := st.kinds; {
+	case k == colObject:
+		return ""
+	case k&(colObject|colArray) != 0:
+		return SQLJSON
+	case k == colBool:
+		return SQLBool
+	case k == colInt:
+		return SQLBigint
+	case k&^(colInt|colFloat) == 0 && k != 0:
+		return SQLDouble
+	}
+	return SQLText // including only nulls
+}
+
+// hasIndex tells whether p contains an array index.
+func (p Pointer) hasIndex() bool {
+	return slices.ContainsFunc(p, func(elt any) bool {
+		_, ok := elt.(int)
+		return ok
+	})
+}
+
+// columnName produces a unique SQL column name for ptr,
+// recording it in names.
+func columnName(ptr Pointer, names map[string]bool) string {
+	var parts []string
+	for _, elt := range ptr {
+		parts = append(parts, fmt.Sprint(elt))
+	}
+	base := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, strings.Join(parts, "_"))
+	if base == "" || unicode.IsDigit(rune(base[0])) {
+		base = "_" + base
+	}
+
+	name := base
+	for i := 2; names[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	names[name] = true
+	return name
+}
+
+// CreateTable produces a CREATE TABLE statement for t.
+// Identifiers are double-quoted.
+func (t Table) CreateTable() string {
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "CREATE TABLE %s (\n", quoteIdent(t.Name))
+	for i, c := range t.Columns {
+		fmt.Fprintf(buf, "  %s %s", quoteIdent(c.Name), c.Type)
+		if !c.Nullable {
+			buf.WriteString(" NOT NULL")
+		}
+		if i < len(t.Columns)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(");\n")
+	return buf.String()
+}
+
+// Insert produces a parameterized INSERT statement for t,
+// whose parameters are the values produced by [Table.Row].
+// The placeholder function renders the placeholder for parameter n,
+// counting from 1;
+// if it is nil, each placeholder is "?".
+// For PostgreSQL-style placeholders, use something like
+//
+//	func(n int) string { return fmt.Sprintf("$%d", n) }
+func (t Table) Insert(placeholder func(n int) string) string {
+	if placeholder == nil {
+		placeholder = func(int) string { return "?" }
+	}
+	var cols, params []string
+	for i, c := range t.Columns {
+		cols = append(cols, quoteIdent(c.Name))
+		params = append(params, placeholder(i+1))
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdent(t.Name), strings.Join(cols, ", "), strings.Join(params, ", "))
+}
+
+// Row extracts the column values of t from rec,
+// for use as the parameters of the statement produced by [Table.Insert].
+// Each value is an int64, float64, bool, string, or nil,
+// according to the column's type;
+// a JSON column gets its value encoded as a string.
+// A TEXT column holding a number or boolean gets its JSON text.
+//
+// It is an error if a value does not fit its column.
+func (t Table) Row(rec any) ([]any, error) {
+	result := make([]any, len(t.Columns))
+	for i, c := range t.Columns {
+		val, err := c.Pointer.Locate(rec)
+		if err != nil || isNull(val) {
+			if !c.Nullable {
+				return nil, fmt.Errorf("column %s: missing value", c.Name)
+			}
+			continue
+		}
+		if result[i], err = c.convert(val); err != nil {
+			return nil, errors.Wrapf(err, "column %s", c.Name)
+		}
+	}
+	return result, nil
+}
+
+func (c Column) convert(val any) (any, error) {
+	if x, ok := val.(Expanded); ok {
+		val = x.Value
+	}
+
+	if c.Type == SQLJSON {
+		buf := new(bytes.Buffer)
+		if err := Encode(buf, val); err != nil {
+			return nil, err
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	}
+
+	switch val := val.(type) {
+	case string:
+		if c.Type == SQLText {
+			return val, nil
+		}
+	case bool:
+		switch c.Type {
+		case SQLBool:
+			return val, nil
+		case SQLText:
+			return fmt.Sprint(val), nil
+		}
+	case Number:
+		switch c.Type {
+		case SQLBigint:
+			if n, ok := val.Int(); ok {
+				return n, nil
+			}
+		case SQLDouble:
+			return val.Float(), nil
+		case SQLText:
+			return val.String(), nil
+		}
+	}
+	return nil, fmt.Errorf("%s value does not fit %s column", TypeName(val), c.Type)
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,111 @@
+package jseq_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestInferTable(t *testing.T) {
+	const inp = `
+{"id": 1, "user": {"name": "Alice", "Zip Code": "02139"}, "score": 9.5, "tags": ["a"], "ok": true, "misc": 1}
+{"id": 2, "user": {"name": "Bob"}, "score": 7, "tags": [], "ok": false, "misc": "x", "extra": {"a": 1}}
+{"id": 3, "user": {"name": "Carol", "Zip Code": null}, "score": 8, "ok": true, "misc": false, "extra": [1]}
+`
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+	table := jseq.InferTable("people", values)
+	if err := *errptr1; err != nil {
+		t.Fatal(err)
+	}
+	if err := *errptr2; err != nil {
+		t.Fatal(err)
+	}
+
+	want := jseq.Table{
+		Name: "people",
+		Columns: []jseq.Column{
+			{Name: "id", Pointer: jseq.Pointer{"id"}, Type: jseq.SQLBigint},
+			{Name: "user_name", Pointer: jseq.Pointer{"user", "name"}, Type: jseq.SQLText},
+			{Name: "user_zip_code", Pointer: jseq.Pointer{"user", "Zip Code"}, Type: jseq.SQLText, Nullable: true},
+			{Name: "score", Pointer: jseq.Pointer{"score"}, Type: jseq.SQLDouble},
+			{Name: "tags", Pointer: jseq.Pointer{"tags"}, Type: jseq.SQLJSON, Nullable: true},
+			{Name: "ok", Pointer: jseq.Pointer{"ok"}, Type: jseq.SQLBool},
+			{Name: "misc", Pointer: jseq.Pointer{"misc"}, Type: jseq.SQLText},
+			{Name: "extra", Pointer: jseq.Pointer{"extra"}, Type: jseq.SQLJSON, Nullable: true},
+		},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("got %+v, want %+v", table, want)
+	}
+
+	const wantCreate = `CREATE TABLE "people" (
+  "id" BIGINT NOT NULL,
+  "user_name" TEXT NOT NULL,
+  "user_zip_code" TEXT,
+  "score" DOUBLE PRECISION NOT NULL,
+  "tags" JSON,
+  "ok" BOOLEAN NOT NULL,
+  "misc" TEXT NOT NULL,
+  "extra" JSON
+);
+`
+	if got := table.CreateTable(); got != wantCreate {
+		t.Errorf("got:\n%s\nwant:\n%s", got, wantCreate)
+	}
+
+	const wantInsert = `INSERT INTO "people" ("id", "user_name", "user_zip_code", "score", "tags", "ok", "misc", "extra") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	if got := table.Insert(func(n int) string { return fmt.Sprintf("$%d", n) }); got != wantInsert {
+		t.Errorf("got %s, want %s", got, wantInsert)
+	}
+	if got := table.Insert(nil); !strings.HasSuffix(got, "VALUES (?, ?, ?, ?, ?, ?, ?, ?)") {
+		t.Errorf("got %s", got)
+	}
+
+	recs := records(t, jseq.ReaderSource(strings.NewReader(inp)))
+	row, err := table.Row(recs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRow := []any{int64(1), "Alice", "02139", 9.5, `["a"]`, true, "1", nil}
+	if !reflect.DeepEqual(row, wantRow) {
+		t.Errorf("got row %#v, want %#v", row, wantRow)
+	}
+
+	if _, err := table.Row(map[string]any{"id": "one"}); err == nil {
+		t.Error("got no error for mistyped record")
+	}
+}
+
+func TestInferTableNames(t *testing.T) {
+	const inp = `{"a_b": 1, "a": {"b": 2}, "2x": 3}`
+	tokens, _ := jseq.Tokens(strings.NewReader(inp))
+	values, _ := jseq.Values(tokens)
+	table := jseq.InferTable("t", values)
+
+	var got []string
+	for _, c := range table.Columns {
+		got = append(got, c.Name)
+	}
+	if want := []string{"a_b", "a_b_2", "_2x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestInferTableDeep(t *testing.T) {
+	const inp = `{"a": {"b": {"c": {"d": 1, "e": "x"}}}}`
+	tokens, _ := jseq.Tokens(strings.NewReader(inp))
+	values, _ := jseq.Values(tokens)
+	table := jseq.InferTable("t", values)
+
+	want := []jseq.Column{
+		{Name: "a_b_c_d", Pointer: jseq.Pointer{"a", "b", "c", "d"}, Type: jseq.SQLBigint},
+		{Name: "a_b_c_e", Pointer: jseq.Pointer{"a", "b", "c", "e"}, Type: jseq.SQLText},
+	}
+	if !reflect.DeepEqual(table.Columns, want) {
+		t.Errorf("got %+v, want %+v", table.Columns, want)
+	}
+}
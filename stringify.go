@@ -0,0 +1,89 @@
+package jseq
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/bobg/errors"
+)
+
+// Stringify replaces the subtrees of val at the given pointers
+// with strings containing their JSON encodings.
+// It is the inverse of the [ExpandStrings] option to [Values].
+//
+// The input is not modified.
+// The result shares structure with val except along the paths to the given pointers.
+// Pointers that do not locate anything in val are ignored.
+// The pointers are applied in order,
+// so a pointer into a subtree that has already been stringified will not be found.
+func Stringify(val any, pointers ...Pointer) (any, error) {
+	for _, p := range pointers {
+		var err error
+		val, err = stringifyAt(val, p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "stringifying %s", p.Text())
+		}
+	}
+	return val, nil
+}
+
+func stringifyAt(val any, p Pointer) (any, error) {
+	if len(p) == 0 {
+		b, err := Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+
+	if e, ok := val.(Expanded); ok {
+		val = e.Value
+	}
+
+	switch first := p[0].(type) {
+	case string:
+		switch v := val.(type) {
+		case map[string]any:
+			child, ok := v[first]
+			if !ok {
+				return val, nil
+			}
+			newChild, err := stringifyAt(child, p[1:])
+			if err != nil {
+				return nil, err
+			}
+			v = maps.Clone(v)
+			v[first] = newChild
+			return v, nil
+
+		case *Object:
+			child, ok := v.Get(first)
+			if !ok {
+				return val, nil
+			}
+			newChild, err := stringifyAt(child, p[1:])
+			if err != nil {
+				return nil, err
+			}
+			v = v.clone()
+			v.Set(first, newChild)
+			return v, nil
+		}
+		return val, nil
+
+	case int:
+		a, ok := val.([]any)
+		if !ok || first < 0 || first >= len(a) {
+			return val, nil
+		}
+		newChild, err := stringifyAt(a[first], p[1:])
+		if err != nil {
+			return nil, err
+		}
+		a = slices.Clone(a)
+		a[first] = newChild
+		return a, nil
+	}
+
+	return val, nil
+}
@@ -0,0 +1,65 @@
+package jseq_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestStringify(t *testing.T) {
+	val := map[string]any{
+		"id": jseq.Int(7),
+		"payload": map[string]any{
+			"b": []any{true, jseq.Null{}, jseq.Float(1.5)},
+			"a": "x",
+		},
+		"list": []any{map[string]any{"k": "v"}},
+	}
+
+	got, err := jseq.Stringify(val, jseq.Pointer{"payload"}, jseq.Pointer{"list", 0}, jseq.Pointer{"missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"id":      jseq.Int(7),
+		"payload": `{"a":"x","b":[true,null,1.5]}`,
+		"list":    []any{`{"k":"v"}`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// The input must be unchanged.
+	if _, ok := val["payload"].(map[string]any); !ok {
+		t.Errorf("input was modified: %v", val)
+	}
+}
+
+func TestStringifyObject(t *testing.T) {
+	var (
+		payload jseq.Object
+		val     jseq.Object
+	)
+	payload.Set("a", "x")
+	val.Set("id", jseq.Int(7))
+	val.Set("payload", &payload)
+
+	got, err := jseq.Stringify(&val, jseq.Pointer{"payload"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := jseq.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":7,"payload":"{\"a\":\"x\"}"}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+
+	// The input must be unchanged.
+	if v, _ := val.Get("payload"); v != &payload {
+		t.Errorf("input was modified: %v", v)
+	}
+}
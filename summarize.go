@@ -0,0 +1,101 @@
+package jseq
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Summarize returns a copy of v whose JSON encoding (see [Marshal])
+// fits within maxBytes, if possible,
+// while preserving its overall structure.
+// It is meant for logging large values such as request bodies.
+//
+// If v already fits, it is returned unchanged.
+// Otherwise, successively tighter limits are applied until the result fits:
+//
+//   - long strings are cut short and marked with their original length,
+//     as in "abcd…(1234 bytes)"
+//   - long arrays keep their first few elements,
+//     followed by a marker string such as "…(95 more)"
+//   - objects with many members keep the first few in sorted key order (see [SortedKeys]),
+//     plus a member with key "…" and a value such as "(12 more)"
+//
+// Even at the tightest limits the result may not fit,
+// in which case the smallest summary is returned.
+func Summarize(v any, maxBytes int) any {
+	if fitsIn(v, maxBytes) {
+		return v
+	}
+
+	lim := summaryLimits{str: maxBytes, elems: maxBytes}
+	for {
+		lim.str = max(lim.str/2, minSummaryString)
+		lim.elems = max(lim.elems/2, 1)
+
+		result := lim.summarize(v)
+		if fitsIn(result, maxBytes) || (lim.str == minSummaryString && lim.elems == 1) {
+			return result
+		}
+	}
+}
+
+// minSummaryString is the smallest number of bytes [Summarize] keeps from a long string.
+const minSummaryString = 8
+
+type summaryLimits struct {
+	str   int // maximum bytes kept from a string
+	elems int // maximum elements kept from an array or members from an object
+}
+
+func fitsIn(v any, maxBytes int) bool {
+	b, err := Marshal(v)
+	return err == nil && len(b) <= maxBytes
+}
+
+func (lim summaryLimits) summarize(v any) any {
+	if e, ok := v.(Expanded); ok {
+		v = e.Value
+	}
+	if b, ok := rawBytes(v); ok {
+		if decoded, err := decodeRaw(b); err == nil {
+			v = decoded
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		if len(v) <= lim.str {
+			return v
+		}
+		cut := lim.str
+		for cut > 0 && !utf8.RuneStart(v[cut]) {
+			cut--
+		}
+		return fmt.Sprintf("%s…(%d bytes)", v[:cut], len(v))
+
+	case []any:
+		n := min(len(v), lim.elems)
+		result := make([]any, 0, n+1)
+		for _, elt := range v[:n] {
+			result = append(result, lim.summarize(elt))
+		}
+		if n < len(v) {
+			result = append(result, fmt.Sprintf("…(%d more)", len(v)-n))
+		}
+		return result
+
+	case map[string]any:
+		keys := SortedKeys(v)
+		n := min(len(keys), lim.elems)
+		result := make(map[string]any, n+1)
+		for _, key := range keys[:n] {
+			result[key] = lim.summarize(v[key])
+		}
+		if n < len(keys) {
+			result["…"] = fmt.Sprintf("(%d more)", len(keys)-n)
+		}
+		return result
+	}
+
+	return v
+}
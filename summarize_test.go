@@ -0,0 +1,58 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestSummarize(t *testing.T) {
+	small := map[string]any{"a": "b"}
+	if got := jseq.Summarize(small, 100); !reflect.DeepEqual(got, small) {
+		t.Errorf("got %v, want %v unchanged", got, small)
+	}
+
+	var items []any
+	for i := range 100 {
+		items = append(items, map[string]any{"id": jseq.Int(int64(i)), "name": "item"})
+	}
+	big := map[string]any{
+		"body":  strings.Repeat("x", 10000),
+		"items": items,
+		"user":  "bob",
+	}
+
+	got := jseq.Summarize(big, 300)
+	b, err := jseq.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) > 300 {
+		t.Errorf("summary is %d bytes, want at most 300: %s", len(b), b)
+	}
+
+	m, ok := got.(map[string]any)
+	if !ok {
+		t.Fatalf("got %T, want map[string]any", got)
+	}
+	if body, _ := m["body"].(string); !strings.HasSuffix(body, "…(10000 bytes)") {
+		t.Errorf("got body %q, want a truncated string", body)
+	}
+	gotItems, _ := m["items"].([]any)
+	if len(gotItems) < 2 {
+		t.Fatalf("got items %v, want a sample and a marker", gotItems)
+	}
+	if marker, _ := gotItems[len(gotItems)-1].(string); !strings.HasPrefix(marker, "…(") {
+		t.Errorf("got last item %v, want a marker", gotItems[len(gotItems)-1])
+	}
+	if m["user"] != "bob" {
+		t.Errorf("got user %v, want bob", m["user"])
+	}
+
+	// The input is unchanged.
+	if len(big["body"].(string)) != 10000 || len(big["items"].([]any)) != 100 {
+		t.Error("input was modified")
+	}
+}
@@ -0,0 +1,77 @@
+package jseq
+
+import (
+	"fmt"
+	"time"
+)
+
+// Summary describes a completed run of [Values] or [Records].
+// See [WithSummary].
+type Summary struct {
+	// Records is the number of top-level values produced.
+	Records int
+
+	// Values is the number of values produced at all depths,
+	// including the top-level ones.
+	Values int
+
+	// Bytes is the number of bytes of input consumed.
+	// It is filled in by [Records] and [ValuesFromDecoder],
+	// but not by Values,
+	// which sees only tokens;
+	// nor by ValuesFromDecoder with [RecordTimeout], [Heartbeat], or [ValuesContext],
+	// which read the decoder in a separate goroutine.
+	Bytes int64
+
+	// Skipped is the number of malformed records
+	// that [Records] reported as errors and skipped.
+	Skipped int
+
+	// Recovered is the number of those
+	// after which Records had to resume parsing at the next line of input
+	// (see [RecordError]).
+	// The others were well-formed JSON that failed to decode,
+	// for example because of the options given.
+	Recovered int
+
+	// Duration is the time between the start of iteration and its end.
+	Duration time.Duration
+
+	// Err is the error, if any, that ended iteration.
+	Err error
+}
+
+// String produces a one-line digest of s suitable for logging.
+func (s Summary) String() string {
+	result := fmt.Sprintf("%d records, %d values", s.Records, s.Values)
+	if s.Bytes > 0 {
+		result += fmt.Sprintf(", %d bytes", s.Bytes)
+	}
+	if s.Skipped > 0 {
+		result += fmt.Sprintf(", %d skipped (%d recovered)", s.Skipped, s.Recovered)
+	}
+	result += fmt.Sprintf(" in %s", s.Duration)
+	if s.Err != nil {
+		result += fmt.Sprintf(" (error: %s)", s.Err)
+	}
+	return result
+}
+
+// WithSummary is an [Option] that causes [Values] (or [Records]) to fill in *s
+// when iteration ends,
+// whether by consuming the entire input, by error, or by the caller breaking out of the loop.
+func WithSummary(s *Summary) Option {
+	return func(c *config) {
+		c.summary = s
+	}
+}
+
+func (p *parser) countingYield(yield func(Pointer, any) bool) func(Pointer, any) bool {
+	return func(pointer Pointer, val any) bool {
+		p.summary.Values++
+		if len(pointer) == 0 {
+			p.summary.Records++
+		}
+		return yield(pointer, val)
+	}
+}
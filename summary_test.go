@@ -0,0 +1,87 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestSummary(t *testing.T) {
+	var s jseq.Summary
+
+	got := collect(t, strings.NewReader(`{"a": [1, 2]} 3 "x"`), jseq.WithSummary(&s))
+	if len(got) != 6 {
+		t.Fatalf("got %d values, want 6", len(got))
+	}
+	if s.Records != 3 {
+		t.Errorf("got %d records, want 3", s.Records)
+	}
+	if s.Values != 6 {
+		t.Errorf("got %d values in summary, want 6", s.Values)
+	}
+	if s.Duration <= 0 {
+		t.Errorf("got duration %s, want > 0", s.Duration)
+	}
+	if s.Err != nil {
+		t.Errorf("got error %v", s.Err)
+	}
+}
+
+func TestSummaryError(t *testing.T) {
+	var s jseq.Summary
+
+	tokens, _ := jseq.Tokens(strings.NewReader(`[1, 2`))
+	values, errptr := jseq.Values(tokens, jseq.WithSummary(&s))
+	for range values {
+	}
+	if *errptr == nil {
+		t.Fatal("got no error")
+	}
+	if s.Err != *errptr {
+		t.Errorf("got summary error %v, want %v", s.Err, *errptr)
+	}
+	if s.Records != 0 || s.Values != 2 {
+		t.Errorf("got %d records, %d values; want 0, 2", s.Records, s.Values)
+	}
+}
+
+func TestSummaryRecords(t *testing.T) {
+	const inp = `{"a": 1}
+{"b": oops}
+  ["c"]
+{"d": "${UNSET}"}
+{"e": [1,
+2]}
+{"f": 
+`
+
+	var s jseq.Summary
+	for range jseq.Records(strings.NewReader(inp), jseq.ExpandVars(jseq.MapResolver(nil), jseq.MissingVarError), jseq.WithSummary(&s)) {
+	}
+
+	s.Duration = 0
+	want := jseq.Summary{Records: 3, Values: 8, Bytes: int64(len(inp)), Skipped: 3, Recovered: 2}
+	if s != want {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+	if got, want := s.String(), "3 records, 8 values, 68 bytes, 3 skipped (2 recovered) in 0s"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSummaryDecoder(t *testing.T) {
+	const inp = `{"a": [1, 2]} 3`
+
+	var s jseq.Summary
+	values, errptr := jseq.ValuesFromDecoder(jsontext.NewDecoder(strings.NewReader(inp)), jseq.WithSummary(&s))
+	for range values {
+	}
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+	if s.Records != 2 || s.Values != 5 || s.Bytes != int64(len(inp)) {
+		t.Errorf("got %+v, want 2 records, 5 values, %d bytes", s, len(inp))
+	}
+}
@@ -0,0 +1,157 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"iter"
+	"os"
+	"slices"
+	"time"
+)
+
+// RecordTimeout is an [Option] that limits the time [Values] may spend on each top-level value.
+// The clock starts when the first token of a top-level value arrives
+// and stops when that value is produced,
+// so an input may be idle between values for any length of time
+// (see [Heartbeat] for monitoring that).
+// (Time spent in the caller's loop body handling nested values counts against the limit.)
+// If the limit is exceeded,
+// iteration ends with a [*RecordTimeoutError].
+//
+// This protects consumers of slow or stalled inputs, such as network uploads, from hanging.
+// To make this possible,
+// Values reads its input tokens in a separate goroutine.
+// After a timeout, that goroutine may remain blocked until its next token arrives;
+// closing the underlying reader will release it.
+// Until then, the caller should not rely on the error pointer returned by [Tokens].
+func RecordTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.recordTimeout = d
+	}
+}
+
+// RecordTimeoutError is the error produced when the limit set by [RecordTimeout] is exceeded.
+type RecordTimeoutError struct {
+	// Record is the ordinal of the timed-out top-level value, counting from zero.
+	Record int
+
+	// Pointer is the location within the record that parsing had reached.
+	Pointer Pointer
+
+	// Timeout is the limit that was exceeded.
+	Timeout time.Duration
+}
+
+func (e *RecordTimeoutError) Error() string {
+	return fmt.Sprintf("record %d timed out after %s at %q", e.Record, e.Timeout, e.Pointer.Text())
+}
+
+// Unwrap returns [os.ErrDeadlineExceeded].
+func (e *RecordTimeoutError) Unwrap() error {
+	return os.ErrDeadlineExceeded
+}
+
+// asyncTokens reads tokens in a separate goroutine,
+// producing them in a sequence that ends early if p.timer fires
+// (see [RecordTimeout])
+// and that calls p.onHeartbeat when tokens are slow to arrive
+// (see [Heartbeat]),
+// and that ends early if p.ctx is done
+// (see [ValuesContext]).
+// The stop function releases the goroutine
+// (once it is no longer waiting for its input).
+func (p *parser) asyncTokens(tokens iter.Seq[jsontext.Token]) (iter.Seq[jsontext.Token], func()) {
+	var (
+		ch   = make(chan jsontext.Token)
+		done = make(chan struct{})
+	)
+
+	go func() {
+		defer close(ch)
+		for tok := range tokens {
+			select {
+			case ch <- tok.Clone(): // the original may be invalidated when the next token is read
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var deadline <-chan time.Time
+	if p.recordTimeout > 0 {
+		p.timer = time.NewTimer(p.recordTimeout)
+		p.timer.Stop() // until the first token arrives
+		deadline = p.timer.C
+	}
+
+	var ctxDone <-chan struct{}
+	if p.ctx != nil {
+		ctxDone = p.ctx.Done()
+	}
+
+	f := func(yield func(jsontext.Token) bool) {
+		var (
+			hbTimer *time.Timer
+			hb      <-chan time.Time
+			last    = time.Now()
+		)
+		if p.heartbeat > 0 {
+			hbTimer = time.NewTimer(p.heartbeat)
+			defer hbTimer.Stop()
+			hb = hbTimer.C
+		}
+
+		for {
+			select {
+			case tok, ok := <-ch:
+				if !ok {
+					return
+				}
+				if hbTimer != nil {
+					last = time.Now()
+					hbTimer.Reset(p.heartbeat)
+				}
+				if p.timer != nil && !p.timerArmed {
+					p.timer.Reset(p.recordTimeout)
+					p.timerArmed = true
+				}
+				if !yield(tok) {
+					return
+				}
+
+			case <-deadline:
+				p.timed = true
+				return
+
+			case <-ctxDone:
+				p.canceled = true
+				return
+
+			case <-hb:
+				p.onHeartbeat(time.Since(last))
+				hbTimer.Reset(p.heartbeat)
+			}
+		}
+	}
+	stop := func() {
+		if p.timer != nil {
+			p.timer.Stop()
+		}
+		close(done)
+	}
+	return f, stop
+}
+
+// endOfInput returns the error to report when the parser's input ends at pointer:
+// a [*RecordTimeoutError] if the input ended because of a timeout,
+// the context's error if it ended because of cancellation (see [ValuesContext]),
+// and err otherwise.
+func (p *parser) endOfInput(pointer Pointer, err error) error {
+	if p.canceled {
+		return p.ctx.Err()
+	}
+	if p.timed {
+		return &RecordTimeoutError{Record: p.record, Pointer: slices.Clone(pointer), Timeout: p.recordTimeout}
+	}
+	return err
+}
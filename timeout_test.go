@@ -0,0 +1,101 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bobg/jseq"
+)
+
+func TestRecordTimeout(t *testing.T) {
+	// A reader that produces one complete record and part of another, then stalls.
+	pr, pw := io.Pipe()
+	go func() {
+		io.WriteString(pw, `{"a": 1} {"b": [true, `)
+		time.Sleep(time.Second)
+		pw.Close()
+	}()
+
+	tokens, _ := jseq.Tokens(pr)
+	values, errptr := jseq.Values(tokens, jseq.RecordTimeout(100*time.Millisecond))
+
+	var records int
+	for ptr := range values {
+		if len(ptr) == 0 {
+			records++
+		}
+	}
+	if records != 1 {
+		t.Errorf("got %d records, want 1", records)
+	}
+
+	var timeoutErr *jseq.RecordTimeoutError
+	if !errors.As(*errptr, &timeoutErr) {
+		t.Fatalf("got error %v, want RecordTimeoutError", *errptr)
+	}
+	if timeoutErr.Record != 1 {
+		t.Errorf("got record %d, want 1", timeoutErr.Record)
+	}
+	if want := (jseq.Pointer{"b"}); !reflect.DeepEqual(timeoutErr.Pointer, want) {
+		t.Errorf("got pointer %v, want %v", timeoutErr.Pointer, want)
+	}
+	if !errors.Is(*errptr, os.ErrDeadlineExceeded) {
+		t.Error("error does not match os.ErrDeadlineExceeded")
+	}
+}
+
+func TestRecordTimeoutNotExceeded(t *testing.T) {
+	toks := []jsontext.Token{jsontext.BeginArray, jsontext.Int(1), jsontext.EndArray, jsontext.Null}
+	tokens := func(yield func(jsontext.Token) bool) {
+		for _, tok := range toks {
+			if !yield(tok) {
+				return
+			}
+		}
+	}
+	values, errptr := jseq.Values(tokens, jseq.RecordTimeout(time.Minute))
+	var n int
+	for range values {
+		n++
+	}
+	if *errptr != nil {
+		t.Fatal(*errptr)
+	}
+	if n != 3 {
+		t.Errorf("got %d values, want 3", n)
+	}
+}
+
+func TestRecordTimeoutIdle(t *testing.T) {
+	// Pauses between records do not count against the limit.
+	pr, pw := io.Pipe()
+	go func() {
+		io.WriteString(pw, `{"a": 1}`)
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(pw, `{"b": 2}`)
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(pw, `{"c": 3}`)
+		pw.Close()
+	}()
+
+	tokens, _ := jseq.Tokens(pr)
+	values, errptr := jseq.Values(tokens, jseq.RecordTimeout(100*time.Millisecond))
+
+	var records int
+	for ptr := range values {
+		if len(ptr) == 0 {
+			records++
+		}
+	}
+	if err := *errptr; err != nil {
+		t.Fatal(err)
+	}
+	if records != 3 {
+		t.Errorf("got %d records, want 3", records)
+	}
+}
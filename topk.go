@@ -0,0 +1,122 @@
+package jseq
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// TopK tracks the approximate k most frequent scalar values in a stream
+// using the Space-Saving algorithm,
+// which needs memory proportional only to k.
+// The zero value is not usable; create one with [NewTopK].
+type TopK struct {
+	k       int
+	entries map[string]*Frequency
+}
+
+// Frequency is an entry in the result of [TopK.Items].
+type Frequency struct {
+	Value any
+
+	// Count is the estimated number of occurrences of Value.
+	// It never underestimates.
+	Count int
+
+	// Error bounds the overestimate in Count:
+	// the true count is at least Count-Error.
+	Error int
+}
+
+// NewTopK creates a new [TopK] tracking up to k values.
+func NewTopK(k int) *TopK {
+	return &TopK{
+		k:       max(k, 1),
+		entries: make(map[string]*Frequency),
+	}
+}
+
+// Add records one occurrence of v.
+// Arrays and objects are ignored.
+func (t *TopK) Add(v any) {
+	b, ok := scalarKey(v)
+	if !ok {
+		return
+	}
+	key := string(b)
+
+	if e, ok := t.entries[key]; ok {
+		e.Count++
+		return
+	}
+	if len(t.entries) < t.k {
+		t.entries[key] = &Frequency{Value: v, Count: 1}
+		return
+	}
+
+	// Evict the entry with the smallest count,
+	// preferring the least reliable one (largest error) among ties.
+	var (
+		minKey string
+		minEnt *Frequency
+	)
+	for k, e := range t.entries {
+		if minEnt == nil || cmp.Or(cmp.Compare(e.Count, minEnt.Count), cmp.Compare(minEnt.Error, e.Error), cmp.Compare(k, minKey)) < 0 {
+			minKey, minEnt = k, e
+		}
+	}
+	delete(t.entries, minKey)
+	t.entries[key] = &Frequency{Value: v, Count: minEnt.Count + 1, Error: minEnt.Count}
+}
+
+// Items returns the tracked values,
+// most frequent first.
+func (t *TopK) Items() []Frequency {
+	result := make([]Frequency, 0, len(t.entries))
+	for _, e := range t.entries {
+		result = append(result, *e)
+	}
+	slices.SortFunc(result, func(a, b Frequency) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		ak, _ := scalarKey(a.Value)
+		bk, _ := scalarKey(b.Value)
+		return cmp.Compare(string(ak), string(bk))
+	})
+	return result
+}
+
+// TopValues consumes a sequence of pointer/value pairs as produced by [Values]
+// and reports the approximate k most frequent scalar values at each of the given paths
+// (see [Pointer.Path]).
+// If no paths are given,
+// every path is tracked.
+func TopValues(values iter.Seq2[Pointer, any], k int, paths ...string) map[string][]Frequency {
+	sketches := make(map[string]*TopK)
+	for _, path := range paths {
+		sketches[path] = NewTopK(k)
+	}
+
+	for pointer, val := range values {
+		if _, ok := scalarKey(val); !ok {
+			continue
+		}
+		path := pointer.Path()
+		t := sketches[path]
+		if t == nil {
+			if len(paths) > 0 {
+				continue
+			}
+			t = NewTopK(k)
+			sketches[path] = t
+		}
+		t.Add(val)
+	}
+
+	result := make(map[string][]Frequency, len(sketches))
+	for path, t := range sketches {
+		result[path] = t.Items()
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package jseq_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestTopK(t *testing.T) {
+	tk := jseq.NewTopK(2)
+	for _, s := range strings.Fields("a b a c a b d a b") {
+		tk.Add(s)
+	}
+	tk.Add([]any{"ignored"})
+
+	got := tk.Items()
+	want := []jseq.Frequency{
+		{Value: "b", Count: 5, Error: 4},
+		{Value: "a", Count: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestTopValues(t *testing.T) {
+	const inp = `
+{"type": "click", "user": {"id": 1}}
+{"type": "view", "user": {"id": 2}}
+{"type": "click", "user": {"id": 1}}
+`
+
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp))
+	values, errptr2 := jseq.Values(tokens)
+	got := jseq.TopValues(values, 5, "/type")
+	if *errptr1 != nil {
+		t.Fatal(*errptr1)
+	}
+	if *errptr2 != nil {
+		t.Fatal(*errptr2)
+	}
+
+	want := map[string][]jseq.Frequency{
+		"/type": {
+			{Value: "click", Count: 2},
+			{Value: "view", Count: 1},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+package jseq
+
+// V1Values is an [Option] that causes [Values] to produce
+// what [encoding/json.Unmarshal] would produce when decoding into an any:
+// float64 for numbers and nil for null,
+// instead of [Number] and [Null].
+// Arrays and objects are still []any and map[string]any
+// (unless other options say otherwise),
+// as they are in encoding/json.
+// This lets a Values stream feed existing code
+// that type-switches on the values encoding/json produces.
+//
+// Numbers are converted as with [NumbersAsFloat64].
+//
+// [Marshal], [TypeName], and [Clone] accept float64 and nil,
+// but other functions in this package that inspect values,
+// such as [Expr.Eval] and [Rules.Apply],
+// expect [Number] and [Null].
+func V1Values() Option {
+	return func(c *config) {
+		c.v1Values = true
+		c.numberMode = NumbersAsFloat64
+	}
+}
+
+// null returns the value produced for a JSON null.
+func (p *parser) null() any {
+	if p.v1Values {
+		return nil
+	}
+	return Null{}
+}
@@ -0,0 +1,63 @@
+package jseq_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestV1Values(t *testing.T) {
+	cases := []struct {
+		inp     string
+		wantErr bool
+	}{
+		{inp: `null`},
+		{inp: `[1, 2.5, -3e2, null, true, "x"]`},
+		{inp: `{"a": {"b": [0.1, 12345678901234567890]}, "c": null}`},
+		{inp: `1e400`, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.inp, func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(tc.inp))
+			values, errptr := jseq.Values(tokens, jseq.V1Values())
+
+			var got any
+			for _, val := range values {
+				got = val
+			}
+			if tc.wantErr {
+				if *errptr == nil {
+					t.Error("got no error, want one")
+				}
+				return
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+
+			var want any
+			if err := json.Unmarshal([]byte(tc.inp), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %#v, want %#v", got, want)
+			}
+
+			b, err := jseq.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantb, err := json.Marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != string(wantb) {
+				t.Errorf("got encoding %s, want %s", b, wantb)
+			}
+		})
+	}
+}
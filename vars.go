@@ -0,0 +1,134 @@
+package jseq
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// VarResolver looks up the value of a variable for [ExpandVars].
+// The boolean result tells whether the variable is defined.
+type VarResolver func(name string) (string, bool)
+
+// EnvResolver is a [VarResolver] that looks up environment variables.
+var EnvResolver VarResolver = os.LookupEnv
+
+// MapResolver returns a [VarResolver] that looks up variables in m,
+// e.g. values from command-line flags.
+func MapResolver(m map[string]string) VarResolver {
+	return func(name string) (string, bool) {
+		val, ok := m[name]
+		return val, ok
+	}
+}
+
+// MissingVarPolicy tells [ExpandVars] what to do about undefined variables.
+type MissingVarPolicy int
+
+const (
+	// MissingVarError causes an undefined variable to end [Values] with an error.
+	MissingVarError MissingVarPolicy = iota
+
+	// MissingVarEmpty replaces an undefined variable with the empty string.
+	MissingVarEmpty
+
+	// MissingVarKeep leaves the placeholder for an undefined variable in place.
+	MissingVarKeep
+)
+
+// ExpandVars is an [Option] that causes [Values] to expand placeholders in string values
+// using the given resolver.
+// Object keys are not expanded.
+//
+// A placeholder has the form ${NAME}
+// or ${NAME:-DEFAULT},
+// where DEFAULT is used if NAME is undefined.
+// To include a literal "${" in a string, write "$${".
+// The policy determines what happens when a variable is undefined and there is no default.
+//
+// This makes it possible to load configuration documents
+// containing references to environment variables (see [EnvResolver])
+// or other settings (see [MapResolver]).
+//
+// Expansion happens before [ExpandStrings] is applied.
+func ExpandVars(resolve VarResolver, policy MissingVarPolicy) Option {
+	return func(c *config) {
+		c.vars = &varConfig{resolve: resolve, policy: policy}
+	}
+}
+
+type varConfig struct {
+	resolve VarResolver
+	policy  MissingVarPolicy
+}
+
+// UndefinedVarError is the error produced by [ExpandVars]
+// for an undefined variable under [MissingVarError].
+type UndefinedVarError struct {
+	Name    string
+	Pointer Pointer
+}
+
+func (e *UndefinedVarError) Error() string {
+	return fmt.Sprintf("undefined variable %s at %q", e.Name, e.Pointer.Text())
+}
+
+func (p *parser) expandVars(pointer Pointer, s string) (string, error) {
+	if p.vars == nil || !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var buf strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			buf.WriteString(s)
+			return buf.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			// Escaped: $${ is a literal ${.
+			buf.WriteString(s[:i])
+			buf.WriteString("{")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			// Unterminated; not a placeholder.
+			buf.WriteString(s)
+			return buf.String(), nil
+		}
+		end += i
+
+		buf.WriteString(s[:i])
+		var (
+			placeholder = s[i : end+1]
+			name        = s[i+2 : end]
+			def         string
+			hasDefault  bool
+		)
+		if j := strings.Index(name, ":-"); j >= 0 {
+			name, def, hasDefault = name[:j], name[j+2:], true
+		}
+		s = s[end+1:]
+
+		if val, ok := p.vars.resolve(name); ok {
+			buf.WriteString(val)
+			continue
+		}
+		if hasDefault {
+			buf.WriteString(def)
+			continue
+		}
+		switch p.vars.policy {
+		case MissingVarError:
+			return "", &UndefinedVarError{Name: name, Pointer: slices.Clone(pointer)}
+		case MissingVarEmpty:
+			p.warn(SeverityWarning, pointer, "undefined variable %q replaced with the empty string", name)
+		case MissingVarKeep:
+			p.warn(SeverityWarning, pointer, "undefined variable %q left in place", name)
+			buf.WriteString(placeholder)
+		}
+	}
+}
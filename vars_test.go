@@ -0,0 +1,75 @@
+package jseq_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestExpandVars(t *testing.T) {
+	const inp = `{"${HOST}": "${HOST}:${PORT:-8080}", "user": "${USER}", "lit": "$${HOST}", "odd": "${unterminated"}`
+
+	resolver := jseq.MapResolver(map[string]string{"HOST": "example.com"})
+
+	cases := []struct {
+		name    string
+		policy  jseq.MissingVarPolicy
+		want    map[string]any
+		wantErr bool
+	}{{
+		name:    "error",
+		policy:  jseq.MissingVarError,
+		wantErr: true,
+	}, {
+		name:   "empty",
+		policy: jseq.MissingVarEmpty,
+		want: map[string]any{
+			"${HOST}": "example.com:8080",
+			"user":    "",
+			"lit":     "${HOST}",
+			"odd":     "${unterminated",
+		},
+	}, {
+		name:   "keep",
+		policy: jseq.MissingVarKeep,
+		want: map[string]any{
+			"${HOST}": "example.com:8080",
+			"user":    "${USER}",
+			"lit":     "${HOST}",
+			"odd":     "${unterminated",
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(inp))
+			values, errptr := jseq.Values(tokens, jseq.ExpandVars(resolver, tc.policy))
+
+			var last any
+			for _, val := range values {
+				last = val
+			}
+
+			if tc.wantErr {
+				var varErr *jseq.UndefinedVarError
+				if !errors.As(*errptr, &varErr) {
+					t.Fatalf("got error %v, want UndefinedVarError", *errptr)
+				}
+				if varErr.Name != "USER" || !reflect.DeepEqual(varErr.Pointer, jseq.Pointer{"user"}) {
+					t.Errorf("got %+v, want USER at /user", varErr)
+				}
+				return
+			}
+
+			if *errptr != nil {
+				t.Fatal(*errptr)
+			}
+			if !reflect.DeepEqual(last, tc.want) {
+				t.Errorf("got %v, want %v", last, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package jseq
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bobg/errors"
+)
+
+// ViewStore holds the records of a [View] by key.
+// Implementations backed by durable storage
+// allow a view to outlive the process maintaining it.
+type ViewStore interface {
+	// Get returns the record with the given key, if there is one.
+	Get(key string) (any, bool, error)
+
+	// Put stores rec under the given key,
+	// replacing any record already there.
+	Put(key string, rec any) error
+
+	// Delete removes the record with the given key, if there is one.
+	Delete(key string) error
+
+	// Keys returns the keys of all the records in the store,
+	// in any order.
+	Keys() ([]string, error)
+}
+
+// MemoryViewStore is an in-memory [ViewStore].
+// The zero value is an empty store ready to use.
+// It is safe for concurrent use.
+type MemoryViewStore struct {
+	mu   sync.Mutex
+	recs map[string]any
+}
+
+// Get implements [ViewStore].
+func (s *MemoryViewStore) Get(key string) (any, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.recs[key]
+	return rec, ok, nil
+}
+
+// Put implements [ViewStore].
+func (s *MemoryViewStore) Put(key string, rec any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.recs == nil {
+		s.recs = make(map[string]any)
+	}
+	s.recs[key] = rec
+	return nil
+}
+
+// Delete implements [ViewStore].
+func (s *MemoryViewStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.recs, key)
+	return nil
+}
+
+// Keys implements [ViewStore].
+func (s *MemoryViewStore) Keys() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Collect(maps.Keys(s.recs)), nil
+}
+
+// ViewSpec describes the view maintained by a [View].
+type ViewSpec struct {
+	// Key locates the values making up each record's key.
+	// With no pointers,
+	// the whole record is its key.
+	// Records lacking any of the key values are ignored.
+	Key []Pointer
+
+	// Delete, if not nil,
+	// tells whether a record is a deletion
+	// (e.g. a change-data-capture event with "op": "delete").
+	// A deletion removes the record with its key from the view
+	// instead of replacing it.
+	Delete func(rec any) bool
+
+	// Store holds the view's records.
+	// The default is a new [MemoryViewStore].
+	Store ViewStore
+}
+
+// View is a [Sink] that maintains a keyed view of a stream of records (top-level values):
+// the latest record for each key.
+// This is the usual way to turn a feed of change events into current state.
+// Create one with [NewView].
+//
+// A View may be queried with [View.Get] and exported with [View.Snapshot]
+// while records are still arriving,
+// if its [ViewStore] is safe for concurrent use
+// (as [MemoryViewStore] is).
+type View struct {
+	spec ViewSpec
+}
+
+// NewView creates a [View] as described by spec.
+func NewView(spec ViewSpec) *View {
+	if spec.Store == nil {
+		spec.Store = new(MemoryViewStore)
+	}
+	return &View{spec: spec}
+}
+
+// Consume implements [Sink].
+// Pairs other than records are ignored.
+func (v *View) Consume(pointer Pointer, val any) error {
+	if len(pointer) > 0 {
+		return nil
+	}
+	key, ok := recordKey(val, v.spec.Key)
+	if !ok {
+		return nil
+	}
+	if v.spec.Delete != nil && v.spec.Delete(val) {
+		return errors.Wrap(v.spec.Store.Delete(key), "deleting from view")
+	}
+	return errors.Wrap(v.spec.Store.Put(key, val), "storing in view")
+}
+
+// Close implements [Sink].
+// It does nothing.
+func (v *View) Close() error {
+	return nil
+}
+
+// Get returns the current record with the given key values,
+// one for each pointer in the spec's Key
+// (or the whole record, if there are none),
+// and whether there is one.
+// Key values are compared by their JSON encodings (see [Marshal]),
+// so they must be of the types produced by [Values],
+// such as [Number] (e.g. from [Int]) for numbers.
+func (v *View) Get(keyVals ...any) (any, bool, error) {
+	var parts []string
+	for _, val := range keyVals {
+		b, err := Marshal(val)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "encoding key")
+		}
+		parts = append(parts, string(b))
+	}
+	return v.spec.Store.Get(strings.Join(parts, "\x00"))
+}
+
+// Snapshot returns the current records in the view,
+// in order of their keys.
+func (v *View) Snapshot() ([]any, error) {
+	keys, err := v.spec.Store.Keys()
+	if err != nil {
+		return nil, errors.Wrap(err, "listing view keys")
+	}
+	slices.Sort(keys)
+
+	var result []any
+	for _, key := range keys {
+		rec, ok, err := v.spec.Store.Get(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading view")
+		}
+		if ok { // it may have been deleted since Keys
+			result = append(result, rec)
+		}
+	}
+	return result, nil
+}
+
+// WriteSnapshot writes the current records in the view to w,
+// in order of their keys,
+// one per line.
+// The output can be read back with [Tokens] and [Values]
+// (e.g. to restore the view).
+func (v *View) WriteSnapshot(w io.Writer) error {
+	recs, err := v.Snapshot()
+	if err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		b, err := Marshal(rec)
+		if err != nil {
+			return errors.Wrap(err, "encoding record")
+		}
+		b = append(b, '\n')
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
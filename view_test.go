@@ -0,0 +1,66 @@
+package jseq_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestView(t *testing.T) {
+	const inp = `
+{"id": 2, "op": "upsert", "name": "b"}
+{"id": 1, "op": "upsert", "name": "a"}
+{"id": 3, "op": "upsert", "name": "c"}
+{"op": "upsert", "name": "no id"}
+{"id": 1, "op": "upsert", "name": "a2"}
+{"id": 3, "op": "delete"}
+`
+
+	view := jseq.NewView(jseq.ViewSpec{
+		Key: []jseq.Pointer{{"id"}},
+		Delete: func(rec any) bool {
+			op, _ := jseq.Pointer{"op"}.Locate(rec)
+			return op == "delete"
+		},
+	})
+	if err := jseq.Pipe(context.Background(), jseq.ReaderSource(strings.NewReader(inp)), view, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := view.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []any{
+		map[string]any{"id": jseq.Int(1), "op": "upsert", "name": "a2"},
+		map[string]any{"id": jseq.Int(2), "op": "upsert", "name": "b"},
+	}
+	if !reflect.DeepEqual(recs, want) {
+		t.Errorf("got %v, want %v", recs, want)
+	}
+
+	rec, ok, err := view.Get(jseq.Int(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || !reflect.DeepEqual(rec, want[1]) {
+		t.Errorf("got %v (found %v), want %v", rec, ok, want[1])
+	}
+	if _, ok, err := view.Get(jseq.Int(3)); err != nil || ok {
+		t.Errorf("got found %v, error %v for deleted key; want neither", ok, err)
+	}
+
+	var buf strings.Builder
+	if err := view.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	const wantText = `{"id":1,"name":"a2","op":"upsert"}
+{"id":2,"name":"b","op":"upsert"}
+`
+	if got := buf.String(); got != wantText {
+		t.Errorf("got snapshot:\n%s\nwant:\n%s", got, wantText)
+	}
+}
@@ -0,0 +1,74 @@
+package jseq
+
+import (
+	"iter"
+	"slices"
+)
+
+// SortedKeys returns the keys of obj in sorted order.
+// It is the same as [LexicalOrder].Keys.
+func SortedKeys(obj map[string]any) []string {
+	return LexicalOrder.Keys(obj)
+}
+
+// Walk produces the parts of v paired with their pointers,
+// in the same depth-first, children-before-parents order that [Values] uses.
+// Object members are visited in sorted key order (see [SortedKeys]),
+// so the result is deterministic.
+//
+// Undecoded JSON values
+// (of type [jsontext.Value] or [encoding/json.RawMessage])
+// are decoded in order to visit their parts,
+// but are themselves produced in their undecoded form.
+//
+// The last pair produced is v itself, with the empty pointer.
+//
+// Walk is the same as [LexicalOrder].Walk.
+func Walk(v any) iter.Seq2[Pointer, any] {
+	return LexicalOrder.Walk(v)
+}
+
+// Walk is like the top-level [Walk] function,
+// but visits object members in order o.
+func (o KeyOrder) Walk(v any) iter.Seq2[Pointer, any] {
+	return func(yield func(Pointer, any) bool) {
+		o.walk(v, nil, yield)
+	}
+}
+
+func (o KeyOrder) walk(v any, pointer Pointer, yield func(Pointer, any) bool) bool {
+	inner := v
+	if e, ok := v.(Expanded); ok {
+		inner = e.Value
+	}
+	if b, ok := rawBytes(inner); ok {
+		if decoded, err := decodeRaw(b); err == nil {
+			inner = decoded
+		}
+	}
+
+	switch inner := inner.(type) {
+	case []any:
+		for i, elt := range inner {
+			if !o.walk(elt, append(slices.Clip(pointer), i), yield) {
+				return false
+			}
+		}
+
+	case map[string]any:
+		for _, key := range o.Keys(inner) {
+			if !o.walk(inner[key], append(slices.Clip(pointer), key), yield) {
+				return false
+			}
+		}
+
+	case *Object:
+		for key, elt := range inner.All() {
+			if !o.walk(elt, append(slices.Clip(pointer), key), yield) {
+				return false
+			}
+		}
+	}
+
+	return yield(pointer, v)
+}
@@ -0,0 +1,35 @@
+package jseq_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestWalk(t *testing.T) {
+	val := map[string]any{
+		"b": []any{jseq.Int(1), jseq.Int(2)},
+		"a": "x",
+	}
+
+	var got []pair
+	for p, v := range jseq.Walk(val) {
+		got = append(got, pair{p: p, v: v})
+	}
+
+	want := []pair{
+		{jseq.Pointer{"a"}, "x"},
+		{jseq.Pointer{"b", 0}, jseq.Int(1)},
+		{jseq.Pointer{"b", 1}, jseq.Int(2)},
+		{jseq.Pointer{"b"}, []any{jseq.Int(1), jseq.Int(2)}},
+		{nil, val},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if keys := jseq.SortedKeys(val); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("got keys %v, want [a b]", keys)
+	}
+}
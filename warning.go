@@ -0,0 +1,74 @@
+package jseq
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Severity is the severity of a [Warning].
+type Severity int
+
+const (
+	// SeverityInfo is for conditions that are normal but may be of interest.
+	SeverityInfo Severity = iota
+
+	// SeverityWarning is for conditions that may indicate a problem in the input,
+	// which was nonetheless handled.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Warning describes a non-fatal condition encountered by [Values].
+// See [OnWarning].
+type Warning struct {
+	Severity Severity
+
+	// Record is the ordinal of the top-level value in which the condition arose,
+	// counting from zero.
+	Record int
+
+	// Pointer locates the condition within its top-level value.
+	Pointer Pointer
+
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: record %d at %q: %s", w.Severity, w.Record, w.Pointer.Text(), w.Message)
+}
+
+// OnWarning is an [Option] that causes [Values] to call f
+// for each non-fatal condition it handles,
+// such as a duplicate object key
+// (when duplicates are allowed, see [jsontext.AllowDuplicateNames])
+// or an undefined variable replaced according to a [MissingVarPolicy].
+//
+// Without this option such conditions pass silently.
+// Either way,
+// the error pointer returned by [Values] is reserved for fatal errors.
+func OnWarning(f func(Warning)) Option {
+	return func(c *config) {
+		c.onWarning = f
+	}
+}
+
+func (p *parser) warn(severity Severity, pointer Pointer, format string, args ...any) {
+	if p.onWarning == nil {
+		return
+	}
+	p.onWarning(Warning{
+		Severity: severity,
+		Record:   p.record,
+		Pointer:  slices.Clone(pointer),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,39 @@
+package jseq_test
+
+import (
+	"encoding/json/jsontext"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestOnWarning(t *testing.T) {
+	const inp = `{"a": 1} {"b": {"x": 1, "x": 2}, "c": "${UNSET}", "email": "p", "Email": "q"}`
+
+	var got []string
+	tokens, errptr1 := jseq.Tokens(strings.NewReader(inp), jsontext.AllowDuplicateNames(true))
+	values, errptr2 := jseq.Values(tokens,
+		jseq.ExpandVars(jseq.MapResolver(nil), jseq.MissingVarKeep),
+		jseq.CanonicalKeys([]string{"email", "Email"}),
+		jseq.OnWarning(func(w jseq.Warning) {
+			got = append(got, w.String())
+		}),
+	)
+	for range values {
+	}
+	if err := errors.Join(*errptr1, *errptr2); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`warning: record 1 at "/b/x": duplicate key "x"; the later value wins`,
+		`warning: record 1 at "/c": undefined variable "UNSET" left in place`,
+		`warning: record 1 at "/email": key "Email" duplicates canonical key "email"; the later value wins`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package jseq
+
+import (
+	"context"
+	"iter"
+	"os"
+	"time"
+
+	"github.com/bobg/errors"
+)
+
+// Update is a new version of a watched file, produced by [Watch].
+type Update struct {
+	// Value is the file's new content.
+	Value any
+
+	// Changes are the differences from the previous version (see [Diff]).
+	// It is nil for the first update.
+	Changes []Change
+}
+
+// Watch produces the JSON value in the named file,
+// and then a new value each time the file changes,
+// until ctx is canceled or the caller stops iterating.
+// The file is checked for changes every interval
+// (or every second, if interval is not positive),
+// by comparing its size and modification time.
+// Updates that leave the value unchanged are not produced.
+//
+// If the file cannot be read or parsed,
+// the error is produced (with a zero [Update]),
+// and watching continues,
+// so that a service can keep running with its old configuration
+// while a bad edit is fixed.
+//
+// The file must contain a single JSON value.
+// The options are passed to [Values].
+func Watch(ctx context.Context, path string, interval time.Duration, opts ...Option) iter.Seq2[Update, error] {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return func(yield func(Update, error) bool) {
+		var (
+			ticker  = time.NewTicker(interval)
+			last    os.FileInfo
+			prev    any
+			loaded  bool
+			missing bool // whether the last check found no file (to report that only once)
+		)
+		defer ticker.Stop()
+
+		for {
+			info, err := os.Stat(path)
+			switch {
+			case err != nil:
+				last = nil
+				if !missing {
+					missing = true
+					if !yield(Update{}, err) {
+						return
+					}
+				}
+
+			case last == nil || info.Size() != last.Size() || !info.ModTime().Equal(last.ModTime()):
+				missing, last = false, info
+
+				val, err := readValue(path, opts)
+				switch {
+				case err != nil:
+					if !yield(Update{}, errors.Wrapf(err, "reading %s", path)) {
+						return
+					}
+
+				case !loaded:
+					loaded, prev = true, val
+					if !yield(Update{Value: val}, nil) {
+						return
+					}
+
+				default:
+					if changes := Diff(prev, val); len(changes) > 0 {
+						prev = val
+						if !yield(Update{Value: val, Changes: changes}, nil) {
+							return
+						}
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+func readValue(path string, opts []Option) (any, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decodeValue(f, opts...)
+}
@@ -0,0 +1,82 @@
+package jseq_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bobg/jseq"
+)
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	write := func(s string, mtime time.Time) {
+		if err := os.WriteFile(path, []byte(s), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Set the modification time explicitly, in case the filesystem's resolution is coarse.
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	base := time.Now().Add(-time.Hour)
+	write(`{"level": "info"}`, base)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var n int
+	for update, err := range jseq.Watch(ctx, path, 10*time.Millisecond) {
+		switch n {
+		case 0:
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(update.Value, map[string]any{"level": "info"}) || update.Changes != nil {
+				t.Errorf("got initial update %+v", update)
+			}
+			write(`{"level": `, base.Add(time.Second)) // invalid
+
+		case 1:
+			if err == nil {
+				t.Fatalf("got update %+v, want error", update)
+			}
+			write(`{"level": "debug"}`, base.Add(2*time.Second))
+
+		case 2:
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []jseq.Change{{Kind: jseq.Modified, Pointer: jseq.Pointer{"level"}, Old: "info", New: "debug"}}
+			if !reflect.DeepEqual(update.Changes, want) {
+				t.Errorf("got changes %v, want %v", update.Changes, want)
+			}
+			cancel()
+		}
+		n++
+	}
+	if n != 3 {
+		t.Errorf("got %d updates, want 3", n)
+	}
+}
+
+func TestWatchZeroInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"level": "info"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for update, err := range jseq.Watch(context.Background(), path, 0) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(update.Value, map[string]any{"level": "info"}) {
+			t.Errorf("got %v", update.Value)
+		}
+		break
+	}
+}
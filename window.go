@@ -0,0 +1,345 @@
+package jseq
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/bobg/errors"
+)
+
+// WindowSpec describes the windows produced by [Windows].
+//
+// Count-based windows hold Size records each,
+// with a new window starting every Slide records.
+//
+// Time-based windows, selected by setting Duration,
+// hold the records whose timestamps (located by Timestamp)
+// fall in the interval [Start, Start+Duration),
+// with a new window starting Every so often,
+// aligned to multiples of Every since the Unix epoch.
+//
+// In either case,
+// a zero Slide or Every means the same as the window's size,
+// giving "tumbling" windows that do not overlap;
+// a smaller Slide gives overlapping "sliding" windows.
+type WindowSpec struct {
+	// Size is the number of records in a count-based window.
+	Size int
+
+	// Duration is the length of a time-based window.
+	Duration time.Duration
+
+	// Slide is the number of records between the starts of successive count-based windows.
+	Slide int
+
+	// Every is the time between the starts of successive time-based windows.
+	Every time.Duration
+
+	// Timestamp locates the timestamp in each record of a time-based window.
+	// It may be a string in RFC 3339 format,
+	// or a number of seconds since the Unix epoch.
+	Timestamp Pointer
+
+	// Lateness is how far a record's timestamp may lag
+	// behind the latest timestamp seen so far
+	// for the record still to be counted in its time-based windows.
+	// A window is complete when the watermark,
+	// which is the latest timestamp less Lateness,
+	// reaches its end.
+	Lateness time.Duration
+
+	// Late is the policy for records arriving after their windows are complete.
+	Late LatePolicy
+
+	// OnLate receives late records under [LateSideOutput].
+	OnLate func(rec any, ts time.Time)
+
+	// Retention is how long after the watermark passes a window's end
+	// the window is kept for [LateRecompute].
+	// The default is Duration.
+	Retention time.Duration
+
+	// Aggregate, if set, computes each window's Value from its records.
+	Aggregate func(records []any) any
+}
+
+// LatePolicy is what [Windows] does with a record
+// arriving after its time-based windows are complete.
+// See [WindowSpec.Lateness].
+type LatePolicy int
+
+const (
+	// LateDrop drops late records.
+	LateDrop LatePolicy = iota
+
+	// LateSideOutput passes late records to [WindowSpec.OnLate].
+	LateSideOutput
+
+	// LateRecompute adds each late record to its complete windows,
+	// if they are still retained (see [WindowSpec.Retention]),
+	// and produces those windows again,
+	// with an increased Revision.
+	// Consumers should let a revised window supersede the earlier version
+	// with the same Start.
+	LateRecompute
+)
+
+// Window is a window of records produced by [Windows].
+type Window struct {
+	// Start and End bound a time-based window.
+	// They are zero for a count-based window.
+	Start, End time.Time
+
+	Records []any
+
+	// Value is the result of [WindowSpec.Aggregate], if any.
+	Value any
+
+	// Revision counts the times a time-based window has been produced again
+	// with added late records (see [LateRecompute]).
+	// It is zero the first time.
+	Revision int
+}
+
+// Windows consumes a sequence of pointer/value pairs,
+// as from [Values],
+// and groups its records (top-level values) into windows as described by spec,
+// producing each window when it is complete.
+// Windows with no records are not produced.
+//
+// Time-based windows use event time:
+// a window is complete when the watermark reaches its end,
+// where the watermark trails the latest timestamp seen
+// by the allowed lateness ([WindowSpec.Lateness]).
+// Records may arrive out of timestamp order by up to that much
+// and still count in the right windows.
+// A record arriving later than that is handled according to [WindowSpec.Late].
+//
+// Count-based windows are complete when full.
+// At the end of the input,
+// remaining windows are produced even if incomplete,
+// except that a count-based window
+// holding only records already produced in another window
+// is omitted.
+//
+// After consuming the windows,
+// the caller may check for errors by dereferencing the returned error pointer.
+// It is an error if a record lacks a valid timestamp.
+func Windows(values iter.Seq2[Pointer, any], spec WindowSpec) (iter.Seq[Window], *error) {
+	var err error
+
+	f := func(yield func(Window) bool) {
+		emit := func(w Window) bool {
+			if spec.Aggregate != nil {
+				w.Value = spec.Aggregate(w.Records)
+			}
+			return yield(w)
+		}
+
+		switch {
+		case spec.Duration > 0:
+			err = timeWindows(values, spec, emit)
+		case spec.Size > 0:
+			countWindows(values, spec, emit)
+		default:
+			err = errors.New("window spec has neither Size nor Duration")
+		}
+	}
+
+	return f, &err
+}
+
+func countWindows(values iter.Seq2[Pointer, any], spec WindowSpec, emit func(Window) bool) {
+	var (
+		slide   = spec.Slide
+		buf     []any // records from ordinal next on
+		n       int   // number of records seen
+		next    int   // ordinal of the first record in the next window
+		lastEnd int   // ordinal after the last record produced
+	)
+	if slide <= 0 {
+		slide = spec.Size
+	}
+
+	for pointer, val := range values {
+		if len(pointer) > 0 {
+			continue
+		}
+		if n >= next {
+			buf = append(buf, val)
+		}
+		n++
+
+		if len(buf) == spec.Size {
+			if !emit(Window{Records: slices.Clone(buf)}) {
+				return
+			}
+			lastEnd = n
+			next += slide
+			buf = buf[min(slide, len(buf)):]
+		}
+	}
+
+	if len(buf) > 0 && n > lastEnd {
+		emit(Window{Records: buf})
+	}
+}
+
+// timeWindower assigns timestamped records to time-based windows.
+type timeWindower struct {
+	spec      WindowSpec
+	slide     time.Duration
+	retention time.Duration
+	open      []*Window // sorted by start
+	closed    []*Window // complete windows retained for LateRecompute, sorted by start
+	maxTS     time.Time // the latest timestamp seen
+	seenTS    bool
+}
+
+func timeWindows(values iter.Seq2[Pointer, any], spec WindowSpec, emit func(Window) bool) error {
+	tw := &timeWindower{spec: spec, slide: spec.Every, retention: spec.Retention}
+	if tw.slide <= 0 {
+		tw.slide = spec.Duration
+	}
+	if tw.retention <= 0 {
+		tw.retention = spec.Duration
+	}
+
+	for pointer, val := range values {
+		if len(pointer) > 0 {
+			continue
+		}
+		ts, err := timestampAt(val, spec.Timestamp)
+		if err != nil {
+			return err
+		}
+
+		if !tw.seenTS || ts.After(tw.maxTS) {
+			tw.maxTS, tw.seenTS = ts, true
+		}
+		if !tw.closeThrough(tw.watermark(), emit) {
+			return nil
+		}
+		if missed := tw.add(ts, val); len(missed) > 0 && !tw.late(ts, val, missed, emit) {
+			return nil
+		}
+	}
+
+	for _, w := range tw.open {
+		if !emit(*w) {
+			break
+		}
+	}
+	return nil
+}
+
+// watermark is the time through which windows are complete.
+func (tw *timeWindower) watermark() time.Time {
+	return tw.maxTS.Add(-tw.spec.Lateness)
+}
+
+// closeThrough produces the open windows that end at or before t.
+func (tw *timeWindower) closeThrough(t time.Time, emit func(Window) bool) bool {
+	for len(tw.open) > 0 && !tw.open[0].End.After(t) {
+		w := tw.open[0]
+		tw.open = tw.open[1:]
+		if !emit(*w) {
+			return false
+		}
+		if tw.spec.Late == LateRecompute {
+			tw.closed = append(tw.closed, w)
+		}
+	}
+
+	// Forget complete windows past their retention.
+	for len(tw.closed) > 0 && !tw.closed[0].End.Add(tw.retention).After(t) {
+		tw.closed = tw.closed[1:]
+	}
+	return true
+}
+
+// add adds val, with timestamp ts,
+// to each of the windows containing ts that are not yet complete.
+// It returns the starts of the complete windows containing ts.
+func (tw *timeWindower) add(ts time.Time, val any) []time.Time {
+	var (
+		watermark = tw.watermark()
+		missed    []time.Time
+	)
+	for start := tw.lastStart(ts); start.Add(tw.spec.Duration).After(ts); start = start.Add(-tw.slide) {
+		end := start.Add(tw.spec.Duration)
+		if !end.After(watermark) {
+			missed = append(missed, start)
+			continue
+		}
+		i, found := slices.BinarySearchFunc(tw.open, start, compareWindowStart)
+		if !found {
+			tw.open = slices.Insert(tw.open, i, &Window{Start: start, End: end})
+		}
+		tw.open[i].Records = append(tw.open[i].Records, val)
+	}
+	return missed
+}
+
+// late handles a late record,
+// which missed the complete windows starting at the given times,
+// according to the late-record policy.
+func (tw *timeWindower) late(ts time.Time, val any, missed []time.Time, emit func(Window) bool) bool {
+	switch tw.spec.Late {
+	case LateSideOutput:
+		if tw.spec.OnLate != nil {
+			tw.spec.OnLate(val, ts)
+		}
+
+	case LateRecompute:
+		slices.SortFunc(missed, time.Time.Compare)
+		for _, start := range missed {
+			i, found := slices.BinarySearchFunc(tw.closed, start, compareWindowStart)
+			if !found {
+				continue // beyond retention
+			}
+			w := tw.closed[i]
+			w.Records = append(w.Records, val)
+			w.Revision++
+			if !emit(*w) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func compareWindowStart(w *Window, t time.Time) int {
+	return w.Start.Compare(t)
+}
+
+// lastStart is the start of the latest window containing ts.
+func (tw *timeWindower) lastStart(ts time.Time) time.Time {
+	ns, slide := ts.UnixNano(), int64(tw.slide)
+	rem := ns % slide
+	if rem < 0 {
+		rem += slide
+	}
+	return time.Unix(0, ns-rem).UTC()
+}
+
+// timestampAt interprets the value at ptr in rec as a timestamp:
+// a string in RFC 3339 format,
+// or a number of seconds since the Unix epoch.
+func timestampAt(rec any, ptr Pointer) (time.Time, error) {
+	val, err := ptr.Locate(rec)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "locating timestamp at %q", ptr.Text())
+	}
+	switch val := decodedForm(val).(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, val)
+		return t, errors.Wrapf(err, "parsing timestamp at %q", ptr.Text())
+	case Number:
+		secs := val.Float()
+		return time.Unix(0, int64(secs*float64(time.Second))).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("%s at %q is not a timestamp", TypeName(val), ptr.Text())
+}
@@ -0,0 +1,221 @@
+package jseq_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobg/jseq"
+)
+
+func TestCountWindows(t *testing.T) {
+	cases := []struct {
+		size, slide int
+		want        [][]int64
+	}{
+		{size: 3, want: [][]int64{{0, 1, 2}, {3, 4, 5}, {6}}},
+		{size: 3, slide: 2, want: [][]int64{{0, 1, 2}, {2, 3, 4}, {4, 5, 6}}},
+		{size: 3, slide: 1, want: [][]int64{{0, 1, 2}, {1, 2, 3}, {2, 3, 4}, {3, 4, 5}, {4, 5, 6}}},
+		{size: 2, slide: 3, want: [][]int64{{0, 1}, {3, 4}, {6}}},
+		{size: 10, want: [][]int64{{0, 1, 2, 3, 4, 5, 6}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("size_%d_slide_%d", tc.size, tc.slide), func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(`0 1 2 3 4 5 [6]`))
+			values, _ := jseq.Values(tokens)
+			windows, errptr := jseq.Windows(values, jseq.WindowSpec{Size: tc.size, Slide: tc.slide})
+
+			var got [][]int64
+			for w := range windows {
+				var ns []int64
+				for _, rec := range w.Records {
+					if a, ok := rec.([]any); ok {
+						rec = a[0]
+					}
+					n, _ := rec.(jseq.Number).Int()
+					ns = append(ns, n)
+				}
+				got = append(got, ns)
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTimeWindows(t *testing.T) {
+	const inp = `
+{"ts": "2024-01-01T00:00:05Z", "v": 1}
+{"ts": "2024-01-01T00:00:50Z", "v": 2}
+{"ts": 1704067270, "v": 3}
+{"ts": "2024-01-01T00:03:00Z", "v": 4}
+`
+
+	type window struct {
+		start, end string
+		sum        int64
+	}
+
+	cases := []struct {
+		name  string
+		every time.Duration
+		want  []window
+	}{{
+		name: "tumbling",
+		want: []window{
+			{"00:00:00", "00:01:00", 3},
+			{"00:01:00", "00:02:00", 3},
+			{"00:03:00", "00:04:00", 4},
+		},
+	}, {
+		name:  "sliding",
+		every: 30 * time.Second,
+		want: []window{
+			{"23:59:30", "00:00:30", 1},
+			{"00:00:00", "00:01:00", 3},
+			{"00:00:30", "00:01:30", 5},
+			{"00:01:00", "00:02:00", 3},
+			{"00:02:30", "00:03:30", 4},
+			{"00:03:00", "00:04:00", 4},
+		},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, _ := jseq.Tokens(strings.NewReader(inp))
+			values, _ := jseq.Values(tokens)
+			windows, errptr := jseq.Windows(values, jseq.WindowSpec{
+				Duration:  time.Minute,
+				Every:     tc.every,
+				Timestamp: jseq.Pointer{"ts"},
+				Aggregate: func(records []any) any {
+					var sum int64
+					for _, rec := range records {
+						n, _ := rec.(map[string]any)["v"].(jseq.Number).Int()
+						sum += n
+					}
+					return sum
+				},
+			})
+
+			var got []window
+			for w := range windows {
+				got = append(got, window{start: w.Start.Format(time.TimeOnly), end: w.End.Format(time.TimeOnly), sum: w.Value.(int64)})
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWindowErrors(t *testing.T) {
+	for _, inp := range []string{`{"ts": "yesterday"}`, `{"ts": true}`, `{}`} {
+		tokens, _ := jseq.Tokens(strings.NewReader(inp))
+		values, _ := jseq.Values(tokens)
+		windows, errptr := jseq.Windows(values, jseq.WindowSpec{Duration: time.Second, Timestamp: jseq.Pointer{"ts"}})
+		for range windows {
+		}
+		if *errptr == nil {
+			t.Errorf("%s: got no error", inp)
+		}
+	}
+
+	windows, errptr := jseq.Windows(func(func(jseq.Pointer, any) bool) {}, jseq.WindowSpec{})
+	for range windows {
+	}
+	if *errptr == nil {
+		t.Error("got no error for empty spec")
+	}
+}
+
+func TestWindowLateness(t *testing.T) {
+	// Records are out of order.
+	// The one at 00:00:58 is late by 4s, within 5s of lateness;
+	// the one at 00:00:10 is late by 55s.
+	const inp = `
+{"ts": "2024-01-01T00:00:05Z", "v": 1}
+{"ts": "2024-01-01T00:01:02Z", "v": 2}
+{"ts": "2024-01-01T00:00:58Z", "v": 4}
+{"ts": "2024-01-01T00:01:05Z", "v": 8}
+{"ts": "2024-01-01T00:00:10Z", "v": 16}
+{"ts": "2024-01-01T00:02:00Z", "v": 32}
+`
+
+	type window struct {
+		start    string
+		sum      int64
+		revision int
+	}
+
+	cases := []struct {
+		name     string
+		late     jseq.LatePolicy
+		want     []window
+		wantLate []int64
+	}{{
+		name: "drop",
+		late: jseq.LateDrop,
+		want: []window{{"00:00:00", 5, 0}, {"00:01:00", 10, 0}, {"00:02:00", 32, 0}},
+	}, {
+		name:     "side output",
+		late:     jseq.LateSideOutput,
+		want:     []window{{"00:00:00", 5, 0}, {"00:01:00", 10, 0}, {"00:02:00", 32, 0}},
+		wantLate: []int64{16},
+	}, {
+		name: "recompute",
+		late: jseq.LateRecompute,
+		want: []window{{"00:00:00", 5, 0}, {"00:00:00", 21, 1}, {"00:01:00", 10, 0}, {"00:02:00", 32, 0}},
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotLate []int64
+
+			tokens, _ := jseq.Tokens(strings.NewReader(inp))
+			values, _ := jseq.Values(tokens)
+			windows, errptr := jseq.Windows(values, jseq.WindowSpec{
+				Duration:  time.Minute,
+				Timestamp: jseq.Pointer{"ts"},
+				Lateness:  5 * time.Second,
+				Late:      tc.late,
+				OnLate: func(rec any, ts time.Time) {
+					n, _ := rec.(map[string]any)["v"].(jseq.Number).Int()
+					gotLate = append(gotLate, n)
+				},
+				Aggregate: func(records []any) any {
+					var sum int64
+					for _, rec := range records {
+						n, _ := rec.(map[string]any)["v"].(jseq.Number).Int()
+						sum += n
+					}
+					return sum
+				},
+			})
+
+			var got []window
+			for w := range windows {
+				got = append(got, window{start: w.Start.Format(time.TimeOnly), sum: w.Value.(int64), revision: w.Revision})
+			}
+			if err := *errptr; err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+			if !reflect.DeepEqual(gotLate, tc.wantLate) {
+				t.Errorf("got late records %v, want %v", gotLate, tc.wantLate)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package jseq
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Writer writes JSON imperatively:
+//
+//	w := jseq.NewWriter(os.Stdout)
+//	w.BeginObject()
+//	w.Key("a")
+//	w.Int(1)
+//	w.EndObject()
+//	if err := w.Close(); err != nil {
+//		...
+//	}
+//
+// It checks that calls are properly nested
+// and that object members are preceded by keys.
+// Multiple top-level values may be written,
+// each on its own line.
+//
+// Errors are sticky:
+// after the first error,
+// subsequent calls do nothing,
+// and the error is reported by [Writer.Err] and [Writer.Close].
+type Writer struct {
+	enc     *jsontext.Encoder
+	stack   []writerFrame
+	pointer Pointer
+	err     error
+}
+
+type writerFrame struct {
+	kind    jsontext.Kind // '{' or '['
+	haveKey bool          // for objects: whether a key has been written for the next member
+	n       int           // for arrays: number of elements written
+}
+
+// NewWriter creates a new [Writer] writing to w.
+// The options are passed to [jsontext.NewEncoder].
+func NewWriter(w io.Writer, opts ...jsontext.Options) *Writer {
+	return &Writer{enc: jsontext.NewEncoder(w, opts...)}
+}
+
+// Err returns the first error encountered by w, if any.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// Close reports the first error encountered by w, if any,
+// or an error if there are unclosed arrays or objects.
+// It does not close the underlying [io.Writer].
+func (w *Writer) Close() error {
+	if w.err == nil && len(w.stack) > 0 {
+		w.fail("%d unclosed array(s) or object(s)", len(w.stack))
+	}
+	return w.err
+}
+
+// BeginObject begins writing an object.
+func (w *Writer) BeginObject() {
+	if w.beginValue() {
+		w.write(jsontext.BeginObject)
+		w.stack = append(w.stack, writerFrame{kind: '{'})
+	}
+}
+
+// EndObject ends the object begun by the matching call to [Writer.BeginObject].
+func (w *Writer) EndObject() {
+	w.end('{', jsontext.EndObject)
+}
+
+// BeginArray begins writing an array.
+func (w *Writer) BeginArray() {
+	if w.beginValue() {
+		w.write(jsontext.BeginArray)
+		w.stack = append(w.stack, writerFrame{kind: '['})
+	}
+}
+
+// EndArray ends the array begun by the matching call to [Writer.BeginArray].
+func (w *Writer) EndArray() {
+	w.end('[', jsontext.EndArray)
+}
+
+// Key writes an object key.
+// It must be followed by a value.
+func (w *Writer) Key(key string) {
+	if w.err != nil {
+		return
+	}
+	if len(w.stack) == 0 || w.top().kind != '{' {
+		w.fail("key %q outside of object", key)
+		return
+	}
+	if w.top().haveKey {
+		w.fail("key %q follows another key", key)
+		return
+	}
+	w.write(jsontext.String(key))
+	w.top().haveKey = true
+	w.pointer = append(w.pointer, key)
+}
+
+// String writes a string value.
+func (w *Writer) String(s string) {
+	w.scalar(jsontext.String(s))
+}
+
+// Bool writes a boolean value.
+func (w *Writer) Bool(b bool) {
+	w.scalar(jsontext.Bool(b))
+}
+
+// Null writes a null value.
+func (w *Writer) Null() {
+	w.scalar(jsontext.Null)
+}
+
+// Int writes an integer value.
+func (w *Writer) Int(n int64) {
+	w.scalar(jsontext.Int(n))
+}
+
+// Uint writes an unsigned integer value.
+func (w *Writer) Uint(n uint64) {
+	w.scalar(jsontext.Uint(n))
+}
+
+// Float writes a floating-point value.
+func (w *Writer) Float(f float64) {
+	w.scalar(jsontext.Float(f))
+}
+
+// Value writes v, which may be any value accepted by [Encode].
+func (w *Writer) Value(v any) {
+	if w.beginValue() {
+		if err := encodeValue(w.enc, v); err != nil {
+			w.fail("%s", err)
+			return
+		}
+		w.endValue()
+	}
+}
+
+func (w *Writer) scalar(tok jsontext.Token) {
+	if w.beginValue() {
+		w.write(tok)
+		w.endValue()
+	}
+}
+
+// beginValue checks that a value may be written here.
+func (w *Writer) beginValue() bool {
+	if w.err != nil {
+		return false
+	}
+	if len(w.stack) == 0 {
+		return true
+	}
+	top := w.top()
+	switch top.kind {
+	case '{':
+		if !top.haveKey {
+			w.fail("object member without key")
+			return false
+		}
+	case '[':
+		w.pointer = append(w.pointer, top.n)
+	}
+	return true
+}
+
+// endValue updates the state after writing a complete value.
+func (w *Writer) endValue() {
+	if len(w.stack) == 0 {
+		return
+	}
+	top := w.top()
+	switch top.kind {
+	case '{':
+		top.haveKey = false
+	case '[':
+		top.n++
+	}
+	w.pointer = w.pointer[:len(w.pointer)-1]
+}
+
+func (w *Writer) end(kind jsontext.Kind, tok jsontext.Token) {
+	if w.err != nil {
+		return
+	}
+	if len(w.stack) == 0 || w.top().kind != kind {
+		w.fail("unexpected %s", tok.Kind())
+		return
+	}
+	if w.top().haveKey {
+		w.fail("key without value")
+		return
+	}
+	w.write(tok)
+	w.stack = w.stack[:len(w.stack)-1]
+	w.endValue()
+}
+
+func (w *Writer) top() *writerFrame {
+	return &w.stack[len(w.stack)-1]
+}
+
+func (w *Writer) write(tok jsontext.Token) {
+	if err := w.enc.WriteToken(tok); err != nil {
+		w.fail("%s", err)
+	}
+}
+
+func (w *Writer) fail(format string, args ...any) {
+	w.err = fmt.Errorf("at %q: %s", slices.Clone(w.pointer).Text(), fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,90 @@
+package jseq_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bobg/jseq"
+)
+
+func TestWriter(t *testing.T) {
+	buf := new(strings.Builder)
+	w := jseq.NewWriter(buf)
+
+	w.BeginObject()
+	w.Key("a")
+	w.Int(1)
+	w.Key("b")
+	w.BeginArray()
+	w.String("x")
+	w.Null()
+	w.Value(map[string]any{"c": jseq.Float(1.5)})
+	w.EndArray()
+	w.EndObject()
+	w.Bool(true)
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	const want = "{\"a\":1,\"b\":[\"x\",null,{\"c\":1.5}]}\ntrue\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		f    func(*jseq.Writer)
+		want string
+	}{{
+		name: "key_outside_object",
+		f: func(w *jseq.Writer) {
+			w.BeginArray()
+			w.Key("a")
+		},
+		want: `at "": key "a" outside of object`,
+	}, {
+		name: "member_without_key",
+		f: func(w *jseq.Writer) {
+			w.BeginObject()
+			w.Key("a")
+			w.BeginArray()
+			w.EndArray()
+			w.Int(1)
+		},
+		want: `at "": object member without key`,
+	}, {
+		name: "mismatched_end",
+		f: func(w *jseq.Writer) {
+			w.BeginObject()
+			w.Key("a")
+			w.BeginArray()
+			w.Int(7)
+			w.EndObject()
+		},
+		want: `at "/a": unexpected }`,
+	}, {
+		name: "unclosed",
+		f: func(w *jseq.Writer) {
+			w.BeginObject()
+			w.Key("a")
+			w.BeginObject()
+		},
+		want: `at "/a": 2 unclosed array(s) or object(s)`,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := jseq.NewWriter(new(strings.Builder))
+			tc.f(w)
+			err := w.Close()
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if err.Error() != tc.want {
+				t.Errorf("got error %q, want %q", err, tc.want)
+			}
+		})
+	}
+}